@@ -0,0 +1,378 @@
+package sctp_go
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/thebagchi/sctp-go/packet"
+)
+
+// pcapng block types, options and the link type of raw IP packets.
+const (
+	pcapngSectionHeader   = 0x0a0d0d0a
+	pcapngInterface       = 0x00000001
+	pcapngEnhancedPacket  = 0x00000006
+	pcapngByteOrderMagic  = 0x1a2b3c4d
+	pcapngOptionEnd       = 0
+	pcapngOptionComment   = 1
+	pcapngInterfaceName   = 2
+	pcapngPacketFlags     = 2
+	pcapngFlagInbound     = 1
+	pcapngFlagOutbound    = 2
+	linkTypeRaw           = 101
+	captureHopLimit       = 64
+	captureFragmentLength = 0xffff - 40 - packet.CommonHeaderSize - 16
+)
+
+// Capture writes the messages sent and received by the connections and listeners it is set
+// on to a pcapng stream readable by Wireshark. Each message is synthesized as SCTP DATA
+// chunks in an IP packet between the local and peer addresses, carrying the stream, stream
+// sequence number, PPID and unordered flag of the message. The verification tag of the
+// packets is the association id, which is also set as the packet comment. Messages larger
+// than an IP packet are split into fragments. Notifications are not captured.
+//
+// The capture reflects the messages as seen by the application, not the packets on the wire:
+// TSNs are numbered per association and direction by the capture and chunks are never
+// bundled or retransmitted.
+type Capture struct {
+	mutex  sync.Mutex
+	writer io.Writer
+	err    error
+	closed bool
+	tsns   map[captureFlow]uint32
+	now    func() time.Time
+}
+
+// captureFlow identifies the TSN sequence of an association in one direction.
+type captureFlow struct {
+	assoc    int32
+	outbound bool
+}
+
+// NewCapture writes the pcapng section and interface headers to w and returns a capture
+// writing packets to it. Writes are serialized, so a capture can be shared by connections.
+func NewCapture(w io.Writer) (*Capture, error) {
+	capture := &Capture{
+		writer: w,
+		tsns:   make(map[captureFlow]uint32),
+		now:    time.Now,
+	}
+	section := make([]byte, 16)
+	endian.PutUint32(section, pcapngByteOrderMagic)
+	endian.PutUint16(section[4:], 1)
+	endian.PutUint16(section[6:], 0)
+	endian.PutUint64(section[8:], 0xffffffffffffffff)
+	iface := make([]byte, 8)
+	endian.PutUint16(iface, linkTypeRaw)
+	iface = appendOption(iface, pcapngInterfaceName, []byte("sctp-go"))
+	for _, block := range []struct {
+		kind uint32
+		body []byte
+	}{
+		{pcapngSectionHeader, appendOption(section, pcapngOptionEnd, nil)},
+		{pcapngInterface, appendOption(iface, pcapngOptionEnd, nil)},
+	} {
+		if _, err := w.Write(appendBlock(nil, block.kind, block.body)); err != nil {
+			return nil, err
+		}
+	}
+	return capture, nil
+}
+
+// Err returns the first error writing to the capture, after which packets are dropped.
+func (capture *Capture) Err() error {
+	capture.mutex.Lock()
+	defer capture.mutex.Unlock()
+	return capture.err
+}
+
+// Close stops the capture and closes its writer when it is an io.Closer. It returns the
+// first error writing to the capture.
+func (capture *Capture) Close() error {
+	capture.mutex.Lock()
+	defer capture.mutex.Unlock()
+	if capture.closed {
+		return capture.err
+	}
+	capture.closed = true
+	if closer, ok := capture.writer.(io.Closer); ok {
+		if err := closer.Close(); err != nil && capture.err == nil {
+			capture.err = err
+		}
+	}
+	return capture.err
+}
+
+// record writes a message sent to or received from remote. info may be nil when the message
+// was sent without send parameters, assoc is then used as association id.
+func (capture *Capture) record(outbound bool, local, remote *SCTPAddr, assoc int, info *SCTPSndRcvInfo, data []byte) {
+	if capture == nil {
+		return
+	}
+	var chunk packet.Data
+	if info != nil {
+		if info.AssocId != 0 {
+			assoc = int(info.AssocId)
+		}
+		chunk.StreamID = info.Stream
+		chunk.StreamSeq = info.Ssn
+		chunk.PPID = NetworkToHost(info.Ppid)
+		chunk.Unordered = info.Flags&SCTP_UNORDERED != 0
+	}
+	src, dst := captureEndpoints(local, remote)
+	if !outbound {
+		src, dst = dst, src
+	}
+	pkt := packet.Packet{
+		SourcePort:      uint16(src.port),
+		DestinationPort: uint16(dst.port),
+		VerificationTag: uint32(assoc),
+		Chunks:          []packet.Chunk{&chunk},
+	}
+	comment := []byte(fmt.Sprintf("assoc_id=%d", assoc))
+	flags := uint32(pcapngFlagInbound)
+	if outbound {
+		flags = pcapngFlagOutbound
+	}
+
+	capture.mutex.Lock()
+	defer capture.mutex.Unlock()
+	if capture.err != nil || capture.closed {
+		return
+	}
+	flow := captureFlow{assoc: int32(assoc), outbound: outbound}
+	timestamp := uint64(capture.now().UnixMicro())
+	for offset := 0; offset == 0 || offset < len(data); offset += captureFragmentLength {
+		end := min(offset+captureFragmentLength, len(data))
+		capture.tsns[flow]++
+		chunk.TSN = capture.tsns[flow]
+		chunk.Beginning = offset == 0
+		chunk.Ending = end == len(data)
+		chunk.UserData = data[offset:end]
+		payload, err := pkt.Marshal()
+		if err != nil {
+			capture.err = err
+			return
+		}
+		ip := captureIP(src.ip, dst.ip, payload)
+		body := make([]byte, 20, 20+len(ip)+32)
+		endian.PutUint32(body, 0)
+		endian.PutUint32(body[4:], uint32(timestamp>>32))
+		endian.PutUint32(body[8:], uint32(timestamp))
+		endian.PutUint32(body[12:], uint32(len(ip)))
+		endian.PutUint32(body[16:], uint32(len(ip)))
+		body = pad4(append(body, ip...))
+		body = appendOption(body, pcapngOptionComment, comment)
+		body = appendOption(body, pcapngPacketFlags, appendUint32(nil, flags))
+		body = appendOption(body, pcapngOptionEnd, nil)
+		if _, err := capture.writer.Write(appendBlock(nil, pcapngEnhancedPacket, body)); err != nil {
+			capture.err = err
+			return
+		}
+	}
+}
+
+// captureEndpoint is an address and port of a synthesized packet.
+type captureEndpoint struct {
+	ip   net.IP
+	port int
+}
+
+// captureEndpoints picks a local and a peer address of the same family for the synthesized
+// packets, the loopback address stands in for unknown addresses.
+func captureEndpoints(local, remote *SCTPAddr) (captureEndpoint, captureEndpoint) {
+	var l, r captureEndpoint
+	if remote != nil {
+		r.port = remote.port
+		if len(remote.addresses) > 0 {
+			r.ip = remote.addresses[0]
+		}
+	}
+	if local != nil {
+		l.port = local.port
+		for _, address := range local.addresses {
+			if r.ip == nil || (address.To4() == nil) == (r.ip.To4() == nil) {
+				l.ip = address
+				break
+			}
+		}
+	}
+	loopback := net.IPv4(127, 0, 0, 1)
+	if (r.ip != nil && r.ip.To4() == nil) || (r.ip == nil && l.ip != nil && l.ip.To4() == nil) {
+		loopback = net.IPv6loopback
+	}
+	if l.ip == nil {
+		l.ip = loopback
+	}
+	if r.ip == nil {
+		r.ip = loopback
+	}
+	return l, r
+}
+
+// captureIP returns an IPv4 or IPv6 packet from src to dst carrying the SCTP packet.
+func captureIP(src, dst net.IP, payload []byte) []byte {
+	if src4, dst4 := src.To4(), dst.To4(); src4 != nil && dst4 != nil {
+		header := make([]byte, 20, 20+len(payload))
+		header[0] = 0x45
+		binary.BigEndian.PutUint16(header[2:], uint16(20+len(payload)))
+		binary.BigEndian.PutUint16(header[6:], 0x4000)
+		header[8] = captureHopLimit
+		header[9] = syscall.IPPROTO_SCTP
+		copy(header[12:], src4)
+		copy(header[16:], dst4)
+		var sum uint32
+		for i := 0; i < 20; i += 2 {
+			sum += uint32(binary.BigEndian.Uint16(header[i:]))
+		}
+		for sum > 0xffff {
+			sum = sum&0xffff + sum>>16
+		}
+		binary.BigEndian.PutUint16(header[10:], ^uint16(sum))
+		return append(header, payload...)
+	}
+	header := make([]byte, 40, 40+len(payload))
+	header[0] = 0x60
+	binary.BigEndian.PutUint16(header[4:], uint16(len(payload)))
+	header[6] = syscall.IPPROTO_SCTP
+	header[7] = captureHopLimit
+	copy(header[8:], src.To16())
+	copy(header[24:], dst.To16())
+	return append(header, payload...)
+}
+
+// appendBlock appends a pcapng block, the body must be padded to 4 bytes.
+func appendBlock(buffer []byte, kind uint32, body []byte) []byte {
+	length := uint32(12 + len(body))
+	buffer = appendUint32(buffer, kind)
+	buffer = appendUint32(buffer, length)
+	buffer = append(buffer, body...)
+	return appendUint32(buffer, length)
+}
+
+// appendOption appends a pcapng option padded to 4 bytes.
+func appendOption(buffer []byte, code uint16, value []byte) []byte {
+	var header [4]byte
+	endian.PutUint16(header[:], code)
+	endian.PutUint16(header[2:], uint16(len(value)))
+	return pad4(append(append(buffer, header[:]...), value...))
+}
+
+// appendUint32 appends a 32-bit integer in host byte order.
+func appendUint32(buffer []byte, value uint32) []byte {
+	var b [4]byte
+	endian.PutUint32(b[:], value)
+	return append(buffer, b[:]...)
+}
+
+// pad4 appends zero bytes up to a multiple of 4 bytes.
+func pad4(buffer []byte) []byte {
+	for len(buffer)%4 != 0 {
+		buffer = append(buffer, 0)
+	}
+	return buffer
+}
+
+// captureHook is the capture of a connection or listener with the addresses of its packets,
+// remote is only known for connections.
+type captureHook struct {
+	capture *Capture
+	local   *SCTPAddr
+	remote  *SCTPAddr
+}
+
+// SetCapture writes the messages sent and received on the connection to capture, nil stops
+// capturing. The addresses of the packets are those of the connection when it is set.
+func (conn *SCTPConn) SetCapture(capture *Capture) {
+	if capture == nil {
+		conn.capture.Store(nil)
+		return
+	}
+	local, _ := conn.LocalAddr().(*SCTPAddr)
+	remote, _ := conn.RemoteAddr().(*SCTPAddr)
+	conn.capture.Store(&captureHook{capture: capture, local: local, remote: remote})
+}
+
+// captured writes a message of the connection to its capture, if any.
+func (conn *SCTPConn) captured(outbound bool, info *SCTPSndRcvInfo, data []byte) {
+	if hook := conn.capture.Load(); hook != nil {
+		hook.capture.record(outbound, hook.local, hook.remote, conn.assoc, info, data)
+	}
+}
+
+// SetCapture writes the messages sent and received on the listener to capture, nil stops
+// capturing. The peer of each packet is resolved from the association of the message.
+func (listener *SCTPListener) SetCapture(capture *Capture) {
+	if capture == nil {
+		listener.capture.Store(nil)
+		return
+	}
+	local, _ := listener.Addr().(*SCTPAddr)
+	listener.capture.Store(&captureHook{capture: capture, local: local})
+}
+
+// captured writes a message of the listener to its capture, if any. remote is looked up from
+// the association of info when nil.
+func (listener *SCTPListener) captured(outbound bool, remote *SCTPAddr, info *SCTPSndRcvInfo, data []byte) {
+	hook := listener.capture.Load()
+	if hook == nil {
+		return
+	}
+	assoc := 0
+	if info != nil {
+		assoc = int(info.AssocId)
+	}
+	if remote == nil && assoc != 0 {
+		if association, ok := listener.assocs.lookup(assoc); ok && association.PeerAddr != nil {
+			remote = association.PeerAddr
+		} else {
+			remote = listener.peerAddr(assoc)
+		}
+	}
+	hook.capture.record(outbound, hook.local, remote, assoc, info, data)
+}
+
+// gather returns the first n bytes of buffers as a single slice.
+func gather(buffers net.Buffers, n int) []byte {
+	data := make([]byte, 0, n)
+	for _, buffer := range buffers {
+		if len(data)+len(buffer) >= n {
+			return append(data, buffer[:n-len(data)]...)
+		}
+		data = append(data, buffer...)
+	}
+	return data
+}
+
+// captureRecv wraps h to write the messages received through an engine to the capture of
+// the connection.
+func (conn *SCTPConn) captureRecv(h RecvHandler) RecvHandler {
+	if conn.capture.Load() == nil {
+		return h
+	}
+	return func(b []byte, info *SCTPSndRcvInfo, flags int, err error) {
+		if err == nil && flags&SCTP_MSG_NOTIFICATION == 0 {
+			conn.captured(false, info, b)
+		}
+		h(b, info, flags, err)
+	}
+}
+
+// captureSend wraps h to write the message sent through an engine to the capture of the
+// connection.
+func (conn *SCTPConn) captureSend(b []byte, info *SCTPSndRcvInfo, h SendHandler) SendHandler {
+	if conn.capture.Load() == nil {
+		return h
+	}
+	return func(n int, err error) {
+		if err == nil {
+			conn.captured(true, info, b[:n])
+		}
+		h(n, err)
+	}
+}
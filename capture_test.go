@@ -0,0 +1,270 @@
+package sctp_go
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/thebagchi/sctp-go/packet"
+)
+
+// capturedPacket is an enhanced packet block read back from a capture.
+type capturedPacket struct {
+	timestamp uint64
+	flags     uint32
+	comment   string
+	ip        []byte
+}
+
+// readCapture parses the pcapng stream written by a capture.
+func readCapture(t *testing.T, data []byte) []capturedPacket {
+	t.Helper()
+	var packets []capturedPacket
+	for len(data) > 0 {
+		if len(data) < 12 {
+			t.Fatalf("truncated block % x", data)
+		}
+		kind, length := endian.Uint32(data), int(endian.Uint32(data[4:]))
+		if length%4 != 0 || length > len(data) || endian.Uint32(data[length-4:]) != uint32(length) {
+			t.Fatalf("invalid block length %d", length)
+		}
+		body := data[8 : length-4]
+		data = data[length:]
+		switch kind {
+		case pcapngSectionHeader:
+			if endian.Uint32(body) != pcapngByteOrderMagic || endian.Uint16(body[4:]) != 1 {
+				t.Fatalf("unexpected section header % x", body)
+			}
+		case pcapngInterface:
+			if endian.Uint16(body) != linkTypeRaw {
+				t.Fatalf("unexpected link type %d", endian.Uint16(body))
+			}
+		case pcapngEnhancedPacket:
+			captured := int(endian.Uint32(body[12:]))
+			p := capturedPacket{
+				timestamp: uint64(endian.Uint32(body[4:]))<<32 | uint64(endian.Uint32(body[8:])),
+				ip:        body[20 : 20+captured],
+			}
+			for options := body[20+(captured+3)&^3:]; len(options) >= 4; {
+				code, size := endian.Uint16(options), int(endian.Uint16(options[2:]))
+				value := options[4 : 4+size]
+				switch code {
+				case pcapngOptionComment:
+					p.comment = string(value)
+				case pcapngPacketFlags:
+					p.flags = endian.Uint32(value)
+				}
+				options = options[4+(size+3)&^3:]
+			}
+			packets = append(packets, p)
+		default:
+			t.Fatalf("unexpected block type %#x", kind)
+		}
+	}
+	return packets
+}
+
+// sctpPayload checks the IP header of a captured packet and decodes the SCTP packet.
+func sctpPayload(t *testing.T, ip []byte) (net.IP, net.IP, *packet.Packet) {
+	t.Helper()
+	var src, dst net.IP
+	switch ip[0] >> 4 {
+	case 4:
+		var sum uint32
+		for i := 0; i < 20; i += 2 {
+			sum += uint32(binary.BigEndian.Uint16(ip[i:]))
+		}
+		for sum > 0xffff {
+			sum = sum&0xffff + sum>>16
+		}
+		if sum != 0xffff || ip[9] != syscall.IPPROTO_SCTP || int(binary.BigEndian.Uint16(ip[2:])) != len(ip) {
+			t.Fatalf("invalid ipv4 header % x", ip[:20])
+		}
+		src, dst, ip = ip[12:16], ip[16:20], ip[20:]
+	case 6:
+		if ip[6] != syscall.IPPROTO_SCTP || int(binary.BigEndian.Uint16(ip[4:])) != len(ip)-40 {
+			t.Fatalf("invalid ipv6 header % x", ip[:40])
+		}
+		src, dst, ip = ip[8:24], ip[24:40], ip[40:]
+	default:
+		t.Fatalf("invalid ip version %d", ip[0]>>4)
+	}
+	var p packet.Packet
+	if err := p.Unmarshal(ip); err != nil {
+		t.Fatal(err)
+	}
+	return src, dst, &p
+}
+
+func TestCapture(t *testing.T) {
+	for _, test := range byteOrders {
+		t.Run(test.name, func(t *testing.T) {
+			withByteOrder(t, test.order)
+			var buffer bytes.Buffer
+			capture, err := NewCapture(&buffer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			capture.now = func() time.Time { return time.UnixMicro(0x123456789) }
+			var (
+				local  = &SCTPAddr{port: 36412, addresses: []net.IP{net.ParseIP("2001:db8::1"), net.IPv4(10, 0, 0, 1)}}
+				remote = &SCTPAddr{port: 5000, addresses: []net.IP{net.IPv4(10, 0, 0, 2)}}
+				info   = &SCTPSndRcvInfo{Stream: 3, Ssn: 9, Flags: SCTP_UNORDERED, Ppid: HostToNetwork(18), AssocId: 7}
+				large  = bytes.Repeat([]byte{0x5a}, captureFragmentLength+10)
+			)
+			capture.record(true, local, remote, 0, info, []byte("s1ap"))
+			capture.record(false, local, remote, 0, info, []byte("reply"))
+			capture.record(true, local, remote, 0, info, large)
+			capture.record(true, &SCTPAddr{port: 1, addresses: []net.IP{net.IPv6loopback}}, nil, 4, nil, []byte("v6"))
+			packets := readCapture(t, buffer.Bytes())
+			if len(packets) != 5 {
+				t.Fatalf("captured %d packets", len(packets))
+			}
+
+			tests := []struct {
+				src, dst   string
+				flags      uint32
+				comment    string
+				tsn        uint32
+				begin, end bool
+				data       []byte
+			}{
+				{"10.0.0.1:36412", "10.0.0.2:5000", pcapngFlagOutbound, "assoc_id=7", 1, true, true, []byte("s1ap")},
+				{"10.0.0.2:5000", "10.0.0.1:36412", pcapngFlagInbound, "assoc_id=7", 1, true, true, []byte("reply")},
+				{"10.0.0.1:36412", "10.0.0.2:5000", pcapngFlagOutbound, "assoc_id=7", 2, true, false, large[:captureFragmentLength]},
+				{"10.0.0.1:36412", "10.0.0.2:5000", pcapngFlagOutbound, "assoc_id=7", 3, false, true, large[captureFragmentLength:]},
+				{"[::1]:1", "[::1]:0", pcapngFlagOutbound, "assoc_id=4", 1, true, true, []byte("v6")},
+			}
+			for i, expected := range tests {
+				p := packets[i]
+				if p.timestamp != 0x123456789 || p.flags != expected.flags || p.comment != expected.comment {
+					t.Errorf("packet %d: timestamp %#x, flags %d, comment %q", i, p.timestamp, p.flags, p.comment)
+				}
+				src, dst, sctp := sctpPayload(t, p.ip)
+				if got := net.JoinHostPort(src.String(), strconv.Itoa(int(sctp.SourcePort))); got != expected.src {
+					t.Errorf("packet %d: source %s, expected %s", i, got, expected.src)
+				}
+				if got := net.JoinHostPort(dst.String(), strconv.Itoa(int(sctp.DestinationPort))); got != expected.dst {
+					t.Errorf("packet %d: destination %s, expected %s", i, got, expected.dst)
+				}
+				if len(sctp.Chunks) != 1 {
+					t.Fatalf("packet %d: %d chunks", i, len(sctp.Chunks))
+				}
+				data, ok := sctp.Chunks[0].(*packet.Data)
+				if !ok {
+					t.Fatalf("packet %d: unexpected chunk %v", i, sctp.Chunks[0].Type())
+				}
+				if data.TSN != expected.tsn || data.Beginning != expected.begin || data.Ending != expected.end ||
+					!bytes.Equal(data.UserData, expected.data) {
+					t.Errorf("packet %d: tsn %d, beginning %v, ending %v, %d bytes", i, data.TSN, data.Beginning, data.Ending, len(data.UserData))
+				}
+				if i < 4 && (data.StreamID != 3 || data.StreamSeq != 9 || data.PPID != 18 || !data.Unordered ||
+					sctp.VerificationTag != 7) {
+					t.Errorf("packet %d: unexpected chunk %+v, tag %d", i, data, sctp.VerificationTag)
+				}
+			}
+		})
+	}
+}
+
+type failingWriter struct {
+	writes int
+	closed bool
+}
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+	w.writes++
+	if w.writes > 2 {
+		return 0, syscall.ENOSPC
+	}
+	return len(b), nil
+}
+
+func (w *failingWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestCaptureErrors(t *testing.T) {
+	w := &failingWriter{}
+	capture, err := NewCapture(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	capture.record(true, nil, nil, 1, nil, []byte("lost"))
+	capture.record(true, nil, nil, 1, nil, []byte("dropped"))
+	if w.writes != 3 || !errors.Is(capture.Err(), syscall.ENOSPC) {
+		t.Errorf("%d writes, error %v", w.writes, capture.Err())
+	}
+	if err := capture.Close(); !errors.Is(err, syscall.ENOSPC) || !w.closed {
+		t.Errorf("close: %v, closed %v", err, w.closed)
+	}
+
+	var buffer bytes.Buffer
+	capture, _ = NewCapture(&buffer)
+	size := buffer.Len()
+	if err := capture.Close(); err != nil {
+		t.Fatal(err)
+	}
+	capture.record(true, nil, nil, 1, nil, []byte("closed"))
+	if buffer.Len() != size {
+		t.Errorf("captured after close")
+	}
+}
+
+func TestConnCapture(t *testing.T) {
+	conn, peer := socketpair(t)
+	var buffer bytes.Buffer
+	capture, err := NewCapture(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetCapture(capture)
+	if _, err := conn.SendMsg([]byte("request"), &SCTPSndRcvInfo{Stream: 1, Ppid: HostToNetwork(60)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.SendMsgv(net.Buffers{[]byte("head"), []byte("er")}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := syscall.Write(peer, []byte("response")); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 64)
+	if _, err := conn.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	conn.SetCapture(nil)
+	if _, err := conn.Write([]byte("uncaptured")); err != nil {
+		t.Fatal(err)
+	}
+
+	packets := readCapture(t, buffer.Bytes())
+	expected := []struct {
+		flags uint32
+		data  string
+		ppid  uint32
+	}{
+		{pcapngFlagOutbound, "request", 60},
+		{pcapngFlagOutbound, "header", 0},
+		{pcapngFlagInbound, "response", 0},
+	}
+	if len(packets) != len(expected) {
+		t.Fatalf("captured %d packets", len(packets))
+	}
+	for i, p := range packets {
+		_, _, sctp := sctpPayload(t, p.ip)
+		data := sctp.Chunks[0].(*packet.Data)
+		if p.flags != expected[i].flags || string(data.UserData) != expected[i].data || data.PPID != expected[i].ppid {
+			t.Errorf("packet %d: flags %d, %q, ppid %d", i, p.flags, data.UserData, data.PPID)
+		}
+		if !strings.HasPrefix(p.comment, "assoc_id=") {
+			t.Errorf("packet %d: comment %q", i, p.comment)
+		}
+	}
+}
@@ -0,0 +1,413 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ctype is a C type as declared in the headers. Scalars are kept by name as their width
+// depends on the architecture the layout is computed for.
+type ctype struct {
+	scalar  string
+	pointer bool
+	elem    *ctype
+	count   int // -1 for a flexible array member
+	record  *crecord
+}
+
+// crecord is a C struct or union.
+type crecord struct {
+	name    string
+	union   bool
+	packed  bool
+	aligned int
+	members []cmember
+}
+
+// cmember is a member of a record, anonymous records have an empty name.
+type cmember struct {
+	name string
+	typ  *ctype
+}
+
+// cheader holds the records and typedefs of the parsed headers.
+type cheader struct {
+	records  map[string]*crecord
+	typedefs map[string]*ctype
+	tokens   []string
+	pos      int
+}
+
+// scalars are the C scalar types understood by the parser, see ctype.
+var scalars = map[string]string{
+	"char":               "u8",
+	"signed char":        "u8",
+	"unsigned char":      "u8",
+	"__u8":               "u8",
+	"__s8":               "u8",
+	"uint8_t":            "u8",
+	"short":              "u16",
+	"unsigned short":     "u16",
+	"__u16":              "u16",
+	"__s16":              "u16",
+	"__be16":             "u16",
+	"uint16_t":           "u16",
+	"int":                "u32",
+	"unsigned":           "u32",
+	"unsigned int":       "u32",
+	"__u32":              "u32",
+	"__s32":              "u32",
+	"__be32":             "u32",
+	"uint32_t":           "u32",
+	"__u64":              "u64",
+	"__s64":              "u64",
+	"uint64_t":           "u64",
+	"long long":          "u64",
+	"unsigned long long": "u64",
+	"long":               "word",
+	"unsigned long":      "word",
+	"size_t":             "word",
+	"__kernel_size_t":    "word",
+}
+
+// parseHeaders parses the headers in order, later headers may use the types of earlier ones.
+func parseHeaders(paths ...string) (*cheader, error) {
+	h := &cheader{
+		records:  make(map[string]*crecord),
+		typedefs: make(map[string]*ctype),
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		h.tokens = tokenize(string(data))
+		h.pos = 0
+		if err := h.parse(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return h, nil
+}
+
+// tokenize splits C source into tokens, dropping comments and preprocessor lines.
+func tokenize(src string) []string {
+	var (
+		tokens []string
+		lines  []string
+	)
+	for len(src) > 0 {
+		if i := strings.Index(src, "/*"); i >= 0 {
+			j := strings.Index(src[i+2:], "*/")
+			if j < 0 {
+				src = src[:i]
+				break
+			}
+			lines = append(lines, src[:i])
+			src = " " + src[i+2+j+2:]
+			continue
+		}
+		lines = append(lines, src)
+		break
+	}
+	src = strings.Join(lines, "")
+	var code []string
+	continued := false
+	for _, line := range strings.Split(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		directive := continued || strings.HasPrefix(trimmed, "#")
+		continued = directive && strings.HasSuffix(trimmed, "\\")
+		if directive {
+			continue
+		}
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		code = append(code, line)
+	}
+	src = strings.Join(code, "\n")
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c):
+			j := i
+			for j < len(src) && (src[j] == '_' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			tokens = append(tokens, src[i:j])
+			i = j
+		default:
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	return tokens
+}
+
+func (h *cheader) peek() string {
+	if h.pos < len(h.tokens) {
+		return h.tokens[h.pos]
+	}
+	return ""
+}
+
+func (h *cheader) next() string {
+	t := h.peek()
+	h.pos++
+	return t
+}
+
+func (h *cheader) expect(t string) error {
+	if got := h.next(); got != t {
+		return fmt.Errorf("expected %q, got %q at token %d", t, got, h.pos-1)
+	}
+	return nil
+}
+
+// skip skips tokens up to and including the end token at nesting depth zero, for a closing
+// bracket that is the one closing the first opened.
+func (h *cheader) skip(end string) {
+	depth := 0
+	for h.pos < len(h.tokens) {
+		t := h.next()
+		switch t {
+		case "{", "(":
+			depth++
+		case "}", ")":
+			depth--
+		}
+		if depth == 0 && t == end {
+			return
+		}
+	}
+}
+
+func (h *cheader) parse() error {
+	for h.pos < len(h.tokens) {
+		switch h.peek() {
+		case "typedef":
+			h.next()
+			base, err := h.specifier()
+			if err != nil {
+				return err
+			}
+			typ, name, err := h.declarator(base)
+			if err != nil {
+				return err
+			}
+			h.typedefs[name] = typ
+			if err := h.expect(";"); err != nil {
+				return err
+			}
+		case "struct", "union", "enum":
+			if _, err := h.specifier(); err != nil {
+				return err
+			}
+			if h.peek() != ";" {
+				h.skip(";")
+				continue
+			}
+			h.next()
+		default:
+			h.skip(";")
+		}
+	}
+	return nil
+}
+
+// specifier parses a type specifier, enums are skipped and taken as int.
+func (h *cheader) specifier() (*ctype, error) {
+	for h.peek() == "const" || h.peek() == "volatile" {
+		h.next()
+	}
+	switch kind := h.peek(); kind {
+	case "struct", "union":
+		h.next()
+		name := ""
+		if h.peek() != "{" {
+			name = h.next()
+		}
+		if h.peek() != "{" {
+			if record, ok := h.records[kind+" "+name]; ok {
+				return &ctype{record: record}, nil
+			}
+			// Forward declaration or a record only used through pointers.
+			return &ctype{record: &crecord{name: kind + " " + name, union: kind == "union"}}, nil
+		}
+		record, err := h.body(kind == "union")
+		if err != nil {
+			return nil, err
+		}
+		if name != "" {
+			record.name = kind + " " + name
+			h.records[record.name] = record
+		}
+		return &ctype{record: record}, nil
+	case "enum":
+		h.next()
+		if h.peek() != "{" {
+			h.next()
+		}
+		if h.peek() == "{" {
+			h.skip("}")
+		}
+		return &ctype{scalar: "u32"}, nil
+	}
+	var words []string
+	for {
+		t := h.peek()
+		if t == "signed" || t == "unsigned" || t == "short" || t == "long" || t == "int" || t == "char" {
+			words = append(words, h.next())
+			continue
+		}
+		if len(words) == 0 && t != "" && t != ";" && t != "*" {
+			words = append(words, h.next())
+		}
+		break
+	}
+	name := strings.Join(words, " ")
+	name = strings.TrimSuffix(name, " int")
+	if name == "signed" {
+		name = "int"
+	}
+	if scalar, ok := scalars[name]; ok {
+		return &ctype{scalar: scalar}, nil
+	}
+	if name == "void" {
+		return &ctype{scalar: "void"}, nil
+	}
+	if typ, ok := h.typedefs[name]; ok {
+		return typ, nil
+	}
+	return nil, fmt.Errorf("unknown type %q at token %d", name, h.pos)
+}
+
+// body parses the members of a record up to the closing brace and its attributes.
+func (h *cheader) body(union bool) (*crecord, error) {
+	if err := h.expect("{"); err != nil {
+		return nil, err
+	}
+	record := &crecord{union: union}
+	for h.peek() != "}" {
+		base, err := h.specifier()
+		if err != nil {
+			return nil, err
+		}
+		if h.peek() == ";" {
+			// Anonymous struct or union member.
+			h.next()
+			record.members = append(record.members, cmember{typ: base})
+			continue
+		}
+		for {
+			typ, name, err := h.declarator(base)
+			if err != nil {
+				return nil, err
+			}
+			record.members = append(record.members, cmember{name: name, typ: typ})
+			if h.peek() != "," {
+				break
+			}
+			h.next()
+		}
+		if err := h.expect(";"); err != nil {
+			return nil, err
+		}
+	}
+	h.next()
+	for h.peek() == "__attribute__" {
+		h.next()
+		start := h.pos
+		h.skip(")")
+		for _, attr := range strings.Split(strings.Join(h.tokens[start:h.pos], ""), ",") {
+			attr = strings.Trim(attr, "()")
+			switch {
+			case attr == "packed" || attr == "__packed__":
+				record.packed = true
+			case strings.HasPrefix(attr, "aligned"):
+				n, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(attr, "aligned"), "()"))
+				if err != nil {
+					return nil, fmt.Errorf("invalid attribute %q", attr)
+				}
+				record.aligned = n
+			}
+		}
+	}
+	return record, nil
+}
+
+// declarator parses pointers, the name and array dimensions of a declaration.
+func (h *cheader) declarator(base *ctype) (*ctype, string, error) {
+	typ := base
+	for h.peek() == "*" || h.peek() == "__user" || h.peek() == "const" {
+		if h.next() == "*" {
+			typ = &ctype{pointer: true, elem: typ}
+		}
+	}
+	name := h.next()
+	var dims []int
+	for h.peek() == "[" {
+		h.next()
+		if h.peek() == "]" {
+			dims = append(dims, -1)
+		} else {
+			n, err := strconv.Atoi(h.next())
+			if err != nil {
+				return nil, "", fmt.Errorf("array size of %s: %w", name, err)
+			}
+			dims = append(dims, n)
+		}
+		if err := h.expect("]"); err != nil {
+			return nil, "", err
+		}
+	}
+	for i := len(dims) - 1; i >= 0; i-- {
+		typ = &ctype{elem: typ, count: dims[i]}
+	}
+	return typ, name, nil
+}
+
+// lookup returns the type named by a record name such as "struct sctp_initmsg", a typedef
+// name or either followed by a member path such as "union sctp_notification.sn_header".
+func (h *cheader) lookup(name string) (*ctype, error) {
+	parts := strings.Split(name, ".")
+	var typ *ctype
+	if record, ok := h.records[parts[0]]; ok {
+		typ = &ctype{record: record}
+	} else if t, ok := h.typedefs[parts[0]]; ok {
+		typ = t
+	} else {
+		return nil, fmt.Errorf("unknown type %q", parts[0])
+	}
+	for _, part := range parts[1:] {
+		if typ.record == nil {
+			return nil, fmt.Errorf("%s: %q is not a record", name, part)
+		}
+		member, ok := typ.record.member(part)
+		if !ok {
+			return nil, fmt.Errorf("%s: no member %q", name, part)
+		}
+		typ = member.typ
+	}
+	return typ, nil
+}
+
+// member finds a member by name, looking into anonymous members.
+func (r *crecord) member(name string) (cmember, bool) {
+	for _, m := range r.members {
+		if m.name == name {
+			return m, true
+		}
+		if m.name == "" && m.typ.record != nil {
+			if inner, ok := m.typ.record.member(name); ok {
+				return inner, true
+			}
+		}
+	}
+	return cmember{}, false
+}
@@ -8,137 +8,12 @@ package sctp_go
 //#include <linux/sctp.h>
 //#include <netinet/in.h>
 //#include <netinet/sctp.h>
-//typedef struct iovec                    IoVector;
-//typedef struct msghdr                   MsgHeader;
-//typedef struct cmsghdr                  CMsgHeader;
-//typedef struct in_addr                  InAddr;
-//typedef struct in6_addr                 In6Addr;
-//typedef struct sockaddr_in6             SockAddrIn6;
-//typedef struct sockaddr_in              SockAddrIn;
-//typedef struct sockaddr                 SockAddr;
-//typedef struct sockaddr_storage         SockAddrStorage;
-//typedef struct sctp_sndrcvinfo          SCTPSndRcvInfo;
-//typedef struct sctp_initmsg             SCTPInitMsg;
-//typedef struct sctp_sndinfo             SCTPSndInfo;
-//typedef struct sctp_rcvinfo             SCTPRcvInfo;
-//typedef struct sctp_nxtinfo             SCTPNxtInfo;
-//typedef struct sctp_prinfo              SCTPPrInfo;
-//typedef struct sctp_authinfo            SCTPAuthInfo;
-//typedef struct sctp_assoc_change        SCTPAssocChange;
-//typedef struct sctp_paddr_change        SCTPPAddrChange;
-//typedef struct sctp_remote_error        SCTPRemoteError;
-//typedef struct sctp_send_failed         SCTPSendFailed;
-//typedef struct sctp_shutdown_event      SCTPShutdownEvent;
-//typedef struct sctp_adaptation_event    SCTPAdaptationEvent;
-//typedef struct sctp_pdapi_event         SCTPPDApiEvent;
-//typedef struct sctp_authkey_event       SCTPAuthKeyEvent;
-//typedef struct sctp_sender_dry_event    SCTPSenderDryEvent;
-//typedef struct sctp_stream_reset_event  SCTPStreamResetEvent;
-//typedef struct sctp_assoc_reset_event   SCTPAssocResetEvent;
-//typedef struct sctp_stream_change_event SCTPStreamChangeEvent;
-//typedef struct sctp_event_subscribe     SCTPEventSubscribe;
-//typedef union sctp_notification         SCTPNotification;
-//typedef sctp_cmsg_data_t                SCTPCmsgData;
-//typedef struct sn_header {
-//	__u16 sn_type;
-//	__u16 sn_flags;
-//	__u32 sn_length;
-//} SCTPNotificationHeader;
-//typedef struct sctp_rtoinfo             SCTPRTOInfo;
-//typedef struct sctp_assocparams         SCTPAssocParams;
-//typedef struct sctp_setpeerprim         SCTPSetPeerPrimary;
-//typedef struct sctp_prim                SCTPPrimaryAddr;
-//typedef struct sctp_setadaptation       SCTPSetAdaptation;
-//typedef struct sctp_paddrparams         SCTPPeerAddrParams;
-//typedef struct sctp_authchunk           SCTPAuthChunk;
-//typedef struct sctp_hmacalgo            SCTPHmacAlgo;
-//typedef struct sctp_authkey             SCTPAuthKey;
-//typedef struct sctp_authkeyid           SCTPAuthKeyId;
-//typedef struct sctp_sack_info           SCTPSackInfo;
-//typedef struct sctp_assoc_value         SCTPAssocValue;
-//typedef struct sctp_stream_value        SCTPStreamValue;
-//typedef struct sctp_paddrinfo           SCTPPeerAddrInfo;
-//typedef struct sctp_status              SCTPStatus;
-//typedef struct sctp_authchunks          SCTPAuthChunks;
-//typedef struct sctp_assoc_ids           SCTPAssocIds;
-//typedef struct sctp_getaddrs_old        SCTPGetAddrsOld;
-//typedef struct sctp_getaddrs            SCTPGetAddrs;
-//typedef struct sctp_assoc_stats         SCTPAssocStats;
-//typedef sctp_peeloff_arg_t              SCTPPeelOffArg;
-//typedef sctp_peeloff_flags_arg_t        SCTPPeelOffFlagsArg;
-//typedef struct sctp_paddrthlds          SCTPPeerAddrThresholds;
-//typedef struct sctp_prstatus            SCTPPRStatus;
-//typedef struct sctp_default_prinfo      SCTPDefaultPRInfo;
-//typedef struct sctp_info                SCTPInfo;
-//typedef struct sctp_reset_streams       SCTPResetStreams;
-//typedef struct sctp_add_streams         SCTPAddStreams;
-//typedef struct sctp_event               SCTPEvent;
 import "C"
 
+// The sizes of the structs are generated with the structs, see main.go.
 const (
 	SOL_SCTP                       = C.SOL_SCTP
 	IPPROTO_SCTP                   = C.IPPROTO_SCTP
-	IoVectorSize                   = C.sizeof_IoVector
-	MsgHeaderSize                  = C.sizeof_MsgHeader
-	CMsgHeaderSize                 = C.sizeof_CMsgHeader
-	InAddrSize                     = C.sizeof_InAddr
-	In6AddrSize                    = C.sizeof_In6Addr
-	SockAddrInSize                 = C.sizeof_SockAddrIn
-	SockAddrIn6Size                = C.sizeof_SockAddrIn6
-	SockAddrSize                   = C.sizeof_SockAddr
-	SockAddrStorageSize            = C.sizeof_SockAddrStorage
-	SCTPSndRcvInfoSize             = C.sizeof_SCTPSndRcvInfo
-	SCTPInitMsgSize                = C.sizeof_SCTPInitMsg
-	SCTPSndInfoSize                = C.sizeof_SCTPSndInfo
-	SCTPRcvInfoSize                = C.sizeof_SCTPRcvInfo
-	SCTPNxtInfoSize                = C.sizeof_SCTPNxtInfo
-	SCTPPrInfoSize                 = C.sizeof_SCTPPrInfo
-	SCTPAuthInfoSize               = C.sizeof_SCTPAuthInfo
-	SCTPAssocChangeSize            = C.sizeof_SCTPAssocChange
-	SCTPPAddrChangeSize            = C.sizeof_SCTPPAddrChange
-	SCTPRemoteErrorSize            = C.sizeof_SCTPRemoteError
-	SCTPSendFailedSize             = C.sizeof_SCTPSendFailed
-	SCTPShutdownEventSize          = C.sizeof_SCTPShutdownEvent
-	SCTPAdaptationEventSize        = C.sizeof_SCTPAdaptationEvent
-	SCTPPDApiEventSize             = C.sizeof_SCTPPDApiEvent
-	SCTPAuthKeyEventSize           = C.sizeof_SCTPAuthKeyEvent
-	SCTPSenderDryEventSize         = C.sizeof_SCTPSenderDryEvent
-	SCTPStreamResetEventSize       = C.sizeof_SCTPStreamResetEvent
-	SCTPAssocResetEventSize        = C.sizeof_SCTPAssocResetEvent
-	SCTPStreamChangeEventSize      = C.sizeof_SCTPStreamChangeEvent
-	SCTPEventSubscribeSize         = C.sizeof_SCTPEventSubscribe
-	SCTPNotificationSize           = C.sizeof_SCTPNotification
-	SCTPCmsgDataSize               = C.sizeof_SCTPCmsgData
-	SCTPNotificationHeaderSize     = C.sizeof_SCTPNotificationHeader
-	SCTPRTOInfoSize                = C.sizeof_SCTPRTOInfo
-	SCTPAssocParamsSize            = C.sizeof_SCTPAssocParams
-	SCTPSetPeerPrimarySize         = C.sizeof_SCTPSetPeerPrimary
-	SCTPPrimaryAddrSize            = C.sizeof_SCTPPrimaryAddr
-	SCTPSetAdaptationSize          = C.sizeof_SCTPSetAdaptation
-	SCTPPeerAddrParamsSize         = C.sizeof_SCTPPeerAddrParams
-	SCTPAuthChunkSize              = C.sizeof_SCTPAuthChunk
-	SCTPHmacAlgoSize               = C.sizeof_SCTPHmacAlgo
-	SCTPAuthKeySize                = C.sizeof_SCTPAuthKey
-	SCTPAuthKeyIdSize              = C.sizeof_SCTPAuthKeyId
-	SCTPSackInfoSize               = C.sizeof_SCTPSackInfo
-	SCTPAssocValueSize             = C.sizeof_SCTPAssocValue
-	SCTPStreamValueSize            = C.sizeof_SCTPStreamValue
-	SCTPPeerAddrInfoSize           = C.sizeof_SCTPPeerAddrInfo
-	SCTPStatusSize                 = C.sizeof_SCTPStatus
-	SCTPAuthChunksSize             = C.sizeof_SCTPAuthChunks
-	SCTPAssocIdsSize               = C.sizeof_SCTPAssocIds
-	SCTPGetAddrsOldSize            = C.sizeof_SCTPGetAddrsOld
-	SCTPGetAddrsSize               = C.sizeof_SCTPGetAddrs
-	SCTPAssocStatsSize             = C.sizeof_SCTPAssocStats
-	SCTPPeelOffArgSize             = C.sizeof_SCTPPeelOffArg
-	SCTPPeelOffFlagsArgSize        = C.sizeof_SCTPPeelOffFlagsArg
-	SCTPPeerAddrThresholdsSize     = C.sizeof_SCTPPeerAddrThresholds
-	SCTPPRStatusSize               = C.sizeof_SCTPPRStatus
-	SCTPDefaultPRInfoSize          = C.sizeof_SCTPDefaultPRInfo
-	SCTPInfoSize                   = C.sizeof_SCTPInfo
-	SCTPResetStreamsSize           = C.sizeof_SCTPResetStreams
-	SCTPAddStreamsSize             = C.sizeof_SCTPAddStreams
-	SCTPEventSize                  = C.sizeof_SCTPEvent
 	SCTP_FUTURE_ASSOC              = C.SCTP_FUTURE_ASSOC
 	SCTP_CURRENT_ASSOC             = C.SCTP_CURRENT_ASSOC
 	SCTP_ALL_ASSOC                 = C.SCTP_ALL_ASSOC
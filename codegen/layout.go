@@ -0,0 +1,118 @@
+package main
+
+import "fmt"
+
+// abi describes the C data model of an architecture, the scalars of the headers other
+// than words and 64-bit integers are aligned to their size everywhere.
+type abi struct {
+	word int // size and alignment of long, size_t and pointers
+	u64  int // alignment of 64-bit integers
+}
+
+// abis are the architectures the structs are generated for.
+var abis = map[string]abi{
+	"386":     {word: 4, u64: 4},
+	"amd64":   {word: 8, u64: 8},
+	"arm":     {word: 4, u64: 8},
+	"arm64":   {word: 8, u64: 8},
+	"ppc64le": {word: 8, u64: 8},
+	"s390x":   {word: 8, u64: 8},
+}
+
+// layout returns the size and alignment of the type.
+func (a abi) layout(t *ctype) (size, align int, err error) {
+	switch {
+	case t.pointer:
+		return a.word, a.word, nil
+	case t.elem != nil:
+		size, align, err = a.layout(t.elem)
+		if err != nil {
+			return 0, 0, err
+		}
+		if t.count < 0 {
+			return 0, align, nil
+		}
+		return size * t.count, align, nil
+	case t.record != nil:
+		size, align, _, err = a.record(t.record)
+		return size, align, err
+	}
+	switch t.scalar {
+	case "u8":
+		return 1, 1, nil
+	case "u16":
+		return 2, 2, nil
+	case "u32":
+		return 4, 4, nil
+	case "u64":
+		return 8, a.u64, nil
+	case "word":
+		return a.word, a.word, nil
+	}
+	return 0, 0, fmt.Errorf("no layout for type %q", t.scalar)
+}
+
+// record returns the size and alignment of the record and the offsets of its members,
+// members of anonymous structs and unions are included under their own names.
+func (a abi) record(r *crecord) (size, align int, offsets map[string]int, err error) {
+	if r.members == nil {
+		return 0, 0, nil, fmt.Errorf("incomplete type %q", r.name)
+	}
+	offsets = make(map[string]int)
+	align = 1
+	for _, m := range r.members {
+		msize, malign, err := a.layout(m.typ)
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("%s.%s: %w", r.name, m.name, err)
+		}
+		if r.packed {
+			malign = 1
+		}
+		offset := 0
+		if !r.union {
+			offset = roundUp(size, malign)
+		}
+		if m.name != "" {
+			offsets[m.name] = offset
+		} else {
+			_, _, inner, err := a.record(m.typ.record)
+			if err != nil {
+				return 0, 0, nil, err
+			}
+			for name, o := range inner {
+				offsets[name] = offset + o
+			}
+		}
+		if r.union {
+			size = max(size, msize)
+		} else {
+			size = offset + msize
+		}
+		align = max(align, malign)
+	}
+	align = max(align, r.aligned)
+	return roundUp(size, align), align, offsets, nil
+}
+
+// without returns a copy of the record without the named members.
+func (r *crecord) without(names []string) (*crecord, error) {
+	c := *r
+	c.members = nil
+	for _, m := range r.members {
+		removed := false
+		for _, name := range names {
+			removed = removed || m.name == name
+		}
+		if !removed {
+			c.members = append(c.members, m)
+		}
+	}
+	if len(c.members)+len(names) != len(r.members) {
+		return nil, fmt.Errorf("%s: cannot remove members %v", r.name, names)
+	}
+	return &c, nil
+}
+
+func roundUp(n, align int) int {
+	return (n + align - 1) / align * align
+}
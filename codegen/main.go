@@ -0,0 +1,308 @@
+// Command codegen generates the structs of the package for each supported architecture
+// from the declarations of types_sctp.go and the C headers in the include directory, the
+// sizes and offsets of the C members are computed with the data model of the architecture.
+//
+// Usage, from this directory:
+//
+//	go run . [-arch amd64,386] [-out ..]
+//
+// writes ../sctp_structs_linux_<arch>.go for each architecture.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// directive marks the types laid out after a C type, see types_sctp.go.
+const directive = "//sctp:type "
+
+// generator lays out the template types for the architectures.
+type generator struct {
+	header   *cheader
+	fset     *token.FileSet
+	file     *ast.File
+	source   []byte
+	template string
+}
+
+// newGenerator parses the template and the C headers, socket.h comes first as sctp.h uses
+// the socket address structs.
+func newGenerator(template, include string) (*generator, error) {
+	header, err := parseHeaders(
+		filepath.Join(include, "socket.h"),
+		filepath.Join(include, "linux", "sctp.h"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	source, err := os.ReadFile(template)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, template, source, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	return &generator{
+		header:   header,
+		fset:     fset,
+		file:     file,
+		source:   source,
+		template: filepath.Base(template),
+	}, nil
+}
+
+// ctypeOf returns the C type named by the directive in the doc comment, nil if there is none.
+func (g *generator) ctypeOf(doc *ast.CommentGroup) (*ctype, string, error) {
+	if doc == nil {
+		return nil, "", nil
+	}
+	for _, c := range doc.List {
+		if !strings.HasPrefix(c.Text, directive) {
+			continue
+		}
+		var (
+			names   []string
+			removed []string
+		)
+		for _, word := range strings.Fields(strings.TrimPrefix(c.Text, directive)) {
+			if strings.HasPrefix(word, "-") {
+				removed = append(removed, word[1:])
+			} else {
+				names = append(names, word)
+			}
+		}
+		name := strings.Join(names, " ")
+		typ, err := g.header.lookup(name)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(removed) > 0 {
+			if typ.record == nil {
+				return nil, "", fmt.Errorf("%s: members removed from a non record type", name)
+			}
+			record, err := typ.record.without(removed)
+			if err != nil {
+				return nil, "", err
+			}
+			typ = &ctype{record: record}
+		}
+		return typ, name, nil
+	}
+	return nil, "", nil
+}
+
+// check type checks the source with the sizes of the architecture.
+func check(fset *token.FileSet, file *ast.File, arch string) (*types.Package, types.Sizes, error) {
+	sizes := types.SizesFor("gc", arch)
+	config := types.Config{Sizes: sizes}
+	pkg, err := config.Check(file.Name.Name, fset, []*ast.File{file}, nil)
+	return pkg, sizes, err
+}
+
+// generate returns the formatted source of the structs for the architecture.
+func (g *generator) generate(arch string) ([]byte, error) {
+	a, ok := abis[arch]
+	if !ok {
+		return nil, fmt.Errorf("unsupported architecture %q", arch)
+	}
+	pkg, sizes, err := check(g.fset, g.file, arch)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		out    bytes.Buffer
+		consts bytes.Buffer
+		csizes = make(map[string]int)
+		packed = make(map[string]bool)
+	)
+	fmt.Fprintf(&out, "// Code generated by codegen -arch %s; DO NOT EDIT.\n", arch)
+	fmt.Fprintf(&out, "// Source: %s, include/socket.h, include/linux/sctp.h\n\n", g.template)
+	fmt.Fprintf(&out, "package %s\n", g.file.Name.Name)
+	for _, decl := range g.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE || len(gen.Specs) != 1 {
+			return nil, fmt.Errorf("%s: expected a single type declaration", g.fset.Position(decl.Pos()))
+		}
+		spec := gen.Specs[0].(*ast.TypeSpec)
+		name := spec.Name.Name
+		ctyp, cname, err := g.ctypeOf(gen.Doc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		text := g.text(spec)
+		if ctyp != nil {
+			csize, _, err := a.layout(ctyp)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			switch expr := spec.Type.(type) {
+			case *ast.StructType:
+				if ctyp.record == nil {
+					return nil, fmt.Errorf("%s: %s is not a struct", name, cname)
+				}
+				typ := pkg.Scope().Lookup(name).Type().Underlying().(*types.Struct)
+				text, err = g.layout(spec, expr, typ, a, ctyp.record, csize, sizes)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", name, err)
+				}
+				packed[name] = ctyp.record.packed
+			case *ast.ArrayType:
+				text = regexp.MustCompile(`\[\w+\]`).ReplaceAllString(text, fmt.Sprintf("[%d]", csize))
+			}
+			csizes[name] = csize
+			fmt.Fprintf(&consts, "\t%sSize = %#x\n", name, csize)
+		}
+		out.WriteString("\n")
+		for _, line := range docLines(gen.Doc) {
+			out.WriteString(line + "\n")
+		}
+		out.WriteString("type " + text + "\n")
+	}
+	out.WriteString("\n// Sizes of the C types.\nconst (\n")
+	out.Write(consts.Bytes())
+	out.WriteString(")\n")
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if err := verify(source, arch, csizes, packed); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// text returns the source of the type specification.
+func (g *generator) text(spec *ast.TypeSpec) string {
+	start := g.fset.Position(spec.Pos()).Offset
+	end := g.fset.Position(spec.End()).Offset
+	return string(g.source[start:end])
+}
+
+// layout returns the source of the struct with the field tags removed and padding added so
+// the tagged fields are at the offsets of their C members and the struct has the C size. A
+// packed C struct may leave fields after their C offset, those are left as they are.
+func (g *generator) layout(spec *ast.TypeSpec, expr *ast.StructType, typ *types.Struct, a abi, record *crecord, csize int, sizes types.Sizes) (string, error) {
+	_, _, offsets, err := a.record(record)
+	if err != nil {
+		return "", err
+	}
+	first := g.fset.Position(spec.Pos()).Line
+	lines := strings.Split(g.text(spec), "\n")
+	padding := make(map[int]int)
+	offset, align := 0, 1
+	for i, field := range expr.Fields.List {
+		if len(field.Names) != 1 {
+			return "", fmt.Errorf("expected one name per field")
+		}
+		ftype := typ.Field(i).Type()
+		falign := int(sizes.Alignof(ftype))
+		line := g.fset.Position(field.Pos()).Line - first
+		if member, ok := reflect.StructTag(typ.Tag(i)).Lookup("c"); ok {
+			coffset, ok := offsets[member]
+			if !ok {
+				return "", fmt.Errorf("%s has no member %s", record.name, member)
+			}
+			if pad := coffset - roundUp(offset, falign); pad > 0 {
+				padding[line] = pad
+				offset += pad
+			}
+			lines[line] = strings.Replace(lines[line], field.Tag.Value, "", 1)
+		}
+		offset = roundUp(offset, falign) + int(sizes.Sizeof(ftype))
+		align = max(align, falign)
+	}
+	if roundUp(offset, align) < csize {
+		padding[len(lines)-1] = csize - offset
+	}
+	var out []string
+	for i, line := range lines {
+		if pad, ok := padding[i]; ok {
+			out = append(out, fmt.Sprintf("\t_ [%d]byte", pad))
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+// docLines returns the lines of the doc comment without the directives of the generator.
+func docLines(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+	var lines []string
+	for _, c := range doc.List {
+		if !strings.HasPrefix(c.Text, directive) {
+			lines = append(lines, c.Text)
+		}
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "//" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// verify type checks the generated source and compares the sizes of the types with the C
+// sizes, the Go size of a packed C struct may only be larger.
+func verify(source []byte, arch string, csizes map[string]int, packed map[string]bool) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", source, 0)
+	if err != nil {
+		return err
+	}
+	pkg, sizes, err := check(fset, file, arch)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(csizes))
+	for name := range csizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		size := int(sizes.Sizeof(pkg.Scope().Lookup(name).Type()))
+		if size != csizes[name] && !(packed[name] && size > csizes[name]) {
+			return fmt.Errorf("%s: %s has size %d, the C type %d", arch, name, size, csizes[name])
+		}
+	}
+	return nil
+}
+
+func main() {
+	var (
+		archs    = flag.String("arch", "386,amd64,arm,arm64,ppc64le,s390x", "comma separated architectures")
+		out      = flag.String("out", "..", "output directory")
+		template = flag.String("template", "types_sctp.go", "template with the Go types")
+		include  = flag.String("include", "include", "directory of the C headers")
+	)
+	flag.Parse()
+	g, err := newGenerator(*template, *include)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, arch := range strings.Split(*archs, ",") {
+		source, err := g.generate(arch)
+		if err != nil {
+			log.Fatal(err)
+		}
+		path := filepath.Join(*out, "sctp_structs_linux_"+arch+".go")
+		if err := os.WriteFile(path, source, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func testGenerator(t *testing.T) *generator {
+	t.Helper()
+	g, err := newGenerator("types_sctp.go", "include")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func architectures() []string {
+	archs := make([]string, 0, len(abis))
+	for arch := range abis {
+		archs = append(archs, arch)
+	}
+	sort.Strings(archs)
+	return archs
+}
+
+func TestGenerate(t *testing.T) {
+	g := testGenerator(t)
+	for _, arch := range architectures() {
+		source, err := g.generate(arch)
+		if err != nil {
+			t.Fatalf("%s: %v", arch, err)
+		}
+		committed, err := os.ReadFile(filepath.Join("..", "sctp_structs_linux_"+arch+".go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(source, committed) {
+			t.Errorf("%s: sctp_structs_linux_%s.go is out of date, run go run .", arch, arch)
+		}
+	}
+}
+
+// asserted returns the types whose sizes are asserted by TestSizes of the package.
+func asserted(t *testing.T) []string {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), filepath.Join("..", "test_test.go"), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == "TestSizes" {
+			ast.Inspect(fn, func(n ast.Node) bool {
+				if ident, ok := n.(*ast.Ident); ok && strings.HasSuffix(ident.Name, "Size") {
+					names = append(names, strings.TrimSuffix(ident.Name, "Size"))
+				}
+				return true
+			})
+		}
+	}
+	if len(names) == 0 {
+		t.Fatal("no sizes asserted by TestSizes")
+	}
+	return names
+}
+
+func TestSizes(t *testing.T) {
+	g := testGenerator(t)
+	packed := make(map[string]bool)
+	for _, decl := range g.file.Decls {
+		gen := decl.(*ast.GenDecl)
+		ctyp, _, err := g.ctypeOf(gen.Doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ctyp != nil && ctyp.record != nil {
+			packed[gen.Specs[0].(*ast.TypeSpec).Name.Name] = ctyp.record.packed
+		}
+	}
+	names := asserted(t)
+	for _, arch := range architectures() {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, filepath.Join("..", "sctp_structs_linux_"+arch+".go"), nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkg, sizes, err := check(fset, file, arch)
+		if err != nil {
+			t.Fatalf("%s: %v", arch, err)
+		}
+		for _, name := range names {
+			typ, size := pkg.Scope().Lookup(name), pkg.Scope().Lookup(name+"Size")
+			if typ == nil || size == nil {
+				t.Errorf("%s: %s or %sSize missing", arch, name, name)
+				continue
+			}
+			want, _ := constant.Int64Val(size.(*types.Const).Val())
+			got := sizes.Sizeof(typ.Type())
+			if got != want && !(packed[name] && got > want) {
+				t.Errorf("%s: %s has size %d, %sSize is %d", arch, name, got, name, want)
+			}
+		}
+	}
+}
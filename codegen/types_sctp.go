@@ -1,138 +1,695 @@
+//go:build ignore
 // +build ignore
 
+// Input of the struct generator, see main.go. Every struct type with a sctp:type directive
+// is laid out like the named C type of codegen/include for each architecture: the c tag of
+// a field names its C member and padding is added where the Go alignment of a member is
+// smaller than the C one. Members following a minus sign are left out of the C type.
+
 package sctp_go
 
-//#include <stdint.h>
-//#include <linux/types.h>
-//#include <sys/socket.h>
-//#include <linux/sctp.h>
-//#include <netinet/in.h>
-//typedef struct iovec                    IoVector;
-//typedef struct msghdr                   MsgHeader;
-//typedef struct cmsghdr                  CMsgHeader;
-//typedef struct in_addr                  InAddr;
-//typedef struct in6_addr                 In6Addr;
-//typedef struct sockaddr_in6             SockAddrIn6;
-//typedef struct sockaddr_in              SockAddrIn;
-//typedef struct sockaddr                 SockAddr;
-//typedef struct sockaddr_storage         SockAddrStorage;
-//typedef struct sctp_sndrcvinfo          SCTPSndRcvInfo;
-//typedef struct sctp_initmsg             SCTPInitMsg;
-//typedef struct sctp_sndinfo             SCTPSndInfo;
-//typedef struct sctp_rcvinfo             SCTPRcvInfo;
-//typedef struct sctp_nxtinfo             SCTPNxtInfo;
-//typedef struct sctp_prinfo              SCTPPrInfo;
-//typedef struct sctp_authinfo            SCTPAuthInfo;
-//typedef struct sctp_assoc_change        SCTPAssocChange;
-//typedef struct sctp_paddr_change        SCTPPAddrChange;
-//typedef struct sctp_remote_error        SCTPRemoteError;
-//typedef struct sctp_send_failed         SCTPSendFailed;
-//typedef struct sctp_shutdown_event      SCTPShutdownEvent;
-//typedef struct sctp_adaptation_event    SCTPAdaptationEvent;
-//typedef struct sctp_pdapi_event         SCTPPDApiEvent;
-//typedef struct sctp_authkey_event       SCTPAuthKeyEvent;
-//typedef struct sctp_sender_dry_event    SCTPSenderDryEvent;
-//typedef struct sctp_stream_reset_event  SCTPStreamResetEvent;
-//typedef struct sctp_assoc_reset_event   SCTPAssocResetEvent;
-//typedef struct sctp_stream_change_event SCTPStreamChangeEvent;
-//typedef struct sctp_event_subscribe     SCTPEventSubscribe;
-//typedef union sctp_notification         SCTPNotification;
-//typedef sctp_cmsg_data_t                SCTPCmsgData;
-//typedef struct sn_header {
-//	__u16 sn_type;
-//	__u16 sn_flags;
-//	__u32 sn_length;
-//} SCTPNotificationHeader;
-//typedef struct sctp_rtoinfo             SCTPRTOInfo;
-//typedef struct sctp_assocparams         SCTPAssocParams;
-//typedef struct sctp_setpeerprim         SCTPSetPeerPrimary;
-//typedef struct sctp_prim                SCTPPrimaryAddr;
-//typedef struct sctp_setadaptation       SCTPSetAdaptation;
-//typedef struct sctp_paddrparams         SCTPPeerAddrParams;
-//typedef struct sctp_authchunk           SCTPAuthChunk;
-//typedef struct sctp_hmacalgo            SCTPHmacAlgo;
-//typedef struct sctp_authkey             SCTPAuthKey;
-//typedef struct sctp_authkeyid           SCTPAuthKeyId;
-//typedef struct sctp_sack_info           SCTPSackInfo;
-//typedef struct sctp_assoc_value         SCTPAssocValue;
-//typedef struct sctp_stream_value        SCTPStreamValue;
-//typedef struct sctp_paddrinfo           SCTPPeerAddrInfo;
-//typedef struct sctp_status              SCTPStatus;
-//typedef struct sctp_authchunks          SCTPAuthChunks;
-//typedef struct sctp_assoc_ids           SCTPAssocIds;
-//typedef struct sctp_getaddrs_old        SCTPGetAddrsOld;
-//typedef struct sctp_getaddrs            SCTPGetAddrs;
-//typedef struct sctp_assoc_stats         SCTPAssocStats;
-//typedef sctp_peeloff_arg_t              SCTPPeelOffArg;
-//typedef sctp_peeloff_flags_arg_t        SCTPPeelOffFlagsArg;
-//typedef struct sctp_paddrthlds          SCTPPeerAddrThresholds;
-//typedef struct sctp_prstatus            SCTPPRStatus;
-//typedef struct sctp_default_prinfo      SCTPDefaultPRInfo;
-//typedef struct sctp_info                SCTPInfo;
-//typedef struct sctp_reset_streams       SCTPResetStreams;
-//typedef struct sctp_add_streams         SCTPAddStreams;
-//typedef struct sctp_event               SCTPEvent;
-import "C"
-
-type IoVector               C.IoVector
-type MsgHeader              C.MsgHeader
-type CMsgHeader             C.CMsgHeader
-type InAddr                 C.InAddr
-type In6Addr                C.In6Addr
-type SockAddrIn6            C.SockAddrIn6
-type SockAddrIn             C.SockAddrIn
-type SockAddr               C.SockAddr
-type SockAddrStorage        C.SockAddrStorage
-type SCTPAssocId            C.sctp_assoc_t
-type SCTPInitMsg            C.SCTPInitMsg
-type SCTPSndRcvInfo         C.SCTPSndRcvInfo
-type SCTPSndInfo            C.SCTPSndInfo
-type SCTPRcvInfo            C.SCTPRcvInfo
-type SCTPNxtInfo            C.SCTPNxtInfo
-type SCTPPrInfo             C.SCTPPrInfo
-type SCTPAuthInfo           C.SCTPAuthInfo
-type SCTPCmsgData           C.SCTPCmsgData
-type SCTPAssocChange        C.SCTPAssocChange
-type SCTPPAddrChange        C.SCTPPAddrChange
-type SCTPRemoteError        C.SCTPRemoteError
-type SCTPSendFailed         C.SCTPSendFailed
-type SCTPShutdownEvent      C.SCTPShutdownEvent
-type SCTPAdaptationEvent    C.SCTPAdaptationEvent
-type SCTPPDApiEvent         C.SCTPPDApiEvent
-type SCTPAuthKeyEvent       C.SCTPAuthKeyEvent
-type SCTPSenderDryEvent     C.SCTPSenderDryEvent
-type SCTPStreamResetEvent   C.SCTPStreamResetEvent
-type SCTPAssocResetEvent    C.SCTPAssocResetEvent
-type SCTPStreamChangeEvent  C.SCTPStreamChangeEvent
-type SCTPEventSubscribe     C.SCTPEventSubscribe
-type SCTPNotification       C.SCTPNotification
-type SCTPNotificationHeader C.SCTPNotificationHeader
-type SCTPRTOInfo            C.SCTPRTOInfo
-type SCTPAssocParams        C.SCTPAssocParams
-type SCTPSetPeerPrimary     C.SCTPSetPeerPrimary
-type SCTPPrimaryAddr        C.SCTPPrimaryAddr
-type SCTPSetAdaptation      C.SCTPSetAdaptation
-type SCTPPeerAddrParams     C.SCTPPeerAddrParams
-type SCTPAuthChunk          C.SCTPAuthChunk
-type SCTPHmacAlgo           C.SCTPHmacAlgo
-type SCTPAuthKey            C.SCTPAuthKey
-type SCTPAuthKeyId          C.SCTPAuthKeyId
-type SCTPSackInfo           C.SCTPSackInfo
-type SCTPAssocValue         C.SCTPAssocValue
-type SCTPStreamValue        C.SCTPStreamValue
-type SCTPPeerAddrInfo       C.SCTPPeerAddrInfo
-type SCTPStatus             C.SCTPStatus
-type SCTPAuthChunks         C.SCTPAuthChunks
-type SCTPAssocIds           C.SCTPAssocIds
-type SCTPGetAddrsOld        C.SCTPGetAddrsOld
-type SCTPGetAddrs           C.SCTPGetAddrs
-type SCTPAssocStats         C.SCTPAssocStats
-type SCTPPeelOffArg         C.SCTPPeelOffArg
-type SCTPPeelOffFlagsArg    C.SCTPPeelOffFlagsArg
-type SCTPPeerAddrThresholds C.SCTPPeerAddrThresholds
-type SCTPPRStatus           C.SCTPPRStatus
-type SCTPDefaultPRInfo      C.SCTPDefaultPRInfo
-type SCTPInfo               C.SCTPInfo
-type SCTPResetStreams       C.SCTPResetStreams
-type SCTPAddStreams         C.SCTPAddStreams
-type SCTPEvent              C.SCTPEvent
\ No newline at end of file
+// IoVector represents the C struct iovec for scatter/gather I/O operations.
+//
+//sctp:type struct iovec
+type IoVector struct {
+	Base *byte `c:"iov_base"`
+	Len  uint  `c:"iov_len"`
+}
+
+// MsgHeader represents the C struct msghdr for socket message headers.
+//
+//sctp:type struct msghdr
+type MsgHeader struct {
+	Name       *byte     `c:"msg_name"`
+	NameLen    uint32    `c:"msg_namelen"`
+	Iov        *IoVector `c:"msg_iov"`
+	IovLen     uint      `c:"msg_iovlen"`
+	Control    *byte     `c:"msg_control"`
+	ControlLen uint      `c:"msg_controllen"`
+	Flags      int32     `c:"msg_flags"`
+}
+
+// CMsgHeader represents the C struct cmsghdr for control message headers.
+//
+//sctp:type struct cmsghdr
+type CMsgHeader struct {
+	Len   uint  `c:"cmsg_len"`
+	Level int32 `c:"cmsg_level"`
+	Type  int32 `c:"cmsg_type"`
+}
+
+// InAddr represents the C struct in_addr for IPv4 addresses.
+//
+//sctp:type struct in_addr
+type InAddr struct {
+	Addr [4]byte `c:"s_addr"`
+}
+
+// In6Addr represents the C struct in6_addr for IPv6 addresses.
+//
+//sctp:type struct in6_addr
+type In6Addr struct {
+	Addr [16]byte `c:"in6_u"`
+}
+
+// SockAddrIn6 represents the C struct sockaddr_in6 for IPv6 socket addresses.
+//
+//sctp:type struct sockaddr_in6
+type SockAddrIn6 struct {
+	Family   uint16  `c:"sin6_family"`
+	Port     uint16  `c:"sin6_port"`
+	FlowInfo uint32  `c:"sin6_flowinfo"`
+	Addr     In6Addr `c:"sin6_addr"`
+	ScopeId  uint32  `c:"sin6_scope_id"`
+}
+
+// SockAddrIn represents the C struct sockaddr_in for IPv4 socket addresses.
+//
+//sctp:type struct sockaddr_in
+type SockAddrIn struct {
+	Family uint16   `c:"sin_family"`
+	Port   uint16   `c:"sin_port"`
+	Addr   InAddr   `c:"sin_addr"`
+	Zero   [8]uint8 `c:"__pad"`
+}
+
+// SockAddr represents the C struct sockaddr for generic socket addresses.
+//
+//sctp:type struct sockaddr
+type SockAddr struct {
+	Family uint16   `c:"sa_family"`
+	Data   [14]int8 `c:"sa_data"`
+}
+
+// SockAddrStorage represents the C struct sockaddr_storage for storing socket addresses.
+//
+//sctp:type struct sockaddr_storage
+type SockAddrStorage struct {
+	Family  uint16 `c:"ss_family"`
+	Padding [118]int8
+	Align   uint64
+}
+
+// SCTPAssocId represents the C type sctp_assoc_t for SCTP association IDs.
+type SCTPAssocId int32
+
+// SCTPInitMsg represents the C struct sctp_initmsg for SCTP initialization parameters.
+//
+//sctp:type struct sctp_initmsg
+type SCTPInitMsg struct {
+	NumOutStreams  uint16 `c:"sinit_num_ostreams"`
+	MaxInStreams   uint16 `c:"sinit_max_instreams"`
+	MaxAttempts    uint16 `c:"sinit_max_attempts"`
+	MaxInitTimeout uint16 `c:"sinit_max_init_timeo"`
+}
+
+// SCTPSndRcvInfo represents the C struct sctp_sndrcvinfo for SCTP send/receive information.
+//
+//sctp:type struct sctp_sndrcvinfo
+type SCTPSndRcvInfo struct {
+	Stream     uint16 `c:"sinfo_stream"`
+	Ssn        uint16 `c:"sinfo_ssn"`
+	Flags      uint16 `c:"sinfo_flags"`
+	_          uint16
+	Ppid       uint32 `c:"sinfo_ppid"`
+	Context    uint32 `c:"sinfo_context"`
+	TimeToLive uint32 `c:"sinfo_timetolive"`
+	Tsn        uint32 `c:"sinfo_tsn"`
+	CumTsn     uint32 `c:"sinfo_cumtsn"`
+	AssocId    int32  `c:"sinfo_assoc_id"`
+}
+
+// SCTPSndInfo represents the C struct sctp_sndinfo for SCTP send information.
+//
+//sctp:type struct sctp_sndinfo
+type SCTPSndInfo struct {
+	Sid     uint16 `c:"snd_sid"`
+	Flags   uint16 `c:"snd_flags"`
+	Ppid    uint32 `c:"snd_ppid"`
+	Context uint32 `c:"snd_context"`
+	AssocId int32  `c:"snd_assoc_id"`
+}
+
+// SCTPRcvInfo represents the C struct sctp_rcvinfo for SCTP receive information.
+//
+//sctp:type struct sctp_rcvinfo
+type SCTPRcvInfo struct {
+	Sid     uint16 `c:"rcv_sid"`
+	Ssn     uint16 `c:"rcv_ssn"`
+	Flags   uint16 `c:"rcv_flags"`
+	Ppid    uint32 `c:"rcv_ppid"`
+	Tsn     uint32 `c:"rcv_tsn"`
+	CumTsn  uint32 `c:"rcv_cumtsn"`
+	Context uint32 `c:"rcv_context"`
+	AssocId int32  `c:"rcv_assoc_id"`
+}
+
+// SCTPNxtInfo represents the C struct sctp_nxtinfo for SCTP next information.
+//
+//sctp:type struct sctp_nxtinfo
+type SCTPNxtInfo struct {
+	Sid     uint16 `c:"nxt_sid"`
+	Flags   uint16 `c:"nxt_flags"`
+	Ppid    uint32 `c:"nxt_ppid"`
+	Length  uint32 `c:"nxt_length"`
+	AssocId int32  `c:"nxt_assoc_id"`
+}
+
+// SCTPPrInfo represents the C struct sctp_prinfo for SCTP partial reliability information.
+//
+//sctp:type struct sctp_prinfo
+type SCTPPrInfo struct {
+	Policy uint16 `c:"pr_policy"`
+	Value  uint32 `c:"pr_value"`
+}
+
+// SCTPAuthInfo represents the C struct sctp_authinfo for SCTP authentication information.
+//
+//sctp:type struct sctp_authinfo
+type SCTPAuthInfo struct {
+	KeyNumber uint16 `c:"auth_keynumber"`
+}
+
+// SCTPCmsgData represents the C type sctp_cmsg_data_t for SCTP control message data.
+//
+//sctp:type sctp_cmsg_data_t
+type SCTPCmsgData [32]byte
+
+// SCTPGetAddrsOld represents the C struct sctp_getaddrs_old for getting SCTP addresses (old version).
+//
+//sctp:type struct sctp_getaddrs_old
+type SCTPGetAddrsOld struct {
+	AssocId int32   `c:"assoc_id"`
+	Num     int32   `c:"addr_num"`
+	Addrs   uintptr `c:"addrs"`
+}
+
+// SCTPGetAddrs represents the C struct sctp_getaddrs for getting SCTP addresses.
+//
+//sctp:type struct sctp_getaddrs
+type SCTPGetAddrs struct {
+	AssocId int32  `c:"assoc_id"`
+	Num     uint32 `c:"addr_num"`
+	// Member Addr is removed as it is variable sized array.
+}
+
+// SCTPEventSubscribe represents the C struct sctp_event_subscribe for SCTP event subscription.
+//
+//sctp:type struct sctp_event_subscribe -sctp_send_failure_event_event
+type SCTPEventSubscribe struct {
+	DataIoEvent          uint8 `c:"sctp_data_io_event"`
+	AssociationEvent     uint8 `c:"sctp_association_event"`
+	AddressEvent         uint8 `c:"sctp_address_event"`
+	SendFailureEvent     uint8 `c:"sctp_send_failure_event"`
+	PeerErrorEvent       uint8 `c:"sctp_peer_error_event"`
+	ShutdownEvent        uint8 `c:"sctp_shutdown_event"`
+	PartialDeliveryEvent uint8 `c:"sctp_partial_delivery_event"`
+	AdaptationLayerEvent uint8 `c:"sctp_adaptation_layer_event"`
+	AuthenticationEvent  uint8 `c:"sctp_authentication_event"`
+	SenderDryEvent       uint8 `c:"sctp_sender_dry_event"`
+	StreamResetEvent     uint8 `c:"sctp_stream_reset_event"`
+	AssocResetEvent      uint8 `c:"sctp_assoc_reset_event"`
+	StreamChangeEvent    uint8 `c:"sctp_stream_change_event"`
+}
+
+// SCTPSetPeerPrimary represents the C struct sctp_setpeerprim for setting SCTP peer primary address.
+//
+//sctp:type struct sctp_setpeerprim
+type SCTPSetPeerPrimary struct {
+	AssocId int32     `c:"sspp_assoc_id"`
+	Addr    [128]byte `c:"sspp_addr"`
+	// Cannot use SockAddrStorage as type for Addr.
+	// Inner structures have alignment requirement of 8 bytes.
+	// This structure has alignment requirement of 4 bytes.
+}
+
+// SCTPPrimaryAddr represents the C struct sctp_prim for SCTP primary address.
+//
+//sctp:type struct sctp_prim
+type SCTPPrimaryAddr SCTPSetPeerPrimary
+
+// SCTPPeelOffArg represents the C type sctp_peeloff_arg_t for SCTP peel-off arguments.
+//
+//sctp:type sctp_peeloff_arg_t
+type SCTPPeelOffArg struct {
+	AssocId int32 `c:"associd"`
+	Sd      int32 `c:"sd"`
+}
+
+// SCTPPeelOffFlagsArg represents the C type sctp_peeloff_flags_arg_t for SCTP peel-off arguments with flags.
+//
+//sctp:type sctp_peeloff_flags_arg_t
+type SCTPPeelOffFlagsArg struct {
+	Arg   SCTPPeelOffArg `c:"p_arg"`
+	Flags uint32         `c:"flags"`
+}
+
+// SCTPNotification represents the C union sctp_notification for SCTP notifications.
+//
+//sctp:type union sctp_notification
+type SCTPNotification [148]byte
+
+// SCTPNotificationHeader represents the C struct sn_header for SCTP notification headers.
+//
+//sctp:type union sctp_notification.sn_header
+type SCTPNotificationHeader struct {
+	Type   uint16 `c:"sn_type"`
+	Flags  uint16 `c:"sn_flags"`
+	Length uint32 `c:"sn_length"`
+}
+
+// SCTPAssocChange represents the C struct sctp_assoc_change for SCTP association change notifications.
+//
+//sctp:type struct sctp_assoc_change
+type SCTPAssocChange struct {
+	Type            uint16 `c:"sac_type"`
+	Flags           uint16 `c:"sac_flags"`
+	Length          uint32 `c:"sac_length"`
+	State           uint16 `c:"sac_state"`
+	Error           uint16 `c:"sac_error"`
+	OutboundStreams uint16 `c:"sac_outbound_streams"`
+	InboundStreams  uint16 `c:"sac_inbound_streams"`
+	AssocId         int32  `c:"sac_assoc_id"`
+}
+
+// SCTPPAddrChange represents the C struct sctp_paddr_change for SCTP peer address change notifications.
+//
+//sctp:type struct sctp_paddr_change
+type SCTPPAddrChange struct {
+	Type    uint16    `c:"spc_type"`
+	Flags   uint16    `c:"spc_flags"`
+	Length  uint32    `c:"spc_length"`
+	Addr    [128]byte `c:"spc_aaddr"`
+	State   int32     `c:"spc_state"`
+	Error   int32     `c:"spc_error"`
+	AssocId int32     `c:"spc_assoc_id"`
+	// Cannot use SockAddrStorage as type for Addr.
+	// Inner structures have alignment requirement of 8 bytes.
+	// This structure has alignment requirement of 4 bytes.
+}
+
+// SCTPRemoteError represents the C struct sctp_remote_error for SCTP remote error notifications.
+//
+//sctp:type struct sctp_remote_error
+type SCTPRemoteError struct {
+	Type    uint16 `c:"sre_type"`
+	Flags   uint16 `c:"sre_flags"`
+	Length  uint32 `c:"sre_length"`
+	Error   uint16 `c:"sre_error"`
+	AssocId int32  `c:"sre_assoc_id"`
+}
+
+// SCTPSendFailed represents the C struct sctp_send_failed for SCTP send failure notifications.
+//
+//sctp:type struct sctp_send_failed
+type SCTPSendFailed struct {
+	Type    uint16         `c:"ssf_type"`
+	Flags   uint16         `c:"ssf_flags"`
+	Length  uint32         `c:"ssf_length"`
+	Error   uint32         `c:"ssf_error"`
+	Info    SCTPSndRcvInfo `c:"ssf_info"`
+	AssocId int32          `c:"ssf_assoc_id"`
+}
+
+// SCTPShutdownEvent represents the C struct sctp_shutdown_event for SCTP shutdown event notifications.
+//
+//sctp:type struct sctp_shutdown_event
+type SCTPShutdownEvent struct {
+	Type    uint16 `c:"sse_type"`
+	Flags   uint16 `c:"sse_flags"`
+	Length  uint32 `c:"sse_length"`
+	AssocId int32  `c:"sse_assoc_id"`
+}
+
+// SCTPAdaptationEvent represents the C struct sctp_adaptation_event for SCTP adaptation layer event notifications.
+//
+//sctp:type struct sctp_adaptation_event
+type SCTPAdaptationEvent struct {
+	Type          uint16 `c:"sai_type"`
+	Flags         uint16 `c:"sai_flags"`
+	Length        uint32 `c:"sai_length"`
+	AdaptationInd uint32 `c:"sai_adaptation_ind"`
+	AssocId       int32  `c:"sai_assoc_id"`
+}
+
+// SCTPPDApiEvent represents the C struct sctp_pdapi_event for SCTP partial delivery API event notifications.
+//
+//sctp:type struct sctp_pdapi_event
+type SCTPPDApiEvent struct {
+	Type       uint16 `c:"pdapi_type"`
+	Flags      uint16 `c:"pdapi_flags"`
+	Length     uint32 `c:"pdapi_length"`
+	Indication uint32 `c:"pdapi_indication"`
+	AssocId    int32  `c:"pdapi_assoc_id"`
+	Stream     uint32 `c:"pdapi_stream"`
+	Sequence   uint32 `c:"pdapi_seq"`
+}
+
+// SCTPAuthKeyEvent represents the C struct sctp_authkey_event for SCTP authentication key event notifications.
+//
+//sctp:type struct sctp_authkey_event
+type SCTPAuthKeyEvent struct {
+	Type         uint16 `c:"auth_type"`
+	Flags        uint16 `c:"auth_flags"`
+	Length       uint32 `c:"auth_length"`
+	KeyNumber    uint16 `c:"auth_keynumber"`
+	AltKeyNumber uint16 `c:"auth_altkeynumber"`
+	Indication   uint32 `c:"auth_indication"`
+	AssocId      int32  `c:"auth_assoc_id"`
+}
+
+// SCTPSenderDryEvent represents the C struct sctp_sender_dry_event for SCTP sender dry event notifications.
+//
+//sctp:type struct sctp_sender_dry_event
+type SCTPSenderDryEvent struct {
+	Type    uint16 `c:"sender_dry_type"`
+	Flags   uint16 `c:"sender_dry_flags"`
+	Length  uint32 `c:"sender_dry_length"`
+	AssocId int32  `c:"sender_dry_assoc_id"`
+}
+
+// SCTPStreamResetEvent represents the C struct sctp_stream_reset_event for SCTP stream reset event notifications.
+//
+//sctp:type struct sctp_stream_reset_event
+type SCTPStreamResetEvent struct {
+	Type    uint16 `c:"strreset_type"`
+	Flags   uint16 `c:"strreset_flags"`
+	Length  uint32 `c:"strreset_length"`
+	AssocId int32  `c:"strreset_assoc_id"`
+}
+
+// SCTPAssocResetEvent represents the C struct sctp_assoc_reset_event for SCTP association reset event notifications.
+//
+//sctp:type struct sctp_assoc_reset_event
+type SCTPAssocResetEvent struct {
+	Type      uint16 `c:"assocreset_type"`
+	Flags     uint16 `c:"assocreset_flags"`
+	Length    uint32 `c:"assocreset_length"`
+	AssocId   int32  `c:"assocreset_assoc_id"`
+	LocalTsn  uint32 `c:"assocreset_local_tsn"`
+	RemoteTsn uint32 `c:"assocreset_remote_tsn"`
+}
+
+// SCTPStreamChangeEvent represents the C struct sctp_stream_change_event for SCTP stream change event notifications.
+//
+//sctp:type struct sctp_stream_change_event
+type SCTPStreamChangeEvent struct {
+	Type       uint16 `c:"strchange_type"`
+	Flags      uint16 `c:"strchange_flags"`
+	Length     uint32 `c:"strchange_length"`
+	AssocId    int32  `c:"strchange_assoc_id"`
+	InStreams  uint16 `c:"strchange_instrms"`
+	OutStreams uint16 `c:"strchange_outstrms"`
+}
+
+// SCTPRTOInfo represents the C struct sctp_rtoinfo for SCTP retransmission timeout information.
+//
+//sctp:type struct sctp_rtoinfo
+type SCTPRTOInfo struct {
+	AssocId int32  `c:"srto_assoc_id"`
+	Initial uint32 `c:"srto_initial"`
+	Max     uint32 `c:"srto_max"`
+	Min     uint32 `c:"srto_min"`
+}
+
+// SCTPResetStreams represents the C struct sctp_reset_streams for SCTP reset streams parameters.
+//
+//sctp:type struct sctp_reset_streams
+type SCTPResetStreams struct {
+	AssocId       int32  `c:"srs_assoc_id"`
+	Flags         uint16 `c:"srs_flags"`
+	NumberStreams uint16 `c:"srs_number_streams"`
+}
+
+// SCTPAddStreams represents the C struct sctp_add_streams for SCTP add streams parameters.
+//
+//sctp:type struct sctp_add_streams
+type SCTPAddStreams struct {
+	AssocId    int32  `c:"sas_assoc_id"`
+	InStreams  uint16 `c:"sas_instrms"`
+	OutStreams uint16 `c:"sas_outstrms"`
+}
+
+// SCTPAssocParams represents the C struct sctp_assocparams for SCTP association parameters.
+//
+//sctp:type struct sctp_assocparams
+type SCTPAssocParams struct {
+	AssocId                int32  `c:"sasoc_assoc_id"`
+	AssocMaxrxt            uint16 `c:"sasoc_asocmaxrxt"`
+	NumberPeerDestinations uint16 `c:"sasoc_number_peer_destinations"`
+	PeerRwnd               uint32 `c:"sasoc_peer_rwnd"`
+	LocalRwnd              uint32 `c:"sasoc_local_rwnd"`
+	CookieLife             uint32 `c:"sasoc_cookie_life"`
+}
+
+// SCTPSetAdaptation represents the C struct sctp_setadaptation for SCTP adaptation layer parameters.
+//
+//sctp:type struct sctp_setadaptation
+type SCTPSetAdaptation struct {
+	AdaptationInd uint32 `c:"ssb_adaptation_ind"`
+}
+
+// SCTPPeerAddrParams represents the C struct sctp_paddrparams for SCTP peer address parameters.
+//
+//sctp:type struct sctp_paddrparams
+type SCTPPeerAddrParams struct {
+	AssocId       int32     `c:"spp_assoc_id"`
+	Addr          [128]byte `c:"spp_address"`
+	HbInterval    uint32    `c:"spp_hbinterval"`
+	PathMaxRxt    uint16    `c:"spp_pathmaxrxt"`
+	PathMtu       uint32    `c:"spp_pathmtu"`
+	SackDelay     uint32    `c:"spp_sackdelay"`
+	Flags         uint32    `c:"spp_flags"`
+	Ipv6FlowLabel uint32    `c:"spp_ipv6_flowlabel"`
+	Dscp          uint8     `c:"spp_dscp"`
+	_             uint8
+}
+
+// SCTPPeerAddrInfo represents the C struct sctp_paddrinfo for SCTP peer address information.
+//
+//sctp:type struct sctp_paddrinfo
+type SCTPPeerAddrInfo struct {
+	AssocId int32     `c:"spinfo_assoc_id"`
+	Addr    [128]byte `c:"spinfo_address"`
+	State   int32     `c:"spinfo_state"`
+	Cwnd    uint32    `c:"spinfo_cwnd"`
+	Srtt    uint32    `c:"spinfo_srtt"`
+	Rto     uint32    `c:"spinfo_rto"`
+	Mtu     uint32    `c:"spinfo_mtu"`
+}
+
+// SCTPAssocValue represents the C struct sctp_assoc_value for SCTP association value parameters.
+//
+//sctp:type struct sctp_assoc_value
+type SCTPAssocValue struct {
+	Id    int32  `c:"assoc_id"`
+	Value uint32 `c:"assoc_value"`
+}
+
+// SCTPSackInfo represents the C struct sctp_sack_info for SCTP selective acknowledgment information.
+//
+//sctp:type struct sctp_sack_info
+type SCTPSackInfo struct {
+	AssocId int32  `c:"sack_assoc_id"`
+	Delay   uint32 `c:"sack_delay"`
+	Freq    uint32 `c:"sack_freq"`
+}
+
+// SCTPStreamValue represents the C struct sctp_stream_value for SCTP stream value parameters.
+//
+//sctp:type struct sctp_stream_value
+type SCTPStreamValue struct {
+	AssocId     int32  `c:"assoc_id"`
+	StreamId    uint16 `c:"stream_id"`
+	StreamValue uint16 `c:"stream_value"`
+}
+
+// SCTPStatus represents the C struct sctp_status for SCTP association status.
+//
+//sctp:type struct sctp_status
+type SCTPStatus struct {
+	AssocId            int32            `c:"sstat_assoc_id"`
+	State              int32            `c:"sstat_state"`
+	Rwnd               uint32           `c:"sstat_rwnd"`
+	UnackedData        uint16           `c:"sstat_unackdata"`
+	PendingData        uint16           `c:"sstat_penddata"`
+	InStreams          uint16           `c:"sstat_instrms"`
+	OutStreams         uint16           `c:"sstat_outstrms"`
+	FragmentationPoint uint32           `c:"sstat_fragmentation_point"`
+	Primary            SCTPPeerAddrInfo `c:"sstat_primary"`
+}
+
+// SCTPAuthKeyId represents the C struct sctp_authkeyid for SCTP authentication key ID.
+//
+//sctp:type struct sctp_authkeyid
+type SCTPAuthKeyId struct {
+	AssocId   int32  `c:"scact_assoc_id"`
+	KeyNumber uint16 `c:"scact_keynumber"`
+	_         uint16
+}
+
+// SCTPAuthKey represents the C struct sctp_authkey for SCTP authentication key.
+//
+//sctp:type struct sctp_authkey
+type SCTPAuthKey struct {
+	AssocId   int32  `c:"sca_assoc_id"`
+	KeyNumber uint16 `c:"sca_keynumber"`
+	KeyLength uint16 `c:"sca_keylength"`
+	// Member Key is removed as it is variable sized array.
+}
+
+// SCTPAuthChunk represents the C struct sctp_authchunk for SCTP authentication chunk.
+//
+//sctp:type struct sctp_authchunk
+type SCTPAuthChunk struct {
+	Chunk uint8 `c:"sauth_chunk"`
+}
+
+// SCTPHmacAlgo represents the C struct sctp_hmacalgo for SCTP HMAC algorithm.
+//
+//sctp:type struct sctp_hmacalgo
+type SCTPHmacAlgo struct {
+	NumIdents uint32 `c:"shmac_num_idents"`
+	// Member Idents is removed as it is variable sized array.
+}
+
+// SCTPAuthChunks represents the C struct sctp_authchunks for SCTP authentication chunks.
+//
+//sctp:type struct sctp_authchunks
+type SCTPAuthChunks struct {
+	AssocId      int32  `c:"gauth_assoc_id"`
+	NumberChunks uint32 `c:"gauth_number_of_chunks"`
+	// Member Chunks is removed as it is variable sized array.
+}
+
+// SCTPAssocIds represents the C struct sctp_assoc_ids for SCTP association IDs.
+//
+//sctp:type struct sctp_assoc_ids
+type SCTPAssocIds struct {
+	NumberIds uint32 `c:"gaids_number_of_ids"`
+	// Member Ids is removed as it is variable sized array.
+}
+
+// SCTPAssocStats represents the C struct sctp_assoc_stats for SCTP association statistics.
+//
+//sctp:type struct sctp_assoc_stats
+type SCTPAssocStats struct {
+	AssocId      int32           `c:"sas_assoc_id"`
+	Addr         SockAddrStorage `c:"sas_obs_rto_ipaddr"`
+	MaxRto       uint64          `c:"sas_maxrto"`
+	ISacks       uint64          `c:"sas_isacks"`
+	OSacks       uint64          `c:"sas_osacks"`
+	OPackets     uint64          `c:"sas_opackets"`
+	IPackets     uint64          `c:"sas_ipackets"`
+	RtxChunks    uint64          `c:"sas_rtxchunks"`
+	OutOfSeqTsns uint64          `c:"sas_outofseqtsns"`
+	IDupChunks   uint64          `c:"sas_idupchunks"`
+	GapCnt       uint64          `c:"sas_gapcnt"`
+	OUodChunks   uint64          `c:"sas_ouodchunks"`
+	IUodChunks   uint64          `c:"sas_iuodchunks"`
+	OodChunks    uint64          `c:"sas_oodchunks"`
+	IodChunks    uint64          `c:"sas_iodchunks"`
+	OCtrlChunks  uint64          `c:"sas_octrlchunks"`
+	ICtrlChunks  uint64          `c:"sas_ictrlchunks"`
+}
+
+// SCTPPeerAddrThresholds represents the C struct sctp_paddrthlds for SCTP peer address thresholds.
+//
+//sctp:type struct sctp_paddrthlds
+type SCTPPeerAddrThresholds struct {
+	AssocId         int32           `c:"spt_assoc_id"`
+	Address         SockAddrStorage `c:"spt_address"`
+	PathMaxRxt      uint16          `c:"spt_pathmaxrxt"`
+	PathPfThreshold uint16          `c:"spt_pathpfthld"`
+}
+
+// SCTPPRStatus represents the C struct sctp_prstatus for SCTP partial reliability status.
+//
+//sctp:type struct sctp_prstatus
+type SCTPPRStatus struct {
+	AssocId         int32  `c:"sprstat_assoc_id"`
+	Sid             uint16 `c:"sprstat_sid"`
+	Policy          uint16 `c:"sprstat_policy"`
+	AbandonedUnsent uint64 `c:"sprstat_abandoned_unsent"`
+	AbandonedSent   uint64 `c:"sprstat_abandoned_sent"`
+}
+
+// SCTPDefaultPRInfo represents the C struct sctp_default_prinfo for SCTP default partial reliability information.
+//
+//sctp:type struct sctp_default_prinfo
+type SCTPDefaultPRInfo struct {
+	AssocId int32  `c:"pr_assoc_id"`
+	Value   uint32 `c:"pr_value"`
+	Policy  uint16 `c:"pr_policy"`
+}
+
+// SCTPEvent represents the C struct sctp_event for SCTP event parameters.
+//
+//sctp:type struct sctp_event
+type SCTPEvent struct {
+	AssocId int32  `c:"se_assoc_id"`
+	Type    uint16 `c:"se_type"`
+	On      uint8  `c:"se_on"`
+}
+
+// SCTPInfo represents the C struct sctp_info for SCTP association information.
+//
+//lint:ignore U1000 "auto-generated"
+//sctp:type struct sctp_info
+type SCTPInfo struct {
+	Tag                      uint32          `c:"sctpi_tag"`
+	State                    uint32          `c:"sctpi_state"`
+	Rwnd                     uint32          `c:"sctpi_rwnd"`
+	UnackedData              uint16          `c:"sctpi_unackdata"`
+	PendingData              uint16          `c:"sctpi_penddata"`
+	InStreams                uint16          `c:"sctpi_instrms"`
+	OutStreams               uint16          `c:"sctpi_outstrms"`
+	FragmentationPoint       uint32          `c:"sctpi_fragmentation_point"`
+	InQueue                  uint32          `c:"sctpi_inqueue"`
+	OutQueue                 uint32          `c:"sctpi_outqueue"`
+	OverallError             uint32          `c:"sctpi_overall_error"`
+	MaxBurst                 uint32          `c:"sctpi_max_burst"`
+	MaxSeg                   uint32          `c:"sctpi_maxseg"`
+	PeerRwnd                 uint32          `c:"sctpi_peer_rwnd"`
+	PeerTag                  uint32          `c:"sctpi_peer_tag"`
+	PeerCapable              uint8           `c:"sctpi_peer_capable"`
+	PeerSack                 uint8           `c:"sctpi_peer_sack"`
+	_1                       uint16          `c:"__reserved1"`
+	ISacks                   uint64          `c:"sctpi_isacks"`
+	OSacks                   uint64          `c:"sctpi_osacks"`
+	OPackets                 uint64          `c:"sctpi_opackets"`
+	IPackets                 uint64          `c:"sctpi_ipackets"`
+	RtxChunks                uint64          `c:"sctpi_rtxchunks"`
+	OutOfSeqTsns             uint64          `c:"sctpi_outofseqtsns"`
+	IDupChunks               uint64          `c:"sctpi_idupchunks"`
+	GapCount                 uint64          `c:"sctpi_gapcnt"`
+	OUodChunks               uint64          `c:"sctpi_ouodchunks"`
+	IUodChunks               uint64          `c:"sctpi_iuodchunks"`
+	OOdChunks                uint64          `c:"sctpi_oodchunks"`
+	IOdChunks                uint64          `c:"sctpi_iodchunks"`
+	OCtrlChunks              uint64          `c:"sctpi_octrlchunks"`
+	ICtrlChunks              uint64          `c:"sctpi_ictrlchunks"`
+	PrimaryAddress           SockAddrStorage `c:"sctpi_p_address"`
+	PrimaryState             int32           `c:"sctpi_p_state"`
+	PrimaryCwnd              uint32          `c:"sctpi_p_cwnd"`
+	PrimarySrtt              uint32          `c:"sctpi_p_srtt"`
+	PrimaryRto               uint32          `c:"sctpi_p_rto"`
+	PrimaryHbInterval        uint32          `c:"sctpi_p_hbinterval"`
+	PrimaryPathMaxRxt        uint32          `c:"sctpi_p_pathmaxrxt"`
+	PrimarySackDelay         uint32          `c:"sctpi_p_sackdelay"`
+	PrimarySackFreq          uint32          `c:"sctpi_p_sackfreq"`
+	PrimarySsThreshold       uint32          `c:"sctpi_p_ssthresh"`
+	PrimaryPartialBytesAcked uint32          `c:"sctpi_p_partial_bytes_acked"`
+	PrimaryFlightSize        uint32          `c:"sctpi_p_flight_size"`
+	PrimaryError             uint16          `c:"sctpi_p_error"`
+	_2                       uint16          `c:"__reserved2"`
+	SockAutoClose            uint32          `c:"sctpi_s_autoclose"`
+	SockAdaptationInd        uint32          `c:"sctpi_s_adaptation_ind"`
+	SockPdPoint              uint32          `c:"sctpi_s_pd_point"`
+	SockNodelay              uint8           `c:"sctpi_s_nodelay"`
+	SockDisableFragments     uint8           `c:"sctpi_s_disable_fragments"`
+	SockV4Mapped             uint8           `c:"sctpi_s_v4mapped"`
+	SockFragInterleave       uint8           `c:"sctpi_s_frag_interleave"`
+	SockType                 uint32          `c:"sctpi_s_type"`
+	_3                       uint32          `c:"__reserved3"`
+}
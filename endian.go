@@ -2,46 +2,59 @@ package sctp_go
 
 import (
 	"encoding/binary"
+	"unsafe"
 )
 
+// Fields of the kernel structs such as the address family, lengths and option values are in
+// host byte order and are encoded with endian, ports and addresses are in network byte order
+// and are always encoded with binary.BigEndian. endian is only replaced by the tests, which
+// run the encoders as on a host of the other byte order.
+var endian binary.ByteOrder = binary.NativeEndian
+
+// HostToNetworkShort converts a 16-bit integer from host to network byte order.
 func HostToNetworkShort(number uint16) uint16 {
-	if endian == binary.LittleEndian {
-		return (number << 8 & 0xff00) | (number >> 8 & 0x00ff)
-	}
-	return number
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], number)
+	return endian.Uint16(b[:])
 }
 
+// NetworkToHostShort converts a 16-bit integer from network to host byte order.
 func NetworkToHostShort(number uint16) uint16 {
-	if endian == binary.LittleEndian {
-		return (number << 8 & 0xff00) | (number >> 8 & 0x00ff)
-	}
-	return number
+	var b [2]byte
+	endian.PutUint16(b[:], number)
+	return binary.BigEndian.Uint16(b[:])
 }
 
+// HostToNetwork converts a 32-bit integer from host to network byte order.
 func HostToNetwork(number uint32) uint32 {
-	if endian == binary.LittleEndian {
-		return uint32(HostToNetworkShort(uint16(number)))<<16 | uint32(HostToNetworkShort(uint16(number>>16)))
-	}
-	return number
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], number)
+	return endian.Uint32(b[:])
 }
 
+// NetworkToHost converts a 32-bit integer from network to host byte order.
 func NetworkToHost(number uint32) uint32 {
-	if endian == binary.LittleEndian {
-		return uint32(NetworkToHostShort(uint16(number)))<<16 | uint32(NetworkToHostShort(uint16(number>>16)))
-	}
-	return number
+	var b [4]byte
+	endian.PutUint32(b[:], number)
+	return binary.BigEndian.Uint32(b[:])
 }
 
+// HostToNetworkLong converts a 64-bit integer from host to network byte order.
 func HostToNetworkLong(number uint64) uint64 {
-	if endian == binary.LittleEndian {
-		return uint64(HostToNetwork(uint32(number)))<<32 | uint64(HostToNetwork(uint32(number>>32)))
-	}
-	return number
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], number)
+	return endian.Uint64(b[:])
 }
 
+// NetworkToHostLong converts a 64-bit integer from network to host byte order.
 func NetworkToHostLong(number uint64) uint64 {
-	if endian == binary.LittleEndian {
-		return uint64(NetworkToHost(uint32(number)))<<32 | uint64(NetworkToHost(uint32(number>>32)))
-	}
-	return number
+	var b [8]byte
+	endian.PutUint64(b[:], number)
+	return binary.BigEndian.Uint64(b[:])
+}
+
+// networkPort returns the port of a socket address, which the kernel stores in network byte
+// order whatever the byte order of the host.
+func networkPort(port *uint16) int {
+	return int(binary.BigEndian.Uint16((*[2]byte)(unsafe.Pointer(port))[:]))
 }
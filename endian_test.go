@@ -0,0 +1,179 @@
+package sctp_go
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+// byteOrders are the host byte orders the encoders are run with, the big-endian one is
+// simulated on little-endian hosts.
+var byteOrders = []struct {
+	name  string
+	order binary.ByteOrder
+}{
+	{"little", binary.LittleEndian},
+	{"big", binary.BigEndian},
+}
+
+// withByteOrder runs the encoders of the test as on a host of the byte order.
+func withByteOrder(t *testing.T, order binary.ByteOrder) {
+	saved := endian
+	endian = order
+	t.Cleanup(func() { endian = saved })
+}
+
+func TestByteOrderConversions(t *testing.T) {
+	tests := []struct {
+		name  string
+		order binary.ByteOrder
+		short uint16
+		long  uint32
+		quad  uint64
+	}{
+		{"little", binary.LittleEndian, 0x3412, 0x78563412, 0xefcdab9078563412},
+		{"big", binary.BigEndian, 0x1234, 0x12345678, 0x1234567890abcdef},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			withByteOrder(t, test.order)
+			if got := HostToNetworkShort(0x1234); got != test.short {
+				t.Errorf("HostToNetworkShort = %#x, expected %#x", got, test.short)
+			}
+			if got := NetworkToHostShort(test.short); got != 0x1234 {
+				t.Errorf("NetworkToHostShort = %#x", got)
+			}
+			if got := HostToNetwork(0x12345678); got != test.long {
+				t.Errorf("HostToNetwork = %#x, expected %#x", got, test.long)
+			}
+			if got := NetworkToHost(test.long); got != 0x12345678 {
+				t.Errorf("NetworkToHost = %#x", got)
+			}
+			if got := HostToNetworkLong(0x1234567890abcdef); got != test.quad {
+				t.Errorf("HostToNetworkLong = %#x, expected %#x", got, test.quad)
+			}
+			if got := NetworkToHostLong(test.quad); got != 0x1234567890abcdef {
+				t.Errorf("NetworkToHostLong = %#x", got)
+			}
+		})
+	}
+}
+
+func TestMakeSockaddrByteOrder(t *testing.T) {
+	tests := []struct {
+		order binary.ByteOrder
+		addr  *SCTPAddr
+		want  []byte
+	}{
+		{
+			binary.LittleEndian,
+			&SCTPAddr{port: 5000, addresses: []net.IP{net.IPv4(127, 0, 0, 1)}},
+			[]byte{0x02, 0x00, 0x13, 0x88, 127, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			binary.BigEndian,
+			&SCTPAddr{port: 5000, addresses: []net.IP{net.IPv4(127, 0, 0, 1)}},
+			[]byte{0x00, 0x02, 0x13, 0x88, 127, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			binary.LittleEndian,
+			&SCTPAddr{port: 38412, addresses: []net.IP{net.IPv6loopback}},
+			append([]byte{0x0a, 0x00, 0x96, 0x0c, 0, 0, 0, 0}, append([]byte(net.IPv6loopback), 0, 0, 0, 0)...),
+		},
+		{
+			binary.BigEndian,
+			&SCTPAddr{port: 38412, addresses: []net.IP{net.IPv6loopback}},
+			append([]byte{0x00, 0x0a, 0x96, 0x0c, 0, 0, 0, 0}, append([]byte(net.IPv6loopback), 0, 0, 0, 0)...),
+		},
+	}
+	for _, test := range tests {
+		withByteOrder(t, test.order)
+		if got := MakeSockaddr(test.addr); !bytes.Equal(got, test.want) {
+			t.Errorf("%v %v: got % x, expected % x", test.order, test.addr, got, test.want)
+		}
+	}
+}
+
+func TestCmsgByteOrder(t *testing.T) {
+	var (
+		info = &SCTPSndRcvInfo{Stream: 1, Flags: SCTP_UNORDERED, Ppid: 0x01020304, AssocId: 7}
+		word = int(unsafe.Sizeof(syscall.Cmsghdr{}.Len))
+	)
+	for _, test := range byteOrders {
+		t.Run(test.name, func(t *testing.T) {
+			withByteOrder(t, test.order)
+			data := Pack(info)
+			if len(data) != SCTPSndRcvInfoSize {
+				t.Fatalf("packed %d bytes", len(data))
+			}
+			if test.order.Uint16(data) != 1 || test.order.Uint16(data[4:]) != SCTP_UNORDERED ||
+				test.order.Uint32(data[8:]) != 0x01020304 || test.order.Uint32(data[28:]) != 7 {
+				t.Errorf("unexpected sctp_sndrcvinfo % x", data)
+			}
+			buffer := AppendCmsg(nil, syscall.IPPROTO_SCTP, SCTP_SNDRCV, data)
+			if len(buffer) != syscall.CmsgSpace(SCTPSndRcvInfoSize) {
+				t.Fatalf("unexpected control message length %d", len(buffer))
+			}
+			var length uint64
+			if word == 8 {
+				length = test.order.Uint64(buffer)
+			} else {
+				length = uint64(test.order.Uint32(buffer))
+			}
+			if length != uint64(syscall.CmsgLen(SCTPSndRcvInfoSize)) ||
+				test.order.Uint32(buffer[word:]) != syscall.IPPROTO_SCTP ||
+				test.order.Uint32(buffer[word+4:]) != SCTP_SNDRCV {
+				t.Errorf("unexpected cmsghdr % x", buffer[:syscall.CmsgLen(0)])
+			}
+		})
+	}
+}
+
+func TestPeerAddrParamsByteOrder(t *testing.T) {
+	for _, test := range byteOrders {
+		t.Run(test.name, func(t *testing.T) {
+			withByteOrder(t, test.order)
+			params := &SCTPPeerAddrParams{AssocId: 3, HbInterval: 30000, PathMaxRxt: 5, Flags: SPP_HB_ENABLE, Dscp: 46}
+			copy(params.Addr[:], MakeSockaddr(&SCTPAddr{port: 5000, addresses: []net.IP{net.IPv4(10, 0, 0, 1)}}))
+			data := params.Pack()
+			if len(data) != SCTPPeerAddrParamsSize {
+				t.Fatalf("packed %d bytes", len(data))
+			}
+			if test.order.Uint32(data) != 3 || test.order.Uint16(data[4:]) != syscall.AF_INET ||
+				binary.BigEndian.Uint16(data[6:]) != 5000 || test.order.Uint32(data[132:]) != 30000 {
+				t.Errorf("unexpected sctp_paddrparams % x", data[:140])
+			}
+			var unpacked SCTPPeerAddrParams
+			unpacked.Unpack(data)
+			if unpacked != *params {
+				t.Errorf("unpacked %+v, expected %+v", unpacked, *params)
+			}
+		})
+	}
+}
+
+func TestSockaddrPort(t *testing.T) {
+	buffer := MakeSockaddr(&SCTPAddr{
+		port:      36412,
+		addresses: []net.IP{net.IPv4(192, 0, 2, 1), net.ParseIP("2001:db8::1")},
+	})
+	data := make([]byte, SCTPGetAddrsSize+len(buffer))
+	copy(data[SCTPGetAddrsSize:], buffer)
+	addrs := (*SCTPGetAddrs)(unsafe.Pointer(&data[0]))
+	addrs.Num = 2
+	addr := FromSCTPGetAddrs(addrs, len(data))
+	if addr == nil || addr.Port() != 36412 || len(addr.addresses) != 2 {
+		t.Fatalf("unexpected address %v", addr)
+	}
+	if addr := FromSCTPGetAddrs(addrs, len(data)-1); addr != nil {
+		t.Errorf("addresses beyond the length parsed: %v", addr)
+	}
+	storage := &SockAddrStorage{}
+	copy((*[SockAddrStorageSize]byte)(unsafe.Pointer(storage))[:], buffer[SockAddrInSize:])
+	if addr := FromSockAddrStorage(storage); addr == nil || addr.Port() != 36412 {
+		t.Errorf("unexpected address %v", addr)
+	}
+}
@@ -0,0 +1,352 @@
+package sctp_go
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"syscall"
+)
+
+// SendHandler is invoked from the engine loop with the result of an asynchronous send.
+type SendHandler func(n int, err error)
+
+// RecvHandler is invoked from the engine loop with a received message. With multishot
+// receives b is only valid for the duration of the call.
+type RecvHandler func(b []byte, info *SCTPSndRcvInfo, flags int, err error)
+
+// Engine submits SCTP socket messages asynchronously and invokes their handlers from Run.
+// Submitting and cancelling are safe to call concurrently with Run and from handlers.
+type Engine interface {
+	// SendMsg submits a message, b must not be modified until h is invoked.
+	SendMsg(fd int, b []byte, info *SCTPSndRcvInfo, h SendHandler) error
+	// RecvMsg submits a single receive into b.
+	RecvMsg(fd int, b []byte, h RecvHandler) error
+	// RecvMultishot keeps receiving messages of up to size bytes into engine owned
+	// buffers until the socket fails or Cancel is called.
+	RecvMultishot(fd int, size int, h RecvHandler) error
+	// Cancel cancels the operations pending on the file descriptor, their handlers are
+	// invoked with syscall.ECANCELED.
+	Cancel(fd int) error
+	// Run runs the completion loop until ctx is cancelled or the engine is closed.
+	Run(ctx context.Context) error
+	// Close releases the engine, a running Run returns nil. The handlers of the operations
+	// still pending are invoked with syscall.ECANCELED.
+	Close() error
+	// Backend returns "io_uring" or "epoll".
+	Backend() string
+}
+
+// NewEngine creates an io_uring engine with room for entries submissions, falling back to
+// an epoll engine when the kernel lacks io_uring or it is disabled.
+func NewEngine(entries int) (Engine, error) {
+	engine, err := NewRingEngine(entries)
+	if err == nil {
+		return engine, nil
+	}
+	if errors.Is(err, syscall.ENOSYS) || errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EACCES) {
+		return NewPollEngine()
+	}
+	return nil, err
+}
+
+// pollEngine implements Engine on top of a Poller, performing the queued operations with
+// non-blocking syscalls once the socket is ready.
+type pollEngine struct {
+	poller *Poller
+	mutex  sync.Mutex
+	states map[int]*pollState
+}
+
+// pollState holds the operations queued on a file descriptor.
+type pollState struct {
+	sends     []pollSend
+	recvs     []pollRecv
+	multishot RecvHandler
+	buffer    []byte
+	events    Events
+	// sending, receiving and streaming mark the first send, the first receive or the
+	// multishot receive as in flight in handle.
+	sending   bool
+	receiving bool
+	streaming bool
+}
+
+type pollSend struct {
+	data    []byte
+	info    *SCTPSndRcvInfo
+	handler SendHandler
+}
+
+type pollRecv struct {
+	data    []byte
+	handler RecvHandler
+}
+
+// NewPollEngine creates an engine driven by an epoll Poller.
+func NewPollEngine() (Engine, error) {
+	p, err := NewPoller()
+	if err != nil {
+		return nil, err
+	}
+	return &pollEngine{
+		poller: p,
+		states: make(map[int]*pollState),
+	}, nil
+}
+
+func (e *pollEngine) Backend() string {
+	return "epoll"
+}
+
+func (e *pollEngine) SendMsg(fd int, b []byte, info *SCTPSndRcvInfo, h SendHandler) error {
+	if h == nil {
+		return errors.New("handler cannot be nil")
+	}
+	return e.queue(fd, func(state *pollState) {
+		state.sends = append(state.sends, pollSend{data: b, info: info, handler: h})
+	})
+}
+
+func (e *pollEngine) RecvMsg(fd int, b []byte, h RecvHandler) error {
+	if h == nil {
+		return errors.New("handler cannot be nil")
+	}
+	return e.queue(fd, func(state *pollState) {
+		state.recvs = append(state.recvs, pollRecv{data: b, handler: h})
+	})
+}
+
+func (e *pollEngine) RecvMultishot(fd int, size int, h RecvHandler) error {
+	if h == nil {
+		return errors.New("handler cannot be nil")
+	}
+	if size <= 0 {
+		return syscall.EINVAL
+	}
+	e.mutex.Lock()
+	if state, ok := e.states[fd]; ok && state.multishot != nil {
+		e.mutex.Unlock()
+		return errors.New("multishot receive already active")
+	}
+	e.mutex.Unlock()
+	return e.queue(fd, func(state *pollState) {
+		state.multishot = h
+		state.buffer = make([]byte, size)
+	})
+}
+
+// queue adds an operation to the state of fd and arms the poller for it.
+func (e *pollEngine) queue(fd int, add func(state *pollState)) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	state, ok := e.states[fd]
+	if !ok {
+		state = &pollState{}
+		e.states[fd] = state
+	}
+	add(state)
+	if err := e.arm(fd, state); err != nil {
+		if !ok {
+			delete(e.states, fd)
+		}
+		return err
+	}
+	return nil
+}
+
+// arm registers fd for the events its queued operations wait for, called with mutex held.
+func (e *pollEngine) arm(fd int, state *pollState) error {
+	var events Events
+	if len(state.recvs) > 0 || state.multishot != nil {
+		events |= EventIn
+	}
+	if len(state.sends) > 0 {
+		events |= EventOut
+	}
+	var err error
+	switch {
+	case events == state.events:
+	case events == 0:
+		delete(e.states, fd)
+		err = e.poller.Del(fd)
+	case state.events == 0:
+		err = e.poller.Add(fd, events, func(ev Events) {
+			e.handle(fd, ev)
+		})
+	default:
+		err = e.poller.Modify(fd, events)
+	}
+	if err == nil {
+		state.events = events
+	}
+	return err
+}
+
+// handle performs the operations of fd until the socket would block. The operation running
+// its syscall is marked in flight, a concurrent Cancel or Close leaves it to handle so that
+// its handler is invoked exactly once.
+func (e *pollEngine) handle(fd int, ev Events) {
+	failed := ev&(EventErr|EventHup) != 0
+	if ev.IsWritable() || failed {
+		for {
+			e.mutex.Lock()
+			state := e.states[fd]
+			if state == nil || len(state.sends) == 0 {
+				e.mutex.Unlock()
+				break
+			}
+			send := state.sends[0]
+			state.sending = true
+			e.mutex.Unlock()
+			var (
+				control controlBuffer
+				buffer  = control.bytes()
+			)
+			n, err := SCTPSendMsg(fd, send.data, buffer[:EncodeSndRcvInfo(buffer, send.info)], syscall.MSG_DONTWAIT)
+			e.mutex.Lock()
+			state.sending = false
+			cancelled := e.states[fd] != state
+			if err == syscall.EAGAIN && !cancelled {
+				e.mutex.Unlock()
+				break
+			}
+			state.sends = state.sends[1:]
+			e.mutex.Unlock()
+			if err == syscall.EAGAIN {
+				err = syscall.ECANCELED
+			}
+			send.handler(n, err)
+		}
+	}
+	if ev.IsReadable() || ev.IsHangup() || failed {
+		for {
+			e.mutex.Lock()
+			state := e.states[fd]
+			if state == nil || (len(state.recvs) == 0 && state.multishot == nil) {
+				e.mutex.Unlock()
+				break
+			}
+			var recv pollRecv
+			if len(state.recvs) > 0 {
+				recv = state.recvs[0]
+				state.receiving = true
+			} else {
+				recv = pollRecv{data: state.buffer, handler: state.multishot}
+				state.streaming = true
+			}
+			e.mutex.Unlock()
+			var (
+				control controlBuffer
+				info    SCTPSndRcvInfo
+				oob     = control.bytes()
+			)
+			n, noob, flags, err := SCTPRecvMsg(fd, recv.data, oob, syscall.MSG_DONTWAIT)
+			e.mutex.Lock()
+			cancelled := e.states[fd] != state
+			if err == syscall.EAGAIN && !cancelled {
+				state.receiving = false
+				state.streaming = false
+				e.mutex.Unlock()
+				break
+			}
+			multishot := state.streaming
+			if state.receiving {
+				state.recvs = state.recvs[1:]
+			} else if err != nil || n == 0 || cancelled {
+				state.multishot = nil
+				state.buffer = nil
+			}
+			state.receiving = false
+			state.streaming = false
+			e.mutex.Unlock()
+			if err == syscall.EAGAIN {
+				err = syscall.ECANCELED
+			}
+			if noob > 0 {
+				ParseSndRcvInfo(&info, oob[:noob])
+			}
+			recv.handler(recv.data[:n], &info, flags, err)
+			if err != nil {
+				break
+			}
+			if cancelled && multishot && n > 0 {
+				recv.handler(nil, nil, 0, syscall.ECANCELED)
+			}
+		}
+	}
+	e.mutex.Lock()
+	if state := e.states[fd]; state != nil {
+		_ = e.arm(fd, state)
+	}
+	e.mutex.Unlock()
+}
+
+func (e *pollEngine) Cancel(fd int) error {
+	e.mutex.Lock()
+	state, ok := e.states[fd]
+	if !ok {
+		e.mutex.Unlock()
+		return nil
+	}
+	delete(e.states, fd)
+	pending := state.pending()
+	e.mutex.Unlock()
+	err := e.poller.Del(fd)
+	pending.cancel()
+	return err
+}
+
+// pending takes the operations out of a state removed from the engine, except those in
+// flight in handle, called with mutex held.
+func (state *pollState) pending() *pollState {
+	var (
+		taken = &pollState{}
+		sends = 0
+		recvs = 0
+	)
+	if state.sending {
+		sends = 1
+	}
+	if state.receiving {
+		recvs = 1
+	}
+	taken.sends, state.sends = state.sends[sends:], state.sends[:sends]
+	taken.recvs, state.recvs = state.recvs[recvs:], state.recvs[:recvs]
+	if !state.streaming {
+		taken.multishot, state.multishot = state.multishot, nil
+	}
+	return taken
+}
+
+// cancel invokes the handlers of the operations taken out of a state with
+// syscall.ECANCELED.
+func (state *pollState) cancel() {
+	for _, send := range state.sends {
+		send.handler(0, syscall.ECANCELED)
+	}
+	for _, recv := range state.recvs {
+		recv.handler(nil, nil, 0, syscall.ECANCELED)
+	}
+	if state.multishot != nil {
+		state.multishot(nil, nil, 0, syscall.ECANCELED)
+	}
+}
+
+func (e *pollEngine) Run(ctx context.Context) error {
+	return e.poller.Run(ctx)
+}
+
+func (e *pollEngine) Close() error {
+	err := e.poller.Close()
+	e.mutex.Lock()
+	pending := make([]*pollState, 0, len(e.states))
+	for _, state := range e.states {
+		pending = append(pending, state.pending())
+	}
+	e.states = make(map[int]*pollState)
+	e.mutex.Unlock()
+	for _, state := range pending {
+		state.cancel()
+	}
+	return err
+}
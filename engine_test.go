@@ -0,0 +1,354 @@
+package sctp_go
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestEngine(t *testing.T) {
+	backends := map[string]func() (Engine, error){
+		"io_uring": func() (Engine, error) { return NewRingEngine(16) },
+		"epoll":    NewPollEngine,
+	}
+	for name, create := range backends {
+		t.Run(name, func(t *testing.T) {
+			engine, err := create()
+			if err != nil {
+				t.Skip("engine not available: ", err)
+			}
+			if engine.Backend() != name {
+				t.Errorf("unexpected backend %s", engine.Backend())
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() {
+				done <- engine.Run(ctx)
+			}()
+			conn, peer := socketpair(t)
+
+			sent := make(chan int, 1)
+			if err := conn.SendAsync(engine, []byte("hello"), nil, func(n int, err error) {
+				if err != nil {
+					t.Error(err)
+				}
+				sent <- n
+			}); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case n := <-sent:
+				if n != 5 {
+					t.Errorf("expected 5 bytes sent, got %d", n)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for send completion")
+			}
+			var buffer [64]byte
+			if n, err := syscall.Read(peer, buffer[:]); err != nil || string(buffer[:n]) != "hello" {
+				t.Fatalf("unexpected message %q: %v", buffer[:n], err)
+			}
+
+			received := make(chan string, 8)
+			handler := func(b []byte, info *SCTPSndRcvInfo, flags int, err error) {
+				if err != nil {
+					received <- err.Error()
+					return
+				}
+				received <- string(b)
+			}
+			wait := func(expected string) {
+				select {
+				case message := <-received:
+					if message != expected {
+						t.Errorf("expected %q, got %q", expected, message)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("timed out waiting for %q", expected)
+				}
+			}
+			if err := conn.RecvAsync(engine, make([]byte, 64), handler); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := syscall.Write(peer, []byte("single")); err != nil {
+				t.Fatal(err)
+			}
+			wait("single")
+
+			if err := conn.RecvMultishot(engine, 64, handler); err != nil {
+				t.Fatal(err)
+			}
+			for _, message := range []string{"one", "two", "three"} {
+				if _, err := syscall.Write(peer, []byte(message)); err != nil {
+					t.Fatal(err)
+				}
+				wait(message)
+			}
+			if err := engine.Cancel(int(conn.FD())); err != nil {
+				t.Fatal(err)
+			}
+			wait(syscall.ECANCELED.Error())
+
+			cancel()
+			select {
+			case err := <-done:
+				if err != context.Canceled {
+					t.Errorf("expected context.Canceled, got %v", err)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("engine did not stop on cancel")
+			}
+			if err := engine.Close(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestEngineHoldsDescriptor(t *testing.T) {
+	backends := map[string]func() (Engine, error){
+		"io_uring": func() (Engine, error) { return NewRingEngine(16) },
+		"epoll":    NewPollEngine,
+	}
+	for name, create := range backends {
+		t.Run(name, func(t *testing.T) {
+			engine, err := create()
+			if err != nil {
+				t.Skip("engine not available: ", err)
+			}
+			conn, _ := socketpair(t)
+			fd := int(conn.FD())
+			result := make(chan error, 1)
+			if err := conn.RecvAsync(engine, make([]byte, 64), func(b []byte, info *SCTPSndRcvInfo, flags int, err error) {
+				if conn.fd.sysfd() != fd {
+					t.Error("socket closed before the handler returned")
+				}
+				result <- err
+			}); err != nil {
+				t.Fatal(err)
+			}
+			// The pending receive keeps the socket open, closing the engine cancels it.
+			_ = conn.Close()
+			if conn.fd.sysfd() != fd {
+				t.Fatal("socket closed with a receive pending")
+			}
+			if err := engine.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if err := <-result; err != syscall.ECANCELED {
+				t.Errorf("expected ECANCELED, got %v", err)
+			}
+			if conn.fd.sysfd() != -1 {
+				t.Error("socket not closed once the receive completed")
+			}
+		})
+	}
+}
+
+func TestEngineCancelRace(t *testing.T) {
+	backends := map[string]func() (Engine, error){
+		"io_uring": func() (Engine, error) { return NewRingEngine(64) },
+		"epoll":    NewPollEngine,
+	}
+	// The race needs the engine loop and the cancelling goroutine running in parallel.
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	for name, create := range backends {
+		for _, closing := range []bool{false, true} {
+			mode := "cancel"
+			if closing {
+				mode = "close"
+			}
+			t.Run(name+"/"+mode, func(t *testing.T) {
+				for round := 0; round < 2000; round++ {
+					raceEngine(t, create, closing, round)
+				}
+			})
+		}
+	}
+}
+
+// raceEngine cancels or closes a running engine while it completes sends and receives on a
+// socketpair, and checks that every handler is invoked exactly once.
+func raceEngine(t *testing.T, create func() (Engine, error), closing bool, round int) {
+	engine, err := create()
+	if err != nil {
+		t.Skip("engine not available: ", err)
+	}
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_SEQPACKET, 0)
+	if err != nil {
+		t.Skip("socketpair not available: ", err)
+	}
+	defer syscall.Close(fds[0])
+	defer syscall.Close(fds[1])
+
+	const ops = 32
+	var (
+		calls     [2 * ops]atomic.Int32
+		completed = make(chan struct{}, 4*ops)
+	)
+	// The operations are all ready once Run starts, the engine completes them one by one
+	// while it is cancelled or closed after a delay varying with the round.
+	for i := 0; i < ops; i++ {
+		i := i
+		if err := engine.SendMsg(fds[0], []byte("ping"), nil, func(n int, err error) {
+			calls[i].Add(1)
+			completed <- struct{}{}
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if err := engine.RecvMsg(fds[0], make([]byte, 16), func(b []byte, info *SCTPSndRcvInfo, flags int, err error) {
+			calls[ops+i].Add(1)
+			completed <- struct{}{}
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := syscall.Write(fds[1], []byte("pong")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- engine.Run(ctx)
+	}()
+	for deadline := time.Now().Add(time.Duration(round%64) * time.Microsecond); time.Now().Before(deadline); {
+	}
+	if closing {
+		_ = engine.Close()
+	} else {
+		_ = engine.Cancel(fds[0])
+	}
+	for i := 0; i < 2*ops; i++ {
+		select {
+		case <-completed:
+		case <-time.After(time.Second):
+			t.Fatalf("round %d: %d of %d handlers invoked", round, i, 2*ops)
+		}
+	}
+	cancel()
+	<-done
+	if !closing {
+		_ = engine.Close()
+	}
+	for i := range calls {
+		if n := calls[i].Load(); n != 1 {
+			t.Fatalf("round %d: handler %d invoked %d times", round, i, n)
+		}
+	}
+}
+
+// runRingEngine returns a running io_uring engine, skipping when io_uring is unavailable.
+func runRingEngine(t *testing.T) *ringEngine {
+	engine, err := NewRingEngine(16)
+	if err != nil {
+		t.Skip("io_uring not available: ", err)
+	}
+	e := engine.(*ringEngine)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- e.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+		_ = e.Close()
+	})
+	return e
+}
+
+func TestRingEngineBatchesSubmissions(t *testing.T) {
+	e := runRingEngine(t)
+	conn, peer := socketpair(t)
+	const count = 8
+	var (
+		sent   = make(chan error, count)
+		before = make(chan uint64, 1)
+	)
+	// Submissions from a handler are queued and submitted by the next pass of Run.
+	if err := e.SendMsg(int(conn.FD()), []byte("first"), nil, func(n int, err error) {
+		before <- e.enters.Load()
+		for i := 0; i < count; i++ {
+			if err := e.SendMsg(int(conn.FD()), []byte("batched"), nil, func(n int, err error) {
+				sent <- err
+			}); err != nil {
+				sent <- err
+			}
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var enters uint64
+	select {
+	case enters = <-before:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the first send")
+	}
+	for i := 0; i < count; i++ {
+		select {
+		case err := <-sent:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the batched sends")
+		}
+	}
+	if calls := e.enters.Load() - enters; calls >= count {
+		t.Errorf("%d sends took %d io_uring_enter calls", count, calls)
+	}
+	var buffer [64]byte
+	for i := 0; i <= count; i++ {
+		if _, err := syscall.Read(peer, buffer[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRingEngineMultishotFallback(t *testing.T) {
+	e := runRingEngine(t)
+	conn, peer := socketpair(t)
+	buffers, err := e.register(64+ringRecvmsgOutSize+syscall.CmsgSpace(SCTPSndRcvInfoSize), 4)
+	if err != nil {
+		t.Skip("buffer rings not available: ", err)
+	}
+	received := make(chan string, 4)
+	op := &ringOp{
+		fd: int(conn.FD()),
+		recv: func(b []byte, info *SCTPSndRcvInfo, flags int, err error) {
+			if err != nil {
+				received <- err.Error()
+				return
+			}
+			received <- string(b)
+		},
+		multishot: buffers,
+		size:      64,
+	}
+	e.mutex.Lock()
+	e.sequence++
+	id := e.sequence
+	e.pending[id] = op
+	e.mutex.Unlock()
+	// A kernel registering buffer rings without multishot recvmsg rejects the receive.
+	e.complete(ringCqe{UserData: id, Res: -int32(syscall.EINVAL)})
+	if e.multishot.Load() != 0 {
+		t.Error("multishot receive still enabled")
+	}
+	for _, message := range []string{"one", "two"} {
+		if _, err := syscall.Write(peer, []byte(message)); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case got := <-received:
+			if got != message {
+				t.Errorf("expected %q, got %q", message, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %q", message)
+		}
+	}
+}
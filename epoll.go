@@ -1,23 +1,78 @@
 package sctp_go
 
 import (
+	"context"
+	"encoding/binary"
 	"errors"
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"syscall"
+
+	"golang.org/x/sys/unix"
 )
 
 const (
 	MaxNumberOfEvents = 64
 )
 
+// Events is a bit mask of epoll conditions, used both to register interest in a file
+// descriptor and to report which conditions fired.
+type Events uint32
+
+const (
+	EventIn            Events = unix.EPOLLIN
+	EventOut           Events = unix.EPOLLOUT
+	EventErr           Events = unix.EPOLLERR
+	EventHup           Events = unix.EPOLLHUP
+	EventRdHup         Events = unix.EPOLLRDHUP
+	EventEdgeTriggered Events = unix.EPOLLET
+	EventOneShot       Events = unix.EPOLLONESHOT
+)
+
+// IsReadable reports whether the file descriptor can be read without blocking.
+func (ev Events) IsReadable() bool {
+	return ev&EventIn != 0
+}
+
+// IsWritable reports whether the file descriptor can be written without blocking.
+func (ev Events) IsWritable() bool {
+	return ev&EventOut != 0
+}
+
+// IsError reports whether an error condition fired on the file descriptor.
+func (ev Events) IsError() bool {
+	return ev&EventErr != 0
+}
+
+// IsHangup reports whether the peer hung up, fully or for writing only.
+func (ev Events) IsHangup() bool {
+	return ev&(EventHup|EventRdHup) != 0
+}
+
 // Callback represents a function that handles epoll events for a file descriptor.
-type Callback func()
+// It receives the conditions that fired, EventErr and EventHup are always reported.
+type Callback func(ev Events)
 
 // Poller manages epoll-based event polling for file descriptors with associated callbacks.
+// Add, Modify, Del and Close are safe to call concurrently with Run and from callbacks.
 type Poller struct {
 	descriptor atomic.Int32
+	wakeup     atomic.Int32
 	callbacks  sync.Map
+	mutex      sync.Mutex
+	running    chan struct{}
+	closed     atomic.Bool
+}
+
+// registration holds the callback of a registered file descriptor. The mutex is held while
+// the callback runs, thread is the id of the OS thread running it so that Del called from
+// the callback does not wait for itself.
+type registration struct {
+	mutex    sync.Mutex
+	callback Callback
+	removed  atomic.Bool
+	thread   atomic.Int32
 }
 
 var (
@@ -25,8 +80,23 @@ var (
 )
 
 func init() {
-	poller = &Poller{}
-	poller.descriptor.Store(-1)
+	poller = newPoller()
+}
+
+func newPoller() *Poller {
+	p := &Poller{}
+	p.descriptor.Store(-1)
+	p.wakeup.Store(-1)
+	return p
+}
+
+// NewPoller creates and initializes a new independent poller.
+func NewPoller() (*Poller, error) {
+	p := newPoller()
+	if err := p.Init(); err != nil {
+		return nil, err
+	}
+	return p, nil
 }
 
 // GetPoller returns the global singleton poller instance.
@@ -34,95 +104,263 @@ func GetPoller() *Poller {
 	return poller
 }
 
-// Init initializes the epoll instance by creating an epoll file descriptor.
-// Returns an error if already initialized.
+// Init initializes the epoll instance by creating an epoll file descriptor and the eventfd
+// used to wake up Run. Returns an error if already initialized.
 func (p *Poller) Init() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	if p.IsInitialized() {
 		return errors.New("poller already initialized")
 	}
-	descriptor, err := syscall.EpollCreate1(0)
+	descriptor, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	if err != nil {
+		return err
+	}
+	wakeup, err := unix.Eventfd(0, unix.EFD_NONBLOCK|unix.EFD_CLOEXEC)
 	if err != nil {
+		syscall.Close(descriptor)
 		return err
 	}
+	err = syscall.EpollCtl(descriptor, syscall.EPOLL_CTL_ADD, wakeup,
+		&syscall.EpollEvent{
+			Fd:     int32(wakeup),
+			Events: syscall.EPOLLIN,
+		},
+	)
+	if err != nil {
+		syscall.Close(wakeup)
+		syscall.Close(descriptor)
+		return err
+	}
+	p.wakeup.Store(int32(wakeup))
 	p.descriptor.Store(int32(descriptor))
+	p.closed.Store(false)
 	return nil
 }
 
-// Finalize closes the epoll file descriptor if initialized.
-func (p *Poller) Finalize() {
-	descriptor := p.descriptor.Load()
-	if descriptor != -1 {
+// Close stops the event loop and closes the epoll and eventfd descriptors. A running Run
+// returns once the callback it is running, if any, returned and closes them itself, Close
+// does not wait for it.
+func (p *Poller) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.IsInitialized() || p.closed.Load() {
+		return errors.New("poller not initialized")
+	}
+	p.closed.Store(true)
+	if p.running != nil {
+		p.wake()
+		return nil
+	}
+	p.release()
+	return nil
+}
+
+// release closes the epoll and eventfd descriptors, the caller must hold the mutex.
+func (p *Poller) release() {
+	if wakeup := p.wakeup.Swap(-1); wakeup != -1 {
+		syscall.Close(int(wakeup))
+	}
+	if descriptor := p.descriptor.Swap(-1); descriptor != -1 {
 		syscall.Close(int(descriptor))
-		p.descriptor.Store(-1)
 	}
 }
 
+// Finalize closes the poller.
+//
+// Deprecated: use Close.
+func (p *Poller) Finalize() {
+	_ = p.Close()
+}
+
 // IsInitialized checks if the poller has been initialized with a valid epoll descriptor.
 func (p *Poller) IsInitialized() bool {
 	return p.descriptor.Load() != -1
 }
 
-// Add registers a file descriptor with the poller and associates it with a callback function.
+// Add registers a file descriptor with the poller for the given events and associates it
+// with a callback function. EventErr and EventHup are always reported by epoll.
 // Returns an error if the file descriptor is already registered.
-func (p *Poller) Add(fd int, cb Callback) error {
-	// Check if already registered
-	if _, exists := p.callbacks.Load(fd); exists {
+func (p *Poller) Add(fd int, events Events, cb Callback) error {
+	if cb == nil {
+		return errors.New("callback cannot be nil")
+	}
+	reg := &registration{
+		callback: cb,
+	}
+	if _, exists := p.callbacks.LoadOrStore(fd, reg); exists {
 		return errors.New("file descriptor already registered")
 	}
-
-	descriptor := p.descriptor.Load()
-	err := syscall.EpollCtl(int(descriptor), syscall.EPOLL_CTL_ADD, fd,
-		&syscall.EpollEvent{
-			Fd:     int32(fd),
-			Events: syscall.EPOLLIN,
-		},
-	)
-	if err != nil {
+	if err := p.control(syscall.EPOLL_CTL_ADD, fd, events); err != nil {
+		p.callbacks.Delete(fd)
 		return err
 	}
-	p.callbacks.Store(fd, cb)
 	return nil
 }
 
-// Del removes a file descriptor from the poller and deletes its associated callback.
+// Modify changes the events a registered file descriptor is polled for. It also re-arms
+// a file descriptor registered with EventOneShot.
 // Returns an error if the file descriptor is not registered.
-func (p *Poller) Del(fd int) error {
+func (p *Poller) Modify(fd int, events Events) error {
 	// Check if registered
 	if _, exists := p.callbacks.Load(fd); !exists {
 		return errors.New("file descriptor not registered")
 	}
+	return p.control(syscall.EPOLL_CTL_MOD, fd, events)
+}
+
+// Del removes a file descriptor from the poller and deletes its associated callback.
+// Once Del returns the callback is not invoked anymore, a callback running concurrently
+// on the event loop is waited for unless Del is called from that callback.
+// Returns an error if the file descriptor is not registered.
+func (p *Poller) Del(fd int) error {
+	value, exists := p.callbacks.LoadAndDelete(fd)
+	if !exists {
+		return errors.New("file descriptor not registered")
+	}
+	err := p.control(syscall.EPOLL_CTL_DEL, fd, 0)
+	value.(*registration).remove()
+	return err
+}
 
-	p.callbacks.Delete(fd)
+// control applies an epoll_ctl operation with the mutex held, so that the epoll descriptor
+// is not released and its number reused concurrently.
+func (p *Poller) control(op int, fd int, events Events) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	descriptor := p.descriptor.Load()
-	return syscall.EpollCtl(int(descriptor), syscall.EPOLL_CTL_DEL, fd, nil)
+	if descriptor == -1 {
+		return errors.New("poller not initialized")
+	}
+	var event *syscall.EpollEvent
+	if op != syscall.EPOLL_CTL_DEL {
+		event = &syscall.EpollEvent{
+			Fd:     int32(fd),
+			Events: uint32(events),
+		}
+	}
+	return syscall.EpollCtl(int(descriptor), op, fd, event)
 }
 
-// Loop runs the event loop, waiting for epoll events and executing associated callbacks.
-// The loop continues until an error occurs or the poller is not initialized.
+// Loop runs the event loop until the poller is closed.
+//
+// Deprecated: use Run.
 func (p *Poller) Loop() {
+	_ = p.Run(context.Background())
+}
+
+// Run runs the event loop, waiting for epoll events and executing associated callbacks.
+// It returns nil when the poller is closed, ctx.Err() when ctx is cancelled, or the error
+// returned by epoll. Only one Run may be active on a poller at a time, it keeps its
+// goroutine locked to its OS thread while running.
+func (p *Poller) Run(ctx context.Context) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	thread := int32(unix.Gettid())
+
+	p.mutex.Lock()
 	if !p.IsInitialized() {
-		return
+		p.mutex.Unlock()
+		return errors.New("poller not initialized")
+	}
+	if p.running != nil {
+		p.mutex.Unlock()
+		return errors.New("poller already running")
 	}
+	var (
+		running    = make(chan struct{})
+		descriptor = int(p.descriptor.Load())
+		wakeup     = int(p.wakeup.Load())
+	)
+	p.running = running
+	p.mutex.Unlock()
 
-	events := make([]syscall.EpollEvent, MaxNumberOfEvents)
-	descriptor := p.descriptor.Load()
+	defer func() {
+		p.mutex.Lock()
+		p.running = nil
+		if p.closed.Load() {
+			p.release()
+		}
+		p.mutex.Unlock()
+		close(running)
+	}()
 
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.mutex.Lock()
+			p.wake()
+			p.mutex.Unlock()
+		case <-stop:
+		}
+	}()
+
+	events := make([]syscall.EpollEvent, MaxNumberOfEvents)
 	for {
-		n, err := syscall.EpollWait(int(descriptor), events, 100)
+		if p.closed.Load() {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := syscall.EpollWait(descriptor, events, -1)
 		if err != nil {
 			if err == syscall.EINTR {
 				continue
 			}
-			// break on any other error
-			break
+			return err
 		}
 		for i := 0; i < n; i++ {
-			fd := events[i].Fd
-			if callback, ok := p.callbacks.Load(int(fd)); ok {
-				if handle, ok := callback.(Callback); ok {
-					handle()
-				}
+			fd := int(events[i].Fd)
+			if fd == wakeup {
+				var buffer [8]byte
+				_, _ = syscall.Read(wakeup, buffer[:])
+				continue
+			}
+			if value, ok := p.callbacks.Load(fd); ok {
+				value.(*registration).dispatch(Events(events[i].Events), thread)
 			}
 		}
 	}
 }
+
+// wake interrupts a blocking epoll wait of Run, the caller must hold the mutex so that
+// the eventfd is not released concurrently.
+func (p *Poller) wake() {
+	if wakeup := p.wakeup.Load(); wakeup != -1 {
+		var buffer [8]byte
+		binary.NativeEndian.PutUint64(buffer[:], 1)
+		_, _ = syscall.Write(int(wakeup), buffer[:])
+	}
+}
+
+// dispatch invokes the callback unless the registration was removed. The calling goroutine
+// must be locked to the OS thread identified by thread.
+func (reg *registration) dispatch(ev Events, thread int32) {
+	reg.mutex.Lock()
+	defer reg.mutex.Unlock()
+	if reg.removed.Load() {
+		return
+	}
+	reg.thread.Store(thread)
+	defer reg.thread.Store(0)
+	reg.callback(ev)
+}
+
+// remove marks the registration removed and waits for a running callback to return, unless
+// it is called from that callback. A goroutine locked to the thread running the callback is
+// the only one that can run on it, so a matching thread id identifies the callback itself.
+func (reg *registration) remove() {
+	reg.removed.Store(true)
+	if reg.mutex.TryLock() {
+		reg.mutex.Unlock()
+		return
+	}
+	if reg.thread.Load() == int32(unix.Gettid()) {
+		return
+	}
+	reg.mutex.Lock()
+	reg.mutex.Unlock()
+}
@@ -0,0 +1,216 @@
+package sctp_go
+
+import (
+	"context"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestPollerEvents(t *testing.T) {
+	p := &Poller{}
+	p.descriptor.Store(-1)
+	if err := p.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Finalize()
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_SEQPACKET, 0)
+	if err != nil {
+		t.Skip("socketpair not available: ", err)
+	}
+	defer syscall.Close(fds[0])
+	defer syscall.Close(fds[1])
+
+	fired := make(chan Events, 16)
+	if err := p.Add(fds[0], EventOut|EventOneShot, func(ev Events) {
+		select {
+		case fired <- ev:
+		default:
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	go p.Loop()
+	wait := func() Events {
+		select {
+		case ev := <-fired:
+			return ev
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+		return 0
+	}
+	if ev := wait(); !ev.IsWritable() || ev.IsReadable() {
+		t.Errorf("expected writable event, got %#x", ev)
+	}
+	if err := p.Modify(fds[0], EventIn); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := syscall.Write(fds[1], []byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	if ev := wait(); !ev.IsReadable() {
+		t.Errorf("expected readable event, got %#x", ev)
+	}
+	if err := p.Modify(fds[1], EventIn); err == nil {
+		t.Error("expected error modifying unregistered descriptor")
+	}
+	if err := p.Del(fds[0]); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPollerRun(t *testing.T) {
+	p, err := NewPoller()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- p.Run(ctx)
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+
+	go func() {
+		done <- p.Run(context.Background())
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected nil after Close, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Close")
+	}
+	if p.IsInitialized() {
+		t.Error("descriptors not released after Close")
+	}
+}
+
+func TestPollerDel(t *testing.T) {
+	p, err := NewPoller()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go p.Run(context.Background())
+	defer p.Close()
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_SEQPACKET, 0)
+	if err != nil {
+		t.Skip("socketpair not available: ", err)
+	}
+	defer syscall.Close(fds[0])
+	defer syscall.Close(fds[1])
+
+	var (
+		entered  = make(chan struct{})
+		release  = make(chan struct{})
+		finished atomic.Bool
+		calls    atomic.Int32
+	)
+	// The descriptor stays writable, the callback would fire again if it were not removed.
+	if err := p.Add(fds[0], EventOut, func(ev Events) {
+		if calls.Add(1) > 1 {
+			return
+		}
+		close(entered)
+		<-release
+		finished.Store(true)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-entered
+	removed := make(chan error, 1)
+	go func() {
+		removed <- p.Del(fds[0])
+	}()
+	select {
+	case <-removed:
+		t.Fatal("Del returned while the callback was running")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case err := <-removed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Del did not return once the callback returned")
+	}
+	if !finished.Load() {
+		t.Error("Del returned before the running callback")
+	}
+
+	deleted := make(chan struct{})
+	if err := p.Add(fds[1], EventOut, func(ev Events) {
+		calls.Add(1)
+		_ = p.Del(fds[1])
+		close(deleted)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-deleted:
+	case <-time.After(time.Second):
+		t.Fatal("Del from callback did not return")
+	}
+	time.Sleep(10 * time.Millisecond)
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 callbacks, got %d", calls.Load())
+	}
+}
+
+func TestPollerCloseFromCallback(t *testing.T) {
+	p, err := NewPoller()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_SEQPACKET, 0)
+	if err != nil {
+		t.Skip("socketpair not available: ", err)
+	}
+	defer syscall.Close(fds[0])
+	defer syscall.Close(fds[1])
+	closed := make(chan error, 1)
+	if err := p.Add(fds[0], EventOut, func(ev Events) {
+		select {
+		case closed <- p.Close():
+		default:
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- p.Run(context.Background())
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected nil after Close, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Close from a callback")
+	}
+	if err := <-closed; err != nil {
+		t.Error(err)
+	}
+	if p.IsInitialized() {
+		t.Error("descriptors not released once Run returned")
+	}
+}
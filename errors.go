@@ -0,0 +1,79 @@
+package sctp_go
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+var (
+	// ErrListenerClosed is returned by operations on a closed listener, it matches net.ErrClosed.
+	ErrListenerClosed = fmt.Errorf("listener closed: %w", net.ErrClosed)
+	// ErrInvalidNotification is returned when notification data is of an unknown type or malformed.
+	ErrInvalidNotification = errors.New("invalid notification")
+	// ErrShortBuffer is returned when data is too short for the structure parsed from it.
+	ErrShortBuffer = errors.New("short buffer")
+	// ErrAssociationNotFound is returned by operations on an association unknown to the socket.
+	ErrAssociationNotFound = errors.New("association not found")
+	// ErrNilArgument is returned when a required argument is nil.
+	ErrNilArgument = errors.New("nil argument")
+)
+
+// SyscallError records the system call that failed in a socket operation, it is carried in
+// the Err field of the *net.OpError returned by SCTPConn and SCTPListener. It matches its
+// Errno with errors.Is.
+type SyscallError struct {
+	Syscall string
+	Errno   syscall.Errno
+}
+
+// NewSyscallError wraps err in a *SyscallError when it is a syscall.Errno, other errors and
+// nil are returned unchanged.
+func NewSyscallError(call string, err error) error {
+	if errno, ok := err.(syscall.Errno); ok {
+		return &SyscallError{
+			Syscall: call,
+			Errno:   errno,
+		}
+	}
+	return err
+}
+
+func (e *SyscallError) Error() string {
+	return e.Syscall + ": " + e.Errno.Error()
+}
+
+func (e *SyscallError) Unwrap() error {
+	return e.Errno
+}
+
+// Timeout reports whether the operation timed out, including an association that failed
+// with ETIMEDOUT after exhausting its retransmissions.
+func (e *SyscallError) Timeout() bool {
+	return e.Errno == syscall.ETIMEDOUT || e.Errno.Timeout()
+}
+
+// Temporary reports whether retrying may succeed. Besides the transient errno values this
+// includes associations reset, refused or timed out by the peer, a new association may be
+// set up to the same peer.
+func (e *SyscallError) Temporary() bool {
+	switch e.Errno {
+	case syscall.ECONNRESET, syscall.ECONNREFUSED, syscall.ECONNABORTED, syscall.ETIMEDOUT:
+		return true
+	}
+	return e.Errno.Temporary()
+}
+
+// associationError reports an association unknown to the socket, keeping its id.
+type associationError struct {
+	assoc int
+}
+
+func (e *associationError) Error() string {
+	return fmt.Sprintf("%s: %d", ErrAssociationNotFound, e.assoc)
+}
+
+func (e *associationError) Unwrap() error {
+	return ErrAssociationNotFound
+}
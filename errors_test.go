@@ -0,0 +1,125 @@
+package sctp_go
+
+import (
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestSyscallError(t *testing.T) {
+	tests := []struct {
+		errno     syscall.Errno
+		timeout   bool
+		temporary bool
+	}{
+		{syscall.ETIMEDOUT, true, true},
+		{syscall.ECONNRESET, false, true},
+		{syscall.ECONNREFUSED, false, true},
+		{syscall.EAGAIN, true, true},
+		{syscall.EBADF, false, false},
+	}
+	for _, test := range tests {
+		err := NewSyscallError("sendmsg", test.errno).(*SyscallError)
+		if err.Timeout() != test.timeout {
+			t.Errorf("%v: expected timeout %v", test.errno, test.timeout)
+		}
+		if err.Temporary() != test.temporary {
+			t.Errorf("%v: expected temporary %v", test.errno, test.temporary)
+		}
+	}
+	if err := NewSyscallError("close", net.ErrClosed); err != net.ErrClosed {
+		t.Errorf("expected non errno error unchanged, got %v", err)
+	}
+	if NewSyscallError("close", nil) != nil {
+		t.Error("expected nil error unchanged")
+	}
+}
+
+func TestConnErrors(t *testing.T) {
+	conn, peer := socketpair(t)
+	syscall.Close(peer)
+	_, err := conn.SendMsg([]byte("data"), nil)
+	var operr *net.OpError
+	if !errors.As(err, &operr) || operr.Op != "write" || operr.Net != "sctp" {
+		t.Fatalf("expected write *net.OpError, got %#v", err)
+	}
+	var syserr *SyscallError
+	if !errors.As(err, &syserr) || syserr.Syscall != "sendmsg" || !errors.Is(err, syscall.EPIPE) {
+		t.Errorf("expected sendmsg EPIPE, got %v", err)
+	}
+	_ = conn.Close()
+	if err := conn.Close(); !errors.Is(err, net.ErrClosed) || !errors.As(err, &operr) {
+		t.Errorf("expected net.ErrClosed in *net.OpError, got %v", err)
+	}
+	if !errors.Is(ErrListenerClosed, net.ErrClosed) {
+		t.Error("expected ErrListenerClosed to match net.ErrClosed")
+	}
+	if !errors.Is(&associationError{assoc: 1}, ErrAssociationNotFound) {
+		t.Error("expected association error to match ErrAssociationNotFound")
+	}
+}
+
+func TestNilArgumentErrors(t *testing.T) {
+	conn, peer := socketpair(t)
+	defer syscall.Close(peer)
+	defer conn.Close()
+	listener := &SCTPListener{sock: peer}
+	errs := map[string]error{
+		"conn events":     conn.SetEventSubscribe(nil),
+		"conn init":       conn.SetInitMsg(nil),
+		"listener events": listener.SetEventSubscribe(nil),
+		"listener init":   listener.SetInitMsg(nil),
+	}
+	_, errs["listen address"] = ListenSCTP("sctp", syscall.SOCK_STREAM, nil, &SCTPInitMsg{})
+	_, errs["listen init"] = ListenSCTP("sctp", syscall.SOCK_STREAM, &SCTPAddr{}, nil)
+	for name, err := range errs {
+		var operr *net.OpError
+		if !errors.As(err, &operr) || !errors.Is(err, ErrNilArgument) {
+			t.Errorf("%s: expected ErrNilArgument in *net.OpError, got %v", name, err)
+		}
+	}
+}
+
+func TestConnErrorAddrs(t *testing.T) {
+	conn, peer := socketpair(t)
+	defer syscall.Close(peer)
+	defer conn.Close()
+	addrs := &errorAddrs{local: &SCTPAddr{}, remote: &SCTPAddr{}}
+	conn.addrs.Store(addrs)
+	conn.nonblock.Store(true)
+	var operr *net.OpError
+	err := conn.wrap("write", "sendmsg", syscall.EAGAIN)
+	if !errors.As(err, &operr) || operr.Source != addrs.local || operr.Addr != addrs.remote {
+		t.Fatalf("expected cached addresses, got %#v", err)
+	}
+	var wouldblock *WouldBlockError
+	if !errors.As(err, &wouldblock) {
+		t.Errorf("expected *WouldBlockError, got %v", err)
+	}
+}
+
+func TestNotificationErrors(t *testing.T) {
+	if _, err := ParseNotification(make([]byte, 2)); err != ErrShortBuffer {
+		t.Errorf("expected ErrShortBuffer, got %v", err)
+	}
+	if _, err := ParseAssocChangeEvent(make([]byte, SCTPNotificationHeaderSize)); err != ErrShortBuffer {
+		t.Errorf("expected ErrShortBuffer, got %v", err)
+	}
+	// Data beyond the notification union, as the message following SCTP_SEND_FAILED, is accepted.
+	data := append(Pack(&SCTPSendFailed{Type: SCTP_SEND_FAILED}), make([]byte, SCTPNotificationSize)...)
+	endian.PutUint32(data[4:], uint32(len(data)))
+	if event, err := ParseNotification(data); err != nil || event.GetLength() != uint32(len(data)) {
+		t.Errorf("over-long notification %+v: %v", event, err)
+	}
+	// A notification truncated by a short read buffer is parsed with its length capped.
+	data = append(Pack(&SCTPSendFailed{Type: SCTP_SEND_FAILED, Length: SCTPSendFailedSize + 100}), "lost"...)
+	if event, err := ParseNotification(data); err != nil || event.GetLength() != uint32(len(data)) {
+		t.Errorf("truncated notification %+v: %v", event, err)
+	}
+	data = make([]byte, SCTPNotificationHeaderSize)
+	endian.PutUint16(data, 0xffff)
+	if _, err := ParseNotification(data); !errors.Is(err, ErrInvalidNotification) {
+		t.Errorf("expected ErrInvalidNotification, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"syscall"
 	"time"
@@ -10,12 +11,16 @@ import (
 
 func main() {
 
-	if err := sctp.GetPoller().Init(); nil != err {
+	poller, err := sctp.NewPoller()
+	if nil != err {
 		fmt.Println(err.Error())
 		return
 	}
 
-	defer sctp.GetPoller().Finalize()
+	defer poller.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	address := "127.0.0.1:12345"
 	addr, err := sctp.MakeSCTPAddr("sctp", address)
@@ -53,7 +58,11 @@ func main() {
 	}
 	go func() {
 		fmt.Println("Registering fd: ", receiver.FD())
-		sctp.GetPoller().Add(receiver.FD(), func() {
+		poller.Add(receiver.FD(), sctp.EventIn, func(ev sctp.Events) {
+			if ev.IsError() || ev.IsHangup() {
+				fmt.Println("Error: receiver socket failed")
+				return
+			}
 			info := &sctp.SCTPSndRcvInfo{}
 			flag := 0
 			data := make([]byte, 8192)
@@ -62,12 +71,15 @@ func main() {
 				fmt.Println("Error: ", err)
 			}
 		})
-		sctp.GetPoller().Loop()
+		if err := poller.Run(ctx); err != nil {
+			fmt.Println("Poller stopped: ", err)
+		}
 	}()
 	fmt.Println("sctp receiver started: ", time.Now())
 	time.Sleep(30 * time.Second)
 	fmt.Println("sctp receiver closing: ", time.Now())
-	sctp.GetPoller().Del(receiver.FD())
+	poller.Del(receiver.FD())
+	cancel()
 	err = receiver.Close()
 	if err != nil {
 		fmt.Println(err.Error())
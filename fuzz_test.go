@@ -0,0 +1,242 @@
+package sctp_go
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"reflect"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+// eventParsers are the parsers of every notification type.
+var eventParsers = map[uint16]func([]byte) (Notification, error){
+	SCTP_DATA_IO_EVENT:          ParseDataIOEvent,
+	SCTP_ASSOC_CHANGE:           ParseAssocChangeEvent,
+	SCTP_PEER_ADDR_CHANGE:       ParsePeerAddrChangeEvent,
+	SCTP_SEND_FAILED:            ParseSendFailedEvent,
+	SCTP_REMOTE_ERROR:           ParseRemoteErrorEvent,
+	SCTP_SHUTDOWN_EVENT:         ParseShutdownEvent,
+	SCTP_PARTIAL_DELIVERY_EVENT: ParsePartialDeliveryEvent,
+	SCTP_ADAPTATION_INDICATION:  ParseAdaptationIndicationEvent,
+	SCTP_AUTHENTICATION_EVENT:   ParseAuthenticationEvent,
+	SCTP_SENDER_DRY_EVENT:       ParseSenderDryEvent,
+	SCTP_STREAM_RESET_EVENT:     ParseStreamResetEvent,
+	SCTP_ASSOC_RESET_EVENT:      ParseAssocResetEvent,
+	SCTP_STREAM_CHANGE_EVENT:    ParseStreamChangeEvent,
+}
+
+// addNotifications seeds the corpus with a well formed notification of every type.
+func addNotifications(f *testing.F) {
+	var addr [128]byte
+	copy(addr[:], MakeSockaddr(&SCTPAddr{port: 36412, addresses: []net.IP{net.IPv4(10, 0, 0, 1)}}))
+	for _, v := range []interface{}{
+		&SCTPNotificationHeader{Type: SCTP_DATA_IO_EVENT, Length: SCTPNotificationHeaderSize},
+		&SCTPAssocChange{Type: SCTP_ASSOC_CHANGE, Length: SCTPAssocChangeSize, State: SCTP_COMM_UP, OutboundStreams: 10, InboundStreams: 5, AssocId: 1},
+		&SCTPPAddrChange{Type: SCTP_PEER_ADDR_CHANGE, Length: SCTPPAddrChangeSize, Addr: addr, State: SCTP_ADDR_UNREACHABLE, AssocId: 1},
+		&SCTPSendFailed{Type: SCTP_SEND_FAILED, Length: SCTPSendFailedSize, Error: uint32(syscall.ETIMEDOUT), Info: SCTPSndRcvInfo{Stream: 3}, AssocId: 1},
+		&SCTPRemoteError{Type: SCTP_REMOTE_ERROR, Length: SCTPRemoteErrorSize, Error: 1, AssocId: 1},
+		&SCTPShutdownEvent{Type: SCTP_SHUTDOWN_EVENT, Length: SCTPShutdownEventSize, AssocId: 1},
+		&SCTPPDApiEvent{Type: SCTP_PARTIAL_DELIVERY_EVENT, Length: SCTPPDApiEventSize, AssocId: 1, Stream: 2, Sequence: 7},
+		&SCTPAdaptationEvent{Type: SCTP_ADAPTATION_INDICATION, Length: SCTPAdaptationEventSize, AdaptationInd: 9, AssocId: 1},
+		&SCTPAuthKeyEvent{Type: SCTP_AUTHENTICATION_EVENT, Length: SCTPAuthKeyEventSize, KeyNumber: 1, AssocId: 1},
+		&SCTPSenderDryEvent{Type: SCTP_SENDER_DRY_EVENT, Length: SCTPSenderDryEventSize, AssocId: 1},
+		&SCTPStreamResetEvent{Type: SCTP_STREAM_RESET_EVENT, Length: SCTPStreamResetEventSize, AssocId: 1},
+		&SCTPAssocResetEvent{Type: SCTP_ASSOC_RESET_EVENT, Length: SCTPAssocResetEventSize, AssocId: 1, LocalTsn: 2, RemoteTsn: 3},
+		&SCTPStreamChangeEvent{Type: SCTP_STREAM_CHANGE_EVENT, Length: SCTPStreamChangeEventSize, AssocId: 1, InStreams: 4, OutStreams: 4},
+	} {
+		f.Add(Pack(v))
+	}
+	f.Add(append(Pack(&SCTPSendFailed{Type: SCTP_SEND_FAILED, Length: SCTPSendFailedSize + 4}), "lost"...))
+	f.Add(Pack(&SCTPAssocChange{Type: SCTP_ASSOC_CHANGE, Length: 0xffffffff}))
+	f.Add(make([]byte, SCTPNotificationHeaderSize-1))
+}
+
+// decodeFields decodes each field of a structure of type typ from its offset in data with
+// encoding/binary, as the C compiler lays the structure out.
+func decodeFields(t *testing.T, typ reflect.Type, data []byte) reflect.Value {
+	t.Helper()
+	value := reflect.New(typ)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Name == "_" {
+			continue
+		}
+		start := field.Offset
+		if err := binary.Read(bytes.NewReader(data[start:start+field.Type.Size()]), endian, value.Elem().Field(i).Addr().Interface()); err != nil {
+			t.Fatalf("%s.%s: %v", typ.Name(), field.Name, err)
+		}
+	}
+	return value
+}
+
+func FuzzParseNotification(f *testing.F) {
+	addNotifications(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		notification, err := ParseNotification(data)
+		if err != nil {
+			if notification != nil {
+				t.Fatalf("notification %+v with error %v", notification, err)
+			}
+			return
+		}
+		if notification.GetType() != endian.Uint16(data) || uint64(notification.GetLength()) > uint64(len(data)) {
+			t.Fatalf("notification %+v parsed from %d bytes", notification, len(data))
+		}
+	})
+}
+
+func FuzzParseEvent(f *testing.F) {
+	addNotifications(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for kind, parser := range eventParsers {
+			notification, err := parser(data)
+			if err != nil {
+				if notification != nil {
+					t.Fatalf("%s: notification %+v with error %v", NotificationName(kind), notification, err)
+				}
+				continue
+			}
+			expected := decodeFields(t, reflect.TypeOf(notification).Elem(), data)
+			// A length beyond the data is capped to the data.
+			if length := expected.Elem().FieldByName("Length"); length.Uint() > uint64(len(data)) {
+				length.SetUint(uint64(len(data)))
+			}
+			if expected.Elem().Interface() != reflect.ValueOf(notification).Elem().Interface() {
+				t.Fatalf("%s: parsed %+v, expected %+v", NotificationName(kind), notification, expected.Interface())
+			}
+			if uint64(notification.GetLength()) > uint64(len(data)) {
+				t.Fatalf("%s: length %d of %d bytes", NotificationName(kind), notification.GetLength(), len(data))
+			}
+		}
+	})
+}
+
+func FuzzParseSndRcvInfo(f *testing.F) {
+	info := SCTPSndRcvInfo{Stream: 3, Ssn: 1, Ppid: HostToNetwork(18), AssocId: 5}
+	f.Add(AppendCmsg(nil, IPPROTO_SCTP, SCTP_SNDRCV, Pack(&info)))
+	f.Add(AppendCmsg(AppendCmsg(nil, IPPROTO_SCTP, SCTP_DSTADDRV4, []byte{127, 0, 0, 1}), IPPROTO_SCTP, SCTP_SNDRCV, Pack(&info)))
+	f.Add(AppendCmsg(nil, IPPROTO_SCTP, SCTP_SNDRCV, Pack(&info))[:syscall.CmsgLen(4)])
+	f.Add(AppendCmsg(nil, syscall.SOL_SOCKET, syscall.SCM_RIGHTS, []byte{1, 2, 3, 4}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var info SCTPSndRcvInfo
+		ParseSndRcvInfo(&info, data)
+		payload := FindCmsg(data, IPPROTO_SCTP, SCTP_SNDRCV)
+		if len(payload) > len(data) {
+			t.Fatalf("control message of %d bytes in %d bytes", len(payload), len(data))
+		}
+		expected := SCTPSndRcvInfo{}
+		if len(payload) >= SCTPSndRcvInfoSize {
+			expected = decodeFields(t, reflect.TypeOf(expected), payload).Elem().Interface().(SCTPSndRcvInfo)
+		}
+		if info != expected {
+			t.Fatalf("parsed %+v, expected %+v", info, expected)
+		}
+	})
+}
+
+func FuzzParseSCTPGetAddrs(f *testing.F) {
+	header := func(num uint32) []byte {
+		return Pack(&SCTPGetAddrs{AssocId: 1, Num: num})
+	}
+	addrs := MakeSockaddr(&SCTPAddr{
+		port:      36412,
+		addresses: []net.IP{net.IPv4(192, 0, 2, 1), net.ParseIP("2001:db8::1")},
+	})
+	f.Add(append(header(2), addrs...))
+	f.Add(append(header(3), addrs...))
+	f.Add(append(header(0xffffffff), addrs...))
+	f.Add(append(header(2), addrs[:len(addrs)-1]...))
+	f.Add(header(1))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		addr := ParseSCTPGetAddrs(data)
+		if addr == nil {
+			return
+		}
+		num := int(endian.Uint32(data[4:]))
+		if len(addr.addresses) != num || num*SockAddrInSize > len(data)-SCTPGetAddrsSize {
+			t.Fatalf("%d addresses parsed from %d bytes", len(addr.addresses), len(data))
+		}
+		buffer := make([]uint64, (len(data)+7)/8)
+		copy(unsafe.Slice((*byte)(unsafe.Pointer(&buffer[0])), len(data)), data)
+		if legacy := FromSCTPGetAddrs((*SCTPGetAddrs)(unsafe.Pointer(&buffer[0])), len(data)); legacy.String() != addr.String() {
+			t.Fatalf("parsed %v, FromSCTPGetAddrs %v", addr, legacy)
+		}
+	})
+}
+
+func FuzzFromSCTPGetAddrs(f *testing.F) {
+	header := func(num uint32) []byte {
+		return Pack(&SCTPGetAddrs{AssocId: 1, Num: num})
+	}
+	addrs := MakeSockaddr(&SCTPAddr{
+		port:      36412,
+		addresses: []net.IP{net.IPv4(192, 0, 2, 1), net.ParseIP("2001:db8::1")},
+	})
+	f.Add(append(header(2), addrs...))
+	f.Add(append(header(3), addrs...))
+	f.Add(append(header(0xffffffff), addrs...))
+	f.Add(header(1)[:SCTPGetAddrsSize-1])
+	f.Add(header(1))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// The buffer holds data followed by zeroes, whatever Num counts nothing beyond the
+		// length is read and the result matches parsing data alone.
+		buffer := make([]uint64, (len(data)+7)/8+1)
+		copy(unsafe.Slice((*byte)(unsafe.Pointer(&buffer[0])), len(data)), data)
+		addr := FromSCTPGetAddrs((*SCTPGetAddrs)(unsafe.Pointer(&buffer[0])), len(data))
+		if parsed := ParseSCTPGetAddrs(data); (addr == nil) != (parsed == nil) || addr != nil && addr.String() != parsed.String() {
+			t.Fatalf("FromSCTPGetAddrs %v, parsed %v", addr, parsed)
+		}
+	})
+}
+
+func FuzzFromSockAddrStorage(f *testing.F) {
+	for _, ip := range []net.IP{net.IPv4(192, 0, 2, 1), net.ParseIP("2001:db8::1"), net.ParseIP("::ffff:10.0.0.1")} {
+		f.Add(MakeSockaddr(&SCTPAddr{port: 36412, addresses: []net.IP{ip}}))
+	}
+	f.Add([]byte{0xff, 0xff})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		storage := &SockAddrStorage{}
+		copy(unsafe.Slice((*byte)(unsafe.Pointer(storage)), SockAddrStorageSize), data)
+		addr := FromSockAddrStorage(storage)
+		if addr == nil {
+			return
+		}
+		if len(addr.addresses) != 1 || addr.Port() < 0 || addr.Port() > 0xffff {
+			t.Fatalf("parsed %v", addr)
+		}
+		// The address must not alias the storage.
+		*storage = SockAddrStorage{}
+		again := ParseSCTPGetAddrs(append(Pack(&SCTPGetAddrs{Num: 1}), MakeSockaddr(addr)...))
+		if again == nil || again.Port() != addr.Port() || !again.addresses[0].Equal(addr.addresses[0]) {
+			t.Fatalf("parsed %v, packed and parsed again %v", addr, again)
+		}
+	})
+}
+
+func FuzzMakeSCTPAddr(f *testing.F) {
+	f.Add("sctp", "127.0.0.1:36412")
+	f.Add("sctp", "::1/127.0.0.1:12345")
+	f.Add("sctp", "[2001:db8::1]/10.0.0.1:80")
+	f.Add("sctp4", ":0")
+	f.Add("sctp6+udp", "/:9899")
+	f.Add("udp", "127.0.0.1:1")
+	f.Add("sctp", "[]:")
+	f.Fuzz(func(t *testing.T, network, address string) {
+		addr, err := MakeSCTPAddr(network, address)
+		if err != nil {
+			if addr != nil {
+				t.Fatalf("address %v with error %v", addr, err)
+			}
+			return
+		}
+		if len(addr.addresses) == 0 || addr.Port() < 0 || addr.Port() > 0xffff {
+			t.Fatalf("parsed %v", addr)
+		}
+		again, err := MakeSCTPAddr("sctp", addr.String())
+		if err != nil || again.String() != addr.String() {
+			t.Fatalf("parsed %v, parsed again %v: %v", addr, again, err)
+		}
+	})
+}
@@ -1,3 +1,5 @@
 module github.com/thebagchi/sctp-go
 
-go 1.17
+go 1.21
+
+require golang.org/x/sys v0.30.0
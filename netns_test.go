@@ -0,0 +1,469 @@
+package sctp_go
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// The tests below run associations between two network namespaces joined by veth pairs,
+// one pair and one address on each side per path. They need the ip command, CAP_NET_ADMIN
+// and CAP_SYS_ADMIN to create the namespaces and the sctp module, and are skipped otherwise.
+
+// netnsCount numbers the namespaces created by the test binary.
+var netnsCount atomic.Int32
+
+// netnsTimeout bounds the wait for an association, a path state or a notification.
+const netnsTimeout = 20 * time.Second
+
+// requireNetns skips the test unless it can create network namespaces and SCTP sockets.
+func requireNetns(t *testing.T) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping network namespace test in short mode")
+	}
+	if _, err := exec.LookPath("ip"); err != nil {
+		t.Skip("ip command not found")
+	}
+	caps, err := effectiveCaps()
+	if err != nil {
+		t.Skipf("capabilities: %v", err)
+	}
+	if caps&(1<<unix.CAP_NET_ADMIN) == 0 || caps&(1<<unix.CAP_SYS_ADMIN) == 0 {
+		t.Skip("CAP_NET_ADMIN and CAP_SYS_ADMIN required")
+	}
+	sock, err := SCTPSocket(syscall.AF_INET, syscall.SOCK_STREAM)
+	if err != nil {
+		t.Skipf("sctp module not available: %v", err)
+	}
+	_ = syscall.Close(sock)
+}
+
+// effectiveCaps returns the effective capabilities of the process.
+func effectiveCaps() (uint64, error) {
+	file, err := os.Open("/proc/self/status")
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "CapEff:"); ok {
+			return strconv.ParseUint(strings.TrimSpace(value), 16, 64)
+		}
+	}
+	return 0, errors.New("CapEff not found")
+}
+
+// command runs the command and fails the test with its output when it fails.
+func command(t *testing.T, name string, args ...string) {
+	t.Helper()
+	if out, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		t.Fatalf("%s %s: %v\n%s", name, strings.Join(args, " "), err, out)
+	}
+}
+
+// netns is a network namespace created with ip netns add.
+type netns struct {
+	name string
+	fd   int
+}
+
+// newNetns creates a network namespace deleted at the end of the test.
+func newNetns(t *testing.T) *netns {
+	t.Helper()
+	name := fmt.Sprintf("sctp-go-%d-%d", os.Getpid(), netnsCount.Add(1))
+	command(t, "ip", "netns", "add", name)
+	t.Cleanup(func() {
+		_ = exec.Command("ip", "netns", "del", name).Run()
+	})
+	fd, err := unix.Open("/run/netns/"+name, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = unix.Close(fd)
+	})
+	ns := &netns{name: name, fd: fd}
+	ns.ip(t, "link", "set", "lo", "up")
+	return ns
+}
+
+// ip runs the ip command in the namespace.
+func (ns *netns) ip(t *testing.T, args ...string) {
+	t.Helper()
+	command(t, "ip", append([]string{"-n", ns.name}, args...)...)
+}
+
+// do runs fn on a thread switched to the namespace, sockets created by fn belong to it.
+func (ns *netns) do(t *testing.T, fn func()) {
+	t.Helper()
+	runtime.LockOSThread()
+	origin, err := unix.Open("/proc/thread-self/ns/net", unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		runtime.UnlockOSThread()
+		t.Fatal(err)
+	}
+	defer unix.Close(origin)
+	if err := unix.Setns(ns.fd, unix.CLONE_NEWNET); err != nil {
+		runtime.UnlockOSThread()
+		t.Fatal(err)
+	}
+	defer func() {
+		// A thread that cannot switch back stays locked and exits with the goroutine.
+		if err := unix.Setns(origin, unix.CLONE_NEWNET); err == nil {
+			runtime.UnlockOSThread()
+		}
+	}()
+	fn()
+}
+
+// sysctl sets the sysctl of the namespace.
+func (ns *netns) sysctl(t *testing.T, key, value string) {
+	t.Helper()
+	ns.do(t, func() {
+		if err := os.WriteFile("/proc/sys/"+strings.ReplaceAll(key, ".", "/"), []byte(value), 0); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// multihomed is a client and a server namespace joined by one veth pair per path, path i
+// is link veth<i> with the addresses 10.0.<i+1>.1 on the client and 10.0.<i+1>.2 on the
+// server.
+type multihomed struct {
+	client, server           *netns
+	clientAddrs, serverAddrs []net.IP
+}
+
+// newMultihomed creates the namespaces and paths, with timers shortened so that a path
+// fails over in a few seconds.
+func newMultihomed(t *testing.T, paths int) *multihomed {
+	t.Helper()
+	m := &multihomed{client: newNetns(t), server: newNetns(t)}
+	for i := 0; i < paths; i++ {
+		link := fmt.Sprintf("veth%d", i)
+		client, server := net.IPv4(10, 0, byte(i+1), 1).To4(), net.IPv4(10, 0, byte(i+1), 2).To4()
+		m.client.ip(t, "link", "add", link, "type", "veth", "peer", "name", link, "netns", m.server.name)
+		m.client.ip(t, "addr", "add", client.String()+"/24", "dev", link)
+		m.server.ip(t, "addr", "add", server.String()+"/24", "dev", link)
+		m.client.ip(t, "link", "set", link, "up")
+		m.server.ip(t, "link", "set", link, "up")
+		m.clientAddrs = append(m.clientAddrs, client)
+		m.serverAddrs = append(m.serverAddrs, server)
+	}
+	for _, ns := range []*netns{m.client, m.server} {
+		ns.sysctl(t, "net.sctp.rto_initial", "300")
+		ns.sysctl(t, "net.sctp.rto_min", "100")
+		ns.sysctl(t, "net.sctp.rto_max", "500")
+		ns.sysctl(t, "net.sctp.hb_interval", "200")
+		ns.sysctl(t, "net.sctp.path_max_retrans", "2")
+	}
+	return m
+}
+
+// setLink takes the client end of path i down or up.
+func (m *multihomed) setLink(t *testing.T, i int, up bool) {
+	t.Helper()
+	state := "down"
+	if up {
+		state = "up"
+	}
+	m.client.ip(t, "link", "set", fmt.Sprintf("veth%d", i), state)
+}
+
+// path returns the path of the server address.
+func (m *multihomed) path(t *testing.T, addr net.IP) int {
+	t.Helper()
+	for i, server := range m.serverAddrs {
+		if server.Equal(addr) {
+			return i
+		}
+	}
+	t.Fatalf("%v is not a server address", addr)
+	return -1
+}
+
+// listen listens on every server address in the server namespace.
+func (m *multihomed) listen(t *testing.T, sockettype, port int) *SCTPListener {
+	t.Helper()
+	var (
+		listener *SCTPListener
+		err      error
+	)
+	m.server.do(t, func() {
+		listener, err = ListenSCTP("sctp4", sockettype, &SCTPAddr{addresses: m.serverAddrs, port: port},
+			&SCTPInitMsg{NumOutStreams: 4, MaxInStreams: 4})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	return listener
+}
+
+// dial dials the server port from every client address in the client namespace.
+func (m *multihomed) dial(t *testing.T, port int) *SCTPConn {
+	t.Helper()
+	var (
+		conn *SCTPConn
+		err  error
+	)
+	m.client.do(t, func() {
+		conn, err = DialSCTP("sctp4", &SCTPAddr{addresses: m.clientAddrs},
+			&SCTPAddr{addresses: m.serverAddrs, port: port}, &SCTPInitMsg{NumOutStreams: 4, MaxInStreams: 4})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return netnsConn(t, conn)
+}
+
+// netnsConn closes the connection at the end of the test and bounds its reads, a read
+// timing out fails with EAGAIN.
+func netnsConn(t *testing.T, conn *SCTPConn) *SCTPConn {
+	t.Helper()
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	timeout := syscall.NsecToTimeval(time.Second.Nanoseconds())
+	if err := syscall.SetsockoptTimeval(int(conn.FD()), syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &timeout); err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+// associate dials the server port and accepts the association.
+func (m *multihomed) associate(t *testing.T, port int) (*SCTPConn, *SCTPConn) {
+	t.Helper()
+	listener := m.listen(t, syscall.SOCK_STREAM, port)
+	client := m.dial(t, port)
+	server, err := listener.AcceptSCTP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, netnsConn(t, server)
+}
+
+// recvNetns receives a message or notification, retrying reads that time out until
+// netnsTimeout.
+func recvNetns(t *testing.T, conn *SCTPConn, b []byte) (int, SCTPSndRcvInfo, int) {
+	t.Helper()
+	var (
+		info     SCTPSndRcvInfo
+		flags    int
+		deadline = time.Now().Add(netnsTimeout)
+	)
+	for {
+		n, err := conn.RecvMsg(b, &info, &flags)
+		if err == nil {
+			return n, info, flags
+		}
+		if !errors.Is(err, syscall.EAGAIN) || time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+	}
+}
+
+// expectAddrChange reads the client until the SCTP_PEER_ADDR_CHANGE of addr to state,
+// skipping data and other notifications.
+func expectAddrChange(t *testing.T, conn *SCTPConn, addr net.IP, state int32) {
+	t.Helper()
+	b := make([]byte, 1024)
+	for deadline := time.Now().Add(netnsTimeout); time.Now().Before(deadline); {
+		n, _, flags := recvNetns(t, conn, b)
+		if flags&SCTP_MSG_NOTIFICATION == 0 {
+			continue
+		}
+		notification, err := ParseNotification(b[:n])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if change, ok := notification.(*SCTPPAddrChange); ok {
+			peer := change.GetAddr()
+			t.Logf("%v: state %d, error %d", peer, change.State, change.Error)
+			if change.State == state && peer != nil && peer.addresses[0].Equal(addr) {
+				return
+			}
+		}
+	}
+	t.Fatalf("no SCTP_PEER_ADDR_CHANGE of %v to state %d", addr, state)
+}
+
+// waitPath waits until the path to the server address is in state.
+func waitPath(t *testing.T, conn *SCTPConn, addr net.IP, port int, state int32) {
+	t.Helper()
+	peer := &SCTPAddr{addresses: []net.IP{addr}, port: port}
+	for deadline := time.Now().Add(netnsTimeout); ; time.Sleep(50 * time.Millisecond) {
+		info, err := SCTPGetPeerAddrInfo(int(conn.FD()), conn.AssocId(), peer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.State == state {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("path to %v in state %d, expected %d", addr, info.State, state)
+		}
+	}
+}
+
+// exchange sends the message from one end and receives it on the other.
+func exchange(t *testing.T, from, to *SCTPConn, message string) {
+	t.Helper()
+	if _, err := from.SendMsg([]byte(message), &SCTPSndRcvInfo{Stream: 1, Ppid: HostToNetwork(46)}); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 1024)
+	for {
+		n, info, flags := recvNetns(t, to, b)
+		if flags&SCTP_MSG_NOTIFICATION != 0 {
+			continue
+		}
+		if string(b[:n]) != message || info.Stream != 1 || NetworkToHost(info.Ppid) != 46 {
+			t.Fatalf("received %q on stream %d, ppid %d", b[:n], info.Stream, NetworkToHost(info.Ppid))
+		}
+		return
+	}
+}
+
+func TestNetnsMultihoming(t *testing.T) {
+	requireNetns(t)
+	m := newMultihomed(t, 2)
+	client, server := m.associate(t, 36412)
+	for _, addr := range []*SCTPAddr{client.RemoteAddr().(*SCTPAddr), server.LocalAddr().(*SCTPAddr)} {
+		if len(addr.addresses) != 2 || addr.Port() != 36412 {
+			t.Errorf("server addresses %v", addr)
+		}
+	}
+	if addr := server.RemoteAddr().(*SCTPAddr); len(addr.addresses) != 2 {
+		t.Errorf("client addresses %v", addr)
+	}
+	exchange(t, client, server, "ping")
+	exchange(t, server, client, "pong")
+	for _, addr := range m.serverAddrs {
+		waitPath(t, client, addr, 36412, SCTP_ACTIVE)
+	}
+	for _, addr := range m.clientAddrs {
+		waitPath(t, server, addr, client.LocalAddr().(*SCTPAddr).Port(), SCTP_ACTIVE)
+	}
+}
+
+func TestNetnsPeelOff(t *testing.T) {
+	requireNetns(t)
+	m := newMultihomed(t, 2)
+	listener := m.listen(t, syscall.SOCK_SEQPACKET, 36413)
+	if err := listener.SetEventSubscribe(&SCTPEventSubscribe{DataIoEvent: 1}); err != nil {
+		t.Fatal(err)
+	}
+	client := m.dial(t, 36413)
+	if _, err := client.SendMsg([]byte("hello"), &SCTPSndRcvInfo{Stream: 2}); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		b     = make([]byte, 1024)
+		info  SCTPSndRcvInfo
+		flags int
+	)
+	n, err := listener.RecvMsg(b, &info, &flags)
+	if err != nil || string(b[:n]) != "hello" || info.Stream != 2 || info.AssocId == 0 {
+		t.Fatalf("received %q, info %+v: %v", b[:n], info, err)
+	}
+	if addr, ok := listener.RemoteAddr(int(info.AssocId)).(*SCTPAddr); !ok || len(addr.addresses) != 2 {
+		t.Errorf("client addresses %v", listener.RemoteAddr(int(info.AssocId)))
+	}
+	server, err := listener.PeelOff(int(info.AssocId))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server = netnsConn(t, server)
+	exchange(t, server, client, "peeled")
+	exchange(t, client, server, "off")
+}
+
+func TestNetnsFailover(t *testing.T) {
+	requireNetns(t)
+	m := newMultihomed(t, 2)
+	client, server := m.associate(t, 36414)
+	if err := client.SetEventSubscribe(&SCTPEventSubscribe{AddressEvent: 1}); err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range m.serverAddrs {
+		waitPath(t, client, addr, 36414, SCTP_ACTIVE)
+	}
+	primary, err := client.GetPrimaryPeerAddr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := primary.addresses[0]
+	path := m.path(t, failed)
+
+	// Send numbered messages while the primary path fails.
+	var (
+		sent atomic.Uint32
+		stop = make(chan struct{})
+		done = make(chan error, 1)
+	)
+	go func() {
+		var b [4]byte
+		for {
+			select {
+			case <-stop:
+				done <- nil
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+			binary.BigEndian.PutUint32(b[:], sent.Load())
+			if _, err := client.SendMsg(b[:], &SCTPSndRcvInfo{Stream: 0}); err != nil {
+				done <- err
+				return
+			}
+			sent.Add(1)
+		}
+	}()
+	receive := func(count uint32, next *uint32) {
+		b := make([]byte, 1024)
+		for *next < count {
+			n, _, flags := recvNetns(t, server, b)
+			if flags&SCTP_MSG_NOTIFICATION != 0 {
+				continue
+			}
+			if n != 4 || binary.BigEndian.Uint32(b) != *next {
+				t.Fatalf("received % x, expected message %d", b[:n], *next)
+			}
+			*next++
+		}
+	}
+
+	var next uint32
+	receive(10, &next)
+	m.setLink(t, path, false)
+	expectAddrChange(t, client, failed, SCTP_ADDR_UNREACHABLE)
+	waitPath(t, client, failed, 36414, SCTP_INACTIVE)
+	// Traffic continues over the other path.
+	receive(sent.Load()+20, &next)
+
+	m.setLink(t, path, true)
+	expectAddrChange(t, client, failed, SCTP_ADDR_AVAILABLE)
+	receive(sent.Load()+20, &next)
+	close(stop)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	receive(sent.Load(), &next)
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package sctp_go
+
+const raceEnabled = false
@@ -0,0 +1,127 @@
+package packet
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// CauseCode is the code of an error cause of ABORT, ERROR and ASCONF-ACK chunks.
+type CauseCode uint16
+
+// Error cause codes of RFC 9260, RFC 4895 and RFC 5061.
+const (
+	CauseInvalidStreamIdentifier       CauseCode = 1
+	CauseMissingMandatoryParameter     CauseCode = 2
+	CauseStaleCookie                   CauseCode = 3
+	CauseOutOfResource                 CauseCode = 4
+	CauseUnresolvableAddress           CauseCode = 5
+	CauseUnrecognizedChunkType         CauseCode = 6
+	CauseInvalidMandatoryParameter     CauseCode = 7
+	CauseUnrecognizedParameters        CauseCode = 8
+	CauseNoUserData                    CauseCode = 9
+	CauseCookieWhileShuttingDown       CauseCode = 10
+	CauseRestartWithNewAddresses       CauseCode = 11
+	CauseUserInitiatedAbort            CauseCode = 12
+	CauseProtocolViolation             CauseCode = 13
+	CauseUnsupportedHMACIdentifier     CauseCode = 105
+	CauseDeleteLastRemainingAddress    CauseCode = 0xa0
+	CauseResourceShortage              CauseCode = 0xa1
+	CauseDeleteSourceAddress           CauseCode = 0xa2
+	CauseIllegalASCONFAck              CauseCode = 0xa3
+	CauseRequestRefusedNoAuthorization CauseCode = 0xa4
+)
+
+var causeNames = map[CauseCode]string{
+	CauseInvalidStreamIdentifier:       "Invalid Stream Identifier",
+	CauseMissingMandatoryParameter:     "Missing Mandatory Parameter",
+	CauseStaleCookie:                   "Stale Cookie Error",
+	CauseOutOfResource:                 "Out of Resource",
+	CauseUnresolvableAddress:           "Unresolvable Address",
+	CauseUnrecognizedChunkType:         "Unrecognized Chunk Type",
+	CauseInvalidMandatoryParameter:     "Invalid Mandatory Parameter",
+	CauseUnrecognizedParameters:        "Unrecognized Parameters",
+	CauseNoUserData:                    "No User Data",
+	CauseCookieWhileShuttingDown:       "Cookie Received While Shutting Down",
+	CauseRestartWithNewAddresses:       "Restart of an Association with New Addresses",
+	CauseUserInitiatedAbort:            "User-Initiated Abort",
+	CauseProtocolViolation:             "Protocol Violation",
+	CauseUnsupportedHMACIdentifier:     "Unsupported HMAC Identifier",
+	CauseDeleteLastRemainingAddress:    "Request to Delete Last Remaining IP Address",
+	CauseResourceShortage:              "Operation Refused Due to Resource Shortage",
+	CauseDeleteSourceAddress:           "Request to Delete Source IP Address",
+	CauseIllegalASCONFAck:              "Association Aborted Due to Illegal ASCONF-ACK",
+	CauseRequestRefusedNoAuthorization: "Request Refused - No Authorization",
+}
+
+// String returns the name of the cause code.
+func (c CauseCode) String() string {
+	if name, ok := causeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("CAUSE(%#04x)", uint16(c))
+}
+
+// ErrorCause represents an error cause, Info is the cause-specific information as carried on
+// the wire, for instance the stream identifier and reserved field of an Invalid Stream
+// Identifier cause or the unrecognized chunk of an Unrecognized Chunk Type cause.
+type ErrorCause struct {
+	Code CauseCode
+	Info []byte
+}
+
+// Marshal encodes the error cause without trailing padding.
+func (e *ErrorCause) Marshal() ([]byte, error) {
+	buffer := make([]byte, 4, 4+len(e.Info))
+	binary.BigEndian.PutUint16(buffer, uint16(e.Code))
+	return setLength(append(buffer, e.Info...))
+}
+
+// Unmarshal decodes the error cause at the start of data and returns the number of bytes it
+// takes including padding.
+func (e *ErrorCause) Unmarshal(data []byte) (int, error) {
+	if len(data) < 4 {
+		return 0, ErrTruncated
+	}
+	length := int(binary.BigEndian.Uint16(data[2:]))
+	if length < 4 || length > len(data) {
+		return 0, ErrInvalidLength
+	}
+	e.Code = CauseCode(binary.BigEndian.Uint16(data))
+	e.Info = append([]byte(nil), data[4:length]...)
+	return min(padded(length), len(data)), nil
+}
+
+// String returns the cause code and information of the error cause.
+func (e ErrorCause) String() string {
+	return fmt.Sprintf("%v % x", e.Code, e.Info)
+}
+
+// appendCauses appends the error causes, padding all but the last one like parameters.
+func appendCauses(buffer []byte, causes []ErrorCause) ([]byte, error) {
+	for i := range causes {
+		if i > 0 {
+			buffer = pad(buffer)
+		}
+		data, err := causes[i].Marshal()
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", causes[i].Code, err)
+		}
+		buffer = append(buffer, data...)
+	}
+	return buffer, nil
+}
+
+// parseCauses decodes the error causes filling data.
+func parseCauses(data []byte) ([]ErrorCause, error) {
+	var causes []ErrorCause
+	for offset := 0; offset < len(data); {
+		var cause ErrorCause
+		n, err := cause.Unmarshal(data[offset:])
+		if err != nil {
+			return nil, err
+		}
+		causes = append(causes, cause)
+		offset += n
+	}
+	return causes, nil
+}
@@ -0,0 +1,878 @@
+package packet
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// ChunkType is the type of a chunk.
+type ChunkType uint8
+
+// Chunk types of RFC 9260, RFC 3758, RFC 4895, RFC 5061, RFC 6525 and RFC 8260.
+const (
+	ChunkData             ChunkType = 0
+	ChunkInit             ChunkType = 1
+	ChunkInitAck          ChunkType = 2
+	ChunkSack             ChunkType = 3
+	ChunkHeartbeat        ChunkType = 4
+	ChunkHeartbeatAck     ChunkType = 5
+	ChunkAbort            ChunkType = 6
+	ChunkShutdown         ChunkType = 7
+	ChunkShutdownAck      ChunkType = 8
+	ChunkError            ChunkType = 9
+	ChunkCookieEcho       ChunkType = 10
+	ChunkCookieAck        ChunkType = 11
+	ChunkShutdownComplete ChunkType = 14
+	ChunkAuth             ChunkType = 15
+	ChunkIData            ChunkType = 64
+	ChunkAsconfAck        ChunkType = 128
+	ChunkReconfig         ChunkType = 130
+	ChunkForwardTSN       ChunkType = 192
+	ChunkAsconf           ChunkType = 193
+	ChunkIForwardTSN      ChunkType = 194
+)
+
+var chunkNames = map[ChunkType]string{
+	ChunkData:             "DATA",
+	ChunkInit:             "INIT",
+	ChunkInitAck:          "INIT-ACK",
+	ChunkSack:             "SACK",
+	ChunkHeartbeat:        "HEARTBEAT",
+	ChunkHeartbeatAck:     "HEARTBEAT-ACK",
+	ChunkAbort:            "ABORT",
+	ChunkShutdown:         "SHUTDOWN",
+	ChunkShutdownAck:      "SHUTDOWN-ACK",
+	ChunkError:            "ERROR",
+	ChunkCookieEcho:       "COOKIE-ECHO",
+	ChunkCookieAck:        "COOKIE-ACK",
+	ChunkShutdownComplete: "SHUTDOWN-COMPLETE",
+	ChunkAuth:             "AUTH",
+	ChunkIData:            "I-DATA",
+	ChunkAsconfAck:        "ASCONF-ACK",
+	ChunkReconfig:         "RE-CONFIG",
+	ChunkForwardTSN:       "FORWARD-TSN",
+	ChunkAsconf:           "ASCONF",
+	ChunkIForwardTSN:      "I-FORWARD-TSN",
+}
+
+// String returns the name of the chunk type.
+func (t ChunkType) String() string {
+	if name, ok := chunkNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("CHUNK(%d)", uint8(t))
+}
+
+// Chunk is an SCTP chunk. Marshal returns the chunk without the padding that follows it in a
+// packet, Unmarshal decodes a chunk of the same type and ignores data past its length.
+type Chunk interface {
+	Type() ChunkType
+	Marshal() ([]byte, error)
+	Unmarshal(data []byte) error
+}
+
+// ParseChunk decodes the chunk at the start of data and returns it with the number of bytes
+// it takes including padding, chunks of unknown types are returned as *UnknownChunk.
+func ParseChunk(data []byte) (Chunk, int, error) {
+	if len(data) < 4 {
+		return nil, 0, ErrTruncated
+	}
+	length := int(binary.BigEndian.Uint16(data[2:]))
+	if length < 4 || length > len(data) {
+		return nil, 0, ErrInvalidLength
+	}
+	var chunk Chunk
+	switch kind := ChunkType(data[0]); kind {
+	case ChunkData:
+		chunk = &Data{}
+	case ChunkInit, ChunkInitAck:
+		chunk = &Init{}
+	case ChunkSack:
+		chunk = &Sack{}
+	case ChunkHeartbeat, ChunkHeartbeatAck:
+		chunk = &Heartbeat{}
+	case ChunkAbort:
+		chunk = &Abort{}
+	case ChunkShutdown:
+		chunk = &Shutdown{}
+	case ChunkShutdownAck:
+		chunk = &ShutdownAck{}
+	case ChunkError:
+		chunk = &Error{}
+	case ChunkCookieEcho:
+		chunk = &CookieEcho{}
+	case ChunkCookieAck:
+		chunk = &CookieAck{}
+	case ChunkShutdownComplete:
+		chunk = &ShutdownComplete{}
+	case ChunkAuth:
+		chunk = &Auth{}
+	case ChunkIData:
+		chunk = &IData{}
+	case ChunkAsconfAck:
+		chunk = &AsconfAck{}
+	case ChunkReconfig:
+		chunk = &Reconfig{}
+	case ChunkForwardTSN:
+		chunk = &ForwardTSN{}
+	case ChunkAsconf:
+		chunk = &Asconf{}
+	case ChunkIForwardTSN:
+		chunk = &IForwardTSN{}
+	default:
+		chunk = &UnknownChunk{}
+	}
+	if err := chunk.Unmarshal(data[:length]); err != nil {
+		return nil, 0, fmt.Errorf("%v: %w", ChunkType(data[0]), err)
+	}
+	return chunk, min(padded(length), len(data)), nil
+}
+
+// newChunk returns the header of a chunk with capacity for size bytes of value, the length is
+// set by setLength once the value is appended.
+func newChunk(kind ChunkType, flags uint8, size int) []byte {
+	buffer := make([]byte, 4, 4+size)
+	buffer[0] = byte(kind)
+	buffer[1] = flags
+	return buffer
+}
+
+// setLength sets the length field of a chunk, parameter or error cause to the length of
+// buffer.
+func setLength(buffer []byte) ([]byte, error) {
+	if len(buffer) > 0xffff {
+		return nil, ErrInvalidLength
+	}
+	binary.BigEndian.PutUint16(buffer[2:], uint16(len(buffer)))
+	return buffer, nil
+}
+
+// chunkValue checks the header of a chunk of one of the types with at least size bytes of
+// value and returns its type, flags and value.
+func chunkValue(data []byte, size int, kinds ...ChunkType) (ChunkType, uint8, []byte, error) {
+	if len(data) < 4 {
+		return 0, 0, nil, ErrTruncated
+	}
+	kind := ChunkType(data[0])
+	expected := len(kinds) == 0
+	for _, k := range kinds {
+		expected = expected || k == kind
+	}
+	if !expected {
+		return 0, 0, nil, fmt.Errorf("%w: %v", ErrUnexpectedType, kind)
+	}
+	length := int(binary.BigEndian.Uint16(data[2:]))
+	if length < 4+size || length > len(data) {
+		return 0, 0, nil, ErrInvalidLength
+	}
+	return kind, data[1], data[4:length], nil
+}
+
+// flag returns bit of flags set when on is true.
+func flag(on bool, bit uint8) uint8 {
+	if on {
+		return bit
+	}
+	return 0
+}
+
+// Flags of DATA and I-DATA chunks.
+const (
+	FlagEnding    uint8 = 1 << 0
+	FlagBeginning uint8 = 1 << 1
+	FlagUnordered uint8 = 1 << 2
+	FlagImmediate uint8 = 1 << 3
+)
+
+// FlagNoTCB is the flag of ABORT and SHUTDOWN-COMPLETE chunks sent without association state,
+// with the verification tag of the packet they answer.
+const FlagNoTCB uint8 = 1 << 0
+
+const (
+	dataHeaderSize  = 12
+	idataHeaderSize = 16
+)
+
+// DataFlags are the flags of DATA and I-DATA chunks, Beginning and Ending mark the first and
+// last fragments of a user message.
+type DataFlags struct {
+	Unordered bool
+	Beginning bool
+	Ending    bool
+	Immediate bool
+}
+
+func (f DataFlags) flags() uint8 {
+	return flag(f.Ending, FlagEnding) | flag(f.Beginning, FlagBeginning) |
+		flag(f.Unordered, FlagUnordered) | flag(f.Immediate, FlagImmediate)
+}
+
+func (f *DataFlags) setFlags(flags uint8) {
+	f.Ending = flags&FlagEnding != 0
+	f.Beginning = flags&FlagBeginning != 0
+	f.Unordered = flags&FlagUnordered != 0
+	f.Immediate = flags&FlagImmediate != 0
+}
+
+// Data represents a DATA chunk.
+type Data struct {
+	DataFlags
+	TSN       uint32
+	StreamID  uint16
+	StreamSeq uint16
+	PPID      uint32
+	UserData  []byte
+}
+
+// Type returns the chunk type.
+func (c *Data) Type() ChunkType { return ChunkData }
+
+// Marshal encodes the DATA chunk.
+func (c *Data) Marshal() ([]byte, error) {
+	buffer := newChunk(ChunkData, c.flags(), dataHeaderSize+len(c.UserData))
+	buffer = binary.BigEndian.AppendUint32(buffer, c.TSN)
+	buffer = binary.BigEndian.AppendUint16(buffer, c.StreamID)
+	buffer = binary.BigEndian.AppendUint16(buffer, c.StreamSeq)
+	buffer = binary.BigEndian.AppendUint32(buffer, c.PPID)
+	return setLength(append(buffer, c.UserData...))
+}
+
+// Unmarshal decodes a DATA chunk.
+func (c *Data) Unmarshal(data []byte) error {
+	_, flags, value, err := chunkValue(data, dataHeaderSize, ChunkData)
+	if err != nil {
+		return err
+	}
+	c.setFlags(flags)
+	c.TSN = binary.BigEndian.Uint32(value[0:])
+	c.StreamID = binary.BigEndian.Uint16(value[4:])
+	c.StreamSeq = binary.BigEndian.Uint16(value[6:])
+	c.PPID = binary.BigEndian.Uint32(value[8:])
+	c.UserData = append([]byte(nil), value[dataHeaderSize:]...)
+	return nil
+}
+
+// IData represents an I-DATA chunk of RFC 8260. The first fragment of a message carries the
+// PPID, the others the fragment sequence number FSN in its place.
+type IData struct {
+	DataFlags
+	TSN       uint32
+	StreamID  uint16
+	MessageID uint32
+	PPID      uint32
+	FSN       uint32
+	UserData  []byte
+}
+
+// Type returns the chunk type.
+func (c *IData) Type() ChunkType { return ChunkIData }
+
+// Marshal encodes the I-DATA chunk.
+func (c *IData) Marshal() ([]byte, error) {
+	buffer := newChunk(ChunkIData, c.flags(), idataHeaderSize+len(c.UserData))
+	buffer = binary.BigEndian.AppendUint32(buffer, c.TSN)
+	buffer = binary.BigEndian.AppendUint16(buffer, c.StreamID)
+	buffer = binary.BigEndian.AppendUint16(buffer, 0)
+	buffer = binary.BigEndian.AppendUint32(buffer, c.MessageID)
+	if c.Beginning {
+		buffer = binary.BigEndian.AppendUint32(buffer, c.PPID)
+	} else {
+		buffer = binary.BigEndian.AppendUint32(buffer, c.FSN)
+	}
+	return setLength(append(buffer, c.UserData...))
+}
+
+// Unmarshal decodes an I-DATA chunk.
+func (c *IData) Unmarshal(data []byte) error {
+	_, flags, value, err := chunkValue(data, idataHeaderSize, ChunkIData)
+	if err != nil {
+		return err
+	}
+	c.setFlags(flags)
+	c.TSN = binary.BigEndian.Uint32(value[0:])
+	c.StreamID = binary.BigEndian.Uint16(value[4:])
+	c.MessageID = binary.BigEndian.Uint32(value[8:])
+	c.PPID, c.FSN = 0, 0
+	if c.Beginning {
+		c.PPID = binary.BigEndian.Uint32(value[12:])
+	} else {
+		c.FSN = binary.BigEndian.Uint32(value[12:])
+	}
+	c.UserData = append([]byte(nil), value[idataHeaderSize:]...)
+	return nil
+}
+
+// Init represents an INIT chunk, or an INIT-ACK chunk when Ack is set. The State Cookie of an
+// INIT-ACK is one of its parameters.
+type Init struct {
+	Ack              bool
+	InitiateTag      uint32
+	AdvertisedWindow uint32
+	OutboundStreams  uint16
+	InboundStreams   uint16
+	InitialTSN       uint32
+	Params           []Param
+}
+
+// Type returns the chunk type.
+func (c *Init) Type() ChunkType {
+	if c.Ack {
+		return ChunkInitAck
+	}
+	return ChunkInit
+}
+
+// Marshal encodes the INIT chunk.
+func (c *Init) Marshal() ([]byte, error) {
+	buffer := newChunk(c.Type(), 0, 16)
+	buffer = binary.BigEndian.AppendUint32(buffer, c.InitiateTag)
+	buffer = binary.BigEndian.AppendUint32(buffer, c.AdvertisedWindow)
+	buffer = binary.BigEndian.AppendUint16(buffer, c.OutboundStreams)
+	buffer = binary.BigEndian.AppendUint16(buffer, c.InboundStreams)
+	buffer = binary.BigEndian.AppendUint32(buffer, c.InitialTSN)
+	buffer, err := appendParams(buffer, c.Params)
+	if err != nil {
+		return nil, err
+	}
+	return setLength(buffer)
+}
+
+// Unmarshal decodes an INIT chunk.
+func (c *Init) Unmarshal(data []byte) error {
+	kind, _, value, err := chunkValue(data, 16, ChunkInit, ChunkInitAck)
+	if err != nil {
+		return err
+	}
+	c.Ack = kind == ChunkInitAck
+	c.InitiateTag = binary.BigEndian.Uint32(value[0:])
+	c.AdvertisedWindow = binary.BigEndian.Uint32(value[4:])
+	c.OutboundStreams = binary.BigEndian.Uint16(value[8:])
+	c.InboundStreams = binary.BigEndian.Uint16(value[10:])
+	c.InitialTSN = binary.BigEndian.Uint32(value[12:])
+	c.Params, err = parseParams(value[16:])
+	return err
+}
+
+// GapAckBlock is a range of TSNs received after the cumulative TSN ack point, as offsets
+// from it.
+type GapAckBlock struct {
+	Start uint16
+	End   uint16
+}
+
+// Sack represents a SACK chunk.
+type Sack struct {
+	CumulativeTSNAck uint32
+	AdvertisedWindow uint32
+	GapAckBlocks     []GapAckBlock
+	DuplicateTSNs    []uint32
+}
+
+// Type returns the chunk type.
+func (c *Sack) Type() ChunkType { return ChunkSack }
+
+// Marshal encodes the SACK chunk.
+func (c *Sack) Marshal() ([]byte, error) {
+	if len(c.GapAckBlocks) > 0xffff || len(c.DuplicateTSNs) > 0xffff {
+		return nil, ErrInvalidLength
+	}
+	buffer := newChunk(ChunkSack, 0, 12+4*len(c.GapAckBlocks)+4*len(c.DuplicateTSNs))
+	buffer = binary.BigEndian.AppendUint32(buffer, c.CumulativeTSNAck)
+	buffer = binary.BigEndian.AppendUint32(buffer, c.AdvertisedWindow)
+	buffer = binary.BigEndian.AppendUint16(buffer, uint16(len(c.GapAckBlocks)))
+	buffer = binary.BigEndian.AppendUint16(buffer, uint16(len(c.DuplicateTSNs)))
+	for _, block := range c.GapAckBlocks {
+		buffer = binary.BigEndian.AppendUint16(buffer, block.Start)
+		buffer = binary.BigEndian.AppendUint16(buffer, block.End)
+	}
+	for _, tsn := range c.DuplicateTSNs {
+		buffer = binary.BigEndian.AppendUint32(buffer, tsn)
+	}
+	return setLength(buffer)
+}
+
+// Unmarshal decodes a SACK chunk.
+func (c *Sack) Unmarshal(data []byte) error {
+	_, _, value, err := chunkValue(data, 12, ChunkSack)
+	if err != nil {
+		return err
+	}
+	c.CumulativeTSNAck = binary.BigEndian.Uint32(value[0:])
+	c.AdvertisedWindow = binary.BigEndian.Uint32(value[4:])
+	var (
+		blocks = int(binary.BigEndian.Uint16(value[8:]))
+		dups   = int(binary.BigEndian.Uint16(value[10:]))
+	)
+	if len(value) < 12+4*blocks+4*dups {
+		return ErrInvalidLength
+	}
+	c.GapAckBlocks, c.DuplicateTSNs = nil, nil
+	offset := 12
+	for i := 0; i < blocks; i++ {
+		c.GapAckBlocks = append(c.GapAckBlocks, GapAckBlock{
+			Start: binary.BigEndian.Uint16(value[offset:]),
+			End:   binary.BigEndian.Uint16(value[offset+2:]),
+		})
+		offset += 4
+	}
+	for i := 0; i < dups; i++ {
+		c.DuplicateTSNs = append(c.DuplicateTSNs, binary.BigEndian.Uint32(value[offset:]))
+		offset += 4
+	}
+	return nil
+}
+
+// Heartbeat represents a HEARTBEAT chunk, or a HEARTBEAT-ACK chunk when Ack is set, carrying
+// the sender specific Heartbeat Info parameter.
+type Heartbeat struct {
+	Ack  bool
+	Info []byte
+}
+
+// Type returns the chunk type.
+func (c *Heartbeat) Type() ChunkType {
+	if c.Ack {
+		return ChunkHeartbeatAck
+	}
+	return ChunkHeartbeat
+}
+
+// Marshal encodes the HEARTBEAT chunk.
+func (c *Heartbeat) Marshal() ([]byte, error) {
+	info, err := (&HeartbeatInfo{Info: c.Info}).Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return setLength(append(newChunk(c.Type(), 0, len(info)), info...))
+}
+
+// Unmarshal decodes a HEARTBEAT chunk.
+func (c *Heartbeat) Unmarshal(data []byte) error {
+	kind, _, value, err := chunkValue(data, 4, ChunkHeartbeat, ChunkHeartbeatAck)
+	if err != nil {
+		return err
+	}
+	var info HeartbeatInfo
+	if err := info.Unmarshal(value); err != nil {
+		return err
+	}
+	c.Ack = kind == ChunkHeartbeatAck
+	c.Info = info.Info
+	return nil
+}
+
+// Abort represents an ABORT chunk, NoTCB is set when the sender had no association state and
+// reflected the verification tag.
+type Abort struct {
+	NoTCB  bool
+	Causes []ErrorCause
+}
+
+// Type returns the chunk type.
+func (c *Abort) Type() ChunkType { return ChunkAbort }
+
+// Marshal encodes the ABORT chunk.
+func (c *Abort) Marshal() ([]byte, error) {
+	buffer, err := appendCauses(newChunk(ChunkAbort, flag(c.NoTCB, FlagNoTCB), 0), c.Causes)
+	if err != nil {
+		return nil, err
+	}
+	return setLength(buffer)
+}
+
+// Unmarshal decodes an ABORT chunk.
+func (c *Abort) Unmarshal(data []byte) error {
+	_, flags, value, err := chunkValue(data, 0, ChunkAbort)
+	if err != nil {
+		return err
+	}
+	c.NoTCB = flags&FlagNoTCB != 0
+	c.Causes, err = parseCauses(value)
+	return err
+}
+
+// Error represents an ERROR chunk.
+type Error struct {
+	Causes []ErrorCause
+}
+
+// Type returns the chunk type.
+func (c *Error) Type() ChunkType { return ChunkError }
+
+// Marshal encodes the ERROR chunk.
+func (c *Error) Marshal() ([]byte, error) {
+	buffer, err := appendCauses(newChunk(ChunkError, 0, 0), c.Causes)
+	if err != nil {
+		return nil, err
+	}
+	return setLength(buffer)
+}
+
+// Unmarshal decodes an ERROR chunk.
+func (c *Error) Unmarshal(data []byte) error {
+	_, _, value, err := chunkValue(data, 0, ChunkError)
+	if err != nil {
+		return err
+	}
+	c.Causes, err = parseCauses(value)
+	return err
+}
+
+// Shutdown represents a SHUTDOWN chunk.
+type Shutdown struct {
+	CumulativeTSNAck uint32
+}
+
+// Type returns the chunk type.
+func (c *Shutdown) Type() ChunkType { return ChunkShutdown }
+
+// Marshal encodes the SHUTDOWN chunk.
+func (c *Shutdown) Marshal() ([]byte, error) {
+	return setLength(binary.BigEndian.AppendUint32(newChunk(ChunkShutdown, 0, 4), c.CumulativeTSNAck))
+}
+
+// Unmarshal decodes a SHUTDOWN chunk.
+func (c *Shutdown) Unmarshal(data []byte) error {
+	_, _, value, err := chunkValue(data, 4, ChunkShutdown)
+	if err != nil {
+		return err
+	}
+	c.CumulativeTSNAck = binary.BigEndian.Uint32(value)
+	return nil
+}
+
+// ShutdownAck represents a SHUTDOWN-ACK chunk.
+type ShutdownAck struct{}
+
+// Type returns the chunk type.
+func (c *ShutdownAck) Type() ChunkType { return ChunkShutdownAck }
+
+// Marshal encodes the SHUTDOWN-ACK chunk.
+func (c *ShutdownAck) Marshal() ([]byte, error) {
+	return setLength(newChunk(ChunkShutdownAck, 0, 0))
+}
+
+// Unmarshal decodes a SHUTDOWN-ACK chunk.
+func (c *ShutdownAck) Unmarshal(data []byte) error {
+	_, _, _, err := chunkValue(data, 0, ChunkShutdownAck)
+	return err
+}
+
+// ShutdownComplete represents a SHUTDOWN-COMPLETE chunk, NoTCB is set when the sender had no
+// association state and reflected the verification tag.
+type ShutdownComplete struct {
+	NoTCB bool
+}
+
+// Type returns the chunk type.
+func (c *ShutdownComplete) Type() ChunkType { return ChunkShutdownComplete }
+
+// Marshal encodes the SHUTDOWN-COMPLETE chunk.
+func (c *ShutdownComplete) Marshal() ([]byte, error) {
+	return setLength(newChunk(ChunkShutdownComplete, flag(c.NoTCB, FlagNoTCB), 0))
+}
+
+// Unmarshal decodes a SHUTDOWN-COMPLETE chunk.
+func (c *ShutdownComplete) Unmarshal(data []byte) error {
+	_, flags, _, err := chunkValue(data, 0, ChunkShutdownComplete)
+	if err != nil {
+		return err
+	}
+	c.NoTCB = flags&FlagNoTCB != 0
+	return nil
+}
+
+// CookieEcho represents a COOKIE-ECHO chunk carrying the State Cookie of the INIT-ACK.
+type CookieEcho struct {
+	Cookie []byte
+}
+
+// Type returns the chunk type.
+func (c *CookieEcho) Type() ChunkType { return ChunkCookieEcho }
+
+// Marshal encodes the COOKIE-ECHO chunk.
+func (c *CookieEcho) Marshal() ([]byte, error) {
+	return setLength(append(newChunk(ChunkCookieEcho, 0, len(c.Cookie)), c.Cookie...))
+}
+
+// Unmarshal decodes a COOKIE-ECHO chunk.
+func (c *CookieEcho) Unmarshal(data []byte) error {
+	_, _, value, err := chunkValue(data, 0, ChunkCookieEcho)
+	if err != nil {
+		return err
+	}
+	c.Cookie = append([]byte(nil), value...)
+	return nil
+}
+
+// CookieAck represents a COOKIE-ACK chunk.
+type CookieAck struct{}
+
+// Type returns the chunk type.
+func (c *CookieAck) Type() ChunkType { return ChunkCookieAck }
+
+// Marshal encodes the COOKIE-ACK chunk.
+func (c *CookieAck) Marshal() ([]byte, error) {
+	return setLength(newChunk(ChunkCookieAck, 0, 0))
+}
+
+// Unmarshal decodes a COOKIE-ACK chunk.
+func (c *CookieAck) Unmarshal(data []byte) error {
+	_, _, _, err := chunkValue(data, 0, ChunkCookieAck)
+	return err
+}
+
+// Auth represents an AUTH chunk of RFC 4895.
+type Auth struct {
+	SharedKeyID uint16
+	HMACID      uint16
+	HMAC        []byte
+}
+
+// Type returns the chunk type.
+func (c *Auth) Type() ChunkType { return ChunkAuth }
+
+// Marshal encodes the AUTH chunk.
+func (c *Auth) Marshal() ([]byte, error) {
+	buffer := newChunk(ChunkAuth, 0, 4+len(c.HMAC))
+	buffer = binary.BigEndian.AppendUint16(buffer, c.SharedKeyID)
+	buffer = binary.BigEndian.AppendUint16(buffer, c.HMACID)
+	return setLength(append(buffer, c.HMAC...))
+}
+
+// Unmarshal decodes an AUTH chunk.
+func (c *Auth) Unmarshal(data []byte) error {
+	_, _, value, err := chunkValue(data, 4, ChunkAuth)
+	if err != nil {
+		return err
+	}
+	c.SharedKeyID = binary.BigEndian.Uint16(value[0:])
+	c.HMACID = binary.BigEndian.Uint16(value[2:])
+	c.HMAC = append([]byte(nil), value[4:]...)
+	return nil
+}
+
+// Asconf represents an ASCONF chunk of RFC 5061, Address is an IPv4 or IPv6 Address parameter
+// identifying the association and Params the requests.
+type Asconf struct {
+	Serial  uint32
+	Address IPAddress
+	Params  []Param
+}
+
+// Type returns the chunk type.
+func (c *Asconf) Type() ChunkType { return ChunkAsconf }
+
+// Marshal encodes the ASCONF chunk.
+func (c *Asconf) Marshal() ([]byte, error) {
+	address, err := c.Address.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	buffer := binary.BigEndian.AppendUint32(newChunk(ChunkAsconf, 0, 4+len(address)), c.Serial)
+	buffer, err = appendParams(append(buffer, address...), c.Params)
+	if err != nil {
+		return nil, err
+	}
+	return setLength(buffer)
+}
+
+// Unmarshal decodes an ASCONF chunk.
+func (c *Asconf) Unmarshal(data []byte) error {
+	_, _, value, err := chunkValue(data, 8, ChunkAsconf)
+	if err != nil {
+		return err
+	}
+	c.Serial = binary.BigEndian.Uint32(value)
+	param, n, err := ParseParam(value[4:])
+	if err != nil {
+		return err
+	}
+	address, ok := param.(*IPAddress)
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrUnexpectedType, param.Type())
+	}
+	c.Address = *address
+	c.Params, err = parseParams(value[4+n:])
+	return err
+}
+
+// AsconfAck represents an ASCONF-ACK chunk of RFC 5061.
+type AsconfAck struct {
+	Serial uint32
+	Params []Param
+}
+
+// Type returns the chunk type.
+func (c *AsconfAck) Type() ChunkType { return ChunkAsconfAck }
+
+// Marshal encodes the ASCONF-ACK chunk.
+func (c *AsconfAck) Marshal() ([]byte, error) {
+	buffer := binary.BigEndian.AppendUint32(newChunk(ChunkAsconfAck, 0, 4), c.Serial)
+	buffer, err := appendParams(buffer, c.Params)
+	if err != nil {
+		return nil, err
+	}
+	return setLength(buffer)
+}
+
+// Unmarshal decodes an ASCONF-ACK chunk.
+func (c *AsconfAck) Unmarshal(data []byte) error {
+	_, _, value, err := chunkValue(data, 4, ChunkAsconfAck)
+	if err != nil {
+		return err
+	}
+	c.Serial = binary.BigEndian.Uint32(value)
+	c.Params, err = parseParams(value[4:])
+	return err
+}
+
+// Reconfig represents a RE-CONFIG chunk of RFC 6525 with one or two requests or responses.
+type Reconfig struct {
+	Params []Param
+}
+
+// Type returns the chunk type.
+func (c *Reconfig) Type() ChunkType { return ChunkReconfig }
+
+// Marshal encodes the RE-CONFIG chunk.
+func (c *Reconfig) Marshal() ([]byte, error) {
+	buffer, err := appendParams(newChunk(ChunkReconfig, 0, 0), c.Params)
+	if err != nil {
+		return nil, err
+	}
+	return setLength(buffer)
+}
+
+// Unmarshal decodes a RE-CONFIG chunk.
+func (c *Reconfig) Unmarshal(data []byte) error {
+	_, _, value, err := chunkValue(data, 4, ChunkReconfig)
+	if err != nil {
+		return err
+	}
+	c.Params, err = parseParams(value)
+	return err
+}
+
+// StreamSequence is a stream and the last skipped stream sequence number of a FORWARD-TSN
+// chunk.
+type StreamSequence struct {
+	StreamID  uint16
+	StreamSeq uint16
+}
+
+// ForwardTSN represents a FORWARD-TSN chunk of RFC 3758.
+type ForwardTSN struct {
+	NewCumulativeTSN uint32
+	Streams          []StreamSequence
+}
+
+// Type returns the chunk type.
+func (c *ForwardTSN) Type() ChunkType { return ChunkForwardTSN }
+
+// Marshal encodes the FORWARD-TSN chunk.
+func (c *ForwardTSN) Marshal() ([]byte, error) {
+	buffer := newChunk(ChunkForwardTSN, 0, 4+4*len(c.Streams))
+	buffer = binary.BigEndian.AppendUint32(buffer, c.NewCumulativeTSN)
+	for _, stream := range c.Streams {
+		buffer = binary.BigEndian.AppendUint16(buffer, stream.StreamID)
+		buffer = binary.BigEndian.AppendUint16(buffer, stream.StreamSeq)
+	}
+	return setLength(buffer)
+}
+
+// Unmarshal decodes a FORWARD-TSN chunk.
+func (c *ForwardTSN) Unmarshal(data []byte) error {
+	_, _, value, err := chunkValue(data, 4, ChunkForwardTSN)
+	if err != nil {
+		return err
+	}
+	if len(value)%4 != 0 {
+		return ErrInvalidLength
+	}
+	c.NewCumulativeTSN = binary.BigEndian.Uint32(value)
+	c.Streams = nil
+	for offset := 4; offset < len(value); offset += 4 {
+		c.Streams = append(c.Streams, StreamSequence{
+			StreamID:  binary.BigEndian.Uint16(value[offset:]),
+			StreamSeq: binary.BigEndian.Uint16(value[offset+2:]),
+		})
+	}
+	return nil
+}
+
+// StreamMessage is a stream and the last skipped message identifier of an I-FORWARD-TSN
+// chunk.
+type StreamMessage struct {
+	StreamID  uint16
+	Unordered bool
+	MessageID uint32
+}
+
+// IForwardTSN represents an I-FORWARD-TSN chunk of RFC 8260.
+type IForwardTSN struct {
+	NewCumulativeTSN uint32
+	Streams          []StreamMessage
+}
+
+// Type returns the chunk type.
+func (c *IForwardTSN) Type() ChunkType { return ChunkIForwardTSN }
+
+// Marshal encodes the I-FORWARD-TSN chunk.
+func (c *IForwardTSN) Marshal() ([]byte, error) {
+	buffer := newChunk(ChunkIForwardTSN, 0, 4+8*len(c.Streams))
+	buffer = binary.BigEndian.AppendUint32(buffer, c.NewCumulativeTSN)
+	for _, stream := range c.Streams {
+		buffer = binary.BigEndian.AppendUint16(buffer, stream.StreamID)
+		buffer = binary.BigEndian.AppendUint16(buffer, uint16(flag(stream.Unordered, 1)))
+		buffer = binary.BigEndian.AppendUint32(buffer, stream.MessageID)
+	}
+	return setLength(buffer)
+}
+
+// Unmarshal decodes an I-FORWARD-TSN chunk.
+func (c *IForwardTSN) Unmarshal(data []byte) error {
+	_, _, value, err := chunkValue(data, 4, ChunkIForwardTSN)
+	if err != nil {
+		return err
+	}
+	if (len(value)-4)%8 != 0 {
+		return ErrInvalidLength
+	}
+	c.NewCumulativeTSN = binary.BigEndian.Uint32(value)
+	c.Streams = nil
+	for offset := 4; offset < len(value); offset += 8 {
+		c.Streams = append(c.Streams, StreamMessage{
+			StreamID:  binary.BigEndian.Uint16(value[offset:]),
+			Unordered: binary.BigEndian.Uint16(value[offset+2:])&1 != 0,
+			MessageID: binary.BigEndian.Uint32(value[offset+4:]),
+		})
+	}
+	return nil
+}
+
+// UnknownChunk is a chunk of a type without a decoder, kept as is.
+type UnknownChunk struct {
+	Kind  ChunkType
+	Flags uint8
+	Value []byte
+}
+
+// Type returns the chunk type.
+func (c *UnknownChunk) Type() ChunkType { return c.Kind }
+
+// Marshal encodes the chunk with its type, flags and value.
+func (c *UnknownChunk) Marshal() ([]byte, error) {
+	return setLength(append(newChunk(c.Kind, c.Flags, len(c.Value)), c.Value...))
+}
+
+// Unmarshal decodes a chunk of any type.
+func (c *UnknownChunk) Unmarshal(data []byte) error {
+	kind, flags, value, err := chunkValue(data, 0)
+	if err != nil {
+		return err
+	}
+	c.Kind, c.Flags = kind, flags
+	c.Value = append([]byte(nil), value...)
+	return nil
+}
@@ -0,0 +1,189 @@
+package packet
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestChunkVectors(t *testing.T) {
+	tests := []struct {
+		data  string
+		chunk Chunk
+	}{
+		{"c0 00 00 0c 00 00 00 03 00 04 00 05", &ForwardTSN{NewCumulativeTSN: 3, Streams: []StreamSequence{{4, 5}}}},
+		{"07 00 00 08 12 34 56 78", &Shutdown{CumulativeTSNAck: 0x12345678}},
+		{"08 00 00 04", &ShutdownAck{}},
+		{"0e 00 00 04", &ShutdownComplete{}},
+		{"0e 01 00 04", &ShutdownComplete{NoTCB: true}},
+		{"0b 00 00 04", &CookieAck{}},
+		{
+			"00 03 00 14 00 00 00 2a 00 01 00 02 00 00 00 33 68 65 6c 6c",
+			&Data{DataFlags: DataFlags{Beginning: true, Ending: true}, TSN: 42, StreamID: 1, StreamSeq: 2, PPID: 51, UserData: []byte("hell")},
+		},
+		{
+			"03 00 00 18 00 00 00 10 00 01 00 00 00 01 00 01 00 02 00 04 00 00 00 0e",
+			&Sack{CumulativeTSNAck: 16, AdvertisedWindow: 0x10000, GapAckBlocks: []GapAckBlock{{2, 4}}, DuplicateTSNs: []uint32{14}},
+		},
+		{
+			"06 00 00 0b 00 0c 00 07 61 62 63 00",
+			&Abort{Causes: []ErrorCause{{Code: CauseUserInitiatedAbort, Info: []byte("abc")}}},
+		},
+	}
+	for _, test := range tests {
+		data := decode(t, test.data)
+		chunk, n, err := ParseChunk(data)
+		if err != nil {
+			t.Errorf("%v: %v", test.chunk.Type(), err)
+			continue
+		}
+		if n != len(data) || !reflect.DeepEqual(chunk, test.chunk) {
+			t.Errorf("%v: parsed %#v (%d bytes), expected %#v", test.chunk.Type(), chunk, n, test.chunk)
+		}
+		marshaled, err := test.chunk.Marshal()
+		if err != nil {
+			t.Errorf("%v: %v", test.chunk.Type(), err)
+			continue
+		}
+		if !bytes.Equal(pad(marshaled), data) {
+			t.Errorf("%v: marshaled % x, expected % x", test.chunk.Type(), marshaled, data)
+		}
+	}
+}
+
+func TestChunkRoundTrip(t *testing.T) {
+	address := IPAddress{IP: net.ParseIP("2001:db8::1")}
+	chunks := []Chunk{
+		&Data{DataFlags: DataFlags{Unordered: true, Immediate: true}, TSN: 1, StreamID: 2, StreamSeq: 3, PPID: 4, UserData: []byte{5, 6, 7}},
+		&IData{DataFlags: DataFlags{Beginning: true}, TSN: 1, StreamID: 2, MessageID: 3, PPID: 4, UserData: []byte{5}},
+		&IData{DataFlags: DataFlags{Ending: true}, TSN: 1, StreamID: 2, MessageID: 3, FSN: 4, UserData: []byte{5}},
+		&Init{
+			InitiateTag:      1,
+			AdvertisedWindow: 2,
+			OutboundStreams:  3,
+			InboundStreams:   4,
+			InitialTSN:       5,
+			Params: []Param{
+				&IPAddress{IP: net.IPv4(192, 0, 2, 1).To4()},
+				&address,
+				&CookiePreservative{Increment: 1000},
+				&HostNameAddress{HostName: "example.org"},
+				&SupportedAddressTypes{Types: []ParamType{ParamIPv4Address, ParamIPv6Address}},
+				&ECNCapable{},
+				&Padding{Padding: make([]byte, 7)},
+				&AdaptationLayerIndication{Indication: 0xabcdef01},
+				&UnknownParam{Kind: 0x4001, Value: []byte{1, 2, 3}},
+			},
+		},
+		&Init{
+			Ack: true,
+			Params: []Param{
+				&StateCookie{Cookie: []byte("cookie")},
+				&UnrecognizedParam{Param: []byte{0x40, 0x01, 0x00, 0x04}},
+			},
+		},
+		&Sack{CumulativeTSNAck: 1},
+		&Heartbeat{Info: []byte("info")},
+		&Heartbeat{Ack: true, Info: []byte("info")},
+		&Abort{NoTCB: true, Causes: []ErrorCause{
+			{Code: CauseInvalidStreamIdentifier, Info: []byte{0, 9, 0, 0}},
+			{Code: CauseProtocolViolation, Info: []byte("bad")},
+			{Code: CauseNoUserData, Info: []byte{0, 0, 0, 1}},
+		}},
+		&Error{Causes: []ErrorCause{{Code: CauseStaleCookie, Info: []byte{0, 0, 1, 0}}}},
+		&CookieEcho{Cookie: []byte("cookie")},
+		&Auth{SharedKeyID: 1, HMACID: HMACSHA256, HMAC: make([]byte, 32)},
+		&Asconf{Serial: 1, Address: address, Params: []Param{
+			&AddressConfig{Kind: ParamAddIPAddress, CorrelationID: 1, Address: IPAddress{IP: net.IPv4(192, 0, 2, 2).To4()}},
+			&AddressConfig{Kind: ParamDeleteIPAddress, CorrelationID: 2, Address: address},
+			&AddressConfig{Kind: ParamSetPrimaryAddress, CorrelationID: 3, Address: address},
+		}},
+		&AsconfAck{Serial: 1, Params: []Param{
+			&SuccessIndication{CorrelationID: 1},
+			&ErrorCauseIndication{CorrelationID: 2, Causes: []ErrorCause{{Code: CauseDeleteLastRemainingAddress}}},
+		}},
+		&Reconfig{Params: []Param{
+			&OutgoingResetRequest{RequestSeq: 1, ResponseSeq: 2, LastTSN: 3, Streams: []uint16{4, 5, 6}},
+			&IncomingResetRequest{RequestSeq: 2, Streams: []uint16{7}},
+		}},
+		&Reconfig{Params: []Param{&SSNTSNResetRequest{RequestSeq: 3}}},
+		&Reconfig{Params: []Param{
+			&ReconfigResponse{ResponseSeq: 1, Result: ReconfigSuccessPerformed},
+			&ReconfigResponse{ResponseSeq: 2, Result: ReconfigInProgress, NextTSN: true, SenderNextTSN: 3, ReceiverNextTSN: 4},
+		}},
+		&Reconfig{Params: []Param{&AddStreamsRequest{RequestSeq: 1, Streams: 2}, &AddStreamsRequest{Incoming: true, RequestSeq: 2, Streams: 3}}},
+		&ForwardTSN{NewCumulativeTSN: 1},
+		&IForwardTSN{NewCumulativeTSN: 1, Streams: []StreamMessage{{StreamID: 2, Unordered: true, MessageID: 3}, {StreamID: 4, MessageID: 5}}},
+		&UnknownChunk{Kind: 0x3f, Flags: 0x5a, Value: []byte{1, 2, 3, 4, 5}},
+	}
+	packet := Packet{SourcePort: 1, DestinationPort: 2, VerificationTag: 3, Chunks: chunks}
+	data, err := packet.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unmarshaled Packet
+	if err := unmarshaled.Unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(unmarshaled.Chunks) != len(chunks) {
+		t.Fatalf("unmarshaled %d chunks, expected %d", len(unmarshaled.Chunks), len(chunks))
+	}
+	for i, chunk := range unmarshaled.Chunks {
+		if !reflect.DeepEqual(chunk, chunks[i]) {
+			t.Errorf("%v: unmarshaled %#v, expected %#v", chunks[i].Type(), chunk, chunks[i])
+		}
+	}
+	if unmarshaled.Checksum != packet.Checksum {
+		t.Errorf("checksum %#08x, expected %#08x", unmarshaled.Checksum, packet.Checksum)
+	}
+}
+
+func TestChunkErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		chunk Chunk
+		err   error
+	}{
+		{"type", "07 00 00 08 00 00 00 01", &ShutdownAck{}, ErrUnexpectedType},
+		{"short value", "07 00 00 06 00 00", &Shutdown{}, ErrInvalidLength},
+		{"header", "07 00", &Shutdown{}, ErrTruncated},
+		{"beyond data", "07 00 00 0c 00 00 00 01", &Shutdown{}, ErrInvalidLength},
+		{"gap blocks", "03 00 00 10 00 00 00 01 00 00 00 01 00 01 00 00", &Sack{}, ErrInvalidLength},
+		{"forward tsn", "c0 00 00 0a 00 00 00 01 00 01", &ForwardTSN{}, ErrInvalidLength},
+		{"asconf address", "c1 00 00 10 00 00 00 01 c0 05 00 08 00 00 00 01", &Asconf{}, ErrUnexpectedType},
+		{"ipv4 length", "01 00 00 1c 00 00 00 01 00 00 00 01 00 01 00 01 00 00 00 01 00 05 00 08 c0 00 02", &Init{}, ErrInvalidLength},
+		{"ipv6 length", "01 00 00 1c 00 00 00 01 00 00 00 01 00 01 00 01 00 00 00 01 00 06 00 08 20 01 0d b8", &Init{}, ErrInvalidLength},
+	}
+	for _, test := range tests {
+		if err := test.chunk.Unmarshal(decode(t, test.data)); !errors.Is(err, test.err) {
+			t.Errorf("%s: error %v, expected %v", test.name, err, test.err)
+		}
+	}
+	if _, err := (&AddressConfig{Kind: ParamStateCookie}).Marshal(); !errors.Is(err, ErrUnexpectedType) {
+		t.Errorf("address configuration of type %v: %v", ParamStateCookie, err)
+	}
+	if _, err := (&CookieEcho{Cookie: make([]byte, 0x10000)}).Marshal(); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("oversized chunk: %v", err)
+	}
+}
+
+func TestTypeNames(t *testing.T) {
+	for _, test := range []struct {
+		value interface{ String() string }
+		name  string
+	}{
+		{ChunkInitAck, "INIT-ACK"},
+		{ChunkType(99), "CHUNK(99)"},
+		{ParamForwardTSNSupported, "Forward-TSN-Supported"},
+		{ParamType(0x4001), "PARAM(0x4001)"},
+		{CauseProtocolViolation, "Protocol Violation"},
+		{CauseCode(0x99), "CAUSE(0x0099)"},
+	} {
+		if got := test.value.String(); got != test.name {
+			t.Errorf("got %q, expected %q", got, test.name)
+		}
+	}
+}
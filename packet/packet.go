@@ -0,0 +1,110 @@
+// Package packet encodes and decodes SCTP packets, their chunks, parameters and error causes
+// as defined by RFC 9260 and its extensions, without the kernel SCTP stack. It is meant for
+// tooling that builds or inspects raw packets, such as tests, captures and fuzzers.
+package packet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// CommonHeaderSize is the size of the SCTP common header.
+const CommonHeaderSize = 12
+
+var (
+	// ErrTruncated is returned when data ends before a header or a field.
+	ErrTruncated = errors.New("sctp packet truncated")
+	// ErrInvalidLength is returned when a length field is smaller than the fixed part of the
+	// chunk, parameter or error cause or larger than the remaining data.
+	ErrInvalidLength = errors.New("sctp invalid length")
+	// ErrUnexpectedType is returned when a chunk or parameter is unmarshaled into the type
+	// of another chunk or parameter.
+	ErrUnexpectedType = errors.New("sctp unexpected type")
+	// ErrChecksum is returned when the CRC32c checksum of a packet does not match.
+	ErrChecksum = errors.New("sctp checksum mismatch")
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Checksum returns the CRC32c checksum of the packet, computed with the checksum field of the
+// common header taken as zero. The checksum is carried in the header in little-endian byte
+// order, unlike the other fields.
+func Checksum(data []byte) uint32 {
+	if len(data) < CommonHeaderSize {
+		return 0
+	}
+	var zero [4]byte
+	crc := crc32.Update(0, castagnoli, data[:8])
+	crc = crc32.Update(crc, castagnoli, zero[:])
+	return crc32.Update(crc, castagnoli, data[CommonHeaderSize:])
+}
+
+// Packet represents an SCTP packet, the common header followed by chunks.
+type Packet struct {
+	SourcePort      uint16
+	DestinationPort uint16
+	VerificationTag uint32
+	Checksum        uint32
+	Chunks          []Chunk
+}
+
+// Marshal encodes the packet, padding each chunk to a multiple of 4 bytes, and sets the
+// checksum of the packet and of the returned header.
+func (p *Packet) Marshal() ([]byte, error) {
+	buffer := make([]byte, CommonHeaderSize, 1500)
+	binary.BigEndian.PutUint16(buffer[0:], p.SourcePort)
+	binary.BigEndian.PutUint16(buffer[2:], p.DestinationPort)
+	binary.BigEndian.PutUint32(buffer[4:], p.VerificationTag)
+	for _, chunk := range p.Chunks {
+		data, err := chunk.Marshal()
+		if err != nil {
+			return nil, fmt.Errorf("chunk %v: %w", chunk.Type(), err)
+		}
+		buffer = append(buffer, data...)
+		buffer = pad(buffer)
+	}
+	p.Checksum = Checksum(buffer)
+	binary.LittleEndian.PutUint32(buffer[8:], p.Checksum)
+	return buffer, nil
+}
+
+// Unmarshal decodes the packet and verifies its checksum, chunks of unknown types are kept
+// as *UnknownChunk.
+func (p *Packet) Unmarshal(data []byte) error {
+	if len(data) < CommonHeaderSize {
+		return ErrTruncated
+	}
+	checksum := binary.LittleEndian.Uint32(data[8:])
+	if sum := Checksum(data); sum != checksum {
+		return fmt.Errorf("%w: %#08x, computed %#08x", ErrChecksum, checksum, sum)
+	}
+	p.SourcePort = binary.BigEndian.Uint16(data[0:])
+	p.DestinationPort = binary.BigEndian.Uint16(data[2:])
+	p.VerificationTag = binary.BigEndian.Uint32(data[4:])
+	p.Checksum = checksum
+	p.Chunks = nil
+	for offset := CommonHeaderSize; offset < len(data); {
+		chunk, n, err := ParseChunk(data[offset:])
+		if err != nil {
+			return fmt.Errorf("chunk at offset %d: %w", offset, err)
+		}
+		p.Chunks = append(p.Chunks, chunk)
+		offset += n
+	}
+	return nil
+}
+
+// pad appends zero bytes up to a multiple of 4 bytes.
+func pad(buffer []byte) []byte {
+	for len(buffer)%4 != 0 {
+		buffer = append(buffer, 0)
+	}
+	return buffer
+}
+
+// padded returns the length rounded up to a multiple of 4 bytes.
+func padded(length int) int {
+	return (length + 3) &^ 3
+}
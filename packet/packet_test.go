@@ -0,0 +1,153 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"hash/crc32"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func decode(t *testing.T, s string) []byte {
+	t.Helper()
+	data, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// Vectors captured from a WebRTC data channel, the header-only packet and the INIT sent by
+// Chrome.
+const (
+	headerOnly = "13 88 13 88 00 00 00 00 06 a9 00 e1"
+	chromeInit = "13 88 13 88 00 00 00 00 81 46 9d fc 01 00 00 56 55 b9 64 a5 00 02 00 00 04 00 08 00" +
+		"e8 6d 10 30 c0 00 00 04 80 08 00 09 c0 0f c1 80 82 00 00 00 80 02 00 24 9f eb bb 5c" +
+		"50 c9 bf 75 9c b1 2c 57 4f a4 5a 51 ba 60 17 78 27 94 5c 31 e6 5d 5b 09 47 e2 22 06" +
+		"80 04 00 06 00 01 00 00 80 03 00 06 80 c1 00 00"
+)
+
+func TestChecksum(t *testing.T) {
+	// RFC 3720 B.4 vectors.
+	sequence := make([]byte, 32)
+	reverse := make([]byte, 32)
+	for i := range sequence {
+		sequence[i], reverse[i] = byte(i), byte(31-i)
+	}
+	tests := []struct {
+		name string
+		data []byte
+		sum  uint32
+	}{
+		{"zeros", make([]byte, 32), 0x8a9136aa},
+		{"ones", bytes.Repeat([]byte{0xff}, 32), 0x62a8ab43},
+		{"incrementing", sequence, 0x46dd794e},
+		{"decrementing", reverse, 0x113fdb5c},
+	}
+	for _, test := range tests {
+		if sum := crc32.Checksum(test.data, castagnoli); sum != test.sum {
+			t.Errorf("%s: crc32c %#08x, expected %#08x", test.name, sum, test.sum)
+		}
+		// The checksum field of the common header is taken as zero.
+		data := append([]byte(nil), test.data...)
+		copy(data[8:], []byte{0, 0, 0, 0})
+		want := crc32.Checksum(data, castagnoli)
+		if sum := Checksum(test.data); sum != want {
+			t.Errorf("%s: checksum %#08x, expected %#08x", test.name, sum, want)
+		}
+	}
+	if sum := Checksum(make([]byte, CommonHeaderSize-1)); sum != 0 {
+		t.Errorf("checksum of a truncated packet %#08x", sum)
+	}
+}
+
+func TestCapturedPackets(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		packet Packet
+	}{
+		{
+			"header only",
+			headerOnly,
+			Packet{SourcePort: 5000, DestinationPort: 5000, Checksum: 0xe100a906},
+		},
+		{
+			"chrome init",
+			chromeInit,
+			Packet{
+				SourcePort:      5000,
+				DestinationPort: 5000,
+				Checksum:        0xfc9d4681,
+				Chunks: []Chunk{&Init{
+					InitiateTag:      0x55b964a5,
+					AdvertisedWindow: 0x20000,
+					OutboundStreams:  1024,
+					InboundStreams:   2048,
+					InitialTSN:       0xe86d1030,
+					Params: []Param{
+						&ForwardTSNSupported{},
+						&SupportedExtensions{Types: []ChunkType{ChunkForwardTSN, ChunkAuth, ChunkAsconf, ChunkAsconfAck, ChunkReconfig}},
+						&Random{Random: decode(t, "9f ebbb5c50c9bf759cb12c574fa45a51ba60177827945c31e65d5b0947e22206")},
+						&RequestedHMACAlgorithm{IDs: []uint16{HMACSHA1}},
+						&ChunkList{Types: []ChunkType{ChunkAsconfAck, ChunkAsconf}},
+					},
+				}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := decode(t, test.data)
+			var packet Packet
+			if err := packet.Unmarshal(data); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(packet, test.packet) {
+				t.Errorf("unmarshaled %+v, expected %+v", packet, test.packet)
+			}
+			marshaled, err := packet.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(marshaled, data) {
+				t.Errorf("marshaled % x, expected % x", marshaled, data)
+			}
+		})
+	}
+}
+
+func TestPacketErrors(t *testing.T) {
+	init := decode(t, chromeInit)
+	corrupt := append([]byte(nil), init...)
+	corrupt[20]++
+	long := append([]byte(nil), init...)
+	long[15] = 0x5a
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"short", init[:CommonHeaderSize-1], ErrTruncated},
+		{"checksum", corrupt, ErrChecksum},
+		{"truncated", withChecksum(init[:CommonHeaderSize+2]), ErrTruncated},
+		{"chunk length", withChecksum(long), ErrInvalidLength},
+		{"param length", withChecksum(append(init[:len(init)-8:len(init)-8], 0x80, 0x03, 0x00, 0x09, 0x80, 0xc1, 0x00, 0x00)), ErrInvalidLength},
+	}
+	for _, test := range tests {
+		var packet Packet
+		if err := packet.Unmarshal(test.data); !errors.Is(err, test.err) {
+			t.Errorf("%s: error %v, expected %v", test.name, err, test.err)
+		}
+	}
+}
+
+// withChecksum sets the checksum of a modified packet.
+func withChecksum(data []byte) []byte {
+	data = append([]byte(nil), data...)
+	sum := Checksum(data)
+	data[8], data[9], data[10], data[11] = byte(sum), byte(sum>>8), byte(sum>>16), byte(sum>>24)
+	return data
+}
@@ -0,0 +1,915 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// ParamType is the type of a parameter of INIT, INIT-ACK, ASCONF, ASCONF-ACK and RE-CONFIG
+// chunks.
+type ParamType uint16
+
+// Parameter types of RFC 9260, RFC 3758, RFC 4895, RFC 5061 and RFC 6525.
+const (
+	ParamHeartbeatInfo             ParamType = 1
+	ParamIPv4Address               ParamType = 5
+	ParamIPv6Address               ParamType = 6
+	ParamStateCookie               ParamType = 7
+	ParamUnrecognized              ParamType = 8
+	ParamCookiePreservative        ParamType = 9
+	ParamHostNameAddress           ParamType = 11
+	ParamSupportedAddressTypes     ParamType = 12
+	ParamOutgoingResetRequest      ParamType = 13
+	ParamIncomingResetRequest      ParamType = 14
+	ParamSSNTSNResetRequest        ParamType = 15
+	ParamReconfigResponse          ParamType = 16
+	ParamAddOutgoingStreams        ParamType = 17
+	ParamAddIncomingStreams        ParamType = 18
+	ParamECNCapable                ParamType = 0x8000
+	ParamRandom                    ParamType = 0x8002
+	ParamChunkList                 ParamType = 0x8003
+	ParamRequestedHMACAlgorithm    ParamType = 0x8004
+	ParamPadding                   ParamType = 0x8005
+	ParamSupportedExtensions       ParamType = 0x8008
+	ParamForwardTSNSupported       ParamType = 0xc000
+	ParamAddIPAddress              ParamType = 0xc001
+	ParamDeleteIPAddress           ParamType = 0xc002
+	ParamErrorCauseIndication      ParamType = 0xc003
+	ParamSetPrimaryAddress         ParamType = 0xc004
+	ParamSuccessIndication         ParamType = 0xc005
+	ParamAdaptationLayerIndication ParamType = 0xc006
+)
+
+var paramNames = map[ParamType]string{
+	ParamHeartbeatInfo:             "Heartbeat Info",
+	ParamIPv4Address:               "IPv4 Address",
+	ParamIPv6Address:               "IPv6 Address",
+	ParamStateCookie:               "State Cookie",
+	ParamUnrecognized:              "Unrecognized Parameter",
+	ParamCookiePreservative:        "Cookie Preservative",
+	ParamHostNameAddress:           "Host Name Address",
+	ParamSupportedAddressTypes:     "Supported Address Types",
+	ParamOutgoingResetRequest:      "Outgoing SSN Reset Request",
+	ParamIncomingResetRequest:      "Incoming SSN Reset Request",
+	ParamSSNTSNResetRequest:        "SSN/TSN Reset Request",
+	ParamReconfigResponse:          "Re-configuration Response",
+	ParamAddOutgoingStreams:        "Add Outgoing Streams Request",
+	ParamAddIncomingStreams:        "Add Incoming Streams Request",
+	ParamECNCapable:                "ECN Capable",
+	ParamRandom:                    "Random",
+	ParamChunkList:                 "Chunk List",
+	ParamRequestedHMACAlgorithm:    "Requested HMAC Algorithm",
+	ParamPadding:                   "Padding",
+	ParamSupportedExtensions:       "Supported Extensions",
+	ParamForwardTSNSupported:       "Forward-TSN-Supported",
+	ParamAddIPAddress:              "Add IP Address",
+	ParamDeleteIPAddress:           "Delete IP Address",
+	ParamErrorCauseIndication:      "Error Cause Indication",
+	ParamSetPrimaryAddress:         "Set Primary Address",
+	ParamSuccessIndication:         "Success Indication",
+	ParamAdaptationLayerIndication: "Adaptation Layer Indication",
+}
+
+// String returns the name of the parameter type.
+func (t ParamType) String() string {
+	if name, ok := paramNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("PARAM(%#04x)", uint16(t))
+}
+
+// Param is a parameter of a chunk. Marshal returns the parameter without the padding that
+// follows it in a chunk, Unmarshal decodes a parameter of the same type and ignores data past
+// its length.
+type Param interface {
+	Type() ParamType
+	Marshal() ([]byte, error)
+	Unmarshal(data []byte) error
+}
+
+// ParseParam decodes the parameter at the start of data and returns it with the number of
+// bytes it takes including padding, parameters of unknown types are returned as
+// *UnknownParam.
+func ParseParam(data []byte) (Param, int, error) {
+	if len(data) < 4 {
+		return nil, 0, ErrTruncated
+	}
+	length := int(binary.BigEndian.Uint16(data[2:]))
+	if length < 4 || length > len(data) {
+		return nil, 0, ErrInvalidLength
+	}
+	var param Param
+	switch ParamType(binary.BigEndian.Uint16(data)) {
+	case ParamHeartbeatInfo:
+		param = &HeartbeatInfo{}
+	case ParamIPv4Address, ParamIPv6Address:
+		param = &IPAddress{}
+	case ParamStateCookie:
+		param = &StateCookie{}
+	case ParamUnrecognized:
+		param = &UnrecognizedParam{}
+	case ParamCookiePreservative:
+		param = &CookiePreservative{}
+	case ParamHostNameAddress:
+		param = &HostNameAddress{}
+	case ParamSupportedAddressTypes:
+		param = &SupportedAddressTypes{}
+	case ParamOutgoingResetRequest:
+		param = &OutgoingResetRequest{}
+	case ParamIncomingResetRequest:
+		param = &IncomingResetRequest{}
+	case ParamSSNTSNResetRequest:
+		param = &SSNTSNResetRequest{}
+	case ParamReconfigResponse:
+		param = &ReconfigResponse{}
+	case ParamAddOutgoingStreams, ParamAddIncomingStreams:
+		param = &AddStreamsRequest{}
+	case ParamECNCapable:
+		param = &ECNCapable{}
+	case ParamRandom:
+		param = &Random{}
+	case ParamChunkList:
+		param = &ChunkList{}
+	case ParamRequestedHMACAlgorithm:
+		param = &RequestedHMACAlgorithm{}
+	case ParamPadding:
+		param = &Padding{}
+	case ParamSupportedExtensions:
+		param = &SupportedExtensions{}
+	case ParamForwardTSNSupported:
+		param = &ForwardTSNSupported{}
+	case ParamAddIPAddress, ParamDeleteIPAddress, ParamSetPrimaryAddress:
+		param = &AddressConfig{}
+	case ParamErrorCauseIndication:
+		param = &ErrorCauseIndication{}
+	case ParamSuccessIndication:
+		param = &SuccessIndication{}
+	case ParamAdaptationLayerIndication:
+		param = &AdaptationLayerIndication{}
+	default:
+		param = &UnknownParam{}
+	}
+	if err := param.Unmarshal(data[:length]); err != nil {
+		return nil, 0, fmt.Errorf("%v: %w", ParamType(binary.BigEndian.Uint16(data)), err)
+	}
+	return param, min(padded(length), len(data)), nil
+}
+
+// appendParams appends the parameters to the chunk, padding all but the last one as the
+// chunk length does not include the final padding.
+func appendParams(buffer []byte, params []Param) ([]byte, error) {
+	for i, param := range params {
+		if i > 0 {
+			buffer = pad(buffer)
+		}
+		data, err := param.Marshal()
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", param.Type(), err)
+		}
+		buffer = append(buffer, data...)
+	}
+	return buffer, nil
+}
+
+// parseParams decodes the parameters filling data.
+func parseParams(data []byte) ([]Param, error) {
+	var params []Param
+	for offset := 0; offset < len(data); {
+		param, n, err := ParseParam(data[offset:])
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+		offset += n
+	}
+	return params, nil
+}
+
+// newParam returns the header of a parameter with capacity for size bytes of value, the
+// length is set by setLength once the value is appended.
+func newParam(kind ParamType, size int) []byte {
+	buffer := make([]byte, 4, 4+size)
+	binary.BigEndian.PutUint16(buffer, uint16(kind))
+	return buffer
+}
+
+// paramValue checks the header of a parameter of one of the types with at least size bytes
+// of value and returns its type and value.
+func paramValue(data []byte, size int, kinds ...ParamType) (ParamType, []byte, error) {
+	if len(data) < 4 {
+		return 0, nil, ErrTruncated
+	}
+	kind := ParamType(binary.BigEndian.Uint16(data))
+	expected := len(kinds) == 0
+	for _, k := range kinds {
+		expected = expected || k == kind
+	}
+	if !expected {
+		return 0, nil, fmt.Errorf("%w: %v", ErrUnexpectedType, kind)
+	}
+	length := int(binary.BigEndian.Uint16(data[2:]))
+	if length < 4+size || length > len(data) {
+		return 0, nil, ErrInvalidLength
+	}
+	return kind, data[4:length], nil
+}
+
+// marshalBytes encodes a parameter whose value is opaque bytes.
+func marshalBytes(kind ParamType, value []byte) ([]byte, error) {
+	return setLength(append(newParam(kind, len(value)), value...))
+}
+
+// unmarshalBytes decodes a parameter whose value is opaque bytes into a copy.
+func unmarshalBytes(data []byte, kind ParamType) ([]byte, error) {
+	_, value, err := paramValue(data, 0, kind)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), value...), nil
+}
+
+// marshalUint32 encodes a parameter whose value is a 32-bit integer.
+func marshalUint32(kind ParamType, value uint32) ([]byte, error) {
+	return setLength(binary.BigEndian.AppendUint32(newParam(kind, 4), value))
+}
+
+// unmarshalUint32 decodes a parameter whose value is a 32-bit integer.
+func unmarshalUint32(data []byte, kind ParamType) (uint32, error) {
+	_, value, err := paramValue(data, 4, kind)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(value), nil
+}
+
+// HeartbeatInfo represents the Heartbeat Info parameter of HEARTBEAT chunks.
+type HeartbeatInfo struct {
+	Info []byte
+}
+
+// Type returns the parameter type.
+func (p *HeartbeatInfo) Type() ParamType { return ParamHeartbeatInfo }
+
+// Marshal encodes the Heartbeat Info parameter.
+func (p *HeartbeatInfo) Marshal() ([]byte, error) {
+	return marshalBytes(ParamHeartbeatInfo, p.Info)
+}
+
+// Unmarshal decodes the Heartbeat Info parameter.
+func (p *HeartbeatInfo) Unmarshal(data []byte) (err error) {
+	p.Info, err = unmarshalBytes(data, ParamHeartbeatInfo)
+	return err
+}
+
+// IPAddress represents the IPv4 Address or IPv6 Address parameter, after the length of IP.
+type IPAddress struct {
+	IP net.IP
+}
+
+// Type returns the parameter type.
+func (p *IPAddress) Type() ParamType {
+	if p.IP.To4() != nil {
+		return ParamIPv4Address
+	}
+	return ParamIPv6Address
+}
+
+// Marshal encodes the IPv4 Address or IPv6 Address parameter.
+func (p *IPAddress) Marshal() ([]byte, error) {
+	if ip := p.IP.To4(); ip != nil {
+		return marshalBytes(ParamIPv4Address, ip)
+	}
+	if ip := p.IP.To16(); ip != nil {
+		return marshalBytes(ParamIPv6Address, ip)
+	}
+	return nil, fmt.Errorf("%w: address %v", ErrInvalidLength, p.IP)
+}
+
+// Unmarshal decodes the IPv4 Address or IPv6 Address parameter.
+func (p *IPAddress) Unmarshal(data []byte) error {
+	kind, value, err := paramValue(data, 4, ParamIPv4Address, ParamIPv6Address)
+	if err != nil {
+		return err
+	}
+	if (kind == ParamIPv4Address && len(value) != net.IPv4len) ||
+		(kind == ParamIPv6Address && len(value) != net.IPv6len) {
+		return ErrInvalidLength
+	}
+	p.IP = append(net.IP(nil), value...)
+	return nil
+}
+
+// StateCookie represents the State Cookie parameter of INIT-ACK chunks.
+type StateCookie struct {
+	Cookie []byte
+}
+
+// Type returns the parameter type.
+func (p *StateCookie) Type() ParamType { return ParamStateCookie }
+
+// Marshal encodes the State Cookie parameter.
+func (p *StateCookie) Marshal() ([]byte, error) {
+	return marshalBytes(ParamStateCookie, p.Cookie)
+}
+
+// Unmarshal decodes the State Cookie parameter.
+func (p *StateCookie) Unmarshal(data []byte) (err error) {
+	p.Cookie, err = unmarshalBytes(data, ParamStateCookie)
+	return err
+}
+
+// UnrecognizedParam represents the Unrecognized Parameter parameter of INIT-ACK chunks, Param
+// is the parameter not understood by the peer including its header.
+type UnrecognizedParam struct {
+	Param []byte
+}
+
+// Type returns the parameter type.
+func (p *UnrecognizedParam) Type() ParamType { return ParamUnrecognized }
+
+// Marshal encodes the Unrecognized Parameter parameter.
+func (p *UnrecognizedParam) Marshal() ([]byte, error) {
+	return marshalBytes(ParamUnrecognized, p.Param)
+}
+
+// Unmarshal decodes the Unrecognized Parameter parameter.
+func (p *UnrecognizedParam) Unmarshal(data []byte) (err error) {
+	p.Param, err = unmarshalBytes(data, ParamUnrecognized)
+	return err
+}
+
+// CookiePreservative represents the Cookie Preservative parameter of INIT chunks, asking for
+// a cookie lifespan longer by Increment milliseconds.
+type CookiePreservative struct {
+	Increment uint32
+}
+
+// Type returns the parameter type.
+func (p *CookiePreservative) Type() ParamType { return ParamCookiePreservative }
+
+// Marshal encodes the Cookie Preservative parameter.
+func (p *CookiePreservative) Marshal() ([]byte, error) {
+	return marshalUint32(ParamCookiePreservative, p.Increment)
+}
+
+// Unmarshal decodes the Cookie Preservative parameter.
+func (p *CookiePreservative) Unmarshal(data []byte) (err error) {
+	p.Increment, err = unmarshalUint32(data, ParamCookiePreservative)
+	return err
+}
+
+// HostNameAddress represents the deprecated Host Name Address parameter.
+type HostNameAddress struct {
+	HostName string
+}
+
+// Type returns the parameter type.
+func (p *HostNameAddress) Type() ParamType { return ParamHostNameAddress }
+
+// Marshal encodes the deprecated Host Name Address parameter.
+func (p *HostNameAddress) Marshal() ([]byte, error) {
+	return marshalBytes(ParamHostNameAddress, append([]byte(p.HostName), 0))
+}
+
+// Unmarshal decodes the deprecated Host Name Address parameter.
+func (p *HostNameAddress) Unmarshal(data []byte) error {
+	value, err := unmarshalBytes(data, ParamHostNameAddress)
+	if err != nil {
+		return err
+	}
+	if i := bytes.IndexByte(value, 0); i >= 0 {
+		value = value[:i]
+	}
+	p.HostName = string(value)
+	return nil
+}
+
+// SupportedAddressTypes represents the Supported Address Types parameter of INIT chunks.
+type SupportedAddressTypes struct {
+	Types []ParamType
+}
+
+// Type returns the parameter type.
+func (p *SupportedAddressTypes) Type() ParamType { return ParamSupportedAddressTypes }
+
+// Marshal encodes the Supported Address Types parameter.
+func (p *SupportedAddressTypes) Marshal() ([]byte, error) {
+	buffer := newParam(ParamSupportedAddressTypes, 2*len(p.Types))
+	for _, kind := range p.Types {
+		buffer = binary.BigEndian.AppendUint16(buffer, uint16(kind))
+	}
+	return setLength(buffer)
+}
+
+// Unmarshal decodes the Supported Address Types parameter.
+func (p *SupportedAddressTypes) Unmarshal(data []byte) error {
+	_, value, err := paramValue(data, 0, ParamSupportedAddressTypes)
+	if err != nil {
+		return err
+	}
+	if len(value)%2 != 0 {
+		return ErrInvalidLength
+	}
+	p.Types = nil
+	for offset := 0; offset < len(value); offset += 2 {
+		p.Types = append(p.Types, ParamType(binary.BigEndian.Uint16(value[offset:])))
+	}
+	return nil
+}
+
+// appendStreams appends 16-bit stream identifiers.
+func appendStreams(buffer []byte, streams []uint16) []byte {
+	for _, stream := range streams {
+		buffer = binary.BigEndian.AppendUint16(buffer, stream)
+	}
+	return buffer
+}
+
+// parseStreams decodes 16-bit stream identifiers.
+func parseStreams(data []byte) ([]uint16, error) {
+	if len(data)%2 != 0 {
+		return nil, ErrInvalidLength
+	}
+	var streams []uint16
+	for offset := 0; offset < len(data); offset += 2 {
+		streams = append(streams, binary.BigEndian.Uint16(data[offset:]))
+	}
+	return streams, nil
+}
+
+// OutgoingResetRequest represents the Outgoing SSN Reset Request parameter of RE-CONFIG
+// chunks, no Streams resets all streams.
+type OutgoingResetRequest struct {
+	RequestSeq  uint32
+	ResponseSeq uint32
+	LastTSN     uint32
+	Streams     []uint16
+}
+
+// Type returns the parameter type.
+func (p *OutgoingResetRequest) Type() ParamType { return ParamOutgoingResetRequest }
+
+// Marshal encodes the Outgoing SSN Reset Request parameter.
+func (p *OutgoingResetRequest) Marshal() ([]byte, error) {
+	buffer := newParam(ParamOutgoingResetRequest, 12+2*len(p.Streams))
+	buffer = binary.BigEndian.AppendUint32(buffer, p.RequestSeq)
+	buffer = binary.BigEndian.AppendUint32(buffer, p.ResponseSeq)
+	buffer = binary.BigEndian.AppendUint32(buffer, p.LastTSN)
+	return setLength(appendStreams(buffer, p.Streams))
+}
+
+// Unmarshal decodes the Outgoing SSN Reset Request parameter.
+func (p *OutgoingResetRequest) Unmarshal(data []byte) error {
+	_, value, err := paramValue(data, 12, ParamOutgoingResetRequest)
+	if err != nil {
+		return err
+	}
+	p.RequestSeq = binary.BigEndian.Uint32(value[0:])
+	p.ResponseSeq = binary.BigEndian.Uint32(value[4:])
+	p.LastTSN = binary.BigEndian.Uint32(value[8:])
+	p.Streams, err = parseStreams(value[12:])
+	return err
+}
+
+// IncomingResetRequest represents the Incoming SSN Reset Request parameter of RE-CONFIG
+// chunks, no Streams resets all streams.
+type IncomingResetRequest struct {
+	RequestSeq uint32
+	Streams    []uint16
+}
+
+// Type returns the parameter type.
+func (p *IncomingResetRequest) Type() ParamType { return ParamIncomingResetRequest }
+
+// Marshal encodes the Incoming SSN Reset Request parameter.
+func (p *IncomingResetRequest) Marshal() ([]byte, error) {
+	buffer := binary.BigEndian.AppendUint32(newParam(ParamIncomingResetRequest, 4+2*len(p.Streams)), p.RequestSeq)
+	return setLength(appendStreams(buffer, p.Streams))
+}
+
+// Unmarshal decodes the Incoming SSN Reset Request parameter.
+func (p *IncomingResetRequest) Unmarshal(data []byte) error {
+	_, value, err := paramValue(data, 4, ParamIncomingResetRequest)
+	if err != nil {
+		return err
+	}
+	p.RequestSeq = binary.BigEndian.Uint32(value)
+	p.Streams, err = parseStreams(value[4:])
+	return err
+}
+
+// SSNTSNResetRequest represents the SSN/TSN Reset Request parameter of RE-CONFIG chunks.
+type SSNTSNResetRequest struct {
+	RequestSeq uint32
+}
+
+// Type returns the parameter type.
+func (p *SSNTSNResetRequest) Type() ParamType { return ParamSSNTSNResetRequest }
+
+// Marshal encodes the SSN/TSN Reset Request parameter.
+func (p *SSNTSNResetRequest) Marshal() ([]byte, error) {
+	return marshalUint32(ParamSSNTSNResetRequest, p.RequestSeq)
+}
+
+// Unmarshal decodes the SSN/TSN Reset Request parameter.
+func (p *SSNTSNResetRequest) Unmarshal(data []byte) (err error) {
+	p.RequestSeq, err = unmarshalUint32(data, ParamSSNTSNResetRequest)
+	return err
+}
+
+// Results of a Re-configuration Response parameter.
+const (
+	ReconfigSuccessNop       uint32 = 0
+	ReconfigSuccessPerformed uint32 = 1
+	ReconfigDenied           uint32 = 2
+	ReconfigErrorWrongSSN    uint32 = 3
+	ReconfigErrorInProgress  uint32 = 4
+	ReconfigErrorBadSequence uint32 = 5
+	ReconfigInProgress       uint32 = 6
+)
+
+// ReconfigResponse represents the Re-configuration Response parameter of RE-CONFIG chunks.
+// The next TSNs are only carried in the response to an SSN/TSN Reset Request, when NextTSN is
+// set.
+type ReconfigResponse struct {
+	ResponseSeq     uint32
+	Result          uint32
+	NextTSN         bool
+	SenderNextTSN   uint32
+	ReceiverNextTSN uint32
+}
+
+// Type returns the parameter type.
+func (p *ReconfigResponse) Type() ParamType { return ParamReconfigResponse }
+
+// Marshal encodes the Re-configuration Response parameter.
+func (p *ReconfigResponse) Marshal() ([]byte, error) {
+	buffer := newParam(ParamReconfigResponse, 16)
+	buffer = binary.BigEndian.AppendUint32(buffer, p.ResponseSeq)
+	buffer = binary.BigEndian.AppendUint32(buffer, p.Result)
+	if p.NextTSN {
+		buffer = binary.BigEndian.AppendUint32(buffer, p.SenderNextTSN)
+		buffer = binary.BigEndian.AppendUint32(buffer, p.ReceiverNextTSN)
+	}
+	return setLength(buffer)
+}
+
+// Unmarshal decodes the Re-configuration Response parameter.
+func (p *ReconfigResponse) Unmarshal(data []byte) error {
+	_, value, err := paramValue(data, 8, ParamReconfigResponse)
+	if err != nil {
+		return err
+	}
+	if len(value) != 8 && len(value) != 16 {
+		return ErrInvalidLength
+	}
+	p.ResponseSeq = binary.BigEndian.Uint32(value[0:])
+	p.Result = binary.BigEndian.Uint32(value[4:])
+	p.NextTSN = len(value) == 16
+	p.SenderNextTSN, p.ReceiverNextTSN = 0, 0
+	if p.NextTSN {
+		p.SenderNextTSN = binary.BigEndian.Uint32(value[8:])
+		p.ReceiverNextTSN = binary.BigEndian.Uint32(value[12:])
+	}
+	return nil
+}
+
+// AddStreamsRequest represents the Add Outgoing Streams Request parameter of RE-CONFIG
+// chunks, or the Add Incoming Streams Request parameter when Incoming is set.
+type AddStreamsRequest struct {
+	Incoming   bool
+	RequestSeq uint32
+	Streams    uint16
+}
+
+// Type returns the parameter type.
+func (p *AddStreamsRequest) Type() ParamType {
+	if p.Incoming {
+		return ParamAddIncomingStreams
+	}
+	return ParamAddOutgoingStreams
+}
+
+// Marshal encodes the Add Outgoing Streams Request parameter.
+func (p *AddStreamsRequest) Marshal() ([]byte, error) {
+	buffer := binary.BigEndian.AppendUint32(newParam(p.Type(), 8), p.RequestSeq)
+	buffer = binary.BigEndian.AppendUint16(buffer, p.Streams)
+	return setLength(binary.BigEndian.AppendUint16(buffer, 0))
+}
+
+// Unmarshal decodes the Add Outgoing Streams Request parameter.
+func (p *AddStreamsRequest) Unmarshal(data []byte) error {
+	kind, value, err := paramValue(data, 8, ParamAddOutgoingStreams, ParamAddIncomingStreams)
+	if err != nil {
+		return err
+	}
+	p.Incoming = kind == ParamAddIncomingStreams
+	p.RequestSeq = binary.BigEndian.Uint32(value)
+	p.Streams = binary.BigEndian.Uint16(value[4:])
+	return nil
+}
+
+// ECNCapable represents the ECN Capable parameter of INIT and INIT-ACK chunks.
+type ECNCapable struct{}
+
+// Type returns the parameter type.
+func (p *ECNCapable) Type() ParamType { return ParamECNCapable }
+
+// Marshal encodes the ECN Capable parameter.
+func (p *ECNCapable) Marshal() ([]byte, error) {
+	return marshalBytes(ParamECNCapable, nil)
+}
+
+// Unmarshal decodes the ECN Capable parameter.
+func (p *ECNCapable) Unmarshal(data []byte) error {
+	_, _, err := paramValue(data, 0, ParamECNCapable)
+	return err
+}
+
+// Random represents the Random parameter of RFC 4895.
+type Random struct {
+	Random []byte
+}
+
+// Type returns the parameter type.
+func (p *Random) Type() ParamType { return ParamRandom }
+
+// Marshal encodes the Random parameter.
+func (p *Random) Marshal() ([]byte, error) {
+	return marshalBytes(ParamRandom, p.Random)
+}
+
+// Unmarshal decodes the Random parameter.
+func (p *Random) Unmarshal(data []byte) (err error) {
+	p.Random, err = unmarshalBytes(data, ParamRandom)
+	return err
+}
+
+// appendChunkTypes appends a list of chunk types, one byte each.
+func appendChunkTypes(buffer []byte, types []ChunkType) []byte {
+	for _, kind := range types {
+		buffer = append(buffer, byte(kind))
+	}
+	return buffer
+}
+
+// parseChunkTypes decodes a list of chunk types, one byte each.
+func parseChunkTypes(data []byte) []ChunkType {
+	var types []ChunkType
+	for _, kind := range data {
+		types = append(types, ChunkType(kind))
+	}
+	return types
+}
+
+// ChunkList represents the Chunk List parameter of RFC 4895, the chunks that must be
+// authenticated.
+type ChunkList struct {
+	Types []ChunkType
+}
+
+// Type returns the parameter type.
+func (p *ChunkList) Type() ParamType { return ParamChunkList }
+
+// Marshal encodes the Chunk List parameter.
+func (p *ChunkList) Marshal() ([]byte, error) {
+	return setLength(appendChunkTypes(newParam(ParamChunkList, len(p.Types)), p.Types))
+}
+
+// Unmarshal decodes the Chunk List parameter.
+func (p *ChunkList) Unmarshal(data []byte) error {
+	_, value, err := paramValue(data, 0, ParamChunkList)
+	if err != nil {
+		return err
+	}
+	p.Types = parseChunkTypes(value)
+	return nil
+}
+
+// HMAC identifiers of RFC 4895.
+const (
+	HMACSHA1   uint16 = 1
+	HMACSHA256 uint16 = 3
+)
+
+// RequestedHMACAlgorithm represents the Requested HMAC Algorithm parameter of RFC 4895, the
+// HMAC identifiers in order of preference.
+type RequestedHMACAlgorithm struct {
+	IDs []uint16
+}
+
+// Type returns the parameter type.
+func (p *RequestedHMACAlgorithm) Type() ParamType { return ParamRequestedHMACAlgorithm }
+
+// Marshal encodes the Requested HMAC Algorithm parameter.
+func (p *RequestedHMACAlgorithm) Marshal() ([]byte, error) {
+	return setLength(appendStreams(newParam(ParamRequestedHMACAlgorithm, 2*len(p.IDs)), p.IDs))
+}
+
+// Unmarshal decodes the Requested HMAC Algorithm parameter.
+func (p *RequestedHMACAlgorithm) Unmarshal(data []byte) error {
+	_, value, err := paramValue(data, 0, ParamRequestedHMACAlgorithm)
+	if err != nil {
+		return err
+	}
+	p.IDs, err = parseStreams(value)
+	return err
+}
+
+// Padding represents the Padding parameter of RFC 4820, used to probe the path MTU with INIT
+// chunks.
+type Padding struct {
+	Padding []byte
+}
+
+// Type returns the parameter type.
+func (p *Padding) Type() ParamType { return ParamPadding }
+
+// Marshal encodes the Padding parameter.
+func (p *Padding) Marshal() ([]byte, error) {
+	return marshalBytes(ParamPadding, p.Padding)
+}
+
+// Unmarshal decodes the Padding parameter.
+func (p *Padding) Unmarshal(data []byte) (err error) {
+	p.Padding, err = unmarshalBytes(data, ParamPadding)
+	return err
+}
+
+// SupportedExtensions represents the Supported Extensions parameter of RFC 5061, the chunk
+// types of the extensions supported by the sender.
+type SupportedExtensions struct {
+	Types []ChunkType
+}
+
+// Type returns the parameter type.
+func (p *SupportedExtensions) Type() ParamType { return ParamSupportedExtensions }
+
+// Marshal encodes the Supported Extensions parameter.
+func (p *SupportedExtensions) Marshal() ([]byte, error) {
+	return setLength(appendChunkTypes(newParam(ParamSupportedExtensions, len(p.Types)), p.Types))
+}
+
+// Unmarshal decodes the Supported Extensions parameter.
+func (p *SupportedExtensions) Unmarshal(data []byte) error {
+	_, value, err := paramValue(data, 0, ParamSupportedExtensions)
+	if err != nil {
+		return err
+	}
+	p.Types = parseChunkTypes(value)
+	return nil
+}
+
+// ForwardTSNSupported represents the Forward-TSN-Supported parameter of RFC 3758.
+type ForwardTSNSupported struct{}
+
+// Type returns the parameter type.
+func (p *ForwardTSNSupported) Type() ParamType { return ParamForwardTSNSupported }
+
+// Marshal encodes the Forward-TSN-Supported parameter.
+func (p *ForwardTSNSupported) Marshal() ([]byte, error) {
+	return marshalBytes(ParamForwardTSNSupported, nil)
+}
+
+// Unmarshal decodes the Forward-TSN-Supported parameter.
+func (p *ForwardTSNSupported) Unmarshal(data []byte) error {
+	_, _, err := paramValue(data, 0, ParamForwardTSNSupported)
+	return err
+}
+
+// AddressConfig represents the Add IP Address, Delete IP Address and Set Primary Address
+// parameters of ASCONF chunks, Kind is one of ParamAddIPAddress, ParamDeleteIPAddress or
+// ParamSetPrimaryAddress.
+type AddressConfig struct {
+	Kind          ParamType
+	CorrelationID uint32
+	Address       IPAddress
+}
+
+// Type returns the parameter type.
+func (p *AddressConfig) Type() ParamType { return p.Kind }
+
+// Marshal encodes the address configuration parameter of type Kind.
+func (p *AddressConfig) Marshal() ([]byte, error) {
+	switch p.Kind {
+	case ParamAddIPAddress, ParamDeleteIPAddress, ParamSetPrimaryAddress:
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedType, p.Kind)
+	}
+	address, err := p.Address.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	buffer := binary.BigEndian.AppendUint32(newParam(p.Kind, 4+len(address)), p.CorrelationID)
+	return setLength(append(buffer, address...))
+}
+
+// Unmarshal decodes an Add IP Address, Delete IP Address or Set Primary Address parameter.
+func (p *AddressConfig) Unmarshal(data []byte) error {
+	kind, value, err := paramValue(data, 8, ParamAddIPAddress, ParamDeleteIPAddress, ParamSetPrimaryAddress)
+	if err != nil {
+		return err
+	}
+	if err := p.Address.Unmarshal(value[4:]); err != nil {
+		return err
+	}
+	p.Kind = kind
+	p.CorrelationID = binary.BigEndian.Uint32(value)
+	return nil
+}
+
+// ErrorCauseIndication represents the Error Cause Indication parameter of ASCONF-ACK chunks,
+// reporting the failure of the request with the correlation id.
+type ErrorCauseIndication struct {
+	CorrelationID uint32
+	Causes        []ErrorCause
+}
+
+// Type returns the parameter type.
+func (p *ErrorCauseIndication) Type() ParamType { return ParamErrorCauseIndication }
+
+// Marshal encodes the Error Cause Indication parameter.
+func (p *ErrorCauseIndication) Marshal() ([]byte, error) {
+	buffer := binary.BigEndian.AppendUint32(newParam(ParamErrorCauseIndication, 4), p.CorrelationID)
+	buffer, err := appendCauses(buffer, p.Causes)
+	if err != nil {
+		return nil, err
+	}
+	return setLength(buffer)
+}
+
+// Unmarshal decodes the Error Cause Indication parameter.
+func (p *ErrorCauseIndication) Unmarshal(data []byte) error {
+	_, value, err := paramValue(data, 4, ParamErrorCauseIndication)
+	if err != nil {
+		return err
+	}
+	p.CorrelationID = binary.BigEndian.Uint32(value)
+	p.Causes, err = parseCauses(value[4:])
+	return err
+}
+
+// SuccessIndication represents the Success Indication parameter of ASCONF-ACK chunks.
+type SuccessIndication struct {
+	CorrelationID uint32
+}
+
+// Type returns the parameter type.
+func (p *SuccessIndication) Type() ParamType { return ParamSuccessIndication }
+
+// Marshal encodes the Success Indication parameter.
+func (p *SuccessIndication) Marshal() ([]byte, error) {
+	return marshalUint32(ParamSuccessIndication, p.CorrelationID)
+}
+
+// Unmarshal decodes the Success Indication parameter.
+func (p *SuccessIndication) Unmarshal(data []byte) (err error) {
+	p.CorrelationID, err = unmarshalUint32(data, ParamSuccessIndication)
+	return err
+}
+
+// AdaptationLayerIndication represents the Adaptation Layer Indication parameter of RFC 5061.
+type AdaptationLayerIndication struct {
+	Indication uint32
+}
+
+// Type returns the parameter type.
+func (p *AdaptationLayerIndication) Type() ParamType { return ParamAdaptationLayerIndication }
+
+// Marshal encodes the Adaptation Layer Indication parameter.
+func (p *AdaptationLayerIndication) Marshal() ([]byte, error) {
+	return marshalUint32(ParamAdaptationLayerIndication, p.Indication)
+}
+
+// Unmarshal decodes the Adaptation Layer Indication parameter.
+func (p *AdaptationLayerIndication) Unmarshal(data []byte) (err error) {
+	p.Indication, err = unmarshalUint32(data, ParamAdaptationLayerIndication)
+	return err
+}
+
+// UnknownParam is a parameter of a type without a decoder, kept as is.
+type UnknownParam struct {
+	Kind  ParamType
+	Value []byte
+}
+
+// Type returns the parameter type.
+func (p *UnknownParam) Type() ParamType { return p.Kind }
+
+// Marshal encodes the parameter with its type and value.
+func (p *UnknownParam) Marshal() ([]byte, error) {
+	return marshalBytes(p.Kind, p.Value)
+}
+
+// Unmarshal decodes a parameter of any type.
+func (p *UnknownParam) Unmarshal(data []byte) error {
+	kind, value, err := paramValue(data, 0)
+	if err != nil {
+		return err
+	}
+	p.Kind = kind
+	p.Value = append([]byte(nil), value...)
+	return nil
+}
@@ -0,0 +1,149 @@
+package procfs
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// State is the state of an association, sctp_state_t of the kernel. It is one less than the
+// SCTP_ESTABLISHED family of states reported by SCTP_STATUS.
+type State int
+
+// Association states of the ST column.
+const (
+	StateClosed State = iota
+	StateCookieWait
+	StateCookieEchoed
+	StateEstablished
+	StateShutdownPending
+	StateShutdownSent
+	StateShutdownReceived
+	StateShutdownAckSent
+)
+
+var stateNames = []string{
+	StateClosed:           "CLOSED",
+	StateCookieWait:       "COOKIE-WAIT",
+	StateCookieEchoed:     "COOKIE-ECHOED",
+	StateEstablished:      "ESTABLISHED",
+	StateShutdownPending:  "SHUTDOWN-PENDING",
+	StateShutdownSent:     "SHUTDOWN-SENT",
+	StateShutdownReceived: "SHUTDOWN-RECEIVED",
+	StateShutdownAckSent:  "SHUTDOWN-ACK-SENT",
+}
+
+// String returns the name of the state of RFC 9260.
+func (s State) String() string {
+	if s >= 0 && int(s) < len(stateNames) {
+		return stateNames[s]
+	}
+	return "State(" + strconv.Itoa(int(s)) + ")"
+}
+
+// Association is a line of net/sctp/assocs, an association of an SCTP socket.
+type Association struct {
+	// Association and Socket are the kernel addresses of the association and the socket,
+	// zero when kptr_restrict hides them.
+	Association uint64
+	Socket      uint64
+	// Style is the style of the socket, StyleOneToOne or StyleOneToMany.
+	Style int
+	// SocketState is the state of the socket and State the state of the association.
+	SocketState int
+	State       State
+	Hash        int
+	AssocId     int
+	// TxQueue and RxQueue are the bytes queued for sending and receiving.
+	TxQueue    int
+	RxQueue    int
+	UID        uint32
+	Inode      uint64
+	LocalPort  int
+	RemotePort int
+	// LocalAddrs are the local addresses and LocalPrimary the source address of the primary
+	// path.
+	LocalAddrs   []net.IP
+	LocalPrimary net.IP
+	// RemoteAddrs are the peer addresses and Primary the address of the primary path.
+	RemoteAddrs []net.IP
+	Primary     net.IP
+	// HeartbeatInterval is the heartbeat interval in jiffies.
+	HeartbeatInterval uint64
+	InStreams         int
+	OutStreams        int
+	MaxRetrans        int
+	// InitRetries and ShutdownRetries are the T1 and T2 timer expirations and Retransmits the
+	// DATA chunks retransmitted.
+	InitRetries     int
+	ShutdownRetries int
+	Retransmits     int
+	// WmemAlloc and WmemQueued are the bytes of the send buffer allocated and queued, zero on
+	// kernels without the columns.
+	WmemAlloc     int
+	WmemQueued    int
+	SendBuffer    int
+	ReceiveBuffer int
+}
+
+// Associations parses net/sctp/assocs.
+func (fs FS) Associations() ([]Association, error) {
+	lines, err := fs.lines("assocs", true)
+	if err != nil {
+		return nil, err
+	}
+	associations := make([]Association, 0, len(lines))
+	for n, line := range lines {
+		association, err := parseAssociation(line)
+		if err != nil {
+			return nil, malformed("assocs", n+2, line, err)
+		}
+		associations = append(associations, association)
+	}
+	return associations, nil
+}
+
+// parseAssociation parses a line of net/sctp/assocs:
+//
+//	ASSOC SOCK STY SST ST HBKT ASSOC-ID TX_QUEUE RX_QUEUE UID INODE LPORT RPORT LADDRS <-> RADDRS
+//	HBINT INS OUTS MAXRT T1X T2X RTXC wmema wmemq sndbuf rcvbuf
+//
+// The columns after RTXC were added by later kernels and are optional.
+func parseAssociation(line string) (Association, error) {
+	c := &columns{tokens: strings.Fields(line)}
+	association := Association{
+		Association: c.uint(16),
+		Socket:      c.uint(16),
+		Style:       c.int(),
+		SocketState: c.int(),
+		State:       State(c.int()),
+		Hash:        c.int(),
+		AssocId:     c.int(),
+		TxQueue:     c.int(),
+		RxQueue:     c.int(),
+		UID:         uint32(c.uint(10)),
+		Inode:       c.uint(10),
+		LocalPort:   c.int(),
+		RemotePort:  c.int(),
+	}
+	association.LocalAddrs, association.LocalPrimary = c.addresses()
+	if separator := c.next(); c.err == nil && separator != "<->" {
+		c.err = errors.New("missing <-> between the addresses")
+	}
+	association.RemoteAddrs, association.Primary = c.addresses()
+	association.HeartbeatInterval = c.uint(10)
+	association.InStreams = c.int()
+	association.OutStreams = c.int()
+	association.MaxRetrans = c.int()
+	association.InitRetries = c.int()
+	association.ShutdownRetries = c.int()
+	association.Retransmits = c.int()
+	for _, value := range []*int{&association.WmemAlloc, &association.WmemQueued, &association.SendBuffer, &association.ReceiveBuffer} {
+		if len(c.tokens) > 0 {
+			*value = c.int()
+		}
+	}
+	c.end()
+	return association, c.err
+}
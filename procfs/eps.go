@@ -0,0 +1,69 @@
+package procfs
+
+import (
+	"net"
+	"strings"
+)
+
+// Socket styles of the STY column, sctp_socket_type_t of the kernel.
+const (
+	// StyleOneToMany is the style of a SOCK_SEQPACKET socket.
+	StyleOneToMany = 0
+	// StyleOneToOne is the style of a SOCK_STREAM socket.
+	StyleOneToOne = 2
+)
+
+// Endpoint is a line of net/sctp/eps, the endpoint of an SCTP socket.
+type Endpoint struct {
+	// Endpoint and Socket are the kernel addresses of the endpoint and the socket, zero
+	// when kptr_restrict hides them.
+	Endpoint uint64
+	Socket   uint64
+	// Style is the style of the socket, StyleOneToOne or StyleOneToMany.
+	Style int
+	// State is the state of the socket, TCP_LISTEN (10) for a listening socket.
+	State int
+	Hash  int
+	Port  int
+	UID   uint32
+	Inode uint64
+	// Addrs are the addresses the endpoint is bound to.
+	Addrs []net.IP
+}
+
+// Endpoints parses net/sctp/eps.
+func (fs FS) Endpoints() ([]Endpoint, error) {
+	lines, err := fs.lines("eps", true)
+	if err != nil {
+		return nil, err
+	}
+	endpoints := make([]Endpoint, 0, len(lines))
+	for n, line := range lines {
+		endpoint, err := parseEndpoint(line)
+		if err != nil {
+			return nil, malformed("eps", n+2, line, err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, nil
+}
+
+// parseEndpoint parses a line of net/sctp/eps:
+//
+//	ENDPT SOCK STY SST HBKT LPORT UID INODE LADDRS
+func parseEndpoint(line string) (Endpoint, error) {
+	c := &columns{tokens: strings.Fields(line)}
+	endpoint := Endpoint{
+		Endpoint: c.uint(16),
+		Socket:   c.uint(16),
+		Style:    c.int(),
+		State:    c.int(),
+		Hash:     c.int(),
+		Port:     c.int(),
+		UID:      uint32(c.uint(10)),
+		Inode:    c.uint(10),
+	}
+	endpoint.Addrs, _ = c.addresses()
+	c.end()
+	return endpoint, c.err
+}
@@ -0,0 +1,247 @@
+// Package procfs parses the SCTP state the Linux kernel exposes in /proc/net/sctp: the
+// endpoints, associations and peer addresses of every SCTP socket of the network namespace
+// and the SNMP counters of the stack. Entries are correlated with an SCTPConn or SCTPListener
+// through the inode of its socket.
+package procfs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	sctp "github.com/thebagchi/sctp-go"
+)
+
+// DefaultRoot is the mount point of procfs.
+const DefaultRoot = "/proc"
+
+var (
+	// ErrMalformed is returned when a line of a file does not have the columns expected.
+	ErrMalformed = errors.New("sctp procfs malformed line")
+	// ErrNotFound is returned when no entry matches the inode of a socket.
+	ErrNotFound = errors.New("sctp procfs entry not found")
+)
+
+// FS reads the files of net/sctp under a procfs root. The files describe the network
+// namespace of the reading process.
+type FS struct {
+	root string
+}
+
+// NewFS returns an FS reading the files under root, a directory holding net/sctp/eps,
+// net/sctp/assocs, net/sctp/remaddr and net/sctp/snmp such as DefaultRoot or fixtures.
+func NewFS(root string) FS {
+	if root == "" {
+		root = DefaultRoot
+	}
+	return FS{root: root}
+}
+
+// Root returns the root of the files.
+func (fs FS) Root() string {
+	return fs.root
+}
+
+// lines returns the lines of the file, without the header line when header is set.
+func (fs FS) lines(name string, header bool) ([]string, error) {
+	file, err := os.Open(filepath.Join(fs.root, "net", "sctp", name))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var (
+		lines   []string
+		scanner = bufio.NewScanner(file)
+	)
+	for scanner.Scan() {
+		if header {
+			header = false
+			continue
+		}
+		if line := scanner.Text(); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// malformed returns the error of the line n of the file.
+func malformed(name string, n int, line string, err error) error {
+	return fmt.Errorf("%w: %s line %d: %v: %q", ErrMalformed, name, n, err, line)
+}
+
+// columns decodes the columns of a line in order, keeping the first error.
+type columns struct {
+	tokens []string
+	err    error
+}
+
+// next returns the next column.
+func (c *columns) next() string {
+	if len(c.tokens) == 0 {
+		if c.err == nil {
+			c.err = errors.New("missing column")
+		}
+		return ""
+	}
+	token := c.tokens[0]
+	c.tokens = c.tokens[1:]
+	return token
+}
+
+// uint decodes the next column as an unsigned integer in base.
+func (c *columns) uint(base int) uint64 {
+	token := c.next()
+	if c.err != nil {
+		return 0
+	}
+	value, err := strconv.ParseUint(token, base, 64)
+	if err != nil {
+		c.err = err
+	}
+	return value
+}
+
+// int decodes the next column as a decimal integer.
+func (c *columns) int() int {
+	token := c.next()
+	if c.err != nil {
+		return 0
+	}
+	value, err := strconv.Atoi(token)
+	if err != nil {
+		c.err = err
+	}
+	return value
+}
+
+// end records an error when columns are left.
+func (c *columns) end() {
+	if c.err == nil && len(c.tokens) > 0 {
+		c.err = fmt.Errorf("unexpected columns %q", c.tokens)
+	}
+}
+
+// address reports whether the column is an address, an IPv4 or IPv6 address that the
+// kernel prefixes with '*' when it is the primary one.
+func address(token string) bool {
+	return strings.ContainsAny(token, ".:")
+}
+
+// addresses decodes the address columns up to the first column that is not an address and
+// returns them with the primary one, nil when none is marked.
+func (c *columns) addresses() ([]net.IP, net.IP) {
+	var addrs []net.IP
+	var primary net.IP
+	for c.err == nil && len(c.tokens) > 0 && address(strings.TrimPrefix(c.tokens[0], "*")) {
+		token := c.next()
+		ip := net.ParseIP(strings.TrimPrefix(token, "*"))
+		if ip == nil {
+			c.err = fmt.Errorf("invalid address %q", token)
+			break
+		}
+		if strings.HasPrefix(token, "*") {
+			primary = ip
+		}
+		addrs = append(addrs, ip)
+	}
+	return addrs, primary
+}
+
+// SocketInode returns the inode of the socket, the INODE column of the files.
+func SocketInode(fd int) (uint64, error) {
+	var stat syscall.Stat_t
+	if err := syscall.Fstat(fd, &stat); err != nil {
+		return 0, os.NewSyscallError("fstat", err)
+	}
+	return uint64(stat.Ino), nil
+}
+
+// EndpointOf returns the endpoint of the socket with the inode.
+func (fs FS) EndpointOf(inode uint64) (Endpoint, error) {
+	endpoints, err := fs.Endpoints()
+	if err != nil {
+		return Endpoint{}, err
+	}
+	for _, endpoint := range endpoints {
+		if endpoint.Inode == inode {
+			return endpoint, nil
+		}
+	}
+	return Endpoint{}, fmt.Errorf("%w: endpoint of inode %d", ErrNotFound, inode)
+}
+
+// AssociationsOf returns the associations of the socket with the inode, one for a
+// one-to-one socket and any number for a one-to-many socket.
+func (fs FS) AssociationsOf(inode uint64) ([]Association, error) {
+	associations, err := fs.Associations()
+	if err != nil {
+		return nil, err
+	}
+	var matched []Association
+	for _, association := range associations {
+		if association.Inode == inode {
+			matched = append(matched, association)
+		}
+	}
+	return matched, nil
+}
+
+// PathsOf returns the peer addresses of the association.
+func (fs FS) PathsOf(assoc int) ([]RemoteAddr, error) {
+	addrs, err := fs.RemoteAddrs()
+	if err != nil {
+		return nil, err
+	}
+	var matched []RemoteAddr
+	for _, addr := range addrs {
+		if addr.AssocId == assoc {
+			matched = append(matched, addr)
+		}
+	}
+	return matched, nil
+}
+
+// ConnAssociation returns the association of the connection.
+func (fs FS) ConnAssociation(conn *sctp.SCTPConn) (Association, error) {
+	inode, err := SocketInode(int(conn.FD()))
+	if err != nil {
+		return Association{}, err
+	}
+	associations, err := fs.AssociationsOf(inode)
+	if err != nil {
+		return Association{}, err
+	}
+	if len(associations) == 0 {
+		return Association{}, fmt.Errorf("%w: association of inode %d", ErrNotFound, inode)
+	}
+	return associations[0], nil
+}
+
+// ListenerEndpoint returns the endpoint of the listener.
+func (fs FS) ListenerEndpoint(listener *sctp.SCTPListener) (Endpoint, error) {
+	inode, err := SocketInode(listener.FD())
+	if err != nil {
+		return Endpoint{}, err
+	}
+	return fs.EndpointOf(inode)
+}
+
+// ListenerAssociations returns the associations of a one-to-many listener. The
+// associations accepted from a one-to-one listener have sockets of their own.
+func (fs FS) ListenerAssociations(listener *sctp.SCTPListener) ([]Association, error) {
+	inode, err := SocketInode(listener.FD())
+	if err != nil {
+		return nil, err
+	}
+	return fs.AssociationsOf(inode)
+}
@@ -0,0 +1,268 @@
+package procfs
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+
+	sctp "github.com/thebagchi/sctp-go"
+)
+
+// fixtures is the root of the files captured from a host with a one-to-one listener, its
+// accepted association and a one-to-many socket with two associations.
+var fixtures = NewFS("testdata")
+
+// writeRoot writes the files under a temporary root.
+func writeRoot(t *testing.T, files map[string]string) FS {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, "net", "sctp")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewFS(root)
+}
+
+// ips parses the addresses.
+func ips(addrs ...string) []net.IP {
+	parsed := make([]net.IP, len(addrs))
+	for i, addr := range addrs {
+		parsed[i] = net.ParseIP(addr)
+	}
+	return parsed
+}
+
+// equalIPs reports whether the addresses are equal.
+func equalIPs(a, b []net.IP) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEndpoints(t *testing.T) {
+	endpoints, err := fixtures.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) != 3 {
+		t.Fatalf("%d endpoints", len(endpoints))
+	}
+	listener := endpoints[0]
+	if listener.Endpoint != 0xffff8881036c8000 || listener.Socket != 0xffff888103e3c000 ||
+		listener.Style != StyleOneToOne || listener.State != 10 || listener.Hash != 29 ||
+		listener.Port != 36412 || listener.UID != 0 || listener.Inode != 31337 ||
+		!equalIPs(listener.Addrs, ips("10.0.1.2", "10.0.2.2")) {
+		t.Errorf("endpoint %+v", listener)
+	}
+	if many := endpoints[1]; many.Endpoint != 0 || many.Style != StyleOneToMany || many.UID != 1000 ||
+		!equalIPs(many.Addrs, ips("::1", "127.0.0.1")) {
+		t.Errorf("endpoint %+v", many)
+	}
+	if endpoint, err := fixtures.EndpointOf(31339); err != nil || endpoint.State != 1 {
+		t.Errorf("endpoint %+v: %v", endpoint, err)
+	}
+	if _, err := fixtures.EndpointOf(1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("endpoint of an unknown inode: %v", err)
+	}
+}
+
+func TestAssociations(t *testing.T) {
+	associations, err := fixtures.Associations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(associations) != 3 {
+		t.Fatalf("%d associations", len(associations))
+	}
+	accepted := associations[0]
+	expected := Association{
+		Association: 0xffff888104b9a000, Socket: 0xffff888103e3c800, Style: StyleOneToOne,
+		SocketState: 1, State: StateEstablished, AssocId: 3, Inode: 31339, LocalPort: 36412, RemotePort: 45678,
+		HeartbeatInterval: 7500, InStreams: 10, OutStreams: 10, MaxRetrans: 10,
+		WmemAlloc: 1, SendBuffer: 212992, ReceiveBuffer: 212992,
+	}
+	addrs := [][]net.IP{accepted.LocalAddrs, {accepted.LocalPrimary}, accepted.RemoteAddrs, {accepted.Primary}}
+	accepted.LocalAddrs, accepted.LocalPrimary, accepted.RemoteAddrs, accepted.Primary = nil, nil, nil, nil
+	if !equalIPs(addrs[0], ips("10.0.1.2", "10.0.2.2")) || !equalIPs(addrs[1], ips("10.0.1.2")) ||
+		!equalIPs(addrs[2], ips("10.0.1.1", "10.0.2.1")) || !equalIPs(addrs[3], ips("10.0.1.1")) {
+		t.Errorf("addresses %v", addrs)
+	}
+	if accepted.State.String() != "ESTABLISHED" || !equalAssociation(accepted, expected) {
+		t.Errorf("association %+v", accepted)
+	}
+
+	many, err := fixtures.AssociationsOf(31338)
+	if err != nil || len(many) != 2 {
+		t.Fatalf("associations %+v: %v", many, err)
+	}
+	if many[0].TxQueue != 64 || many[0].InitRetries != 1 || many[0].Retransmits != 2 || many[0].WmemQueued != 768 ||
+		!many[0].Primary.Equal(net.IPv6loopback) || len(many[0].RemoteAddrs) != 1 {
+		t.Errorf("association %+v", many[0])
+	}
+	// The line of an older kernel ends with RTXC.
+	if old := many[1]; old.State != StateShutdownSent || old.State.String() != "SHUTDOWN-SENT" ||
+		old.RxQueue != 12 || old.ShutdownRetries != 1 || old.SendBuffer != 0 {
+		t.Errorf("association %+v", old)
+	}
+	if name := State(9).String(); name != "State(9)" {
+		t.Errorf("state %s", name)
+	}
+}
+
+// equalAssociation compares the associations without their addresses.
+func equalAssociation(a, b Association) bool {
+	a.LocalAddrs, a.LocalPrimary, a.RemoteAddrs, a.Primary = nil, nil, nil, nil
+	b.LocalAddrs, b.LocalPrimary, b.RemoteAddrs, b.Primary = nil, nil, nil, nil
+	return a.Association == b.Association && a.Socket == b.Socket && a.Style == b.Style &&
+		a.SocketState == b.SocketState && a.State == b.State && a.Hash == b.Hash && a.AssocId == b.AssocId &&
+		a.TxQueue == b.TxQueue && a.RxQueue == b.RxQueue && a.UID == b.UID && a.Inode == b.Inode &&
+		a.LocalPort == b.LocalPort && a.RemotePort == b.RemotePort && a.HeartbeatInterval == b.HeartbeatInterval &&
+		a.InStreams == b.InStreams && a.OutStreams == b.OutStreams && a.MaxRetrans == b.MaxRetrans &&
+		a.InitRetries == b.InitRetries && a.ShutdownRetries == b.ShutdownRetries && a.Retransmits == b.Retransmits &&
+		a.WmemAlloc == b.WmemAlloc && a.WmemQueued == b.WmemQueued && a.SendBuffer == b.SendBuffer &&
+		a.ReceiveBuffer == b.ReceiveBuffer
+}
+
+func TestRemoteAddrs(t *testing.T) {
+	addrs, err := fixtures.RemoteAddrs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 4 {
+		t.Fatalf("%d addresses", len(addrs))
+	}
+	if addr := addrs[1]; !addr.IP.Equal(net.IPv4(10, 0, 2, 1)) || addr.AssocId != 3 || !addr.HeartbeatActive ||
+		addr.RTO != 3000 || addr.MaxPathRetrans != 5 || addr.State != sctp.SCTP_INACTIVE {
+		t.Errorf("address %+v", addr)
+	}
+	if addr := addrs[3]; addr.HeartbeatActive || addr.State != sctp.SCTP_UNCONFIRMED {
+		t.Errorf("address %+v", addr)
+	}
+	paths, err := fixtures.PathsOf(3)
+	if err != nil || len(paths) != 2 || paths[0].State != sctp.SCTP_ACTIVE {
+		t.Errorf("paths %+v: %v", paths, err)
+	}
+}
+
+func TestSNMP(t *testing.T) {
+	snmp, err := fixtures.SNMP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snmp.CurrEstab != 3 || snmp.PassiveEstabs != 2 || snmp.OutOrderChunks != 3400 || snmp.T3RtxExpireds != 5 ||
+		snmp.InPktSoftirq != 3490 || snmp.FastRetransmits != 1 || len(snmp.Counters) != 32 ||
+		snmp.Counters["SctpDelaySackExpireds"] != 40 {
+		t.Errorf("counters %+v", snmp)
+	}
+
+	// Counters unknown to the struct are kept by name.
+	snmp, err = writeRoot(t, map[string]string{"snmp": "SctpCurrEstab 1\nSctpNewCounter\t7\n"}).SNMP()
+	if err != nil || snmp.CurrEstab != 1 || snmp.Counters["SctpNewCounter"] != 7 {
+		t.Errorf("counters %+v: %v", snmp, err)
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	header := map[string]string{
+		"eps":     " ENDPT     SOCK   STY SST HBKT LPORT   UID INODE LADDRS\n",
+		"assocs":  " ASSOC     SOCK   STY SST ST HBKT ASSOC-ID TX_QUEUE RX_QUEUE UID INODE LPORT RPORT LADDRS <-> RADDRS\n",
+		"remaddr": "ADDR ASSOC_ID HB_ACT RTO MAX_PATH_RTX REM_ADDR_RTX START STATE\n",
+		"snmp":    "",
+	}
+	parse := map[string]func(FS) error{
+		"eps":     func(fs FS) error { _, err := fs.Endpoints(); return err },
+		"assocs":  func(fs FS) error { _, err := fs.Associations(); return err },
+		"remaddr": func(fs FS) error { _, err := fs.RemoteAddrs(); return err },
+		"snmp":    func(fs FS) error { _, err := fs.SNMP(); return err },
+	}
+	tests := []struct {
+		file, line string
+	}{
+		{"eps", "0 0 1 10 29 36412 0"},
+		{"eps", "0 0 1 10 29 36412 0 inode 10.0.0.1"},
+		{"eps", "0 0 1 10 29 36412 0 1 10.0.0.1 extra"},
+		{"eps", "0 0 1 10 29 36412 0 1 10.0.0.999"},
+		{"assocs", "0 0 1 1 3 0 3 0 0 0 1 1 2 10.0.0.1 10.0.0.2 7500 10 10 10 0 0 0"},
+		{"assocs", "0 0 1 1 3 0 3 0 0 0 1 1 2 10.0.0.1 <-> 10.0.0.2 7500 10 10"},
+		{"remaddr", "3 1 1000 5 0 0 2"},
+		{"remaddr", "10.0.0.1 3 1 1000 5 0 0"},
+		{"snmp", "SctpCurrEstab"},
+		{"snmp", "SctpCurrEstab -1"},
+	}
+	for _, test := range tests {
+		fs := writeRoot(t, map[string]string{test.file: header[test.file] + test.line + "\n"})
+		if err := parse[test.file](fs); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%s %q: %v", test.file, test.line, err)
+		}
+	}
+	if _, err := writeRoot(t, nil).Endpoints(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file: %v", err)
+	}
+}
+
+func TestConnAssociation(t *testing.T) {
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_SEQPACKET, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Close(fds[1])
+	conn := sctp.NewSCTPConn(fds[0])
+	defer conn.Close()
+	inode, err := SocketInode(fds[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := writeRoot(t, map[string]string{
+		"assocs": " ASSOC     SOCK   STY SST ST HBKT ASSOC-ID TX_QUEUE RX_QUEUE UID INODE LPORT RPORT LADDRS <-> RADDRS\n" +
+			"0 0 2 1 3 0 3 0 0 0 1 36412 5000 *10.0.0.1 <-> *10.0.0.2 \t 7500 10 10 10 0 0 0\n" +
+			"0 0 2 1 3 0 4 0 0 0 " + strconv.FormatUint(inode, 10) + " 36412 5001 *10.0.0.1 <-> *10.0.0.3 \t 7500 10 10 10 0 0 0\n",
+	})
+	association, err := fs.ConnAssociation(conn)
+	if err != nil || association.AssocId != 4 || association.RemotePort != 5001 {
+		t.Errorf("association %+v: %v", association, err)
+	}
+	if _, err := fixtures.ConnAssociation(conn); !errors.Is(err, ErrNotFound) {
+		t.Errorf("association of an unknown socket: %v", err)
+	}
+	_ = conn.Close()
+	if _, err := fs.ConnAssociation(conn); err == nil {
+		t.Error("association of a closed connection")
+	}
+}
+
+func TestHost(t *testing.T) {
+	fs := NewFS("")
+	if fs.Root() != DefaultRoot {
+		t.Errorf("root %s", fs.Root())
+	}
+	if _, err := os.Stat(filepath.Join(DefaultRoot, "net", "sctp")); err != nil {
+		t.Skip("sctp module not loaded")
+	}
+	if _, err := fs.Endpoints(); err != nil {
+		t.Error(err)
+	}
+	if _, err := fs.Associations(); err != nil {
+		t.Error(err)
+	}
+	if _, err := fs.RemoteAddrs(); err != nil {
+		t.Error(err)
+	}
+	if _, err := fs.SNMP(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,63 @@
+package procfs
+
+import (
+	"errors"
+	"net"
+	"strings"
+)
+
+// RemoteAddr is a line of net/sctp/remaddr, a peer address of an association.
+type RemoteAddr struct {
+	IP      net.IP
+	AssocId int
+	// HeartbeatActive reports whether the heartbeat timer of the path is running.
+	HeartbeatActive bool
+	// RTO is the retransmission timeout of the path in jiffies.
+	RTO            uint64
+	MaxPathRetrans int
+	// Retransmits and Start are reported as zero by the kernel.
+	Retransmits int
+	Start       int
+	// State is the state of the path, SCTP_INACTIVE, SCTP_PF, SCTP_ACTIVE or
+	// SCTP_UNCONFIRMED.
+	State int
+}
+
+// RemoteAddrs parses net/sctp/remaddr.
+func (fs FS) RemoteAddrs() ([]RemoteAddr, error) {
+	lines, err := fs.lines("remaddr", true)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]RemoteAddr, 0, len(lines))
+	for n, line := range lines {
+		addr, err := parseRemoteAddr(line)
+		if err != nil {
+			return nil, malformed("remaddr", n+2, line, err)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+// parseRemoteAddr parses a line of net/sctp/remaddr:
+//
+//	ADDR ASSOC_ID HB_ACT RTO MAX_PATH_RTX REM_ADDR_RTX START STATE
+func parseRemoteAddr(line string) (RemoteAddr, error) {
+	c := &columns{tokens: strings.Fields(line)}
+	var addr RemoteAddr
+	if ips, _ := c.addresses(); len(ips) == 1 {
+		addr.IP = ips[0]
+	} else if c.err == nil {
+		c.err = errors.New("expected one address")
+	}
+	addr.AssocId = c.int()
+	addr.HeartbeatActive = c.int() != 0
+	addr.RTO = c.uint(10)
+	addr.MaxPathRetrans = c.int()
+	addr.Retransmits = c.int()
+	addr.Start = c.int()
+	addr.State = c.int()
+	c.end()
+	return addr, c.err
+}
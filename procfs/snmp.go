@@ -0,0 +1,110 @@
+package procfs
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// SNMP holds the counters of net/sctp/snmp, the SCTP MIB of RFC 3873 and the counters the
+// kernel adds to it.
+type SNMP struct {
+	CurrEstab               uint64
+	ActiveEstabs            uint64
+	PassiveEstabs           uint64
+	Aborteds                uint64
+	Shutdowns               uint64
+	OutOfBlues              uint64
+	ChecksumErrors          uint64
+	OutCtrlChunks           uint64
+	OutOrderChunks          uint64
+	OutUnorderChunks        uint64
+	InCtrlChunks            uint64
+	InOrderChunks           uint64
+	InUnorderChunks         uint64
+	FragUsrMsgs             uint64
+	ReasmUsrMsgs            uint64
+	OutSCTPPacks            uint64
+	InSCTPPacks             uint64
+	T1InitExpireds          uint64
+	T1CookieExpireds        uint64
+	T2ShutdownExpireds      uint64
+	T3RtxExpireds           uint64
+	T4RtoExpireds           uint64
+	T5ShutdownGuardExpireds uint64
+	DelaySackExpireds       uint64
+	AutocloseExpireds       uint64
+	T3Retransmits           uint64
+	PmtudRetransmits        uint64
+	FastRetransmits         uint64
+	InPktSoftirq            uint64
+	InPktBacklog            uint64
+	InPktDiscards           uint64
+	InDataChunkDiscards     uint64
+	// Counters holds every counter by its name in the file, including those of kernels newer
+	// than the fields above.
+	Counters map[string]uint64
+}
+
+// counter returns the field of the counter named in the file, nil for an unknown counter.
+func (s *SNMP) counter(name string) *uint64 {
+	fields := map[string]*uint64{
+		"SctpCurrEstab":               &s.CurrEstab,
+		"SctpActiveEstabs":            &s.ActiveEstabs,
+		"SctpPassiveEstabs":           &s.PassiveEstabs,
+		"SctpAborteds":                &s.Aborteds,
+		"SctpShutdowns":               &s.Shutdowns,
+		"SctpOutOfBlues":              &s.OutOfBlues,
+		"SctpChecksumErrors":          &s.ChecksumErrors,
+		"SctpOutCtrlChunks":           &s.OutCtrlChunks,
+		"SctpOutOrderChunks":          &s.OutOrderChunks,
+		"SctpOutUnorderChunks":        &s.OutUnorderChunks,
+		"SctpInCtrlChunks":            &s.InCtrlChunks,
+		"SctpInOrderChunks":           &s.InOrderChunks,
+		"SctpInUnorderChunks":         &s.InUnorderChunks,
+		"SctpFragUsrMsgs":             &s.FragUsrMsgs,
+		"SctpReasmUsrMsgs":            &s.ReasmUsrMsgs,
+		"SctpOutSCTPPacks":            &s.OutSCTPPacks,
+		"SctpInSCTPPacks":             &s.InSCTPPacks,
+		"SctpT1InitExpireds":          &s.T1InitExpireds,
+		"SctpT1CookieExpireds":        &s.T1CookieExpireds,
+		"SctpT2ShutdownExpireds":      &s.T2ShutdownExpireds,
+		"SctpT3RtxExpireds":           &s.T3RtxExpireds,
+		"SctpT4RtoExpireds":           &s.T4RtoExpireds,
+		"SctpT5ShutdownGuardExpireds": &s.T5ShutdownGuardExpireds,
+		"SctpDelaySackExpireds":       &s.DelaySackExpireds,
+		"SctpAutocloseExpireds":       &s.AutocloseExpireds,
+		"SctpT3Retransmits":           &s.T3Retransmits,
+		"SctpPmtudRetransmits":        &s.PmtudRetransmits,
+		"SctpFastRetransmits":         &s.FastRetransmits,
+		"SctpInPktSoftirq":            &s.InPktSoftirq,
+		"SctpInPktBacklog":            &s.InPktBacklog,
+		"SctpInPktDiscards":           &s.InPktDiscards,
+		"SctpInDataChunkDiscards":     &s.InDataChunkDiscards,
+	}
+	return fields[name]
+}
+
+// SNMP parses net/sctp/snmp, a counter name and value per line.
+func (fs FS) SNMP() (*SNMP, error) {
+	lines, err := fs.lines("snmp", false)
+	if err != nil {
+		return nil, err
+	}
+	snmp := &SNMP{Counters: make(map[string]uint64, len(lines))}
+	for n, line := range lines {
+		columns := strings.Fields(line)
+		if len(columns) != 2 {
+			return nil, malformed("snmp", n+1, line, errors.New("expected a name and a value"))
+		}
+		value, err := strconv.ParseUint(columns[1], 10, 64)
+		if err != nil {
+			return nil, malformed("snmp", n+1, line, err)
+		}
+		snmp.Counters[columns[0]] = value
+		if counter := snmp.counter(columns[0]); counter != nil {
+			*counter = value
+		}
+	}
+	return snmp, nil
+}
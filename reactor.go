@@ -0,0 +1,284 @@
+package sctp_go
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	DefaultReactorQueueSize = 1024
+)
+
+// ReactorConfig holds the parameters of a Reactor.
+type ReactorConfig struct {
+	// Loops is the number of epoll loops, defaults to runtime.NumCPU().
+	Loops int
+	// Workers is the number of goroutines running callbacks, 0 runs them inline on the loops.
+	Workers int
+	// QueueSize is the capacity of the queue feeding the workers, defaults to DefaultReactorQueueSize.
+	QueueSize int
+}
+
+// ReactorMetrics holds a snapshot of the counters of a Reactor.
+type ReactorMetrics struct {
+	QueueDepth      int
+	QueueCapacity   int
+	Callbacks       uint64
+	CallbackTime    time.Duration
+	MaxCallbackTime time.Duration
+	QueueTime       time.Duration
+	// Overflows counts the events run on their loop because the queue was full.
+	Overflows uint64
+}
+
+// AverageCallbackTime returns the mean time spent in a callback.
+func (m ReactorMetrics) AverageCallbackTime() time.Duration {
+	if m.Callbacks == 0 {
+		return 0
+	}
+	return m.CallbackTime / time.Duration(m.Callbacks)
+}
+
+// Reactor runs several pollers, sharding file descriptors across them by hash, and
+// optionally hands the callbacks over to a bounded pool of workers so a slow handler
+// does not stall the other descriptors of its loop.
+type Reactor struct {
+	pollers   []*Poller
+	workers   int
+	queue     chan reactorTask
+	entries   sync.Map
+	callbacks atomic.Uint64
+	elapsed   atomic.Int64
+	maximum   atomic.Int64
+	queued    atomic.Int64
+	overflows atomic.Uint64
+}
+
+// reactorEntry is the registration of a file descriptor dispatched to the workers.
+type reactorEntry struct {
+	registration
+	fd     int
+	poller *Poller
+	events atomic.Uint32
+}
+
+// reactorTask is an event waiting in the queue for a worker.
+type reactorTask struct {
+	entry *reactorEntry
+	ev    Events
+	since time.Time
+}
+
+// NewReactor creates a reactor with its pollers initialized.
+func NewReactor(config ReactorConfig) (*Reactor, error) {
+	if config.Loops <= 0 {
+		config.Loops = runtime.NumCPU()
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultReactorQueueSize
+	}
+	r := &Reactor{
+		pollers: make([]*Poller, 0, config.Loops),
+		workers: config.Workers,
+	}
+	if r.workers > 0 {
+		r.queue = make(chan reactorTask, config.QueueSize)
+	}
+	for i := 0; i < config.Loops; i++ {
+		p, err := NewPoller()
+		if err != nil {
+			_ = r.Close()
+			return nil, err
+		}
+		r.pollers = append(r.pollers, p)
+	}
+	return r, nil
+}
+
+// poller returns the poller the file descriptor is sharded to.
+func (r *Reactor) poller(fd int) *Poller {
+	return r.pollers[(uint32(fd)*2654435761)%uint32(len(r.pollers))]
+}
+
+// Add registers a file descriptor for the given events on the loop it is sharded to.
+// With workers the descriptor is armed one-shot and re-armed once its callback returns,
+// so a level-triggered descriptor is never queued twice. When the queue is full the
+// callback runs on the loop instead, the loop never blocks on the workers.
+func (r *Reactor) Add(fd int, events Events, cb Callback) error {
+	if cb == nil {
+		return errors.New("callback cannot be nil")
+	}
+	p := r.poller(fd)
+	if r.workers <= 0 {
+		return p.Add(fd, events, func(ev Events) {
+			r.measure(cb, ev)
+		})
+	}
+	entry := &reactorEntry{
+		fd:     fd,
+		poller: p,
+	}
+	entry.callback = func(ev Events) {
+		r.measure(cb, ev)
+	}
+	entry.events.Store(uint32(events))
+	if _, exists := r.entries.LoadOrStore(fd, entry); exists {
+		return errors.New("file descriptor already registered")
+	}
+	err := p.Add(fd, events|EventOneShot, func(ev Events) {
+		task := reactorTask{entry: entry, ev: ev, since: time.Now()}
+		select {
+		case r.queue <- task:
+		default:
+			r.overflows.Add(1)
+			r.execute(task, int32(unix.Gettid()))
+		}
+	})
+	if err != nil {
+		r.entries.Delete(fd)
+	}
+	return err
+}
+
+// Modify changes the events a registered file descriptor is polled for.
+func (r *Reactor) Modify(fd int, events Events) error {
+	if r.workers <= 0 {
+		return r.poller(fd).Modify(fd, events)
+	}
+	value, exists := r.entries.Load(fd)
+	if !exists {
+		return errors.New("file descriptor not registered")
+	}
+	entry := value.(*reactorEntry)
+	entry.events.Store(uint32(events))
+	return entry.poller.Modify(fd, events|EventOneShot)
+}
+
+// Del removes a file descriptor from its loop. Once Del returns the callback is not invoked
+// anymore, a callback running concurrently is waited for unless Del is called from it.
+func (r *Reactor) Del(fd int) error {
+	if r.workers <= 0 {
+		return r.poller(fd).Del(fd)
+	}
+	value, exists := r.entries.LoadAndDelete(fd)
+	if !exists {
+		return errors.New("file descriptor not registered")
+	}
+	entry := value.(*reactorEntry)
+	err := entry.poller.Del(fd)
+	entry.remove()
+	return err
+}
+
+// Run runs the loops and the workers until ctx is cancelled or the reactor is closed.
+// It returns nil when the reactor is closed, ctx.Err() when ctx is cancelled, or the first
+// error reported by a loop. A loop stopping stops the whole reactor.
+func (r *Reactor) Run(parent context.Context) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	var (
+		loops   sync.WaitGroup
+		workers sync.WaitGroup
+		once    sync.Once
+		result  error
+	)
+	for i := 0; i < r.workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			r.work(ctx)
+		}()
+	}
+	for _, p := range r.pollers {
+		loops.Add(1)
+		go func(p *Poller) {
+			defer loops.Done()
+			if err := p.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				once.Do(func() {
+					result = err
+				})
+			}
+			cancel()
+		}(p)
+	}
+	loops.Wait()
+	workers.Wait()
+	if result == nil {
+		result = parent.Err()
+	}
+	return result
+}
+
+// work runs queued callbacks until ctx is done, locked to its OS thread like the loops.
+func (r *Reactor) work(ctx context.Context) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	thread := int32(unix.Gettid())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task := <-r.queue:
+			r.execute(task, thread)
+		}
+	}
+}
+
+// execute runs the callback of the task on the locked OS thread identified by thread and
+// re-arms its descriptor.
+func (r *Reactor) execute(task reactorTask, thread int32) {
+	r.queued.Add(int64(time.Since(task.since)))
+	entry := task.entry
+	entry.dispatch(task.ev, thread)
+	if !entry.removed.Load() {
+		events := Events(entry.events.Load())
+		if events&EventOneShot == 0 {
+			_ = entry.poller.Modify(entry.fd, events|EventOneShot)
+		}
+	}
+}
+
+// measure invokes the callback and records its latency.
+func (r *Reactor) measure(cb Callback, ev Events) {
+	start := time.Now()
+	cb(ev)
+	elapsed := int64(time.Since(start))
+	r.callbacks.Add(1)
+	r.elapsed.Add(elapsed)
+	for {
+		maximum := r.maximum.Load()
+		if elapsed <= maximum || r.maximum.CompareAndSwap(maximum, elapsed) {
+			break
+		}
+	}
+}
+
+// Metrics returns a snapshot of the reactor counters.
+func (r *Reactor) Metrics() ReactorMetrics {
+	return ReactorMetrics{
+		QueueDepth:      len(r.queue),
+		QueueCapacity:   cap(r.queue),
+		Callbacks:       r.callbacks.Load(),
+		CallbackTime:    time.Duration(r.elapsed.Load()),
+		MaxCallbackTime: time.Duration(r.maximum.Load()),
+		QueueTime:       time.Duration(r.queued.Load()),
+		Overflows:       r.overflows.Load(),
+	}
+}
+
+// Close closes every loop of the reactor, a running Run returns once they stopped.
+func (r *Reactor) Close() error {
+	var result error
+	for _, p := range r.pollers {
+		if err := p.Close(); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
@@ -0,0 +1,221 @@
+package sctp_go
+
+import (
+	"context"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReactor(t *testing.T) {
+	for _, workers := range []int{0, 2} {
+		r, err := NewReactor(ReactorConfig{Loops: 2, Workers: workers, QueueSize: 4})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- r.Run(ctx)
+		}()
+
+		fired := make(chan int, 16)
+		var pairs [][2]int
+		for i := 0; i < 4; i++ {
+			fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_SEQPACKET, 0)
+			if err != nil {
+				t.Skip("socketpair not available: ", err)
+			}
+			defer syscall.Close(fds[0])
+			defer syscall.Close(fds[1])
+			pairs = append(pairs, [2]int{fds[0], fds[1]})
+			fd := fds[0]
+			if err := r.Add(fd, EventIn, func(ev Events) {
+				var buffer [16]byte
+				_, _ = syscall.Read(fd, buffer[:])
+				fired <- fd
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for round := 0; round < 2; round++ {
+			for _, fds := range pairs {
+				if _, err := syscall.Write(fds[1], []byte("ping")); err != nil {
+					t.Fatal(err)
+				}
+				select {
+				case fd := <-fired:
+					if fd != fds[0] {
+						t.Errorf("callback fired for %d, expected %d", fd, fds[0])
+					}
+				case <-time.After(time.Second):
+					t.Fatal("timed out waiting for callback")
+				}
+			}
+		}
+		for _, fds := range pairs {
+			if err := r.Del(fds[0]); err != nil {
+				t.Error(err)
+			}
+		}
+		if err := r.Del(pairs[0][0]); err == nil {
+			t.Error("expected error deleting unregistered descriptor")
+		}
+		metrics := r.Metrics()
+		if metrics.Callbacks != 8 {
+			t.Errorf("expected 8 callbacks, got %d", metrics.Callbacks)
+		}
+		if workers > 0 && metrics.QueueCapacity != 4 {
+			t.Errorf("expected queue capacity 4, got %d", metrics.QueueCapacity)
+		}
+
+		cancel()
+		select {
+		case err := <-done:
+			if err != context.Canceled {
+				t.Errorf("expected context.Canceled, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("reactor did not stop on cancel")
+		}
+		if err := r.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestReactorQueueFull(t *testing.T) {
+	r, err := NewReactor(ReactorConfig{Loops: 1, Workers: 1, QueueSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx)
+
+	var (
+		release = make(chan struct{})
+		fired   = make(chan int, 3)
+		pairs   [3][2]int
+	)
+	defer close(release)
+	for i := range pairs {
+		fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_SEQPACKET, 0)
+		if err != nil {
+			t.Skip("socketpair not available: ", err)
+		}
+		defer syscall.Close(fds[0])
+		defer syscall.Close(fds[1])
+		pairs[i] = [2]int{fds[0], fds[1]}
+		i, fd := i, fds[0]
+		if err := r.Add(fd, EventIn, func(ev Events) {
+			var buffer [16]byte
+			_, _ = syscall.Read(fd, buffer[:])
+			fired <- i
+			if i == 0 {
+				<-release
+			}
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wait := func(expected int) {
+		t.Helper()
+		select {
+		case i := <-fired:
+			if i != expected {
+				t.Fatalf("callback %d fired, expected %d", i, expected)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("callback %d stalled", expected)
+		}
+	}
+	write := func(i int) {
+		t.Helper()
+		if _, err := syscall.Write(pairs[i][1], []byte("ping")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first callback holds the only worker, the second event fills the queue.
+	write(0)
+	wait(0)
+	write(1)
+	for deadline := time.Now().Add(time.Second); r.Metrics().QueueDepth != 1; {
+		if time.Now().After(deadline) {
+			t.Fatal("event not queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// The third event runs on the loop instead of blocking it.
+	write(2)
+	wait(2)
+	if metrics := r.Metrics(); metrics.Overflows != 1 {
+		t.Errorf("expected 1 overflow, got %d", metrics.Overflows)
+	}
+	release <- struct{}{}
+	wait(1)
+}
+
+func TestReactorDel(t *testing.T) {
+	r, err := NewReactor(ReactorConfig{Loops: 1, Workers: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx)
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_SEQPACKET, 0)
+	if err != nil {
+		t.Skip("socketpair not available: ", err)
+	}
+	defer syscall.Close(fds[0])
+	defer syscall.Close(fds[1])
+
+	var (
+		entered  = make(chan struct{})
+		release  = make(chan struct{})
+		finished atomic.Bool
+	)
+	if err := r.Add(fds[0], EventOut, func(ev Events) {
+		close(entered)
+		<-release
+		finished.Store(true)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-entered
+	removed := make(chan error, 1)
+	go func() {
+		removed <- r.Del(fds[0])
+	}()
+	select {
+	case <-removed:
+		t.Fatal("Del returned while the callback was running on a worker")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	if err := <-removed; err != nil {
+		t.Fatal(err)
+	}
+	if !finished.Load() {
+		t.Error("Del returned before the running callback")
+	}
+
+	deleted := make(chan error, 1)
+	if err := r.Add(fds[1], EventOut, func(ev Events) {
+		deleted <- r.Del(fds[1])
+	}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-deleted:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Del from a worker callback did not return")
+	}
+}
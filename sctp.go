@@ -8,34 +8,16 @@ import (
 	"strings"
 	"syscall"
 	"unsafe"
-)
 
-var (
-	endian binary.ByteOrder = binary.LittleEndian
+	"golang.org/x/sys/unix"
 )
 
-func init() {
-	endian = Endianness()
-}
-
-func htons(port uint16) uint16 {
-	if endian == binary.LittleEndian {
-		return (port << 8 & 0xff00) | (port >> 8 & 0xff)
-	}
-	return port
-}
-
-func ntohs(port uint16) uint16 {
-	if endian == binary.LittleEndian {
-		return (port << 8 & 0xff00) | (port >> 8 & 0xff)
-	}
-	return port
-}
-
-// Endianness returns the byte order (big-endian or little-endian) of the system.
+// Endianness returns the byte order of the host, binary.LittleEndian or binary.BigEndian.
+//
+// Deprecated: Use binary.NativeEndian, ports and addresses are in network byte order and
+// are encoded with binary.BigEndian.
 func Endianness() binary.ByteOrder {
-	i := uint16(1)
-	if *(*byte)(unsafe.Pointer(&i)) == 0 {
+	if binary.NativeEndian.Uint16([]byte{0, 1}) == 1 {
 		return binary.BigEndian
 	}
 	return binary.LittleEndian
@@ -49,7 +31,8 @@ func DetectAddrFamily(network string) int {
 	return syscall.AF_INET6
 }
 
-// Pack serializes the given value into a byte slice using the system's endianness.
+// Pack serializes the given value into a byte slice in host byte order, as the kernel
+// expects the fields of its structs.
 func Pack(v interface{}) []byte {
 	var buf bytes.Buffer
 	if err := binary.Write(&buf, endian, v); err != nil {
@@ -58,6 +41,18 @@ func Pack(v interface{}) []byte {
 	return buf.Bytes()
 }
 
+// AppendCmsg appends a control message with the given level, type and data to buffer.
+func AppendCmsg(buffer []byte, level, kind int32, data []byte) []byte {
+	hdr := syscall.Cmsghdr{
+		Level: level,
+		Type:  kind,
+	}
+	hdr.SetLen(syscall.CmsgLen(len(data)))
+	buffer = append(buffer, Pack(&hdr)...)
+	buffer = append(buffer, data...)
+	return append(buffer, make([]byte, syscall.CmsgSpace(len(data))-syscall.CmsgLen(len(data)))...)
+}
+
 // SCTPSocket creates a new SCTP socket with the specified address family and type.
 func SCTPSocket(family, flag int) (int, error) {
 	if family == syscall.AF_INET {
@@ -68,7 +63,7 @@ func SCTPSocket(family, flag int) (int, error) {
 
 // SCTPBind binds the SCTP socket to the specified address with the given flags.
 func SCTPBind(sock int, addr *SCTPAddr, flags int) error {
-	var option uintptr
+	var option int
 	switch flags {
 	case SCTP_BINDX_ADD_ADDR:
 		option = SCTP_SOCKOPT_BINDX_ADD
@@ -82,19 +77,7 @@ func SCTPBind(sock int, addr *SCTPAddr, flags int) error {
 	if len(buffer) == 0 {
 		return syscall.EINVAL
 	}
-	_, _, errno := syscall.Syscall6(
-		syscall.SYS_SETSOCKOPT,
-		uintptr(sock),
-		SOL_SCTP,
-		option,
-		uintptr(unsafe.Pointer(&buffer[0])),
-		uintptr(len(buffer)),
-		0,
-	)
-	if errno != 0 {
-		return errno
-	}
-	return nil
+	return setsockopt(sock, SOL_SCTP, option, unsafe.Pointer(&buffer[0]), uintptr(len(buffer)))
 }
 
 // SCTPConnect connects the SCTP socket to the specified address and returns the association ID.
@@ -108,36 +91,16 @@ func SCTPConnect(sock int, addr *SCTPAddr) (int, error) {
 		Num:     int32(len(buffer)),
 		Addrs:   uintptr(unsafe.Pointer(&buffer[0])),
 	}
-	length := unsafe.Sizeof(*addr)
-	_, _, errno := syscall.Syscall6(
-		syscall.SYS_GETSOCKOPT,
-		uintptr(sock),
-		syscall.IPPROTO_SCTP,
-		SCTP_SOCKOPT_CONNECTX3,
-		uintptr(unsafe.Pointer(addrs)),
-		uintptr(unsafe.Pointer(&length)),
-		0,
-	)
-	if errno == 0 || errno == syscall.EINPROGRESS {
+	length := uint32(unsafe.Sizeof(*addrs))
+	err := getsockopt(sock, syscall.IPPROTO_SCTP, SCTP_SOCKOPT_CONNECTX3, unsafe.Pointer(addrs), &length)
+	if err == nil || err == syscall.EINPROGRESS {
 		return int(addrs.AssocId), nil
 	}
-	if errno != syscall.ENOPROTOOPT {
-		return 0, errno
+	if err != syscall.ENOPROTOOPT {
+		return 0, err
 	}
 	// Fallback to CONNECTX
-	assoc, _, errno := syscall.Syscall6(
-		syscall.SYS_SETSOCKOPT,
-		uintptr(sock),
-		syscall.IPPROTO_SCTP,
-		SCTP_SOCKOPT_CONNECTX,
-		uintptr(unsafe.Pointer(&buffer[0])),
-		uintptr(len(buffer)),
-		0,
-	)
-	if errno != 0 {
-		return 0, errno
-	}
-	return int(assoc), nil
+	return connectx(sock, buffer)
 }
 
 // SCTPPeelOffFlag peels off an association from the SCTP socket with the specified flags and returns a new SCTPConn.
@@ -152,18 +115,10 @@ func SCTPPeelOffFlag(sock, assoc, flags int) (*SCTPConn, error) {
 		},
 		Flags: uint32(flags),
 	}
-	length := unsafe.Sizeof(params)
-	_, _, errno := syscall.Syscall6(
-		syscall.SYS_GETSOCKOPT,
-		uintptr(sock),
-		syscall.IPPROTO_SCTP,
-		SCTP_SOCKOPT_PEELOFF_FLAGS,
-		uintptr(unsafe.Pointer(&params)),
-		uintptr(unsafe.Pointer(&length)),
-		0,
-	)
-	if errno != 0 {
-		return nil, errno
+	length := uint32(unsafe.Sizeof(params))
+	err := getsockopt(sock, syscall.IPPROTO_SCTP, SCTP_SOCKOPT_PEELOFF_FLAGS, unsafe.Pointer(&params), &length)
+	if err != nil {
+		return nil, err
 	}
 	return NewSCTPConn(int(params.Arg.Sd)), nil
 }
@@ -174,22 +129,94 @@ func SCTPPeelOff(sock, assoc int) (*SCTPConn, error) {
 		AssocId: int32(assoc),
 		Sd:      0,
 	}
-	length := unsafe.Sizeof(params)
-	_, _, errno := syscall.Syscall6(
-		syscall.SYS_GETSOCKOPT,
-		uintptr(sock),
-		syscall.IPPROTO_SCTP,
-		SCTP_SOCKOPT_PEELOFF,
-		uintptr(unsafe.Pointer(&params)),
-		uintptr(unsafe.Pointer(&length)),
-		0,
-	)
-	if errno != 0 {
-		return nil, errno
+	length := uint32(unsafe.Sizeof(params))
+	err := getsockopt(sock, syscall.IPPROTO_SCTP, SCTP_SOCKOPT_PEELOFF, unsafe.Pointer(&params), &length)
+	if err != nil {
+		return nil, err
 	}
 	return NewSCTPConn(int(params.Sd)), nil
 }
 
+// SCTPGetAssocNumber returns the number of associations on the one-to-many SCTP socket.
+func SCTPGetAssocNumber(sock int) (int, error) {
+	var (
+		number uint32
+		length = uint32(unsafe.Sizeof(number))
+	)
+	err := getsockopt(sock, syscall.IPPROTO_SCTP, SCTP_GET_ASSOC_NUMBER, unsafe.Pointer(&number), &length)
+	if err != nil {
+		return 0, err
+	}
+	return int(number), nil
+}
+
+// SCTPGetAssocIdList returns the ids of all associations on the one-to-many SCTP socket.
+func SCTPGetAssocIdList(sock int) ([]int, error) {
+	number, err := SCTPGetAssocNumber(sock)
+	if err != nil {
+		return nil, err
+	}
+	// Associations may come up between the two calls, the kernel rejects
+	// a buffer that is too small with EINVAL so grow it and retry.
+	for attempt := 0; attempt < 4; attempt++ {
+		var (
+			buffer = make([]uint32, 1+number+attempt*16)
+			length = uint32(uintptr(len(buffer)) * unsafe.Sizeof(buffer[0]))
+		)
+		err := getsockopt(sock, syscall.IPPROTO_SCTP, SCTP_GET_ASSOC_ID_LIST, unsafe.Pointer(&buffer[0]), &length)
+		if err == syscall.EINVAL {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ids := (*SCTPAssocIds)(unsafe.Pointer(&buffer[0]))
+		if int(ids.NumberIds) > len(buffer)-1 {
+			return nil, syscall.EINVAL
+		}
+		assocs := make([]int, ids.NumberIds)
+		for i := range assocs {
+			assocs[i] = int(int32(buffer[1+i]))
+		}
+		return assocs, nil
+	}
+	return nil, syscall.EINVAL
+}
+
+// SCTPGetPeerAddrInfo returns the information of the peer address on the association. When assoc
+// is 0 on a one-to-many socket the association is looked up by the peer address.
+func SCTPGetPeerAddrInfo(sock, assoc int, addr *SCTPAddr) (*SCTPPeerAddrInfo, error) {
+	if addr == nil || len(addr.addresses) == 0 {
+		return nil, syscall.EINVAL
+	}
+	var info = SCTPPeerAddrInfo{
+		AssocId: int32(assoc),
+	}
+	copy(info.Addr[:], MakeSockaddr(&SCTPAddr{
+		addresses: addr.addresses[:1],
+		port:      addr.port,
+	}))
+	length := uint32(unsafe.Sizeof(info))
+	err := getsockopt(sock, syscall.IPPROTO_SCTP, SCTP_GET_PEER_ADDR_INFO, unsafe.Pointer(&info), &length)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// SCTPGetStatus returns the status of the association on the SCTP socket.
+func SCTPGetStatus(sock, assoc int) (*SCTPStatus, error) {
+	var status = SCTPStatus{
+		AssocId: int32(assoc),
+	}
+	length := uint32(unsafe.Sizeof(status))
+	err := getsockopt(sock, syscall.IPPROTO_SCTP, SCTP_STATUS, unsafe.Pointer(&status), &length)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
 // AddrFamily returns the address family (AF_INET or AF_INET6) based on the network string.
 func AddrFamily(network string) int {
 	if strings.HasSuffix(network, "4") {
@@ -216,223 +243,169 @@ func ParseSndRcvInfo(info *SCTPSndRcvInfo, data []byte) {
 	if info == nil || len(data) == 0 {
 		return
 	}
-	messages, err := syscall.ParseSocketControlMessage(data)
-	if err != nil {
-		return
+	if payload := FindCmsg(data, IPPROTO_SCTP, SCTP_SNDRCV); len(payload) >= SCTPSndRcvInfoSize {
+		copy(unsafe.Slice((*byte)(unsafe.Pointer(info)), SCTPSndRcvInfoSize), payload)
 	}
-	for _, message := range messages {
-		if message.Header.Level == IPPROTO_SCTP && message.Header.Type == SCTP_SNDRCV {
-			temp := (*SCTPSndRcvInfo)(unsafe.Pointer(&message.Data[0]))
-			*info = *temp
-			break
+}
+
+// FindCmsg walks the socket control message data and returns the data of the first control
+// message with the given level and type. Unlike syscall.ParseSocketControlMessage it does not
+// allocate.
+func FindCmsg(data []byte, level, kind int32) []byte {
+	var hdr syscall.Cmsghdr
+	for len(data) >= syscall.CmsgLen(0) {
+		copy(unsafe.Slice((*byte)(unsafe.Pointer(&hdr)), unsafe.Sizeof(hdr)), data)
+		length := int(hdr.Len)
+		if length < syscall.CmsgLen(0) || length > len(data) {
+			return nil
+		}
+		if hdr.Level == level && hdr.Type == kind {
+			return data[syscall.CmsgLen(0):length]
 		}
+		space := syscall.CmsgSpace(length - syscall.CmsgLen(0))
+		if space >= len(data) {
+			return nil
+		}
+		data = data[space:]
+	}
+	return nil
+}
+
+// decodeNotification copies the notification data into event. The data must hold the whole
+// structure, the variable part that follows it is bounded by the data: a length in the
+// notification header beyond the data, as of a notification truncated by a short read
+// buffer, is capped to the data. Copying rather than casting also keeps misaligned data
+// from being dereferenced.
+func decodeNotification[T any](event *T, data []byte) error {
+	size := int(unsafe.Sizeof(*event))
+	if len(data) < size {
+		return ErrShortBuffer
+	}
+	b := unsafe.Slice((*byte)(unsafe.Pointer(event)), size)
+	copy(b, data)
+	if uint64(endian.Uint32(b[4:])) > uint64(len(data)) {
+		endian.PutUint32(b[4:], uint32(len(data)))
 	}
+	return nil
 }
 
 // ParseDataIOEvent parses the notification data into a SCTPNotificationHeader.
 func ParseDataIOEvent(data []byte) (Notification, error) {
-	if len(data) < SCTPNotificationHeaderSize {
-		return nil, fmt.Errorf("invalid data len, too small")
+	event := &SCTPNotificationHeader{}
+	if err := decodeNotification(event, data); err != nil {
+		return nil, err
 	}
-	temp := (*SCTPNotificationHeader)(unsafe.Pointer(&data[0]))
-	return &SCTPNotificationHeader{
-		Type:   temp.Type,
-		Flags:  temp.Flags,
-		Length: temp.Length,
-	}, nil
+	return event, nil
 }
 
 // ParseAssocChangeEvent parses the notification data into a SCTPAssocChange.
 func ParseAssocChangeEvent(data []byte) (Notification, error) {
-	if len(data) < int(unsafe.Sizeof(SCTPAssocChange{})) {
-		return nil, fmt.Errorf("invalid data len, too small")
-	}
-	temp := (*SCTPAssocChange)(unsafe.Pointer(&data[0]))
-	return &SCTPAssocChange{
-		Type:            temp.Type,
-		Flags:           temp.Flags,
-		Length:          temp.Length,
-		State:           temp.State,
-		Error:           temp.Error,
-		OutboundStreams: temp.OutboundStreams,
-		InboundStreams:  temp.InboundStreams,
-		AssocId:         temp.AssocId,
-	}, nil
+	event := &SCTPAssocChange{}
+	if err := decodeNotification(event, data); err != nil {
+		return nil, err
+	}
+	return event, nil
 }
 
 // ParsePeerAddrChangeEvent parses the notification data into a SCTPPAddrChange.
 func ParsePeerAddrChangeEvent(data []byte) (Notification, error) {
-	if len(data) < int(unsafe.Sizeof(SCTPPAddrChange{})) {
-		return nil, fmt.Errorf("invalid data len, too small")
+	event := &SCTPPAddrChange{}
+	if err := decodeNotification(event, data); err != nil {
+		return nil, err
 	}
-	var (
-		temp = (*SCTPPAddrChange)(unsafe.Pointer(&data[0]))
-		addr [128]byte
-	)
-	copy(addr[:], temp.Addr[:])
-	return &SCTPPAddrChange{
-		Type:    temp.Type,
-		Flags:   temp.Flags,
-		Length:  temp.Length,
-		Addr:    addr,
-		State:   temp.State,
-		Error:   temp.Error,
-		AssocId: temp.AssocId,
-	}, nil
-}
-
-// ParseSendFailedEvent parses the notification data into a SCTPSendFailed.
+	return event, nil
+}
+
+// ParseSendFailedEvent parses the notification data into a SCTPSendFailed. The undelivered
+// message follows the structure in data, up to the length of the notification which does
+// not exceed data.
 func ParseSendFailedEvent(data []byte) (Notification, error) {
-	if len(data) < int(unsafe.Sizeof(SCTPSendFailed{})) {
-		return nil, fmt.Errorf("invalid data len, too small")
+	event := &SCTPSendFailed{}
+	if err := decodeNotification(event, data); err != nil {
+		return nil, err
 	}
-	temp := (*SCTPSendFailed)(unsafe.Pointer(&data[0]))
-	return &SCTPSendFailed{
-		Type:    temp.Type,
-		Flags:   temp.Flags,
-		Length:  temp.Length,
-		Error:   temp.Error,
-		Info:    temp.Info,
-		AssocId: temp.AssocId,
-	}, nil
+	return event, nil
 }
 
 // ParseRemoteErrorEvent parses the notification data into a SCTPRemoteError.
 func ParseRemoteErrorEvent(data []byte) (Notification, error) {
-	if len(data) < int(unsafe.Sizeof(SCTPRemoteError{})) {
-		return nil, fmt.Errorf("invalid data len, too small")
+	event := &SCTPRemoteError{}
+	if err := decodeNotification(event, data); err != nil {
+		return nil, err
 	}
-	temp := (*SCTPRemoteError)(unsafe.Pointer(&data[0]))
-	return &SCTPRemoteError{
-		Type:    temp.Type,
-		Flags:   temp.Flags,
-		Length:  temp.Length,
-		Error:   temp.Error,
-		AssocId: temp.AssocId,
-	}, nil
+	return event, nil
 }
 
 // ParseShutdownEvent parses the notification data into a SCTPShutdownEvent.
 func ParseShutdownEvent(data []byte) (Notification, error) {
-	if len(data) < int(unsafe.Sizeof(SCTPShutdownEvent{})) {
-		return nil, fmt.Errorf("invalid data len, too small")
+	event := &SCTPShutdownEvent{}
+	if err := decodeNotification(event, data); err != nil {
+		return nil, err
 	}
-	temp := (*SCTPShutdownEvent)(unsafe.Pointer(&data[0]))
-	return &SCTPShutdownEvent{
-		Type:    temp.Type,
-		Flags:   temp.Flags,
-		Length:  temp.Length,
-		AssocId: temp.AssocId,
-	}, nil
+	return event, nil
 }
 
 // ParsePartialDeliveryEvent parses the notification data into a SCTPPDApiEvent.
 func ParsePartialDeliveryEvent(data []byte) (Notification, error) {
-	if len(data) < int(unsafe.Sizeof(SCTPPDApiEvent{})) {
-		return nil, fmt.Errorf("invalid data len, too small")
+	event := &SCTPPDApiEvent{}
+	if err := decodeNotification(event, data); err != nil {
+		return nil, err
 	}
-	temp := (*SCTPPDApiEvent)(unsafe.Pointer(&data[0]))
-	return &SCTPPDApiEvent{
-		Type:       temp.Type,
-		Flags:      temp.Flags,
-		Length:     temp.Length,
-		Indication: temp.Indication,
-		AssocId:    temp.AssocId,
-		Stream:     temp.Stream,
-		Sequence:   temp.Sequence,
-	}, nil
+	return event, nil
 }
 
 // ParseAdaptationIndicationEvent parses the notification data into a SCTPAdaptationEvent.
 func ParseAdaptationIndicationEvent(data []byte) (Notification, error) {
-	if len(data) < int(unsafe.Sizeof(SCTPAdaptationEvent{})) {
-		return nil, fmt.Errorf("invalid data len, too small")
+	event := &SCTPAdaptationEvent{}
+	if err := decodeNotification(event, data); err != nil {
+		return nil, err
 	}
-	temp := (*SCTPAdaptationEvent)(unsafe.Pointer(&data[0]))
-	return &SCTPAdaptationEvent{
-		Type:          temp.Type,
-		Flags:         temp.Flags,
-		Length:        temp.Length,
-		AdaptationInd: temp.AdaptationInd,
-		AssocId:       temp.AssocId,
-	}, nil
+	return event, nil
 }
 
 // ParseAuthenticationEvent parses the notification data into a SCTPAuthKeyEvent.
 func ParseAuthenticationEvent(data []byte) (Notification, error) {
-	if len(data) < int(unsafe.Sizeof(SCTPAuthKeyEvent{})) {
-		return nil, fmt.Errorf("invalid data len, too small")
+	event := &SCTPAuthKeyEvent{}
+	if err := decodeNotification(event, data); err != nil {
+		return nil, err
 	}
-	temp := (*SCTPAuthKeyEvent)(unsafe.Pointer(&data[0]))
-	return &SCTPAuthKeyEvent{
-		Type:         temp.Type,
-		Flags:        temp.Flags,
-		Length:       temp.Length,
-		KeyNumber:    temp.KeyNumber,
-		AltKeyNumber: temp.AltKeyNumber,
-		Indication:   temp.Indication,
-		AssocId:      temp.AssocId,
-	}, nil
+	return event, nil
 }
 
 // ParseSenderDryEvent parses the notification data into a SCTPSenderDryEvent.
 func ParseSenderDryEvent(data []byte) (Notification, error) {
-	if len(data) < int(unsafe.Sizeof(SCTPSenderDryEvent{})) {
-		return nil, fmt.Errorf("invalid data len, too small")
+	event := &SCTPSenderDryEvent{}
+	if err := decodeNotification(event, data); err != nil {
+		return nil, err
 	}
-	temp := (*SCTPSenderDryEvent)(unsafe.Pointer(&data[0]))
-	return &SCTPSenderDryEvent{
-		Type:    temp.Type,
-		Flags:   temp.Flags,
-		Length:  temp.Length,
-		AssocId: temp.AssocId,
-	}, nil
+	return event, nil
 }
 
 // ParseStreamResetEvent parses the notification data into a SCTPStreamResetEvent.
 func ParseStreamResetEvent(data []byte) (Notification, error) {
-	if len(data) < int(unsafe.Sizeof(SCTPStreamResetEvent{})) {
-		return nil, fmt.Errorf("invalid data len, too small")
+	event := &SCTPStreamResetEvent{}
+	if err := decodeNotification(event, data); err != nil {
+		return nil, err
 	}
-	temp := (*SCTPStreamResetEvent)(unsafe.Pointer(&data[0]))
-	return &SCTPStreamResetEvent{
-		Type:    temp.Type,
-		Flags:   temp.Flags,
-		Length:  temp.Length,
-		AssocId: temp.AssocId,
-	}, nil
+	return event, nil
 }
 
 // ParseAssocResetEvent parses the notification data into a SCTPAssocResetEvent.
 func ParseAssocResetEvent(data []byte) (Notification, error) {
-	if len(data) < int(unsafe.Sizeof(SCTPAssocResetEvent{})) {
-		return nil, fmt.Errorf("invalid data len, too small")
+	event := &SCTPAssocResetEvent{}
+	if err := decodeNotification(event, data); err != nil {
+		return nil, err
 	}
-	temp := (*SCTPAssocResetEvent)(unsafe.Pointer(&data[0]))
-	return &SCTPAssocResetEvent{
-		Type:      temp.Type,
-		Flags:     temp.Flags,
-		Length:    temp.Length,
-		AssocId:   temp.AssocId,
-		LocalTsn:  temp.LocalTsn,
-		RemoteTsn: temp.RemoteTsn,
-	}, nil
+	return event, nil
 }
 
 // ParseStreamChangeEvent parses the notification data into a SCTPStreamChangeEvent.
 func ParseStreamChangeEvent(data []byte) (Notification, error) {
-	if len(data) < int(unsafe.Sizeof(SCTPStreamChangeEvent{})) {
-		return nil, fmt.Errorf("invalid data len, too small")
+	event := &SCTPStreamChangeEvent{}
+	if err := decodeNotification(event, data); err != nil {
+		return nil, err
 	}
-	temp := (*SCTPStreamChangeEvent)(unsafe.Pointer(&data[0]))
-	return &SCTPStreamChangeEvent{
-		Type:       temp.Type,
-		Flags:      temp.Flags,
-		Length:     temp.Length,
-		AssocId:    temp.AssocId,
-		InStreams:  temp.InStreams,
-		OutStreams: temp.OutStreams,
-	}, nil
+	return event, nil
 }
 
 // NotificationName returns the string name of the given SCTP notification type.
@@ -456,14 +429,13 @@ func NotificationName(notification uint16) string {
 }
 
 // ParseNotification parses the SCTP notification data based on its type and returns the appropriate Notification.
+// Data may extend beyond the structure of its type, e.g. with the undelivered message of
+// SCTP_SEND_FAILED, a length in the header beyond the data is capped to the data.
 func ParseNotification(data []byte) (Notification, error) {
 	if len(data) < SCTPNotificationHeaderSize {
-		return nil, fmt.Errorf("invalid data len, too small")
-	}
-	if len(data) > SCTPNotificationSize {
-		return nil, fmt.Errorf("invalid data len, too large")
+		return nil, ErrShortBuffer
 	}
-	temp := (*SCTPNotificationHeader)(unsafe.Pointer(&data[0]))
+	kind := endian.Uint16(data)
 	parsers := map[uint16]func([]byte) (Notification, error){
 		SCTP_DATA_IO_EVENT:          ParseDataIOEvent,
 		SCTP_ASSOC_CHANGE:           ParseAssocChangeEvent,
@@ -479,20 +451,29 @@ func ParseNotification(data []byte) (Notification, error) {
 		SCTP_ASSOC_RESET_EVENT:      ParseAssocResetEvent,
 		SCTP_STREAM_CHANGE_EVENT:    ParseStreamChangeEvent,
 	}
-	if parser, ok := parsers[temp.Type]; ok {
+	if parser, ok := parsers[kind]; ok {
 		return parser(data)
 	}
-	return nil, fmt.Errorf("invalid notification type")
+	return nil, fmt.Errorf("%w: unknown type %d", ErrInvalidNotification, kind)
 }
 
 // SCTPSendMsg sends a message with optional control data over the SCTP socket.
 func SCTPSendMsg(sock int, buffer, control []byte, flags int) (int, error) {
+	return SCTPSendMsgTo(sock, buffer, control, nil, flags)
+}
+
+// SCTPSendMsgTo sends a message with optional control data to the destination socket
+// address over the SCTP socket. On a one-to-many socket the kernel sets up the
+// association implicitly when none exists for the destination.
+func SCTPSendMsgTo(sock int, buffer, control, to []byte, flags int) (int, error) {
 	var (
-		msg syscall.Msghdr
-		iov syscall.Iovec
+		msg unix.Msghdr
+		iov unix.Iovec
 	)
-	msg.Name = nil
-	msg.Namelen = uint32(0)
+	if len(to) > 0 {
+		msg.Name = &to[0]
+		msg.Namelen = uint32(len(to))
+	}
 	if len(buffer) > 0 {
 		iov.Base = &buffer[0]
 		iov.SetLen(len(buffer))
@@ -502,18 +483,107 @@ func SCTPSendMsg(sock int, buffer, control []byte, flags int) (int, error) {
 		msg.SetControllen(len(control))
 	}
 	msg.Iov = &iov
-	msg.Iovlen = 1
-	length, _, errno := syscall.Syscall(
-		syscall.SYS_SENDMSG,
-		uintptr(sock),
-		uintptr(unsafe.Pointer(&msg)),
-		uintptr(flags),
-	)
-	if errno != 0 {
-		return 0, errno
+	msg.SetIovlen(1)
+	length, err := sendmsg(sock, &msg, flags)
+	if err != nil {
+		return 0, err
 	}
 	if len(control) > 0 && len(buffer) == 0 {
 		return 0, nil
 	}
-	return int(length), nil
+	return length, nil
+}
+
+// SCTPRecvMsg receives a message with control data from the SCTP socket. It returns the
+// number of bytes and control bytes received and the message flags. Unlike syscall.Recvmsg
+// it does not report the source address and does not allocate.
+func SCTPRecvMsg(sock int, buffer, control []byte, flags int) (int, int, int, error) {
+	var (
+		msg unix.Msghdr
+		iov unix.Iovec
+	)
+	if len(buffer) > 0 {
+		iov.Base = &buffer[0]
+		iov.SetLen(len(buffer))
+	}
+	if len(control) > 0 {
+		msg.Control = &control[0]
+		msg.SetControllen(len(control))
+	}
+	msg.Iov = &iov
+	msg.SetIovlen(1)
+	length, err := recvmsg(sock, &msg, flags)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return length, int(msg.Controllen), int(msg.Flags), nil
+}
+
+// maxStackIovecs is the number of buffers a vectored call handles without allocating.
+const maxStackIovecs = 8
+
+// makeIovecs fills iovs with an iovec for every non empty buffer, growing it when needed.
+func makeIovecs(iovs []unix.Iovec, buffers [][]byte) []unix.Iovec {
+	iovs = iovs[:0]
+	for _, buffer := range buffers {
+		if len(buffer) == 0 {
+			continue
+		}
+		iov := unix.Iovec{
+			Base: &buffer[0],
+		}
+		iov.SetLen(len(buffer))
+		iovs = append(iovs, iov)
+	}
+	return iovs
+}
+
+// SCTPSendMsgv sends the buffers as a single message with optional control data over the
+// SCTP socket, gathering them without copying.
+func SCTPSendMsgv(sock int, buffers [][]byte, control []byte, flags int) (int, error) {
+	var (
+		msg   unix.Msghdr
+		stack [maxStackIovecs]unix.Iovec
+		iovs  = makeIovecs(stack[:], buffers)
+	)
+	if len(iovs) > 0 {
+		msg.Iov = &iovs[0]
+		msg.SetIovlen(len(iovs))
+	}
+	if len(control) > 0 {
+		msg.Control = &control[0]
+		msg.SetControllen(len(control))
+	}
+	length, err := sendmsg(sock, &msg, flags)
+	if err != nil {
+		return 0, err
+	}
+	if len(control) > 0 && len(iovs) == 0 {
+		return 0, nil
+	}
+	return length, nil
+}
+
+// SCTPRecvMsgv receives a message with control data from the SCTP socket, scattering it
+// over the buffers. It returns the number of bytes and control bytes received and the
+// message flags.
+func SCTPRecvMsgv(sock int, buffers [][]byte, control []byte, flags int) (int, int, int, error) {
+	var (
+		msg   unix.Msghdr
+		stack [maxStackIovecs]unix.Iovec
+		iovs  = makeIovecs(stack[:], buffers)
+	)
+	if len(iovs) > 0 {
+		msg.Iov = &iovs[0]
+		msg.SetIovlen(len(iovs))
+	}
+	if len(control) > 0 {
+		msg.Control = &control[0]
+		msg.SetControllen(len(control))
+	}
+	length, err := recvmsg(sock, &msg, flags)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return length, int(msg.Controllen), int(msg.Flags), nil
 }
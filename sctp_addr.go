@@ -1,7 +1,6 @@
 package sctp_go
 
 import (
-	"bytes"
 	"encoding/binary"
 	"net"
 	"strconv"
@@ -76,6 +75,7 @@ func (addr *SCTPAddr) String() string {
 		}
 		if ip4 := address.To4(); ip4 != nil {
 			b.WriteString(ip4.String())
+			continue
 		}
 		if ip6 := address.To16(); ip6 != nil {
 			b.WriteByte('[')
@@ -102,13 +102,14 @@ func (addr *SCTPAddr) Addr() net.Addr {
 }
 
 // MakeSockaddr converts an SCTPAddr to a byte slice containing socket address structures
-// suitable for use with SCTP system calls. It handles both IPv4 and IPv6 addresses.
+// suitable for use with SCTP system calls. It handles both IPv4 and IPv6 addresses, the
+// family is in host byte order and the port in network byte order.
 func MakeSockaddr(addr *SCTPAddr) []byte {
 	if len(addr.addresses) == 0 {
 		return nil
 	}
-	// Pre-calculate buffer capacity and cache port conversion
-	port := htons(uint16(addr.port))
+	// Pre-calculate buffer capacity
+	port := uint16(addr.port)
 	capacity := 0
 	for _, address := range addr.addresses {
 		if address.To4() != nil {
@@ -117,110 +118,146 @@ func MakeSockaddr(addr *SCTPAddr) []byte {
 			capacity += SockAddrIn6Size
 		}
 	}
-	var buffer bytes.Buffer
-	buffer.Grow(capacity)
+	var (
+		buffer = make([]byte, capacity)
+		offset = 0
+	)
 	for _, address := range addr.addresses {
 		if ip4 := address.To4(); ip4 != nil {
-			/*
-				sa := &syscall.RawSockaddrInet4{
-					Family: syscall.AF_INET,
-					Port: port,
-				}
-				copy(sa.Addr[:], ip4)
-				buffer = append(buffer, Pack(sa)...)
-			*/
 			// IPv4: Family(2) + Port(2) + Addr(4) + Zero(8) = 16 bytes
-			binary.Write(&buffer, endian, uint16(syscall.AF_INET))
-			binary.Write(&buffer, endian, port)
-			buffer.Write(ip4)
-			binary.Write(&buffer, endian, uint64(0))
+			endian.PutUint16(buffer[offset:], syscall.AF_INET)
+			binary.BigEndian.PutUint16(buffer[offset+2:], port)
+			copy(buffer[offset+4:], ip4)
+			offset += SockAddrInSize
 			continue
 		}
 		if ip6 := address.To16(); ip6 != nil {
-			/*
-				sa := syscall.RawSockaddrInet6{
-					Family: syscall.AF_INET6,
-					Port:   port,
-				}
-				copy(sa.Addr[:], ip6)
-				buffer = append(buffer, Pack(sa)...)
-			*/
 			// IPv6: Family(2) + Port(2) + FlowInfo(4) + Addr(16) + ScopeId(4) = 28 bytes
-			binary.Write(&buffer, endian, uint16(syscall.AF_INET6))
-			binary.Write(&buffer, endian, port)
-			binary.Write(&buffer, endian, uint32(0)) // FlowInfo
-			buffer.Write(ip6)
-			binary.Write(&buffer, endian, uint32(0)) // ScopeId
+			endian.PutUint16(buffer[offset:], syscall.AF_INET6)
+			binary.BigEndian.PutUint16(buffer[offset+2:], port)
+			copy(buffer[offset+8:], ip6)
+			offset += SockAddrIn6Size
 		}
 	}
-	return buffer.Bytes()
+	return buffer[:offset]
 }
 
 // FromSockAddrStorage converts a socket address storage structure to an SCTPAddr.
 // It supports both IPv4 (AF_INET) and IPv6 (AF_INET6) address families.
 func FromSockAddrStorage(addr *SockAddrStorage) *SCTPAddr {
-	if addr != nil {
-		switch addr.Family {
-		case syscall.AF_INET:
-			sa := (*SockAddrIn)(unsafe.Pointer(addr))
-			return &SCTPAddr{
-				port: int(ntohs(sa.Port)),
-				addresses: []net.IP{
-					sa.Addr.Addr[:],
-				},
-			}
-		case syscall.AF_INET6:
-			sa := (*SockAddrIn6)(unsafe.Pointer(addr))
-			return &SCTPAddr{
-				port: int(ntohs(sa.Port)),
-				addresses: []net.IP{
-					sa.Addr.Addr[:],
-				},
-			}
+	if addr == nil {
+		return nil
+	}
+	ip, port, _ := parseSockaddr(unsafe.Slice((*byte)(unsafe.Pointer(addr)), SockAddrStorageSize))
+	if ip == nil {
+		return nil
+	}
+	return &SCTPAddr{
+		port:      port,
+		addresses: []net.IP{ip},
+	}
+}
+
+// parseSockaddr decodes the IPv4 or IPv6 socket address at the start of data and returns
+// its address, its port and its size. The address is nil when data holds no complete
+// socket address of a supported family.
+func parseSockaddr(data []byte) (net.IP, int, int) {
+	if len(data) < 2 {
+		return nil, 0, 0
+	}
+	switch endian.Uint16(data) {
+	case syscall.AF_INET:
+		if len(data) < SockAddrInSize {
+			return nil, 0, 0
+		}
+		// IPv4: Family(2) + Port(2) + Addr(4) + Zero(8) = 16 bytes
+		return append(net.IP(nil), data[4:8]...), int(binary.BigEndian.Uint16(data[2:])), SockAddrInSize
+	case syscall.AF_INET6:
+		if len(data) < SockAddrIn6Size {
+			return nil, 0, 0
 		}
+		// IPv6: Family(2) + Port(2) + FlowInfo(4) + Addr(16) + ScopeId(4) = 28 bytes
+		return append(net.IP(nil), data[8:24]...), int(binary.BigEndian.Uint16(data[2:])), SockAddrIn6Size
 	}
-	return nil
+	return nil, 0, 0
 }
 
-// FromSCTPGetAddrs converts a SCTPGetAddrs structure to an SCTPAddr.
-// It handles both IPv4 and IPv6 addresses, extracting the port from the first address.
-func FromSCTPGetAddrs(addr *SCTPGetAddrs) *SCTPAddr {
-	if addr != nil && addr.Num > 0 {
-		address := &SCTPAddr{
-			addresses: make([]net.IP, addr.Num),
+// FromSockaddr converts a syscall.Sockaddr to an SCTPAddr.
+// It supports both IPv4 and IPv6 socket addresses.
+func FromSockaddr(sa syscall.Sockaddr) *SCTPAddr {
+	switch sa := sa.(type) {
+	case *syscall.SockaddrInet4:
+		return &SCTPAddr{
+			port: sa.Port,
+			addresses: []net.IP{
+				append(net.IP(nil), sa.Addr[:]...),
+			},
 		}
-		ptr := unsafe.Add(unsafe.Pointer(addr), SCTPGetAddrsSize)
-		for i := uint32(0); i < addr.Num; i++ {
-			sockaddr := (*SockAddr)(unsafe.Pointer(ptr))
-			switch sockaddr.Family {
-			case syscall.AF_INET:
-				sa := (*SockAddrIn)(unsafe.Pointer(ptr))
-				if i == 0 {
-					address.port = int(ntohs(sa.Port))
-				}
-				address.addresses[i] = sa.Addr.Addr[:]
-				ptr = unsafe.Add(ptr, SockAddrInSize)
-			case syscall.AF_INET6:
-				sa := (*SockAddrIn6)(unsafe.Pointer(ptr))
-				if i == 0 {
-					address.port = int(ntohs(sa.Port))
-				}
-				address.addresses[i] = sa.Addr.Addr[:]
-				ptr = unsafe.Add(ptr, SockAddrIn6Size)
-			default:
-				return nil
-			}
+	case *syscall.SockaddrInet6:
+		return &SCTPAddr{
+			port: sa.Port,
+			addresses: []net.IP{
+				append(net.IP(nil), sa.Addr[:]...),
+			},
 		}
-		return address
 	}
 	return nil
 }
 
+// ParseSCTPGetAddrs converts the data returned by SCTP_GET_LOCAL_ADDRS and
+// SCTP_GET_PEER_ADDRS, a SCTPGetAddrs structure followed by its socket addresses, to an
+// SCTPAddr with the port of the first address. It returns nil when the addresses counted by
+// Num do not fit in data or are of an unsupported family.
+func ParseSCTPGetAddrs(data []byte) *SCTPAddr {
+	if len(data) < SCTPGetAddrsSize {
+		return nil
+	}
+	num := uint64(endian.Uint32(data[4:]))
+	data = data[SCTPGetAddrsSize:]
+	if num == 0 || num > uint64(len(data)/SockAddrInSize) {
+		return nil
+	}
+	address := &SCTPAddr{
+		addresses: make([]net.IP, num),
+	}
+	for i := range address.addresses {
+		ip, port, size := parseSockaddr(data)
+		if ip == nil {
+			return nil
+		}
+		if i == 0 {
+			address.port = port
+		}
+		address.addresses[i] = ip
+		data = data[size:]
+	}
+	return address
+}
+
+// sctpGetAddrsBufferSize is the size of the buffers passed to SCTP_GET_LOCAL_ADDRS and
+// SCTP_GET_PEER_ADDRS.
+const sctpGetAddrsBufferSize = 4096
+
+// FromSCTPGetAddrs converts a SCTPGetAddrs structure followed by its socket addresses to an
+// SCTPAddr, length is the size of that data as returned by getsockopt. No more than length
+// bytes are read from addr, it returns nil when the addresses counted by Num do not fit.
+//
+// Deprecated: use ParseSCTPGetAddrs with the buffer passed to getsockopt.
+func FromSCTPGetAddrs(addr *SCTPGetAddrs, length int) *SCTPAddr {
+	if addr == nil || length < SCTPGetAddrsSize {
+		return nil
+	}
+	return ParseSCTPGetAddrs(unsafe.Slice((*byte)(unsafe.Pointer(addr)), length))
+}
+
 // MakeSCTPAddr parses a network address string and creates an SCTPAddr.
 // It supports "sctp", "sctp4", and "sctp6" networks, parsing comma-separated IP addresses.
+// The "sctp+udp", "sctp4+udp" and "sctp6+udp" networks of the UDP encapsulation are
+// accepted as their SCTP network. IPv6 addresses may be enclosed in brackets, as String
+// writes them.
 func MakeSCTPAddr(network, addr string) (*SCTPAddr, error) {
 	// Normalize network
-	switch network {
+	switch network = strings.TrimSuffix(network, "+udp"); network {
 	case "", "sctp":
 		network = "sctp"
 	case "sctp4":
@@ -254,6 +291,9 @@ func MakeSCTPAddr(network, addr string) (*SCTPAddr, error) {
 	addresses := make([]net.IP, 0, len(addrs))
 
 	for _, addrPart := range addrs {
+		if n := len(addrPart); n > 2 && addrPart[0] == '[' && addrPart[n-1] == ']' {
+			addrPart = addrPart[1 : n-1]
+		}
 		if len(addrPart) == 0 {
 			// Empty address part
 			switch network {
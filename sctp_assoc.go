@@ -0,0 +1,302 @@
+package sctp_go
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// SCTPAssociation holds the metadata tracked for an association of a listener socket.
+type SCTPAssociation struct {
+	Id              int
+	State           int
+	PeerAddr        *SCTPAddr
+	InboundStreams  uint16
+	OutboundStreams uint16
+	UpSince         time.Time
+}
+
+// SCTPAssociationHooks holds the callbacks invoked on association lifecycle changes.
+// Callbacks receive a copy of the association and are invoked without holding any lock.
+type SCTPAssociationHooks struct {
+	OnUp      func(assoc *SCTPAssociation)
+	OnDown    func(assoc *SCTPAssociation)
+	OnRestart func(assoc *SCTPAssociation)
+}
+
+// associations is the association table of a listener, maintained from notifications.
+type associations struct {
+	mutex   sync.RWMutex
+	table   map[int]*SCTPAssociation
+	hooks   SCTPAssociationHooks
+	drained map[int]struct{}
+	signal  chan struct{}
+}
+
+// drain starts recording associations that complete shutdown or report SENDER_DRY.
+func (a *associations) drain() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.drained = make(map[int]struct{})
+}
+
+// isDrained reports whether the association completed shutdown or reported SENDER_DRY
+// since drain was called.
+func (a *associations) isDrained(id int) bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	_, ok := a.drained[id]
+	return ok
+}
+
+// changed returns a channel that is closed on the next update of the table.
+func (a *associations) changed() <-chan struct{} {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.signal == nil {
+		a.signal = make(chan struct{})
+	}
+	return a.signal
+}
+
+func (a *associations) notify() {
+	if a.signal != nil {
+		close(a.signal)
+		a.signal = nil
+	}
+}
+
+func (a *associations) setHooks(hooks SCTPAssociationHooks) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.hooks = hooks
+}
+
+func (a *associations) list() []*SCTPAssociation {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	list := make([]*SCTPAssociation, 0, len(a.table))
+	for _, assoc := range a.table {
+		temp := *assoc
+		list = append(list, &temp)
+	}
+	return list
+}
+
+func (a *associations) lookup(id int) (*SCTPAssociation, bool) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	if assoc, ok := a.table[id]; ok {
+		temp := *assoc
+		return &temp, true
+	}
+	return nil, false
+}
+
+func (a *associations) store(assoc *SCTPAssociation) {
+	if a.table == nil {
+		a.table = make(map[int]*SCTPAssociation)
+	}
+	a.table[assoc.Id] = assoc
+}
+
+// remove drops the association without invoking any hook, used when an association
+// leaves the socket without going down, e.g. when it is peeled off.
+func (a *associations) remove(id int) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	delete(a.table, id)
+}
+
+// update applies the notification to the table, resolve is used to look up the peer
+// addresses of associations that come up or restart. It is called before the table is
+// locked.
+func (a *associations) update(notification Notification, resolve func(id int) *SCTPAddr) {
+	var (
+		hook  func(assoc *SCTPAssociation)
+		event SCTPAssociation
+		peer  *SCTPAddr
+	)
+	if n, ok := notification.(*SCTPAssocChange); ok && resolve != nil &&
+		(n.State == SCTP_COMM_UP || n.State == SCTP_RESTART) {
+		peer = resolve(int(n.AssocId))
+	}
+	a.mutex.Lock()
+	switch n := notification.(type) {
+	case *SCTPAssocChange:
+		id := int(n.AssocId)
+		switch n.State {
+		case SCTP_COMM_UP, SCTP_RESTART:
+			assoc, ok := a.table[id]
+			if !ok {
+				assoc = &SCTPAssociation{
+					Id: id,
+				}
+				a.store(assoc)
+			}
+			assoc.State = SCTP_ESTABLISHED
+			assoc.InboundStreams = n.InboundStreams
+			assoc.OutboundStreams = n.OutboundStreams
+			assoc.UpSince = time.Now()
+			if resolve != nil {
+				assoc.PeerAddr = peer
+			}
+			hook = a.hooks.OnUp
+			if n.State == SCTP_RESTART {
+				hook = a.hooks.OnRestart
+			}
+			event = *assoc
+		case SCTP_COMM_LOST, SCTP_SHUTDOWN_COMP:
+			if a.drained != nil {
+				a.drained[id] = struct{}{}
+			}
+			if assoc, ok := a.table[id]; ok {
+				delete(a.table, id)
+				assoc.State = SCTP_CLOSED
+				hook = a.hooks.OnDown
+				event = *assoc
+			}
+		}
+	case *SCTPShutdownEvent:
+		if assoc, ok := a.table[int(n.AssocId)]; ok {
+			assoc.State = SCTP_SHUTDOWN_RECEIVED
+		}
+	case *SCTPSenderDryEvent:
+		if a.drained != nil {
+			a.drained[int(n.AssocId)] = struct{}{}
+		}
+	}
+	a.notify()
+	a.mutex.Unlock()
+	if hook != nil {
+		hook(&event)
+	}
+}
+
+// sync reconciles the table with the association ids reported by the kernel. Newly
+// discovered associations are reported as up, vanished ones as down. discover is called
+// before the table is locked.
+func (a *associations) sync(ids []int, discover func(id int) *SCTPAssociation) {
+	var (
+		up         []SCTPAssociation
+		down       []SCTPAssociation
+		discovered []*SCTPAssociation
+	)
+	a.mutex.RLock()
+	unknown := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := a.table[id]; !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	a.mutex.RUnlock()
+	for _, id := range unknown {
+		if assoc := discover(id); assoc != nil {
+			discovered = append(discovered, assoc)
+		}
+	}
+	a.mutex.Lock()
+	present := make(map[int]struct{}, len(ids))
+	for _, id := range ids {
+		present[id] = struct{}{}
+	}
+	for _, assoc := range discovered {
+		if _, ok := a.table[assoc.Id]; ok {
+			continue
+		}
+		a.store(assoc)
+		up = append(up, *assoc)
+	}
+	for id, assoc := range a.table {
+		if _, ok := present[id]; !ok {
+			delete(a.table, id)
+			assoc.State = SCTP_CLOSED
+			down = append(down, *assoc)
+		}
+	}
+	hooks := a.hooks
+	a.notify()
+	a.mutex.Unlock()
+	if hooks.OnUp != nil {
+		for i := range up {
+			hooks.OnUp(&up[i])
+		}
+	}
+	if hooks.OnDown != nil {
+		for i := range down {
+			hooks.OnDown(&down[i])
+		}
+	}
+}
+
+// shutdownOps are the socket operations of a graceful shutdown.
+type shutdownOps struct {
+	// list returns the associations of the socket.
+	list func() ([]int, error)
+	// disconnect sends SHUTDOWN on an association and abort sends ABORT.
+	disconnect func(assoc int) error
+	abort      func(assoc int) error
+}
+
+// shutdown disconnects the associations of the socket, falling back to the table when
+// the kernel cannot list them, and waits until they drained or ctx expires. The
+// associations still pending then are aborted and returned with the joined errors of
+// disconnect and abort.
+func (a *associations) shutdown(ctx context.Context, ops shutdownOps) ([]int, error) {
+	ids, err := ops.list()
+	if err != nil {
+		ids = ids[:0]
+		for _, assoc := range a.list() {
+			ids = append(ids, assoc.Id)
+		}
+	}
+	a.drain()
+	var errs []error
+	pending := make(map[int]struct{}, len(ids))
+	for _, id := range ids {
+		if err := ops.disconnect(id); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		pending[id] = struct{}{}
+	}
+	ticker := time.NewTicker(ShutdownPollInterval)
+	defer ticker.Stop()
+	var forced []int
+	for len(pending) > 0 {
+		changed := a.changed()
+		for id := range pending {
+			if a.isDrained(id) {
+				delete(pending, id)
+			}
+		}
+		if ids, err := ops.list(); err == nil {
+			present := make(map[int]struct{}, len(ids))
+			for _, id := range ids {
+				present[id] = struct{}{}
+			}
+			for id := range pending {
+				if _, ok := present[id]; !ok {
+					delete(pending, id)
+				}
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			for id := range pending {
+				if err := ops.abort(id); err != nil {
+					errs = append(errs, err)
+				}
+				forced = append(forced, id)
+			}
+			pending = nil
+		case <-changed:
+		case <-ticker.C:
+		}
+	}
+	return forced, errors.Join(errs...)
+}
@@ -0,0 +1,257 @@
+package sctp_go
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestAssociationTable(t *testing.T) {
+	var (
+		table    associations
+		up       = 0
+		restarts = 0
+		down     = 0
+	)
+	table.setHooks(SCTPAssociationHooks{
+		OnUp:      func(assoc *SCTPAssociation) { up++ },
+		OnDown:    func(assoc *SCTPAssociation) { down++ },
+		OnRestart: func(assoc *SCTPAssociation) { restarts++ },
+	})
+	notify := func(v interface{}) {
+		notification, err := ParseNotification(Pack(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		table.update(notification, nil)
+	}
+
+	notify(&SCTPAssocChange{
+		Type:            SCTP_ASSOC_CHANGE,
+		Length:          SCTPAssocChangeSize,
+		State:           SCTP_COMM_UP,
+		OutboundStreams: 10,
+		InboundStreams:  5,
+		AssocId:         7,
+	})
+	assoc, ok := table.lookup(7)
+	if !ok {
+		t.Fatal("association not tracked after SCTP_COMM_UP")
+	}
+	if assoc.InboundStreams != 5 || assoc.OutboundStreams != 10 || assoc.UpSince.IsZero() {
+		t.Errorf("unexpected association metadata: %+v", assoc)
+	}
+
+	notify(&SCTPAssocChange{
+		Type:    SCTP_ASSOC_CHANGE,
+		Length:  SCTPAssocChangeSize,
+		State:   SCTP_RESTART,
+		AssocId: 7,
+	})
+	notify(&SCTPShutdownEvent{
+		Type:    SCTP_SHUTDOWN_EVENT,
+		Length:  SCTPShutdownEventSize,
+		AssocId: 7,
+	})
+	if assoc, _ := table.lookup(7); assoc.State != SCTP_SHUTDOWN_RECEIVED {
+		t.Errorf("expected state SCTP_SHUTDOWN_RECEIVED, got %d", assoc.State)
+	}
+
+	notify(&SCTPAssocChange{
+		Type:    SCTP_ASSOC_CHANGE,
+		Length:  SCTPAssocChangeSize,
+		State:   SCTP_SHUTDOWN_COMP,
+		AssocId: 7,
+	})
+	if _, ok := table.lookup(7); ok {
+		t.Error("association still tracked after SCTP_SHUTDOWN_COMP")
+	}
+	if up != 1 || restarts != 1 || down != 1 {
+		t.Errorf("unexpected hook counts: up=%d restart=%d down=%d", up, restarts, down)
+	}
+
+	table.sync([]int{1, 2}, func(id int) *SCTPAssociation {
+		return &SCTPAssociation{Id: id}
+	})
+	table.sync([]int{2}, func(id int) *SCTPAssociation {
+		return &SCTPAssociation{Id: id}
+	})
+	if list := table.list(); len(list) != 1 || list[0].Id != 2 {
+		t.Errorf("unexpected associations after sync: %+v", list)
+	}
+	if up != 3 || down != 2 {
+		t.Errorf("unexpected hook counts after sync: up=%d down=%d", up, down)
+	}
+}
+
+func TestAssociationDrain(t *testing.T) {
+	var table associations
+	changed := table.changed()
+	table.drain()
+	notification, err := ParseNotification(Pack(&SCTPSenderDryEvent{
+		Type:    SCTP_SENDER_DRY_EVENT,
+		Length:  SCTPSenderDryEventSize,
+		AssocId: 3,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	table.update(notification, nil)
+	select {
+	case <-changed:
+	default:
+		t.Error("waiters not signalled on update")
+	}
+	if !table.isDrained(3) {
+		t.Error("association not drained after SCTP_SENDER_DRY_EVENT")
+	}
+	if table.isDrained(4) {
+		t.Error("unexpected drained association")
+	}
+}
+
+func TestAssociationResolveUnlocked(t *testing.T) {
+	var table associations
+	notification, err := ParseNotification(Pack(&SCTPAssocChange{
+		Type:    SCTP_ASSOC_CHANGE,
+		Length:  SCTPAssocChangeSize,
+		State:   SCTP_COMM_UP,
+		AssocId: 9,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer := &SCTPAddr{}
+	// The callbacks read the table, they would deadlock if called with the lock held.
+	table.update(notification, func(id int) *SCTPAddr {
+		table.list()
+		return peer
+	})
+	if assoc, ok := table.lookup(9); !ok || assoc.PeerAddr != peer {
+		t.Errorf("unexpected association: %+v", assoc)
+	}
+	table.sync([]int{9, 10}, func(id int) *SCTPAssociation {
+		table.lookup(id)
+		return &SCTPAssociation{Id: id}
+	})
+	if _, ok := table.lookup(10); !ok {
+		t.Error("association not discovered by sync")
+	}
+}
+
+// fakeSocket stands in for the listener socket in the shutdown tests.
+type fakeSocket struct {
+	mutex        sync.Mutex
+	assocs       []int
+	disconnected []int
+	aborted      []int
+	// fail holds the errors of disconnect by association and of abort by negated association.
+	fail map[int]error
+}
+
+func (s *fakeSocket) ops() shutdownOps {
+	return shutdownOps{
+		list: func() ([]int, error) {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			return slices.Clone(s.assocs), nil
+		},
+		disconnect: func(assoc int) error {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			if err := s.fail[assoc]; err != nil {
+				return err
+			}
+			s.disconnected = append(s.disconnected, assoc)
+			return nil
+		},
+		abort: func(assoc int) error {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			s.aborted = append(s.aborted, assoc)
+			return s.fail[-assoc]
+		},
+	}
+}
+
+// disconnectedCount returns the number of associations shut down.
+func (s *fakeSocket) disconnectedCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.disconnected)
+}
+
+// vanish removes the association from the socket.
+func (s *fakeSocket) vanish(assoc int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.assocs = slices.DeleteFunc(s.assocs, func(id int) bool { return id == assoc })
+}
+
+func TestAssociationShutdown(t *testing.T) {
+	var table associations
+	socket := &fakeSocket{assocs: []int{1, 2, 3}}
+	go func() {
+		// 1 completes shutdown, 2 reports SENDER_DRY and 3 leaves the socket.
+		for socket.disconnectedCount() < 3 {
+			time.Sleep(time.Millisecond)
+		}
+		for _, v := range []interface{}{
+			&SCTPAssocChange{Type: SCTP_ASSOC_CHANGE, Length: SCTPAssocChangeSize, State: SCTP_SHUTDOWN_COMP, AssocId: 1},
+			&SCTPSenderDryEvent{Type: SCTP_SENDER_DRY_EVENT, Length: SCTPSenderDryEventSize, AssocId: 2},
+		} {
+			notification, err := ParseNotification(Pack(v))
+			if err != nil {
+				panic(err)
+			}
+			table.update(notification, nil)
+		}
+		socket.vanish(3)
+	}()
+	forced, err := table.shutdown(context.Background(), socket.ops())
+	if err != nil || len(forced) != 0 {
+		t.Fatalf("forced %v: %v", forced, err)
+	}
+	if len(socket.disconnected) != 3 || len(socket.aborted) != 0 {
+		t.Errorf("disconnected %v, aborted %v", socket.disconnected, socket.aborted)
+	}
+}
+
+func TestAssociationShutdownDeadline(t *testing.T) {
+	var table associations
+	socket := &fakeSocket{
+		assocs: []int{1, 2, 3},
+		fail:   map[int]error{1: syscall.EPIPE, -3: syscall.EBADF},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	forced, err := table.shutdown(ctx, socket.ops())
+	slices.Sort(forced)
+	slices.Sort(socket.aborted)
+	if !slices.Equal(forced, []int{2, 3}) || !slices.Equal(socket.aborted, forced) {
+		t.Errorf("forced %v, aborted %v", forced, socket.aborted)
+	}
+	// The association failing to shut down is not waited for, both failures are returned.
+	if !errors.Is(err, syscall.EPIPE) || !errors.Is(err, syscall.EBADF) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAssociationShutdownFallback(t *testing.T) {
+	var table associations
+	table.sync([]int{4}, func(id int) *SCTPAssociation { return &SCTPAssociation{Id: id} })
+	socket := &fakeSocket{}
+	ops := socket.ops()
+	ops.list = func() ([]int, error) { return nil, syscall.ENOPROTOOPT }
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	// Without the list of the kernel the associations of the table are shut down.
+	forced, err := table.shutdown(ctx, ops)
+	if !slices.Equal(socket.disconnected, []int{4}) || !slices.Equal(forced, []int{4}) || err != nil {
+		t.Errorf("disconnected %v, forced %v: %v", socket.disconnected, forced, err)
+	}
+}
@@ -0,0 +1,165 @@
+package sctp_go
+
+import (
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Message represents a single SCTP message of a batched send or receive.
+type Message struct {
+	// Buffer holds the payload to send or the buffer to receive into.
+	Buffer []byte
+	// Info holds the send parameters, or receives the message information when non nil.
+	Info *SCTPSndRcvInfo
+	// N is the number of bytes sent or received.
+	N int
+	// Flags holds the flags of the received message.
+	Flags int
+	// Err holds the error of the message that failed the batch.
+	Err error
+}
+
+// MultiMsgHeader represents the C struct mmsghdr for batched socket messages.
+type MultiMsgHeader struct {
+	Header unix.Msghdr
+	Len    uint32
+}
+
+// batch holds the preallocated headers, iovecs and control buffers of a batched call.
+type batch struct {
+	headers []MultiMsgHeader
+	iovecs  []unix.Iovec
+	control []byte
+}
+
+var batches = sync.Pool{
+	New: func() interface{} {
+		return &batch{}
+	},
+}
+
+// reset drops the references of the headers and iovecs to the buffers of the messages,
+// so that a pooled batch does not keep them reachable.
+func (b *batch) reset() {
+	clear(b.headers)
+	clear(b.iovecs)
+}
+
+// release resets the batch and returns it to the pool.
+func (b *batch) release() {
+	b.reset()
+	batches.Put(b)
+}
+
+// prepare fills the headers, iovecs and control buffers for the messages. When send is
+// set the SCTP_SNDRCV control message is encoded from Info, otherwise room is reserved
+// to receive it.
+func (b *batch) prepare(msgs []Message, send bool) {
+	var (
+		count = len(msgs)
+		space = unix.CmsgSpace(SCTPSndRcvInfoSize)
+	)
+	if cap(b.headers) < count {
+		b.headers = make([]MultiMsgHeader, count)
+		b.iovecs = make([]unix.Iovec, count)
+		b.control = make([]byte, count*space)
+	}
+	b.headers = b.headers[:count]
+	b.iovecs = b.iovecs[:count]
+	b.control = b.control[:count*space]
+	for i := range msgs {
+		var (
+			msg     = &msgs[i]
+			header  = &b.headers[i]
+			iov     = &b.iovecs[i]
+			control = b.control[i*space : (i+1)*space]
+		)
+		*header = MultiMsgHeader{}
+		*iov = unix.Iovec{}
+		if len(msg.Buffer) > 0 {
+			iov.Base = &msg.Buffer[0]
+			iov.SetLen(len(msg.Buffer))
+		}
+		header.Header.Iov = iov
+		header.Header.SetIovlen(1)
+		if send && msg.Info == nil {
+			continue
+		}
+		if send {
+			data := putCmsg(control, syscall.IPPROTO_SCTP, SCTP_SNDRCV, SCTPSndRcvInfoSize)
+			*(*SCTPSndRcvInfo)(unsafe.Pointer(&data[0])) = *msg.Info
+		}
+		header.Header.Control = &control[0]
+		header.Header.SetControllen(space)
+	}
+}
+
+// SCTPSendBatch sends the messages over the SCTP socket using sendmmsg. It returns the
+// number of messages sent, on failure the error is also recorded on the failing message.
+func SCTPSendBatch(sock int, msgs []Message) (int, error) {
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+	b := batches.Get().(*batch)
+	defer b.release()
+	b.prepare(msgs, true)
+	sent := 0
+	for sent < len(msgs) {
+		n, err := sendmmsg(sock, b.headers[sent:], 0)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			msgs[sent].Err = err
+			return sent, err
+		}
+		if n == 0 {
+			msgs[sent].Err = syscall.EAGAIN
+			return sent, syscall.EAGAIN
+		}
+		for i := sent; i < sent+n; i++ {
+			msgs[i].N = int(b.headers[i].Len)
+			msgs[i].Err = nil
+		}
+		sent += n
+	}
+	return sent, nil
+}
+
+// SCTPRecvBatch receives up to len(msgs) messages from the SCTP socket using recvmmsg.
+// It blocks until at least one message is available and returns the number of messages
+// received.
+func SCTPRecvBatch(sock int, msgs []Message, flags int) (int, error) {
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+	b := batches.Get().(*batch)
+	defer b.release()
+	b.prepare(msgs, false)
+	for {
+		n, err := recvmmsg(sock, b.headers, flags|unix.MSG_WAITFORONE)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			msgs[0].Err = err
+			return 0, err
+		}
+		for i := 0; i < n; i++ {
+			var (
+				msg    = &msgs[i]
+				header = &b.headers[i]
+			)
+			msg.N = int(header.Len)
+			msg.Flags = int(header.Header.Flags)
+			msg.Err = nil
+			if header.Header.Controllen > 0 {
+				ParseSndRcvInfo(msg.Info, unsafe.Slice(header.Header.Control, header.Header.Controllen))
+			}
+		}
+		return n, nil
+	}
+}
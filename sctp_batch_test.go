@@ -0,0 +1,286 @@
+package sctp_go
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+func TestBatchPrepare(t *testing.T) {
+	var (
+		b    batch
+		msgs = []Message{
+			{Buffer: []byte("first"), Info: &SCTPSndRcvInfo{Stream: 1, Ppid: 60, AssocId: 5}},
+			{Buffer: []byte("second")},
+		}
+	)
+	b.prepare(msgs, true)
+	if len(b.headers) != 2 {
+		t.Fatalf("expected 2 headers, got %d", len(b.headers))
+	}
+	if b.headers[1].Header.Control != nil {
+		t.Error("unexpected control data for message without info")
+	}
+	header := &b.headers[0].Header
+	info := &SCTPSndRcvInfo{}
+	ParseSndRcvInfo(info, unsafe.Slice(header.Control, header.Controllen))
+	if *info != *msgs[0].Info {
+		t.Errorf("unexpected info: %+v", info)
+	}
+	b.prepare(msgs[:1], false)
+	if int(b.headers[0].Header.Controllen) != syscall.CmsgSpace(SCTPSndRcvInfoSize) {
+		t.Error("receive control buffer not reserved")
+	}
+	b.prepare(msgs, true)
+	b.reset()
+	for i := range b.headers {
+		if b.iovecs[i].Base != nil || b.headers[i].Header.Iov != nil || b.headers[i].Header.Control != nil {
+			t.Errorf("batch %d still references the message buffers", i)
+		}
+	}
+}
+
+// connect creates a connected one-to-many listener and client, skipping when SCTP is
+// unavailable.
+func connect(tb testing.TB) (*SCTPListener, *SCTPConn) {
+	addr, err := MakeSCTPAddr("sctp4", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	init := &SCTPInitMsg{NumOutStreams: 10, MaxInStreams: 10}
+	server, err := ListenSCTP("sctp4", syscall.SOCK_SEQPACKET, addr, init)
+	if err != nil {
+		tb.Skip("sctp not available: ", err)
+	}
+	remote, _ := server.Addr().(*SCTPAddr)
+	client, err := DialSCTP("sctp4", nil, remote, init)
+	if err != nil {
+		server.Close()
+		tb.Skip("sctp not available: ", err)
+	}
+	return server, client
+}
+
+// pair creates a connected one-to-many listener and client, the listener discards what it
+// receives.
+func pair(b *testing.B) (*SCTPListener, *SCTPConn) {
+	server, client := connect(b)
+	go func() {
+		msgs := make([]Message, 64)
+		for i := range msgs {
+			msgs[i].Buffer = make([]byte, 512)
+		}
+		for {
+			if _, err := server.RecvBatch(msgs); err != nil {
+				return
+			}
+		}
+	}()
+	return server, client
+}
+
+func BenchmarkSendMsg(b *testing.B) {
+	server, client := pair(b)
+	defer server.Close()
+	defer client.Close()
+	var (
+		data = make([]byte, 64)
+		info = &SCTPSndRcvInfo{Ppid: 60}
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.SendMsg(data, info); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSendBatch(b *testing.B) {
+	server, client := pair(b)
+	defer server.Close()
+	defer client.Close()
+	var (
+		data = make([]byte, 64)
+		info = &SCTPSndRcvInfo{Ppid: 60}
+		msgs = make([]Message, 32)
+	)
+	for i := range msgs {
+		msgs[i].Buffer = data
+		msgs[i].Info = info
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i += len(msgs) {
+		if _, err := client.SendBatch(msgs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// received is a message as returned by RecvMsg or RecvBatch.
+type received struct {
+	data  []byte
+	info  SCTPSndRcvInfo
+	flags int
+}
+
+// recvEach receives count messages with RecvMsg into buffers of size.
+func recvEach(t *testing.T, recv func([]byte, *SCTPSndRcvInfo, *int) (int, error), count, size int) []received {
+	t.Helper()
+	messages := make([]received, count)
+	for i := range messages {
+		b := make([]byte, size)
+		n, err := recv(b, &messages[i].info, &messages[i].flags)
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages[i].data = b[:n]
+	}
+	return messages
+}
+
+// recvBatched receives count messages with RecvBatch into buffers of size.
+func recvBatched(t *testing.T, recv func([]Message) (int, error), count, size int) []received {
+	t.Helper()
+	var messages []received
+	for len(messages) < count {
+		msgs := make([]Message, count-len(messages))
+		for i := range msgs {
+			msgs[i].Buffer = make([]byte, size)
+			msgs[i].Info = &SCTPSndRcvInfo{}
+		}
+		n, err := recv(msgs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, msg := range msgs[:n] {
+			messages = append(messages, received{data: msg.Buffer[:msg.N], info: *msg.Info, flags: msg.Flags})
+		}
+	}
+	return messages
+}
+
+// compareReceived reports the messages of batch that differ from those of each, the
+// sequence numbers of info differ between the two rounds of sends.
+func compareReceived(t *testing.T, each, batch []received) {
+	t.Helper()
+	for i := range each {
+		e, b := each[i], batch[i]
+		e.info.Ssn, e.info.Tsn, e.info.CumTsn = 0, 0, 0
+		b.info.Ssn, b.info.Tsn, b.info.CumTsn = 0, 0, 0
+		if !bytes.Equal(e.data, b.data) || e.info != b.info || e.flags != b.flags {
+			t.Errorf("message %d: RecvMsg %q %+v flags %#x, RecvBatch %q %+v flags %#x",
+				i, e.data, e.info, e.flags, b.data, b.info, b.flags)
+		}
+	}
+}
+
+func TestRecvBatchMatchesRecvMsg(t *testing.T) {
+	conn, peer := socketpair(t)
+	payloads := [][]byte{[]byte("a"), []byte("second message"), bytes.Repeat([]byte("x"), 100), []byte("last")}
+	send := func() {
+		for _, payload := range payloads {
+			if _, err := syscall.Write(peer, payload); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// The third message is truncated to the buffers of 32 bytes.
+	send()
+	each := recvEach(t, conn.RecvMsg, len(payloads), 32)
+	send()
+	batch := recvBatched(t, conn.RecvBatch, len(payloads), 32)
+	compareReceived(t, each, batch)
+	if each[2].flags&syscall.MSG_TRUNC == 0 || len(each[2].data) != 32 {
+		t.Errorf("truncated message of %d bytes, flags %#x", len(each[2].data), each[2].flags)
+	}
+}
+
+func TestRecvBatchMatchesRecvMsgSCTP(t *testing.T) {
+	server, client := connect(t)
+	defer server.Close()
+	defer client.Close()
+	infos := []*SCTPSndRcvInfo{
+		{Stream: 0, Ppid: HostToNetwork(18)},
+		{Stream: 3, Ppid: HostToNetwork(60)},
+		{Stream: 1, Flags: SCTP_UNORDERED},
+	}
+	send := func() {
+		for i, info := range infos {
+			if _, err := client.SendMsg(bytes.Repeat([]byte{byte(i)}, 10*(i+1)), info); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	send()
+	each := recvEach(t, server.RecvMsg, len(infos), 512)
+	send()
+	batch := recvBatched(t, server.RecvBatch, len(infos), 512)
+	compareReceived(t, each, batch)
+	for i, message := range each {
+		if message.info.Stream != infos[i].Stream || message.info.Ppid != infos[i].Ppid || message.flags&syscall.MSG_EOR == 0 {
+			t.Errorf("message %d: info %+v, flags %#x", i, message.info, message.flags)
+		}
+	}
+}
+
+// sendCount sends count messages of 64 bytes in batches from client.
+func sendCount(b *testing.B, client *SCTPConn, count int) {
+	var (
+		info = &SCTPSndRcvInfo{Ppid: 60}
+		msgs = make([]Message, 32)
+	)
+	for i := range msgs {
+		msgs[i].Buffer = make([]byte, 64)
+		msgs[i].Info = info
+	}
+	for sent := 0; sent < count; {
+		n, err := client.SendBatch(msgs[:min(len(msgs), count-sent)])
+		if err != nil {
+			b.Error(err)
+			return
+		}
+		sent += n
+	}
+}
+
+func BenchmarkRecvMsg(b *testing.B) {
+	server, client := connect(b)
+	defer server.Close()
+	defer client.Close()
+	var (
+		data = make([]byte, 512)
+		info SCTPSndRcvInfo
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	go sendCount(b, client, b.N)
+	for i := 0; i < b.N; i++ {
+		if _, err := server.RecvMsg(data, &info, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRecvBatch(b *testing.B) {
+	server, client := connect(b)
+	defer server.Close()
+	defer client.Close()
+	msgs := make([]Message, 32)
+	for i := range msgs {
+		msgs[i].Buffer = make([]byte, 512)
+		msgs[i].Info = &SCTPSndRcvInfo{}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	go sendCount(b, client, b.N)
+	for i := 0; i < b.N; {
+		n, err := server.RecvBatch(msgs[:min(len(msgs), b.N-i)])
+		if err != nil {
+			b.Fatal(err)
+		}
+		i += n
+	}
+}
@@ -0,0 +1,84 @@
+package sctp_go
+
+import (
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// controlBuffer is an aligned buffer large enough for a SCTP_SNDRCV control message.
+type controlBuffer [(CMsgHeaderSize + SCTPSndRcvInfoSize + 7) / 8]uint64
+
+func (c *controlBuffer) bytes() []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(&c[0])), len(c)*8)
+}
+
+// EncodeSndRcvInfo encodes a SCTP_SNDRCV control message carrying info into buffer and returns
+// the number of bytes written, or 0 when buffer is too small. The buffer should be word aligned.
+func EncodeSndRcvInfo(buffer []byte, info *SCTPSndRcvInfo) int {
+	space := unix.CmsgSpace(SCTPSndRcvInfoSize)
+	if info == nil || len(buffer) < space {
+		return 0
+	}
+	data := putCmsg(buffer, unix.IPPROTO_SCTP, SCTP_SNDRCV, SCTPSndRcvInfoSize)
+	*(*SCTPSndRcvInfo)(unsafe.Pointer(&data[0])) = *info
+	return space
+}
+
+// EncodeSndInfo encodes a SCTP_SNDINFO control message carrying info into buffer and returns
+// the number of bytes written, or 0 when buffer is too small. The buffer should be word aligned.
+func EncodeSndInfo(buffer []byte, info *SCTPSndInfo) int {
+	space := unix.CmsgSpace(SCTPSndInfoSize)
+	if info == nil || len(buffer) < space {
+		return 0
+	}
+	data := putCmsg(buffer, unix.IPPROTO_SCTP, SCTP_SNDINFO, SCTPSndInfoSize)
+	*(*SCTPSndInfo)(unsafe.Pointer(&data[0])) = *info
+	return space
+}
+
+// BufferPool is a sync.Pool backed pool of fixed size message buffers.
+type BufferPool struct {
+	size int
+	pool sync.Pool
+}
+
+// NewBufferPool creates a pool of message buffers of the given size.
+func NewBufferPool(size int) *BufferPool {
+	p := &BufferPool{
+		size: size,
+	}
+	p.pool.New = func() interface{} {
+		return &Buffer{
+			data: make([]byte, size),
+			pool: p,
+		}
+	}
+	return p
+}
+
+// Get returns a buffer from the pool, Data spans the whole buffer.
+func (p *BufferPool) Get() *Buffer {
+	buffer := p.pool.Get().(*Buffer)
+	buffer.Data = buffer.data
+	return buffer
+}
+
+// Size returns the size of the buffers of the pool.
+func (p *BufferPool) Size() int {
+	return p.size
+}
+
+// Buffer is a message buffer owned by a BufferPool.
+type Buffer struct {
+	Data []byte
+	data []byte
+	pool *BufferPool
+}
+
+// Release returns the buffer to its pool, it must not be used afterwards.
+func (b *Buffer) Release() {
+	b.Data = nil
+	b.pool.pool.Put(b)
+}
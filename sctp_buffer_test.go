@@ -0,0 +1,108 @@
+package sctp_go
+
+import (
+	"syscall"
+	"testing"
+)
+
+// socketpair returns a connected message oriented socket pair standing in for an SCTP
+// association, the unix socket layer ignores the SCTP control messages.
+func socketpair(t *testing.T) (*SCTPConn, int) {
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_SEQPACKET, 0)
+	if err != nil {
+		t.Skip("socketpair not available: ", err)
+	}
+	t.Cleanup(func() {
+		syscall.Close(fds[0])
+		syscall.Close(fds[1])
+	})
+	return NewSCTPConn(fds[0]), fds[1]
+}
+
+func TestEncodeSndRcvInfo(t *testing.T) {
+	var (
+		control controlBuffer
+		buffer  = control.bytes()
+		info    = &SCTPSndRcvInfo{Stream: 3, Ppid: 46, AssocId: 9}
+		parsed  SCTPSndRcvInfo
+	)
+	n := EncodeSndRcvInfo(buffer, info)
+	if n != syscall.CmsgSpace(SCTPSndRcvInfoSize) {
+		t.Fatalf("unexpected encoded length %d", n)
+	}
+	ParseSndRcvInfo(&parsed, buffer[:n])
+	if parsed != *info {
+		t.Errorf("unexpected info: %+v", parsed)
+	}
+	if EncodeSndRcvInfo(buffer[:n-1], info) != 0 {
+		t.Error("encoded into a short buffer")
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		ParseSndRcvInfo(&parsed, buffer[:EncodeSndRcvInfo(buffer, info)])
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocs, got %v", allocs)
+	}
+}
+
+func TestEncodeSndInfo(t *testing.T) {
+	var (
+		control controlBuffer
+		buffer  = control.bytes()
+	)
+	n := EncodeSndInfo(buffer, &SCTPSndInfo{Sid: 1, Ppid: 60})
+	if payload := FindCmsg(buffer[:n], IPPROTO_SCTP, SCTP_SNDINFO); len(payload) != SCTPSndInfoSize {
+		t.Errorf("SCTP_SNDINFO not found, got %d bytes", len(payload))
+	}
+}
+
+func TestRecvMsgAllocs(t *testing.T) {
+	conn, peer := socketpair(t)
+	var (
+		data    = []byte("message")
+		buffer  = make([]byte, 64)
+		info    SCTPSndRcvInfo
+		flags   int
+		control = &SCTPSndRcvInfo{Ppid: 60}
+	)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := conn.SendMsg(data, control); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := syscall.Read(peer, buffer); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := syscall.Write(peer, data); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := conn.RecvMsg(buffer, &info, &flags); err != nil || n != len(data) {
+			t.Fatal(n, err)
+		}
+	})
+	if allocs != 0 && !raceEnabled {
+		t.Errorf("expected 0 allocs per message, got %v", allocs)
+	}
+}
+
+func TestRecvBuffer(t *testing.T) {
+	conn, peer := socketpair(t)
+	var (
+		pool  = NewBufferPool(128)
+		info  SCTPSndRcvInfo
+		flags int
+	)
+	pool.Get().Release()
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := syscall.Write(peer, []byte("message")); err != nil {
+			t.Fatal(err)
+		}
+		buffer, err := conn.RecvBuffer(pool, &info, &flags)
+		if err != nil || string(buffer.Data) != "message" {
+			t.Fatal(err)
+		}
+		buffer.Release()
+	})
+	if allocs != 0 && !raceEnabled {
+		t.Errorf("expected 0 allocs per message, got %v", allocs)
+	}
+}
@@ -1,7 +1,9 @@
 package sctp_go
 
 import (
+	"fmt"
 	"net"
+	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
@@ -10,20 +12,36 @@ import (
 
 // SCTPConn represents an SCTP connection.
 type SCTPConn struct {
-	sock  int64
-	assoc int
+	fd       descriptor
+	network  string
+	assoc    int
+	rmutex   sync.Mutex
+	rcontrol controlBuffer
+	wmutex   sync.Mutex
+	wcontrol controlBuffer
+	nonblock atomic.Bool
+	qmutex   sync.Mutex
+	out      outbound
+	capture  atomic.Pointer[captureHook]
+	addrs    atomic.Pointer[errorAddrs]
+}
+
+// errorAddrs holds the addresses reported in the errors of a connection.
+type errorAddrs struct {
+	local  net.Addr
+	remote net.Addr
 }
 
 // NewSCTPConn creates a new SCTPConn from a socket file descriptor.
 func NewSCTPConn(sock int) *SCTPConn {
-	return &SCTPConn{
-		sock: int64(sock),
-	}
+	conn := &SCTPConn{}
+	conn.fd.init(sock)
+	return conn
 }
 
 // FD returns the socket file descriptor.
 func (conn *SCTPConn) FD() int64 {
-	return conn.sock
+	return int64(conn.fd.sysfd())
 }
 
 // AssocId returns the association ID.
@@ -33,21 +51,18 @@ func (conn *SCTPConn) AssocId() int {
 
 // GetPrimaryPeerAddr returns the primary peer address for the association.
 func (conn *SCTPConn) GetPrimaryPeerAddr() (*SCTPAddr, error) {
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return nil, conn.wrap("get", "", err)
+	}
+	defer conn.fd.release()
 	param := SCTPPrimaryAddr{
 		AssocId: int32(conn.assoc),
 	}
-	length := unsafe.Sizeof(param)
-	_, _, err := syscall.Syscall6(
-		syscall.SYS_GETSOCKOPT,
-		uintptr(conn.sock),
-		syscall.IPPROTO_SCTP,
-		SCTP_PRIMARY_ADDR,
-		uintptr(unsafe.Pointer(&param)),
-		uintptr(unsafe.Pointer(&length)),
-		0,
-	)
-	if err != 0 {
-		return nil, err
+	length := uint32(unsafe.Sizeof(param))
+	err = getsockopt(sock, syscall.IPPROTO_SCTP, SCTP_PRIMARY_ADDR, unsafe.Pointer(&param), &length)
+	if err != nil {
+		return nil, conn.wrap("get", "getsockopt", err)
 	}
 	addr := FromSockAddrStorage((*SockAddrStorage)(unsafe.Pointer(&param.Addr)))
 	return addr, nil
@@ -55,16 +70,13 @@ func (conn *SCTPConn) GetPrimaryPeerAddr() (*SCTPAddr, error) {
 
 // Read reads data from the connection, skipping notifications.
 func (conn *SCTPConn) Read(b []byte) (n int, err error) {
-	if !conn.ok() {
-		return 0, syscall.EINVAL
-	}
 	var (
 		flags = 0
-		info  = &SCTPSndRcvInfo{}
+		info  SCTPSndRcvInfo
 	)
 	for {
-		n, err = conn.RecvMsg(b, info, &flags)
-		if flags&SCTP_MSG_NOTIFICATION == 0 {
+		n, err = conn.RecvMsg(b, &info, &flags)
+		if err != nil || flags&SCTP_MSG_NOTIFICATION == 0 {
 			return n, err
 		}
 	}
@@ -72,21 +84,65 @@ func (conn *SCTPConn) Read(b []byte) (n int, err error) {
 
 // RecvMsg receives a message from the connection.
 func (conn *SCTPConn) RecvMsg(b []byte, info *SCTPSndRcvInfo, flags *int) (n int, err error) {
-	if !conn.ok() {
-		return 0, syscall.EINVAL
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return 0, conn.wrap("read", "", err)
+	}
+	defer conn.fd.release()
+	conn.rmutex.Lock()
+	defer conn.rmutex.Unlock()
+	oob := conn.rcontrol.bytes()
+	n, noob, flag, err := SCTPRecvMsg(sock, b, oob, 0)
+	if err = conn.closed(n, err); err != nil {
+		return 0, conn.wrap("read", "recvmsg", err)
+	}
+	*flags = flag
+	if noob > 0 {
+		ParseSndRcvInfo(info, oob[:noob])
 	}
-	oob := make([]byte, syscall.CmsgSpace(SCTPSndRcvInfoSize))
-	n, noob, flag, _, err := syscall.Recvmsg(int(conn.sock), b, oob, 0)
+	if flag&SCTP_MSG_NOTIFICATION == 0 {
+		conn.captured(false, info, b[:n])
+	}
+	return n, nil
+}
+
+// RecvMsgv receives a message from the connection, scattering it over the buffers in order.
+func (conn *SCTPConn) RecvMsgv(buffers net.Buffers, info *SCTPSndRcvInfo, flags *int) (n int, err error) {
+	sock, err := conn.fd.acquire()
 	if err != nil {
-		return n, err
+		return 0, conn.wrap("read", "", err)
+	}
+	defer conn.fd.release()
+	conn.rmutex.Lock()
+	defer conn.rmutex.Unlock()
+	oob := conn.rcontrol.bytes()
+	n, noob, flag, err := SCTPRecvMsgv(sock, buffers, oob, 0)
+	if err = conn.closed(n, err); err != nil {
+		return 0, conn.wrap("read", "recvmsg", err)
 	}
 	*flags = flag
 	if noob > 0 {
 		ParseSndRcvInfo(info, oob[:noob])
 	}
+	if flag&SCTP_MSG_NOTIFICATION == 0 && conn.capture.Load() != nil {
+		conn.captured(false, info, gather(buffers, n))
+	}
 	return n, nil
 }
 
+// RecvBuffer receives a message from the connection into a buffer taken from pool. The
+// returned buffer holds the message in Data and must be released by the caller.
+func (conn *SCTPConn) RecvBuffer(pool *BufferPool, info *SCTPSndRcvInfo, flags *int) (*Buffer, error) {
+	buffer := pool.Get()
+	n, err := conn.RecvMsg(buffer.Data, info, flags)
+	if err != nil {
+		buffer.Release()
+		return nil, err
+	}
+	buffer.Data = buffer.Data[:n]
+	return buffer, nil
+}
+
 // Write writes data to the connection.
 func (conn *SCTPConn) Write(b []byte) (n int, err error) {
 	return conn.SendMsg(b, nil)
@@ -94,109 +150,212 @@ func (conn *SCTPConn) Write(b []byte) (n int, err error) {
 
 // SendMsg sends a message on the connection.
 func (conn *SCTPConn) SendMsg(b []byte, info *SCTPSndRcvInfo) (int, error) {
-	if !conn.ok() {
-		return 0, syscall.EINVAL
-	}
-	var buffer []byte
-	if info != nil {
-		hdr := &syscall.Cmsghdr{
-			Level: syscall.IPPROTO_SCTP,
-			Type:  SCTP_SNDRCV,
-			Len:   uint64(syscall.CmsgSpace(SCTPSndRcvInfoSize)),
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return 0, conn.wrap("write", "", err)
+	}
+	defer conn.fd.release()
+	var n int
+	if info == nil {
+		n, err = SCTPSendMsg(sock, b, nil, 0)
+	} else {
+		conn.wmutex.Lock()
+		control := conn.wcontrol.bytes()
+		n, err = SCTPSendMsg(sock, b, control[:EncodeSndRcvInfo(control, info)], 0)
+		conn.wmutex.Unlock()
+	}
+	if err == nil {
+		conn.captured(true, info, b[:n])
+	}
+	return n, conn.wrap("write", "sendmsg", err)
+}
+
+// SendMsgv sends the buffers as a single message on the connection without copying them,
+// e.g. a protocol header followed by its payload.
+func (conn *SCTPConn) SendMsgv(buffers net.Buffers, info *SCTPSndRcvInfo) (int, error) {
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return 0, conn.wrap("write", "", err)
+	}
+	defer conn.fd.release()
+	var n int
+	if info == nil {
+		n, err = SCTPSendMsgv(sock, buffers, nil, 0)
+	} else {
+		conn.wmutex.Lock()
+		control := conn.wcontrol.bytes()
+		n, err = SCTPSendMsgv(sock, buffers, control[:EncodeSndRcvInfo(control, info)], 0)
+		conn.wmutex.Unlock()
+	}
+	if err == nil && conn.capture.Load() != nil {
+		conn.captured(true, info, gather(buffers, n))
+	}
+	return n, conn.wrap("write", "sendmsg", err)
+}
+
+// SendBatch sends the messages on the connection with a single sendmmsg call where possible.
+// It returns the number of messages sent.
+func (conn *SCTPConn) SendBatch(msgs []Message) (int, error) {
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return 0, conn.wrap("write", "", err)
+	}
+	defer conn.fd.release()
+	n, err := SCTPSendBatch(sock, msgs)
+	for i := 0; i < n; i++ {
+		conn.captured(true, msgs[i].Info, msgs[i].Buffer[:msgs[i].N])
+	}
+	return n, conn.wrap("write", "sendmmsg", err)
+}
+
+// RecvBatch receives up to len(msgs) messages from the connection with a single recvmmsg call.
+// It returns the number of messages received.
+func (conn *SCTPConn) RecvBatch(msgs []Message) (int, error) {
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return 0, conn.wrap("read", "", err)
+	}
+	defer conn.fd.release()
+	n, err := SCTPRecvBatch(sock, msgs, 0)
+	if n > 0 && msgs[0].N == 0 {
+		if err = conn.closed(0, err); err != nil {
+			msgs[0].Err = err
+			n = 0
+		}
+	}
+	for i := 0; i < n; i++ {
+		if msgs[i].Flags&SCTP_MSG_NOTIFICATION == 0 {
+			conn.captured(false, msgs[i].Info, msgs[i].Buffer[:msgs[i].N])
 		}
-		buffer = append(Pack(hdr), Pack(info)...)
 	}
-	// return syscall.SendmsgN(int(conn.sock), b, buffer, nil, 0)
-	return SCTPSendMsg(int(conn.sock), b, buffer, 0)
+	return n, conn.wrap("read", "recvmmsg", err)
+}
+
+// closed returns net.ErrClosed for a receive that got nothing because the connection is
+// closing: the shutdown of Close ends a blocked receive without an error.
+func (conn *SCTPConn) closed(n int, err error) error {
+	if err == nil && n == 0 && conn.fd.closing() {
+		return net.ErrClosed
+	}
+	return err
+}
+
+// SendAsync submits the message to engine, h is invoked from the engine loop once it is sent.
+// b must not be modified until then.
+func (conn *SCTPConn) SendAsync(engine Engine, b []byte, info *SCTPSndRcvInfo, h SendHandler) error {
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return conn.wrap("write", "", err)
+	}
+	// The reference is held until the handler is invoked.
+	err = engine.SendMsg(sock, b, info, conn.fd.holdSend(conn.captureSend(b, info, h)))
+	if err != nil {
+		conn.fd.release()
+	}
+	return conn.wrap("write", "submit", err)
 }
 
-// Abort aborts the SCTP association.
+// RecvAsync submits a receive into b to engine, h is invoked from the engine loop with the message.
+func (conn *SCTPConn) RecvAsync(engine Engine, b []byte, h RecvHandler) error {
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return conn.wrap("read", "", err)
+	}
+	err = engine.RecvMsg(sock, b, conn.fd.holdRecv(conn.captureRecv(h), false))
+	if err != nil {
+		conn.fd.release()
+	}
+	return conn.wrap("read", "submit", err)
+}
+
+// RecvMultishot keeps receiving messages of up to size bytes through engine until the
+// connection fails or the receive is cancelled. b passed to h is only valid during the call.
+func (conn *SCTPConn) RecvMultishot(engine Engine, size int, h RecvHandler) error {
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return conn.wrap("read", "", err)
+	}
+	err = engine.RecvMultishot(sock, size, conn.fd.holdRecv(conn.captureRecv(h), true))
+	if err != nil {
+		conn.fd.release()
+	}
+	return conn.wrap("read", "submit", err)
+}
+
+// Abort aborts the SCTP association by closing the socket with a zero linger time, queued
+// messages are discarded. Blocked receives are woken and fail with net.ErrClosed, the socket
+// is closed, and the association aborted, once the last operation in flight returned.
 func (conn *SCTPConn) Abort() error {
-	sock := atomic.SwapInt64(&conn.sock, -1)
-	if sock > 0 {
+	conn.detach(false)
+	return conn.wrap("close", "close", conn.fd.close(func(sock int) {
 		linger := syscall.Linger{
 			Onoff:  1,
 			Linger: 0,
 		}
-		_, _, _ = syscall.Syscall6(
-			syscall.SYS_SETSOCKOPT,
-			uintptr(sock),
-			syscall.SOL_SOCKET,
-			syscall.SO_LINGER,
-			uintptr(unsafe.Pointer(&linger)),
-			unsafe.Sizeof(linger),
-			0,
-		)
-		return syscall.Close(int(sock))
-	}
-	return syscall.EBADFD
+		_ = setsockopt(sock, syscall.SOL_SOCKET, syscall.SO_LINGER, unsafe.Pointer(&linger), unsafe.Sizeof(linger))
+		_ = syscall.Shutdown(sock, syscall.SHUT_RD)
+	}))
 }
 
-// Close closes the SCTP connection.
+// Close closes the SCTP connection. Operations blocked on the connection are woken by
+// shutting the socket down and fail with net.ErrClosed, the socket is closed once the
+// last of them returned. Messages in the outbound queue are flushed first, as far as the
+// socket accepts them without blocking in non-blocking mode. The connection is closed
+// regardless, an error matching ErrQueueDropped reports the messages left unsent.
 func (conn *SCTPConn) Close() error {
-	if !conn.ok() {
-		return syscall.EINVAL
+	var result error
+	if dropped := conn.detach(true); dropped > 0 {
+		result = conn.wrap("close", "", fmt.Errorf("%w: %d messages", ErrQueueDropped, dropped))
 	}
 	msg := &SCTPSndRcvInfo{
 		Flags: SCTP_EOF,
 	}
 	_, _ = conn.SendMsg(nil, msg)
-	sock := atomic.SwapInt64(&conn.sock, -1)
-	if sock > 0 {
-		_ = syscall.Shutdown(int(sock), syscall.SHUT_RDWR)
-		return syscall.Close(int(sock))
+	err := conn.fd.close(func(sock int) {
+		_ = syscall.Shutdown(sock, syscall.SHUT_RDWR)
+	})
+	if err != nil {
+		return conn.wrap("close", "close", err)
 	}
-	return syscall.EBADFD
+	return result
 }
 
 // LocalAddr returns the local network address.
 func (conn *SCTPConn) LocalAddr() net.Addr {
-	if !conn.ok() {
+	sock, err := conn.fd.acquire()
+	if err != nil {
 		return nil
 	}
+	defer conn.fd.release()
 	var (
-		data   [4096]byte
+		data   [sctpGetAddrsBufferSize]byte
 		addrs  = (*SCTPGetAddrs)(unsafe.Pointer(&data[0]))
-		length = len(data)
+		length = uint32(len(data))
 	)
 	addrs.AssocId = 0
-	_, _, err := syscall.Syscall6(
-		syscall.SYS_GETSOCKOPT,
-		uintptr(conn.sock),
-		syscall.IPPROTO_SCTP,
-		SCTP_GET_LOCAL_ADDRS,
-		uintptr(unsafe.Pointer(addrs)),
-		uintptr(unsafe.Pointer(&length)),
-		0,
-	)
-	if err == 0 {
-		return FromSCTPGetAddrs(addrs)
+	err = getsockopt(sock, syscall.IPPROTO_SCTP, SCTP_GET_LOCAL_ADDRS, unsafe.Pointer(addrs), &length)
+	if err == nil {
+		return ParseSCTPGetAddrs(data[:length]).Addr()
 	}
 	return nil
 }
 
 // RemoteAddr returns the remote network address.
 func (conn *SCTPConn) RemoteAddr() net.Addr {
-	if !conn.ok() {
+	sock, err := conn.fd.acquire()
+	if err != nil {
 		return nil
 	}
+	defer conn.fd.release()
 	var (
-		data   [4096]byte
+		data   [sctpGetAddrsBufferSize]byte
 		addrs  = (*SCTPGetAddrs)(unsafe.Pointer(&data[0]))
-		length = len(data)
+		length = uint32(len(data))
 	)
 	addrs.AssocId = 0
-	_, _, err := syscall.Syscall6(
-		syscall.SYS_GETSOCKOPT,
-		uintptr(conn.sock),
-		syscall.IPPROTO_SCTP,
-		SCTP_GET_PEER_ADDRS,
-		uintptr(unsafe.Pointer(addrs)),
-		uintptr(unsafe.Pointer(&length)),
-		0,
-	)
-	if err == 0 {
-		return FromSCTPGetAddrs(addrs)
+	err = getsockopt(sock, syscall.IPPROTO_SCTP, SCTP_GET_PEER_ADDRS, unsafe.Pointer(addrs), &length)
+	if err == nil {
+		return ParseSCTPGetAddrs(data[:length]).Addr()
 	}
 	return nil
 }
@@ -218,160 +377,148 @@ func (conn *SCTPConn) SetWriteDeadline(_ time.Time) error {
 
 // SetWriteBufferSize sets the size of the send buffer.
 func (conn *SCTPConn) SetWriteBufferSize(bytes int) error {
-	return syscall.SetsockoptInt(int(conn.sock), syscall.SOL_SOCKET, syscall.SO_SNDBUF, bytes)
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return conn.wrap("set", "", err)
+	}
+	defer conn.fd.release()
+	return conn.wrap("set", "setsockopt", syscall.SetsockoptInt(sock, syscall.SOL_SOCKET, syscall.SO_SNDBUF, bytes))
 }
 
 // GetWriteBufferSize gets the size of the send buffer.
 func (conn *SCTPConn) GetWriteBufferSize() (int, error) {
-	return syscall.GetsockoptInt(int(conn.sock), syscall.SOL_SOCKET, syscall.SO_SNDBUF)
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return 0, conn.wrap("get", "", err)
+	}
+	defer conn.fd.release()
+	value, err := syscall.GetsockoptInt(sock, syscall.SOL_SOCKET, syscall.SO_SNDBUF)
+	return value, conn.wrap("get", "getsockopt", err)
 }
 
 // SetReadBufferSize sets the size of the receive buffer.
 func (conn *SCTPConn) SetReadBufferSize(bytes int) error {
-	return syscall.SetsockoptInt(int(conn.sock), syscall.SOL_SOCKET, syscall.SO_RCVBUF, bytes)
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return conn.wrap("set", "", err)
+	}
+	defer conn.fd.release()
+	return conn.wrap("set", "setsockopt", syscall.SetsockoptInt(sock, syscall.SOL_SOCKET, syscall.SO_RCVBUF, bytes))
 }
 
 // GetReadBufferSize gets the size of the receive buffer.
 func (conn *SCTPConn) GetReadBufferSize() (int, error) {
-	return syscall.GetsockoptInt(int(conn.sock), syscall.SOL_SOCKET, syscall.SO_RCVBUF)
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return 0, conn.wrap("get", "", err)
+	}
+	defer conn.fd.release()
+	value, err := syscall.GetsockoptInt(sock, syscall.SOL_SOCKET, syscall.SO_RCVBUF)
+	return value, conn.wrap("get", "getsockopt", err)
 }
 
 // SetEventSubscribe sets the SCTP event subscriptions.
 func (conn *SCTPConn) SetEventSubscribe(events *SCTPEventSubscribe) error {
-	_, _, err := syscall.Syscall6(
-		syscall.SYS_SETSOCKOPT,
-		uintptr(conn.sock),
-		SOL_SCTP,
-		SCTP_EVENTS,
-		uintptr(unsafe.Pointer(events)),
-		unsafe.Sizeof(*events),
-		0,
-	)
-	if err != 0 {
-		return err
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return conn.wrap("set", "", err)
+	}
+	defer conn.fd.release()
+	if events == nil {
+		return conn.wrap("set", "", fmt.Errorf("%w: events", ErrNilArgument))
+	}
+	err = setsockopt(sock, SOL_SCTP, SCTP_EVENTS, unsafe.Pointer(events), unsafe.Sizeof(*events))
+	if err != nil {
+		return conn.wrap("set", "setsockopt", err)
 	}
 	return nil
 }
 
 // GetEventSubscribe gets the current SCTP event subscriptions.
 func (conn *SCTPConn) GetEventSubscribe() (*SCTPEventSubscribe, error) {
-	if !conn.ok() {
-		return nil, syscall.EINVAL
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return nil, conn.wrap("get", "", err)
 	}
+	defer conn.fd.release()
 	var (
 		events SCTPEventSubscribe
-		length = unsafe.Sizeof(events)
+		length = uint32(unsafe.Sizeof(events))
 	)
-	_, _, err := syscall.Syscall6(
-		syscall.SYS_GETSOCKOPT,
-		uintptr(conn.sock),
-		SOL_SCTP,
-		SCTP_EVENTS,
-		uintptr(unsafe.Pointer(&events)),
-		uintptr(unsafe.Pointer(&length)),
-		0,
-	)
-	if err != 0 {
-		return nil, err
+	err = getsockopt(sock, SOL_SCTP, SCTP_EVENTS, unsafe.Pointer(&events), &length)
+	if err != nil {
+		return nil, conn.wrap("get", "getsockopt", err)
 	}
 	return &events, nil
 }
 
 // SetInitMsg sets the SCTP initialization message parameters.
 func (conn *SCTPConn) SetInitMsg(init *SCTPInitMsg) error {
-	if !conn.ok() {
-		return syscall.EINVAL
-	}
-	_, _, err := syscall.Syscall6(
-		syscall.SYS_SETSOCKOPT,
-		uintptr(conn.sock),
-		SOL_SCTP,
-		SCTP_INITMSG,
-		uintptr(unsafe.Pointer(init)),
-		unsafe.Sizeof(*init),
-		0,
-	)
-	if err != 0 {
-		return err
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return conn.wrap("set", "", err)
+	}
+	defer conn.fd.release()
+	if init == nil {
+		return conn.wrap("set", "", fmt.Errorf("%w: init message", ErrNilArgument))
+	}
+	err = setsockopt(sock, SOL_SCTP, SCTP_INITMSG, unsafe.Pointer(init), unsafe.Sizeof(*init))
+	if err != nil {
+		return conn.wrap("set", "setsockopt", err)
 	}
 	return nil
 }
 
 // GetInitMsg gets the current SCTP initialization message parameters.
 func (conn *SCTPConn) GetInitMsg() (*SCTPInitMsg, error) {
-	if !conn.ok() {
-		return nil, syscall.EINVAL
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return nil, conn.wrap("get", "", err)
 	}
+	defer conn.fd.release()
 	var (
 		init   SCTPInitMsg
-		length = unsafe.Sizeof(init)
-	)
-	_, _, err := syscall.Syscall6(
-		syscall.SYS_GETSOCKOPT,
-		uintptr(conn.sock),
-		SOL_SCTP,
-		SCTP_INITMSG,
-		uintptr(unsafe.Pointer(&init)),
-		uintptr(unsafe.Pointer(&length)),
-		0,
+		length = uint32(unsafe.Sizeof(init))
 	)
-	if err != 0 {
-		return nil, err
+	err = getsockopt(sock, SOL_SCTP, SCTP_INITMSG, unsafe.Pointer(&init), &length)
+	if err != nil {
+		return nil, conn.wrap("get", "getsockopt", err)
 	}
 	return &init, nil
 }
 
 // SetDefaultSendParam sets the default send parameters for the association.
 func (conn *SCTPConn) SetDefaultSendParam(param *SCTPSndRcvInfo) error {
-	if !conn.ok() {
-		return syscall.EINVAL
-	}
-	_, _, err := syscall.Syscall6(
-		syscall.SYS_SETSOCKOPT,
-		uintptr(conn.sock),
-		SOL_SCTP,
-		SCTP_DEFAULT_SEND_PARAM,
-		uintptr(unsafe.Pointer(param)),
-		unsafe.Sizeof(*param),
-		0,
-	)
-	if err != 0 {
-		return err
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return conn.wrap("set", "", err)
+	}
+	defer conn.fd.release()
+	err = setsockopt(sock, SOL_SCTP, SCTP_DEFAULT_SEND_PARAM, unsafe.Pointer(param), unsafe.Sizeof(*param))
+	if err != nil {
+		return conn.wrap("set", "setsockopt", err)
 	}
 	return nil
 }
 
 // GetDefaultSendParam gets the default send parameters for the association.
 func (conn *SCTPConn) GetDefaultSendParam() (*SCTPSndRcvInfo, error) {
-	if !conn.ok() {
-		return nil, syscall.EINVAL
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return nil, conn.wrap("get", "", err)
 	}
+	defer conn.fd.release()
 	var (
 		param  SCTPSndRcvInfo
-		length = unsafe.Sizeof(param)
-	)
-	_, _, err := syscall.Syscall6(
-		syscall.SYS_GETSOCKOPT,
-		uintptr(conn.sock),
-		SOL_SCTP,
-		SCTP_DEFAULT_SEND_PARAM,
-		uintptr(unsafe.Pointer(&param)),
-		uintptr(unsafe.Pointer(&length)),
-		0,
+		length = uint32(unsafe.Sizeof(param))
 	)
-	if err != 0 {
-		return nil, err
+	err = getsockopt(sock, SOL_SCTP, SCTP_DEFAULT_SEND_PARAM, unsafe.Pointer(&param), &length)
+	if err != nil {
+		return nil, conn.wrap("get", "getsockopt", err)
 	}
 	return &param, nil
 }
 
-func (conn *SCTPConn) ok() bool {
-	if nil != conn && conn.sock > 0 {
-		return true
-	}
-	return false
-}
-
 // DialSCTP dials an SCTP connection to the remote address.
 func DialSCTP(network string, local, remote *SCTPAddr, init *SCTPInitMsg) (*SCTPConn, error) {
 	switch network {
@@ -394,33 +541,90 @@ func DialSCTP(network string, local, remote *SCTPAddr, init *SCTPInitMsg) (*SCTP
 			Err:    net.InvalidAddrError("invalid remote addr"),
 		}
 	}
+	failure := func(call string, err error) error {
+		if operr, ok := err.(*net.OpError); ok {
+			err = operr.Err
+		}
+		return &net.OpError{
+			Op:     "dial",
+			Net:    network,
+			Source: local.Addr(),
+			Addr:   remote.Addr(),
+			Err:    NewSyscallError(call, err),
+		}
+	}
 	// syscall.SOCK_SEQPACKET vs syscall.SOCK_STREAM
 	sock, err := SCTPSocket(AddrFamily(network), syscall.SOCK_STREAM)
 	if err != nil {
-		return nil, err
-	}
-	conn := &SCTPConn{
-		sock: int64(sock),
+		return nil, failure("socket", err)
 	}
+	conn := NewSCTPConn(sock)
+	conn.network = network
 	defer func() {
 		if err != nil && conn != nil {
 			_ = conn.Close()
 		}
 	}()
 	if err = syscall.SetsockoptInt(sock, syscall.SOL_SOCKET, syscall.SO_BROADCAST, 1); err != nil {
-		return nil, err
+		return nil, failure("setsockopt", err)
 	}
 	if err = conn.SetInitMsg(init); err != nil {
-		return nil, err
+		return nil, failure("setsockopt", err)
 	}
 	if local != nil {
 		if err = SCTPBind(sock, local, SCTP_BINDX_ADD_ADDR); err != nil {
-			return nil, err
+			return nil, failure("bind", err)
 		}
 	}
 	conn.assoc, err = SCTPConnect(sock, remote)
 	if err != nil {
-		return nil, err
+		return nil, failure("connect", err)
 	}
 	return conn, nil
 }
+
+// wrap wraps the failure of a socket operation in a *net.OpError. The failure of an
+// operation interrupted by Close becomes net.ErrClosed, EAGAIN on a non-blocking connection
+// a *WouldBlockError and any other errno a *SyscallError naming call.
+func (conn *SCTPConn) wrap(op, call string, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch {
+	case conn.fd.closing():
+		err = net.ErrClosed
+	case err == syscall.EAGAIN && conn.nonblock.Load():
+		err = &WouldBlockError{Op: op}
+	default:
+		err = NewSyscallError(call, err)
+	}
+	network := conn.network
+	if network == "" {
+		network = "sctp"
+	}
+	addrs := conn.errorAddrs()
+	return &net.OpError{
+		Op:     op,
+		Net:    network,
+		Source: addrs.local,
+		Addr:   addrs.remote,
+		Err:    err,
+	}
+}
+
+// errorAddrs returns the addresses reported in errors. They are looked up on the first
+// error and kept once both are known, so that errors on hot paths such as EAGAIN do not
+// query the kernel each time.
+func (conn *SCTPConn) errorAddrs() *errorAddrs {
+	if addrs := conn.addrs.Load(); addrs != nil {
+		return addrs
+	}
+	addrs := &errorAddrs{
+		local:  conn.LocalAddr(),
+		remote: conn.RemoteAddr(),
+	}
+	if addrs.local != nil && addrs.remote != nil {
+		conn.addrs.Store(addrs)
+	}
+	return addrs
+}
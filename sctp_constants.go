@@ -6,67 +6,6 @@ package sctp_go
 const (
 	SOL_SCTP                       = 0x84
 	IPPROTO_SCTP                   = 0x84
-	IoVectorSize                   = 0x10
-	MsgHeaderSize                  = 0x38
-	CMsgHeaderSize                 = 0x10
-	InAddrSize                     = 0x4
-	In6AddrSize                    = 0x10
-	SockAddrInSize                 = 0x10
-	SockAddrIn6Size                = 0x1c
-	SockAddrSize                   = 0x10
-	SockAddrStorageSize            = 0x80
-	SCTPSndRcvInfoSize             = 0x20
-	SCTPInitMsgSize                = 0x8
-	SCTPSndInfoSize                = 0x10
-	SCTPRcvInfoSize                = 0x1c
-	SCTPNxtInfoSize                = 0x10
-	SCTPPrInfoSize                 = 0x8
-	SCTPAuthInfoSize               = 0x2
-	SCTPAssocChangeSize            = 0x14
-	SCTPPAddrChangeSize            = 0x94
-	SCTPRemoteErrorSize            = 0x10
-	SCTPSendFailedSize             = 0x30
-	SCTPShutdownEventSize          = 0xc
-	SCTPAdaptationEventSize        = 0x10
-	SCTPPDApiEventSize             = 0x18
-	SCTPAuthKeyEventSize           = 0x14
-	SCTPSenderDryEventSize         = 0xc
-	SCTPStreamResetEventSize       = 0xc
-	SCTPAssocResetEventSize        = 0x14
-	SCTPStreamChangeEventSize      = 0x10
-	SCTPEventSubscribeSize         = 0xd
-	SCTPNotificationSize           = 0x94
-	SCTPCmsgDataSize               = 0x20
-	SCTPNotificationHeaderSize     = 0x8
-	SCTPRTOInfoSize                = 0x10
-	SCTPAssocParamsSize            = 0x14
-	SCTPSetPeerPrimarySize         = 0x84
-	SCTPPrimaryAddrSize            = 0x84
-	SCTPSetAdaptationSize          = 0x4
-	SCTPPeerAddrParamsSize         = 0x9c
-	SCTPAuthChunkSize              = 0x1
-	SCTPHmacAlgoSize               = 0x4
-	SCTPAuthKeySize                = 0x8
-	SCTPAuthKeyIdSize              = 0x8
-	SCTPSackInfoSize               = 0xc
-	SCTPAssocValueSize             = 0x8
-	SCTPStreamValueSize            = 0x8
-	SCTPPeerAddrInfoSize           = 0x98
-	SCTPStatusSize                 = 0xb0
-	SCTPAuthChunksSize             = 0x8
-	SCTPAssocIdsSize               = 0x4
-	SCTPGetAddrsOldSize            = 0x10
-	SCTPGetAddrsSize               = 0x8
-	SCTPAssocStatsSize             = 0x100
-	SCTPPeelOffArgSize             = 0x8
-	SCTPPeelOffFlagsArgSize        = 0xc
-	SCTPPeerAddrThresholdsSize     = 0x90
-	SCTPPRStatusSize               = 0x18
-	SCTPDefaultPRInfoSize          = 0xc
-	SCTPInfoSize                   = 0x170
-	SCTPResetStreamsSize           = 0x8
-	SCTPAddStreamsSize             = 0x8
-	SCTPEventSize                  = 0x8
 	SCTP_FUTURE_ASSOC              = 0x0
 	SCTP_CURRENT_ASSOC             = 0x1
 	SCTP_ALL_ASSOC                 = 0x2
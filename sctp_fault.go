@@ -0,0 +1,553 @@
+package sctp_go
+
+import (
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// FaultKind is a fault injected by a FaultConn.
+type FaultKind int
+
+// Faults of a FaultRule.
+const (
+	// FaultDrop loses the message.
+	FaultDrop FaultKind = iota + 1
+	// FaultDelay delivers the message after the delay of the rule, later messages overtake it.
+	FaultDelay
+	// FaultDuplicate delivers the message twice.
+	FaultDuplicate
+	// FaultReorder holds the message back until the next message of its stream is delivered.
+	FaultReorder
+	// FaultError fails the operation with the errno of the rule, a message sent is not sent
+	// and a message received is returned by the next RecvMsg.
+	FaultError
+	// FaultPeerAddrChange queues an SCTP_PEER_ADDR_CHANGE notification before the message.
+	FaultPeerAddrChange
+	// FaultSendFailed does not send the message and queues an SCTP_SEND_FAILED notification
+	// carrying it instead, it applies to sends only.
+	FaultSendFailed
+)
+
+var faultKindNames = map[FaultKind]string{
+	FaultDrop:           "drop",
+	FaultDelay:          "delay",
+	FaultDuplicate:      "duplicate",
+	FaultReorder:        "reorder",
+	FaultError:          "error",
+	FaultPeerAddrChange: "peer-addr-change",
+	FaultSendFailed:     "send-failed",
+}
+
+// String returns the name of the fault.
+func (kind FaultKind) String() string {
+	if name, ok := faultKindNames[kind]; ok {
+		return name
+	}
+	return "FaultKind(" + strconv.Itoa(int(kind)) + ")"
+}
+
+// FaultDirection selects the operations a FaultRule applies to.
+type FaultDirection int
+
+// Directions of a FaultRule, a zero direction applies to both.
+const (
+	FaultSend FaultDirection = 1 << iota
+	FaultRecv
+)
+
+// FaultRule injects a fault into the messages sent or received on some streams, on a
+// scripted schedule with Messages or a probabilistic one with Probability.
+type FaultRule struct {
+	Kind      FaultKind
+	Direction FaultDirection
+	// Streams restricts the rule to messages of these streams, all streams when empty.
+	Streams []uint16
+	// Messages are the ordinals, counted from 1, of the messages matching the rule that are
+	// faulted.
+	Messages []int
+	// Probability is the chance each message matching the rule is faulted.
+	Probability float64
+	// Delay is the delay of FaultDelay.
+	Delay time.Duration
+	// Errno is the error of FaultError, EAGAIN when zero, and of FaultSendFailed.
+	Errno syscall.Errno
+	// State is the state of FaultPeerAddrChange, SCTP_ADDR_AVAILABLE when zero.
+	State int32
+	// Addr is the address of FaultPeerAddrChange, the remote address when nil.
+	Addr net.IP
+	// After fires a FaultPeerAddrChange or FaultSendFailed rule once, that long after the
+	// FaultConn is created, to fabricate its notification on an idle association. The
+	// SCTP_SEND_FAILED notification then carries no message.
+	After time.Duration
+}
+
+// FaultPlan is the faults injected by a FaultConn. Every rule matching a message counts it
+// and the first rule due applies, the random numbers of probabilistic rules are drawn from
+// Seed so that a plan replays the same faults.
+type FaultPlan struct {
+	Rules []FaultRule
+	Seed  int64
+}
+
+// faultMessage is a message held or queued by a FaultConn.
+type faultMessage struct {
+	data  []byte
+	info  SCTPSndRcvInfo
+	flags int
+}
+
+// faultRecv is the result of a RecvMsg on the wrapped Conn.
+type faultRecv struct {
+	message faultMessage
+	err     error
+}
+
+// FaultConn wraps a Conn and injects the faults of a FaultPlan into its messages, to test
+// code against lossy, reordering and failing paths without a network. Send faults affect
+// what the peer receives, receive faults what RecvMsg returns. Notifications received are
+// passed through, fabricated ones are returned by the next RecvMsg when the wrapped Conn
+// subscribes them with SetEventSubscribe.
+type FaultConn struct {
+	Conn
+	plan FaultPlan
+
+	mutex   sync.Mutex
+	random  *rand.Rand
+	counts  [2][]int
+	pending []faultMessage
+	wake    chan struct{}
+	timers  map[*time.Timer]struct{}
+
+	smutex sync.Mutex
+	sheld  map[uint16]faultMessage
+
+	rmutex  sync.Mutex
+	rheld   map[uint16]faultMessage
+	recv    chan faultRecv
+	partial bool
+}
+
+// NewFaultConn returns conn injecting the faults of plan, the rules with After are timed
+// from now.
+func NewFaultConn(conn Conn, plan FaultPlan) *FaultConn {
+	faulty := &FaultConn{
+		Conn:   conn,
+		plan:   plan,
+		random: rand.New(rand.NewSource(plan.Seed)),
+		counts: [2][]int{make([]int, len(plan.Rules)), make([]int, len(plan.Rules))},
+		wake:   make(chan struct{}, 1),
+		timers: make(map[*time.Timer]struct{}),
+		sheld:  make(map[uint16]faultMessage),
+		rheld:  make(map[uint16]faultMessage),
+	}
+	for i := range faulty.plan.Rules {
+		if rule := &faulty.plan.Rules[i]; rule.After > 0 {
+			faulty.after(rule.After, func() { faulty.inject(rule) })
+		}
+	}
+	return faulty
+}
+
+// fault returns the rule due for a message of stream in direction, nil for none. Every rule
+// matching the message counts it.
+func (conn *FaultConn) fault(direction FaultDirection, stream uint16) *FaultRule {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	var due *FaultRule
+	counts := conn.counts[direction>>1]
+	for i := range conn.plan.Rules {
+		rule := &conn.plan.Rules[i]
+		if !rule.applies(direction) || !rule.matches(stream) {
+			continue
+		}
+		counts[i]++
+		if rule.Probability > 0 && conn.random.Float64() < rule.Probability && due == nil {
+			due = rule
+		}
+		for _, n := range rule.Messages {
+			if n == counts[i] && due == nil {
+				due = rule
+			}
+		}
+	}
+	return due
+}
+
+// applies reports whether the rule applies to messages in direction.
+func (rule *FaultRule) applies(direction FaultDirection) bool {
+	if rule.Kind == FaultSendFailed && direction != FaultSend {
+		return false
+	}
+	return rule.Direction == 0 || rule.Direction&direction != 0
+}
+
+// matches reports whether the rule applies to messages of stream.
+func (rule *FaultRule) matches(stream uint16) bool {
+	if len(rule.Streams) == 0 {
+		return true
+	}
+	for _, s := range rule.Streams {
+		if s == stream {
+			return true
+		}
+	}
+	return false
+}
+
+// errno returns the error of FaultError.
+func (rule *FaultRule) errno() syscall.Errno {
+	if rule.Errno == 0 {
+		return syscall.EAGAIN
+	}
+	return rule.Errno
+}
+
+// queue adds a fabricated notification to be returned by RecvMsg when the wrapped Conn
+// subscribes it.
+func (conn *FaultConn) queue(data []byte, subscribed func(events *SCTPEventSubscribe) bool) {
+	if events, err := conn.Conn.GetEventSubscribe(); err != nil || !subscribed(events) {
+		return
+	}
+	conn.push(faultMessage{
+		data:  data,
+		info:  SCTPSndRcvInfo{AssocId: int32(conn.AssocId())},
+		flags: SCTP_MSG_NOTIFICATION | syscall.MSG_EOR,
+	})
+}
+
+// queuePeerAddrChange queues the SCTP_PEER_ADDR_CHANGE notification of rule.
+func (conn *FaultConn) queuePeerAddrChange(rule *FaultRule) {
+	conn.queue(conn.peerAddrChange(rule), func(events *SCTPEventSubscribe) bool { return events.AddressEvent != 0 })
+}
+
+// queueSendFailed queues the SCTP_SEND_FAILED notification of rule for a message not sent.
+func (conn *FaultConn) queueSendFailed(rule *FaultRule, b []byte, info SCTPSndRcvInfo) {
+	conn.queue(conn.sendFailed(rule, b, info), func(events *SCTPEventSubscribe) bool { return events.SendFailureEvent != 0 })
+}
+
+// inject queues the notification of a rule fired by its After timer.
+func (conn *FaultConn) inject(rule *FaultRule) {
+	switch rule.Kind {
+	case FaultPeerAddrChange:
+		conn.queuePeerAddrChange(rule)
+	case FaultSendFailed:
+		var info SCTPSndRcvInfo
+		if len(rule.Streams) > 0 {
+			info.Stream = rule.Streams[0]
+		}
+		conn.queueSendFailed(rule, nil, info)
+	}
+}
+
+// peerAddrChange returns the SCTP_PEER_ADDR_CHANGE notification of rule.
+func (conn *FaultConn) peerAddrChange(rule *FaultRule) []byte {
+	change := SCTPPAddrChange{
+		Type:    SCTP_PEER_ADDR_CHANGE,
+		Length:  SCTPPAddrChangeSize,
+		State:   rule.State,
+		AssocId: int32(conn.AssocId()),
+	}
+	addr, _ := conn.RemoteAddr().(*SCTPAddr)
+	if rule.Addr != nil {
+		port := 0
+		if addr != nil {
+			port = addr.port
+		}
+		addr = &SCTPAddr{addresses: []net.IP{rule.Addr}, port: port}
+	}
+	if addr != nil && len(addr.addresses) > 0 {
+		copy(change.Addr[:], MakeSockaddr(&SCTPAddr{addresses: addr.addresses[:1], port: addr.port}))
+	}
+	return Pack(&change)
+}
+
+// sendFailed returns the SCTP_SEND_FAILED notification of a message not sent.
+func (conn *FaultConn) sendFailed(rule *FaultRule, b []byte, info SCTPSndRcvInfo) []byte {
+	info.AssocId = int32(conn.AssocId())
+	return append(Pack(&SCTPSendFailed{
+		Type:    SCTP_SEND_FAILED,
+		Flags:   SCTP_DATA_UNSENT,
+		Length:  uint32(SCTPSendFailedSize + len(b)),
+		Error:   uint32(rule.Errno),
+		Info:    info,
+		AssocId: info.AssocId,
+	}), b...)
+}
+
+// wrap returns the *net.OpError of an injected failure.
+func (conn *FaultConn) wrap(op, call string, errno syscall.Errno) error {
+	network := "sctp"
+	if addr := conn.LocalAddr(); addr != nil {
+		network = addr.Network()
+	}
+	return &net.OpError{
+		Op:     op,
+		Net:    network,
+		Source: conn.LocalAddr(),
+		Addr:   conn.RemoteAddr(),
+		Err:    NewSyscallError(call, errno),
+	}
+}
+
+// Write writes data to the connection on stream 0.
+func (conn *FaultConn) Write(b []byte) (int, error) {
+	return conn.SendMsg(b, nil)
+}
+
+// SendMsg sends a message through the faults of the plan.
+func (conn *FaultConn) SendMsg(b []byte, info *SCTPSndRcvInfo) (int, error) {
+	var message SCTPSndRcvInfo
+	if info != nil {
+		message = *info
+	}
+	if len(b) == 0 {
+		return conn.Conn.SendMsg(b, info)
+	}
+	rule := conn.fault(FaultSend, message.Stream)
+	conn.smutex.Lock()
+	defer conn.smutex.Unlock()
+	if rule != nil {
+		switch rule.Kind {
+		case FaultDrop:
+			return len(b), nil
+		case FaultDelay:
+			data := append([]byte(nil), b...)
+			conn.after(rule.Delay, func() { _, _ = conn.Conn.SendMsg(data, &message) })
+			return len(b), nil
+		case FaultDuplicate:
+			if _, err := conn.Conn.SendMsg(b, &message); err != nil {
+				return 0, err
+			}
+		case FaultReorder:
+			if _, ok := conn.sheld[message.Stream]; !ok {
+				conn.sheld[message.Stream] = faultMessage{data: append([]byte(nil), b...), info: message}
+				return len(b), nil
+			}
+		case FaultError:
+			return 0, conn.wrap("write", "sendmsg", rule.errno())
+		case FaultPeerAddrChange:
+			conn.queuePeerAddrChange(rule)
+		case FaultSendFailed:
+			conn.queueSendFailed(rule, b, message)
+			return len(b), nil
+		}
+	}
+	n, err := conn.Conn.SendMsg(b, info)
+	if err != nil {
+		return n, err
+	}
+	if held, ok := conn.sheld[message.Stream]; ok {
+		delete(conn.sheld, message.Stream)
+		_, _ = conn.Conn.SendMsg(held.data, &held.info)
+	}
+	return n, nil
+}
+
+// after runs f after d, unless Close cancels it first.
+func (conn *FaultConn) after(d time.Duration, f func()) {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	var timer *time.Timer
+	timer = time.AfterFunc(d, func() {
+		conn.mutex.Lock()
+		_, ok := conn.timers[timer]
+		delete(conn.timers, timer)
+		conn.mutex.Unlock()
+		if ok {
+			f()
+		}
+	})
+	conn.timers[timer] = struct{}{}
+}
+
+// Read reads data from the connection, skipping notifications.
+func (conn *FaultConn) Read(b []byte) (n int, err error) {
+	var (
+		flags = 0
+		info  SCTPSndRcvInfo
+	)
+	for {
+		n, err = conn.RecvMsg(b, &info, &flags)
+		if err != nil || flags&SCTP_MSG_NOTIFICATION == 0 {
+			return n, err
+		}
+	}
+}
+
+// RecvMsg receives a message through the faults of the plan, or a fabricated notification.
+// Faults apply to messages read whole into b. The wrapped Conn is read in the background,
+// so that delayed messages and timed notifications are returned while it is idle.
+func (conn *FaultConn) RecvMsg(b []byte, info *SCTPSndRcvInfo, flags *int) (int, error) {
+	conn.rmutex.Lock()
+	defer conn.rmutex.Unlock()
+	for {
+		if n, ok := conn.next(b, info, flags); ok {
+			return n, nil
+		}
+		if conn.recv == nil {
+			conn.recv = make(chan faultRecv, 1)
+			go conn.receive(conn.recv, len(b))
+		}
+		select {
+		case <-conn.wake:
+		case received := <-conn.recv:
+			conn.recv = nil
+			if received.err != nil {
+				return 0, received.err
+			}
+			if err := conn.filter(received.message); err != nil {
+				return 0, err
+			}
+		}
+	}
+}
+
+// receive reads a message of up to size bytes from the wrapped Conn into recv.
+func (conn *FaultConn) receive(recv chan<- faultRecv, size int) {
+	var received faultRecv
+	b := make([]byte, size)
+	n, err := conn.Conn.RecvMsg(b, &received.message.info, &received.message.flags)
+	received.message.data, received.err = b[:max(n, 0)], err
+	recv <- received
+}
+
+// filter applies the faults of the plan to a received message, queueing what RecvMsg
+// returns. It returns the error of FaultError.
+func (conn *FaultConn) filter(received faultMessage) error {
+	whole := !conn.partial && received.flags&syscall.MSG_EOR != 0
+	conn.partial = received.flags&(syscall.MSG_EOR|SCTP_MSG_NOTIFICATION) == 0
+	if !whole || received.flags&SCTP_MSG_NOTIFICATION != 0 {
+		conn.push(received)
+		return nil
+	}
+	stream := received.info.Stream
+	if rule := conn.fault(FaultRecv, stream); rule != nil {
+		switch rule.Kind {
+		case FaultDrop:
+			return nil
+		case FaultDelay:
+			conn.after(rule.Delay, func() { conn.push(received) })
+			return nil
+		case FaultDuplicate:
+			conn.push(received)
+		case FaultReorder:
+			if _, ok := conn.rheld[stream]; !ok {
+				conn.rheld[stream] = received
+				return nil
+			}
+		case FaultError:
+			conn.push(received)
+			return conn.wrap("read", "recvmsg", rule.errno())
+		case FaultPeerAddrChange:
+			conn.queuePeerAddrChange(rule)
+		}
+	}
+	conn.push(received)
+	if held, ok := conn.rheld[stream]; ok {
+		delete(conn.rheld, stream)
+		conn.push(held)
+	}
+	return nil
+}
+
+// push queues a message to be returned by RecvMsg, waking it.
+func (conn *FaultConn) push(message faultMessage) {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	conn.pending = append(conn.pending, message)
+	select {
+	case conn.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next copies the first queued message into b, a message larger than b is returned over
+// several calls.
+func (conn *FaultConn) next(b []byte, info *SCTPSndRcvInfo, flags *int) (int, bool) {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	if len(conn.pending) == 0 {
+		return 0, false
+	}
+	message := &conn.pending[0]
+	n := copy(b, message.data)
+	flag := message.flags
+	if n < len(message.data) {
+		message.data = message.data[n:]
+		flag &^= syscall.MSG_EOR
+	} else {
+		conn.pending = conn.pending[1:]
+	}
+	results(message.info, flag, info, flags)
+	return n, true
+}
+
+// results sets the info and flags returned by RecvMsg.
+func results(message SCTPSndRcvInfo, flag int, info *SCTPSndRcvInfo, flags *int) {
+	if info != nil {
+		*info = message
+	}
+	if flags != nil {
+		*flags = flag
+	}
+}
+
+// Close sends the messages held for reordering, cancels the delayed ones and the timed
+// notifications and closes the connection.
+func (conn *FaultConn) Close() error {
+	conn.smutex.Lock()
+	for stream, held := range conn.sheld {
+		delete(conn.sheld, stream)
+		_, _ = conn.Conn.SendMsg(held.data, &held.info)
+	}
+	conn.smutex.Unlock()
+	conn.mutex.Lock()
+	for timer := range conn.timers {
+		timer.Stop()
+		delete(conn.timers, timer)
+	}
+	conn.mutex.Unlock()
+	return conn.Conn.Close()
+}
+
+// FaultListener wraps a Listener, the associations accepted inject the faults of its plan.
+type FaultListener struct {
+	Listener
+	plan FaultPlan
+}
+
+// NewFaultListener returns listener injecting the faults of plan into the associations it
+// accepts, each with its own schedule.
+func NewFaultListener(listener Listener, plan FaultPlan) *FaultListener {
+	return &FaultListener{
+		Listener: listener,
+		plan:     plan,
+	}
+}
+
+// AcceptConn waits for and returns the next association wrapped in a FaultConn.
+func (listener *FaultListener) AcceptConn() (Conn, error) {
+	conn, err := listener.AcceptFault()
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// AcceptFault waits for and returns the next association wrapped in a FaultConn.
+func (listener *FaultListener) AcceptFault() (*FaultConn, error) {
+	conn, err := listener.Listener.AcceptConn()
+	if err != nil {
+		return nil, err
+	}
+	return NewFaultConn(conn, listener.plan), nil
+}
+
+// Accept waits for and returns the next association wrapped in a FaultConn.
+func (listener *FaultListener) Accept() (net.Conn, error) {
+	return listener.AcceptConn()
+}
@@ -0,0 +1,272 @@
+package sctp_go
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// sendAll sends one byte messages with the values of data on stream.
+func sendAll(t *testing.T, conn Conn, stream uint16, data ...byte) {
+	t.Helper()
+	for _, b := range data {
+		if _, err := conn.SendMsg([]byte{b}, &SCTPSndRcvInfo{Stream: stream}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readAll reads n one byte messages.
+func readAll(t *testing.T, conn Conn, n int) []byte {
+	t.Helper()
+	var received []byte
+	for len(received) < n {
+		data, _, flags := recvConn(t, conn)
+		if flags&SCTP_MSG_NOTIFICATION == 0 {
+			received = append(received, data...)
+		}
+	}
+	return received
+}
+
+func TestFaultSend(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     FaultRule
+		expected []byte
+	}{
+		{"drop", FaultRule{Kind: FaultDrop, Messages: []int{2, 4}}, []byte{1, 3, 5}},
+		{"duplicate", FaultRule{Kind: FaultDuplicate, Messages: []int{1}}, []byte{1, 1, 2, 3, 4, 5}},
+		{"reorder", FaultRule{Kind: FaultReorder, Messages: []int{2}}, []byte{1, 3, 2, 4, 5}},
+		{"receive only", FaultRule{Kind: FaultDrop, Direction: FaultRecv, Messages: []int{1}}, []byte{1, 2, 3, 4, 5}},
+		{"other stream", FaultRule{Kind: FaultDrop, Streams: []uint16{1}, Messages: []int{1}}, []byte{1, 2, 3, 4, 5}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn, peer := Pipe(nil)
+			faulty := NewFaultConn(conn, FaultPlan{Rules: []FaultRule{test.rule}})
+			sendAll(t, faulty, 0, 1, 2, 3, 4, 5)
+			if received := readAll(t, peer, len(test.expected)); !bytes.Equal(received, test.expected) {
+				t.Errorf("received %v, expected %v", received, test.expected)
+			}
+		})
+	}
+}
+
+func TestFaultRecv(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     FaultRule
+		expected []byte
+	}{
+		{"drop", FaultRule{Kind: FaultDrop, Direction: FaultRecv, Messages: []int{1}}, []byte{2, 3, 4}},
+		{"duplicate", FaultRule{Kind: FaultDuplicate, Direction: FaultRecv, Messages: []int{3}}, []byte{1, 2, 3, 3, 4}},
+		{"reorder", FaultRule{Kind: FaultReorder, Direction: FaultRecv, Messages: []int{1}}, []byte{2, 1, 3, 4}},
+		{"delay", FaultRule{Kind: FaultDelay, Direction: FaultRecv, Messages: []int{1}, Delay: 20 * time.Millisecond}, []byte{2, 3, 4, 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn, peer := Pipe(nil)
+			faulty := NewFaultConn(peer, FaultPlan{Rules: []FaultRule{test.rule}})
+			sendAll(t, conn, 0, 1, 2, 3, 4)
+			if received := readAll(t, faulty, len(test.expected)); !bytes.Equal(received, test.expected) {
+				t.Errorf("received %v, expected %v", received, test.expected)
+			}
+		})
+	}
+}
+
+func TestFaultDelay(t *testing.T) {
+	conn, peer := Pipe(nil)
+	faulty := NewFaultConn(conn, FaultPlan{Rules: []FaultRule{{Kind: FaultDelay, Messages: []int{1}, Delay: 20 * time.Millisecond}}})
+	start := time.Now()
+	sendAll(t, faulty, 0, 1, 2)
+	if received := readAll(t, peer, 2); !bytes.Equal(received, []byte{2, 1}) {
+		t.Errorf("received %v", received)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("delayed message received after %v", elapsed)
+	}
+
+	// Close cancels the delayed messages.
+	faulty = NewFaultConn(conn, FaultPlan{Rules: []FaultRule{{Kind: FaultDelay, Messages: []int{1}, Delay: time.Hour}}})
+	sendAll(t, faulty, 0, 3)
+	if err := faulty.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(faulty.timers) != 0 {
+		t.Errorf("%d timers left", len(faulty.timers))
+	}
+}
+
+func TestFaultErrors(t *testing.T) {
+	conn, peer := Pipe(nil)
+	faulty := NewFaultConn(conn, FaultPlan{Rules: []FaultRule{
+		{Kind: FaultError, Direction: FaultSend, Messages: []int{1}},
+		{Kind: FaultError, Direction: FaultSend, Errno: syscall.ECONNRESET, Messages: []int{2}},
+		{Kind: FaultError, Direction: FaultSend, Errno: syscall.EPIPE, Messages: []int{3}},
+		{Kind: FaultError, Direction: FaultRecv, Errno: syscall.ECONNRESET, Messages: []int{1}},
+	}})
+	for _, errno := range []syscall.Errno{syscall.EAGAIN, syscall.ECONNRESET, syscall.EPIPE} {
+		var operr *net.OpError
+		if _, err := faulty.Write([]byte("x")); !errors.Is(err, errno) || !errors.As(err, &operr) {
+			t.Errorf("expected %v, got %v", errno, err)
+		}
+	}
+	sendAll(t, faulty, 0, 1)
+	if received := readAll(t, peer, 1); received[0] != 1 {
+		t.Errorf("received %v", received)
+	}
+
+	sendAll(t, peer, 0, 2)
+	if _, err := faulty.Read(make([]byte, 8)); !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("read: %v", err)
+	}
+	if received := readAll(t, faulty, 1); received[0] != 2 {
+		t.Errorf("received %v after the error", received)
+	}
+}
+
+func TestFaultNotifications(t *testing.T) {
+	conn, peer := Pipe(nil)
+	if err := conn.SetEventSubscribe(&SCTPEventSubscribe{AddressEvent: 1, SendFailureEvent: 1}); err != nil {
+		t.Fatal(err)
+	}
+	faulty := NewFaultConn(conn, FaultPlan{Rules: []FaultRule{
+		{Kind: FaultSendFailed, Errno: syscall.ETIMEDOUT, Messages: []int{1}},
+		{Kind: FaultPeerAddrChange, Direction: FaultSend, State: SCTP_ADDR_UNREACHABLE, Addr: net.IPv4(10, 0, 0, 2), Messages: []int{2}},
+		{Kind: FaultPeerAddrChange, Direction: FaultRecv, State: SCTP_ADDR_MADE_PRIM, Messages: []int{1}},
+	}})
+	if _, err := faulty.SendMsg([]byte("lost"), &SCTPSndRcvInfo{Stream: 3, Ppid: HostToNetwork(5)}); err != nil {
+		t.Fatal(err)
+	}
+	data, info, flags := recvConn(t, faulty)
+	if flags&SCTP_MSG_NOTIFICATION == 0 || info.AssocId != int32(conn.AssocId()) {
+		t.Fatalf("received %q, flags %#x", data, flags)
+	}
+	failed, err := ParseSendFailedEvent(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := failed.(*SCTPSendFailed)
+	if event.Type != SCTP_SEND_FAILED || event.Error != uint32(syscall.ETIMEDOUT) || event.Info.Stream != 3 ||
+		NetworkToHost(event.Info.Ppid) != 5 || string(data[SCTPSendFailedSize:]) != "lost" ||
+		int(event.Length) != len(data) {
+		t.Errorf("notification %+v, data %q", event, data[SCTPSendFailedSize:])
+	}
+
+	sendAll(t, faulty, 0, 1)
+	change := expectNotification(t, faulty, SCTP_PEER_ADDR_CHANGE).(*SCTPPAddrChange)
+	if change.State != SCTP_ADDR_UNREACHABLE || change.GetAddr().String() != "10.0.0.2:2" {
+		t.Errorf("notification %+v, address %v", change, change.GetAddr())
+	}
+	if received := readAll(t, peer, 1); received[0] != 1 {
+		t.Errorf("received %v", received)
+	}
+
+	sendAll(t, peer, 0, 2)
+	change = expectNotification(t, faulty, SCTP_PEER_ADDR_CHANGE).(*SCTPPAddrChange)
+	if change.State != SCTP_ADDR_MADE_PRIM || change.GetAddr().String() != "127.0.0.1:2" {
+		t.Errorf("notification %+v, address %v", change, change.GetAddr())
+	}
+	if received := readAll(t, faulty, 1); received[0] != 2 {
+		t.Errorf("received %v", received)
+	}
+}
+
+func TestFaultTimedNotifications(t *testing.T) {
+	conn, _ := Pipe(nil)
+	if err := conn.SetEventSubscribe(&SCTPEventSubscribe{AddressEvent: 1, SendFailureEvent: 1}); err != nil {
+		t.Fatal(err)
+	}
+	faulty := NewFaultConn(conn, FaultPlan{Rules: []FaultRule{
+		{Kind: FaultPeerAddrChange, State: SCTP_ADDR_UNREACHABLE, After: 10 * time.Millisecond},
+		{Kind: FaultSendFailed, Errno: syscall.ETIMEDOUT, Streams: []uint16{2}, After: 20 * time.Millisecond},
+	}})
+	defer faulty.Close()
+	start := time.Now()
+	change := expectNotification(t, faulty, SCTP_PEER_ADDR_CHANGE).(*SCTPPAddrChange)
+	if change.State != SCTP_ADDR_UNREACHABLE {
+		t.Errorf("notification %+v", change)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("notification received after %v", elapsed)
+	}
+	failed := expectNotification(t, faulty, SCTP_SEND_FAILED).(*SCTPSendFailed)
+	if failed.Error != uint32(syscall.ETIMEDOUT) || failed.Info.Stream != 2 || failed.Length != SCTPSendFailedSize {
+		t.Errorf("notification %+v", failed)
+	}
+}
+
+func TestFaultUnsubscribed(t *testing.T) {
+	conn, peer := Pipe(nil)
+	faulty := NewFaultConn(conn, FaultPlan{Rules: []FaultRule{
+		{Kind: FaultSendFailed, Messages: []int{1}},
+		{Kind: FaultPeerAddrChange, Direction: FaultRecv, Messages: []int{1}},
+		{Kind: FaultPeerAddrChange, After: time.Millisecond},
+	}})
+	defer faulty.Close()
+	sendAll(t, faulty, 0, 1, 2)
+	if received := readAll(t, peer, 1); received[0] != 2 {
+		t.Errorf("received %v", received)
+	}
+	time.Sleep(10 * time.Millisecond)
+	sendAll(t, peer, 0, 3)
+	if data, _, flags := recvConn(t, faulty); flags&SCTP_MSG_NOTIFICATION != 0 || data[0] != 3 {
+		t.Errorf("received %v, flags %#x without subscription", data, flags)
+	}
+}
+
+func TestFaultProbability(t *testing.T) {
+	run := func(seed int64) []byte {
+		conn, peer := Pipe(nil)
+		faulty := NewFaultConn(conn, FaultPlan{Seed: seed, Rules: []FaultRule{{Kind: FaultDrop, Probability: 0.5}}})
+		for i := 0; i < 100; i++ {
+			sendAll(t, faulty, 0, byte(i))
+		}
+		_ = faulty.Close()
+		var received []byte
+		for b := make([]byte, 1); ; {
+			if _, err := peer.Read(b); err != nil {
+				return received
+			}
+			received = append(received, b[0])
+		}
+	}
+	first := run(1)
+	if len(first) < 25 || len(first) > 75 {
+		t.Errorf("%d of 100 messages received", len(first))
+	}
+	if again := run(1); !bytes.Equal(again, first) {
+		t.Errorf("same seed received %v and %v", first, again)
+	}
+}
+
+func TestFaultListener(t *testing.T) {
+	addr, _ := MakeSCTPAddr("sctp", "127.0.0.1:36412")
+	pipe := ListenPipe(addr, nil)
+	listener := NewFaultListener(pipe, FaultPlan{Rules: []FaultRule{{Kind: FaultDrop, Direction: FaultRecv, Messages: []int{1}}}})
+	defer listener.Close()
+	conn, err := pipe.Dial(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accepted, err := listener.AcceptConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := accepted.(*FaultConn); !ok {
+		t.Fatalf("accepted %T", accepted)
+	}
+	sendAll(t, conn, 0, 1, 2)
+	if received := readAll(t, accepted, 1); received[0] != 2 {
+		t.Errorf("received %v", received)
+	}
+	_ = listener.Close()
+	if conn, err := listener.Accept(); conn != nil || !errors.Is(err, ErrListenerClosed) {
+		t.Errorf("accept after close: %v, %v", conn, err)
+	}
+}
@@ -0,0 +1,105 @@
+package sctp_go
+
+import (
+	"net"
+	"sync/atomic"
+	"syscall"
+)
+
+const (
+	descriptorClosing = 1 << 63
+)
+
+// descriptor guards a socket file descriptor against use after close, in the manner of
+// internal/poll.FD. Operations hold a reference while they use the socket, closing marks
+// the descriptor so new operations fail with net.ErrClosed, and the socket is closed once
+// the last reference is released so its number is never reused under an operation.
+type descriptor struct {
+	sock  atomic.Int64
+	state atomic.Uint64
+}
+
+// init takes ownership of sock, the reference of the owner is dropped by close.
+func (d *descriptor) init(sock int) {
+	d.sock.Store(int64(sock))
+	d.state.Store(1)
+}
+
+// sysfd returns the socket number without taking a reference, or -1 once destroyed.
+func (d *descriptor) sysfd() int {
+	return int(d.sock.Load())
+}
+
+// closing reports whether the descriptor has been closed.
+func (d *descriptor) closing() bool {
+	return d.state.Load()&descriptorClosing != 0
+}
+
+// acquire takes a reference on the socket, it fails with net.ErrClosed once closing.
+func (d *descriptor) acquire() (int, error) {
+	for {
+		state := d.state.Load()
+		if state == 0 || state&descriptorClosing != 0 {
+			return -1, net.ErrClosed
+		}
+		if d.state.CompareAndSwap(state, state+1) {
+			return int(d.sock.Load()), nil
+		}
+	}
+}
+
+// release drops a reference taken by acquire.
+func (d *descriptor) release() {
+	_ = d.decref()
+}
+
+// decref drops a reference and closes the socket when it was the last one of a closing
+// descriptor, returning the error of the close.
+func (d *descriptor) decref() error {
+	if d.state.Add(^uint64(0)) != descriptorClosing {
+		return nil
+	}
+	sock := d.sock.Swap(-1)
+	return syscall.Close(int(sock))
+}
+
+// close marks the descriptor closing and drops the reference of the owner. prepare is
+// invoked with the socket still open, e.g. to shut it down and wake blocked operations.
+// The socket is closed right away without operations in flight, otherwise by the last
+// one returning. It returns net.ErrClosed when the descriptor is already closing.
+func (d *descriptor) close(prepare func(sock int)) error {
+	for {
+		state := d.state.Load()
+		if state == 0 || state&descriptorClosing != 0 {
+			return net.ErrClosed
+		}
+		if d.state.CompareAndSwap(state, state|descriptorClosing) {
+			break
+		}
+	}
+	if prepare != nil {
+		prepare(int(d.sock.Load()))
+	}
+	return d.decref()
+}
+
+// holdSend returns h releasing a reference taken by acquire once the asynchronous send
+// completed, so the socket is not closed while the engine uses its number.
+func (d *descriptor) holdSend(h SendHandler) SendHandler {
+	return func(n int, err error) {
+		defer d.release()
+		h(n, err)
+	}
+}
+
+// holdRecv returns h releasing a reference taken by acquire once the asynchronous receive
+// completed. A multishot receive completes with an error or an empty message.
+func (d *descriptor) holdRecv(h RecvHandler, multishot bool) RecvHandler {
+	var released atomic.Bool
+	return func(b []byte, info *SCTPSndRcvInfo, flags int, err error) {
+		if (!multishot || err != nil || len(b) == 0) && released.CompareAndSwap(false, true) {
+			defer d.release()
+		}
+		h(b, info, flags, err)
+	}
+}
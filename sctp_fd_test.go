@@ -0,0 +1,214 @@
+package sctp_go
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// isOpen reports whether fd refers to an open file descriptor.
+func isOpen(fd int) bool {
+	_, _, errno := syscall.Syscall(syscall.SYS_FCNTL, uintptr(fd), syscall.F_GETFD, 0)
+	return errno == 0
+}
+
+func TestDescriptorLifecycle(t *testing.T) {
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_SEQPACKET, 0)
+	if err != nil {
+		t.Skip("socketpair not available: ", err)
+	}
+	defer syscall.Close(fds[1])
+	var d descriptor
+	d.init(fds[0])
+	sock, err := d.acquire()
+	if err != nil || sock != fds[0] {
+		t.Fatalf("unexpected acquire result %d: %v", sock, err)
+	}
+	if err := d.close(nil); err != nil {
+		t.Fatal(err)
+	}
+	if !isOpen(fds[0]) {
+		t.Fatal("descriptor closed while still referenced")
+	}
+	if _, err := d.acquire(); err != net.ErrClosed {
+		t.Errorf("expected net.ErrClosed, got %v", err)
+	}
+	if err := d.close(nil); err != net.ErrClosed {
+		t.Errorf("expected net.ErrClosed on second close, got %v", err)
+	}
+	d.release()
+	if isOpen(fds[0]) {
+		t.Error("descriptor left open after last release")
+	}
+	if d.sysfd() != -1 {
+		t.Errorf("expected -1, got %d", d.sysfd())
+	}
+}
+
+func TestConnConcurrentClose(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_SEQPACKET, 0)
+		if err != nil {
+			t.Skip("socketpair not available: ", err)
+		}
+		conn := NewSCTPConn(fds[0])
+		var (
+			wg     sync.WaitGroup
+			start  = make(chan struct{})
+			failed = make(chan error, 4)
+		)
+		check := func(err error) {
+			if !errors.Is(err, net.ErrClosed) {
+				failed <- err
+			}
+		}
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			var buffer [64]byte
+			<-start
+			// Nothing is sent to the connection, the read blocks until it is closed.
+			n, err := conn.Read(buffer[:])
+			if n != 0 || err == nil {
+				failed <- fmt.Errorf("read %d bytes after close: %v", n, err)
+				return
+			}
+			check(err)
+		}()
+		go func() {
+			defer wg.Done()
+			<-start
+			for {
+				if _, err := conn.Write([]byte("ping")); err != nil {
+					check(err)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			<-start
+			if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+				failed <- err
+			}
+		}()
+		close(start)
+		if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			t.Error(err)
+		}
+		wg.Wait()
+		close(failed)
+		for err := range failed {
+			t.Errorf("expected net.ErrClosed, got %v", err)
+		}
+		if conn.FD() != -1 || isOpen(fds[0]) {
+			t.Error("descriptor left open after concurrent close")
+		}
+		if _, err := conn.SendMsg(nil, nil); !errors.Is(err, net.ErrClosed) {
+			t.Errorf("expected net.ErrClosed after close, got %v", err)
+		}
+		syscall.Close(fds[1])
+	}
+}
+
+func TestConnCloseBlockedRead(t *testing.T) {
+	reads := map[string]func(conn *SCTPConn) (int, error){
+		"RecvMsg": func(conn *SCTPConn) (int, error) {
+			var (
+				info  SCTPSndRcvInfo
+				flags int
+			)
+			return conn.RecvMsg(make([]byte, 64), &info, &flags)
+		},
+		"RecvMsgv": func(conn *SCTPConn) (int, error) {
+			var (
+				info  SCTPSndRcvInfo
+				flags int
+			)
+			return conn.RecvMsgv(net.Buffers{make([]byte, 32), make([]byte, 32)}, &info, &flags)
+		},
+		"RecvBatch": func(conn *SCTPConn) (int, error) {
+			msgs := []Message{{Buffer: make([]byte, 64)}, {Buffer: make([]byte, 64)}}
+			return conn.RecvBatch(msgs)
+		},
+	}
+	for name, read := range reads {
+		t.Run(name, func(t *testing.T) {
+			conn, _ := socketpair(t)
+			type result struct {
+				n   int
+				err error
+			}
+			done := make(chan result, 1)
+			go func() {
+				n, err := read(conn)
+				done <- result{n, err}
+			}()
+			// Give the read time to block in the kernel before closing.
+			time.Sleep(20 * time.Millisecond)
+			select {
+			case r := <-done:
+				t.Fatalf("read returned before close: %d, %v", r.n, r.err)
+			default:
+			}
+			if err := conn.Close(); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case r := <-done:
+				if r.n != 0 || !errors.Is(r.err, net.ErrClosed) {
+					t.Errorf("expected net.ErrClosed, got %d, %v", r.n, r.err)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("blocked read not woken by close")
+			}
+		})
+	}
+}
+
+func TestConnAbort(t *testing.T) {
+	conn, _ := socketpair(t)
+	fd := int(conn.FD())
+	done := make(chan error, 1)
+	go func() {
+		_, err := conn.Read(make([]byte, 64))
+		done <- err
+	}()
+	// Give the read time to block in the kernel before aborting.
+	time.Sleep(20 * time.Millisecond)
+	// The reference held here keeps the socket open to check its linger time.
+	if _, err := conn.fd.acquire(); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Abort(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, net.ErrClosed) {
+			t.Errorf("expected net.ErrClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("blocked read not woken by abort")
+	}
+	linger, err := unix.GetsockoptLinger(fd, unix.SOL_SOCKET, unix.SO_LINGER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linger.Onoff != 1 || linger.Linger != 0 {
+		t.Errorf("expected a zero linger time, got %+v", *linger)
+	}
+	conn.fd.release()
+	if isOpen(fd) {
+		t.Error("descriptor left open after the last release")
+	}
+	if err := conn.Abort(); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("expected net.ErrClosed on second abort, got %v", err)
+	}
+}
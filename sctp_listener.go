@@ -1,17 +1,28 @@
 package sctp_go
 
 import (
-	"bytes"
-	"encoding/binary"
+	"context"
 	"errors"
+	"fmt"
 	"net"
+	"sync/atomic"
 	"syscall"
+	"time"
 	"unsafe"
 )
 
+// ShutdownPollInterval is the interval at which Shutdown polls the kernel for
+// associations that completed shutdown without a notification being received.
+var ShutdownPollInterval = 10 * time.Millisecond
+
 // SCTPListener represents an SCTP listener socket.
 type SCTPListener struct {
-	sock int
+	sock    int
+	closed  atomic.Bool
+	network string
+	assocs  associations
+	capture atomic.Pointer[captureHook]
+	addrs   atomic.Pointer[errorAddrs]
 }
 
 // FD returns the file descriptor of the listener socket.
@@ -19,120 +30,144 @@ func (listener *SCTPListener) FD() int {
 	return listener.sock
 }
 
+// socket returns the listener socket, failing with ErrListenerClosed once it is closed.
+func (listener *SCTPListener) socket() (int, error) {
+	if listener.closed.Load() {
+		return -1, ErrListenerClosed
+	}
+	return listener.sock, nil
+}
+
 // Addr returns the local network address.
 func (listener *SCTPListener) Addr() net.Addr {
-	if listener.sock <= 0 {
+	sock, err := listener.socket()
+	if err != nil {
 		return nil
 	}
 	var (
-		data   [4096]byte
+		data   [sctpGetAddrsBufferSize]byte
 		addrs  = (*SCTPGetAddrs)(unsafe.Pointer(&data[0]))
-		length = len(data)
+		length = uint32(len(data))
 	)
 	addrs.AssocId = 0
-	_, _, err := syscall.Syscall6(
-		syscall.SYS_GETSOCKOPT,
-		uintptr(listener.sock),
-		syscall.IPPROTO_SCTP,
-		SCTP_GET_LOCAL_ADDRS,
-		uintptr(unsafe.Pointer(addrs)),
-		uintptr(unsafe.Pointer(&length)),
-		0,
-	)
-	if err == 0 {
-		return FromSCTPGetAddrs(addrs)
+	err = getsockopt(sock, syscall.IPPROTO_SCTP, SCTP_GET_LOCAL_ADDRS, unsafe.Pointer(addrs), &length)
+	if err == nil {
+		return ParseSCTPGetAddrs(data[:length]).Addr()
 	}
 	return nil
 }
 
 // RemoteAddr returns the remote network address for the specified association.
 func (listener *SCTPListener) RemoteAddr(assoc int) net.Addr {
-	if listener.sock <= 0 {
+	sock, err := listener.socket()
+	if err != nil {
 		return nil
 	}
 	var (
-		data   [4096]byte
+		data   [sctpGetAddrsBufferSize]byte
 		addrs  = (*SCTPGetAddrs)(unsafe.Pointer(&data[0]))
-		length = len(data)
+		length = uint32(len(data))
 	)
 	addrs.AssocId = int32(assoc)
-	_, _, err := syscall.Syscall6(
-		syscall.SYS_GETSOCKOPT,
-		uintptr(listener.sock),
-		syscall.IPPROTO_SCTP,
-		SCTP_GET_PEER_ADDRS,
-		uintptr(unsafe.Pointer(addrs)),
-		uintptr(unsafe.Pointer(&length)),
-		0,
-	)
-	if err == 0 {
-		return FromSCTPGetAddrs(addrs)
+	err = getsockopt(sock, syscall.IPPROTO_SCTP, SCTP_GET_PEER_ADDRS, unsafe.Pointer(addrs), &length)
+	if err == nil {
+		return ParseSCTPGetAddrs(data[:length]).Addr()
 	}
 	return nil
 }
 
 // Connect connects to the remote SCTP address.
 func (listener *SCTPListener) Connect(remote *SCTPAddr) (int, error) {
-	if listener.sock <= 0 {
-		return 0, errors.New("invalid listener")
+	sock, err := listener.socket()
+	if err != nil {
+		return 0, listener.wrap("connect", "", err)
+	}
+	assoc, err := SCTPConnect(sock, remote)
+	if err != nil {
+		return 0, listener.wrapAddr("connect", "connect", remote.Addr(), err)
 	}
-	return SCTPConnect(listener.sock, remote)
+	return assoc, nil
 }
 
 // Abort aborts the SCTP association specified by assoc.
 func (listener *SCTPListener) Abort(assoc int) error {
-	if listener.sock <= 0 {
-		return errors.New("invalid listener")
-	}
-	var msg = SCTPSndRcvInfo{
-		Flags:   SCTP_ABORT,
-		AssocId: int32(assoc),
+	sock, err := listener.socket()
+	if err != nil {
+		return listener.wrap("abort", "", err)
 	}
-	_, err := listener.SendMsg(nil, &msg)
-	return err
+	var (
+		msg = SCTPSndRcvInfo{
+			Flags:   SCTP_ABORT,
+			AssocId: int32(assoc),
+		}
+		control controlBuffer
+		buffer  = control.bytes()
+	)
+	_, err = SCTPSendMsg(sock, nil, buffer[:EncodeSndRcvInfo(buffer, &msg)], 0)
+	return listener.wrapAssoc("abort", "sendmsg", sock, assoc, err)
 }
 
 // Disconnect disconnects the SCTP association specified by assoc.
 func (listener *SCTPListener) Disconnect(assoc int) error {
-	if listener.sock <= 0 {
-		return errors.New("invalid listener")
-	}
-	var msg = SCTPSndRcvInfo{
-		Stream:  0,
-		Ppid:    0,
-		Flags:   SCTP_EOF,
-		AssocId: int32(assoc),
+	sock, err := listener.socket()
+	if err != nil {
+		return listener.wrap("shutdown", "", err)
 	}
-	_, err := listener.SendMsg(nil, &msg)
-	return err
+	var (
+		msg = SCTPSndRcvInfo{
+			Stream:  0,
+			Ppid:    0,
+			Flags:   SCTP_EOF,
+			AssocId: int32(assoc),
+		}
+		control controlBuffer
+		buffer  = control.bytes()
+	)
+	_, err = SCTPSendMsg(sock, nil, buffer[:EncodeSndRcvInfo(buffer, &msg)], 0)
+	return listener.wrapAssoc("shutdown", "sendmsg", sock, assoc, err)
 }
 
 // PeelOff peels off the SCTP association specified by assoc.
 func (listener *SCTPListener) PeelOff(assoc int) (*SCTPConn, error) {
-	if listener.sock <= 0 {
-		return nil, errors.New("invalid listener")
+	sock, err := listener.socket()
+	if err != nil {
+		return nil, listener.wrap("peeloff", "", err)
+	}
+	conn, err := SCTPPeelOff(sock, assoc)
+	if err != nil {
+		return nil, listener.wrapAssoc("peeloff", "getsockopt", sock, assoc, err)
 	}
-	return SCTPPeelOff(listener.sock, assoc)
+	listener.assocs.remove(assoc)
+	return conn, nil
 }
 
 // PeelOffFlags peels off the SCTP association specified by assoc with flags.
 func (listener *SCTPListener) PeelOffFlags(assoc, flag int) (*SCTPConn, error) {
-	if listener.sock <= 0 {
-		return nil, errors.New("invalid listener")
+	sock, err := listener.socket()
+	if err != nil {
+		return nil, listener.wrap("peeloff", "", err)
+	}
+	conn, err := SCTPPeelOffFlag(sock, assoc, flag)
+	if err != nil {
+		return nil, listener.wrapAssoc("peeloff", "getsockopt", sock, assoc, err)
 	}
-	return SCTPPeelOffFlag(listener.sock, assoc, flag)
+	listener.assocs.remove(assoc)
+	return conn, nil
 }
 
 // AcceptSCTP accepts an incoming SCTP connection.
 func (listener *SCTPListener) AcceptSCTP() (*SCTPConn, error) {
-	if listener.sock <= 0 {
-		return nil, errors.New("invalid listener")
+	sock, err := listener.socket()
+	if err != nil {
+		return nil, listener.wrap("accept", "", err)
 	}
-	sock, _, err := syscall.Accept4(listener.sock, 0)
+	fd, _, err := syscall.Accept4(sock, 0)
 	if err != nil {
-		return nil, err
+		return nil, listener.wrap("accept", "accept4", err)
 	}
-	return NewSCTPConn(sock), nil
+	conn := NewSCTPConn(fd)
+	conn.network = listener.network
+	return conn, nil
 }
 
 // Accept accepts a generic network connection.
@@ -140,144 +175,488 @@ func (listener *SCTPListener) Accept() (net.Conn, error) {
 	return listener.AcceptSCTP()
 }
 
-// Close closes the listener.
+// AcceptConn waits for and returns the next connection as a Conn.
+func (listener *SCTPListener) AcceptConn() (Conn, error) {
+	conn, err := listener.AcceptSCTP()
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Close closes the listener. Operations blocked on the listener and later ones fail with
+// ErrListenerClosed.
 func (listener *SCTPListener) Close() error {
-	if listener.sock <= 0 {
-		return errors.New("invalid listener")
+	if listener.closed.Swap(true) {
+		return listener.wrap("close", "", ErrListenerClosed)
 	}
 	// Shutdown doesn't work on RAW Sockets, SCTP Socket is essentially a RAW Socket.
 	_ = syscall.Shutdown(listener.sock, syscall.SHUT_RDWR)
-	return syscall.Close(listener.sock)
+	return listener.wrap("close", "close", syscall.Close(listener.sock))
+}
+
+// Shutdown gracefully shuts down the listener. It stops accepting new associations,
+// sends SHUTDOWN (SCTP_EOF) on every association and waits until each one reports
+// SCTP_SHUTDOWN_COMP or SCTP_SENDER_DRY_EVENT, or disappears from the socket. The
+// associations still pending when ctx expires are aborted and returned. The listener
+// is closed before returning. Failures to shut down or abort an association are joined
+// into the returned error.
+func (listener *SCTPListener) Shutdown(ctx context.Context) ([]int, error) {
+	sock, err := listener.socket()
+	if err != nil {
+		return nil, listener.wrap("shutdown", "", err)
+	}
+	// A zero backlog stops the socket from accepting new associations.
+	if err := syscall.Listen(sock, 0); err != nil {
+		return nil, listener.wrap("shutdown", "listen", err)
+	}
+	forced, err := listener.assocs.shutdown(ctx, shutdownOps{
+		list: func() ([]int, error) {
+			return SCTPGetAssocIdList(sock)
+		},
+		disconnect: listener.Disconnect,
+		abort:      listener.Abort,
+	})
+	return forced, errors.Join(err, listener.Close(), ctx.Err())
 }
 
 // SetEventSubscribe sets the SCTP event subscription.
 func (listener *SCTPListener) SetEventSubscribe(events *SCTPEventSubscribe) error {
-	if listener.sock <= 0 {
-		return errors.New("invalid listener")
+	sock, err := listener.socket()
+	if err != nil {
+		return listener.wrap("set", "", err)
 	}
 	if events == nil {
-		return errors.New("events cannot be nil")
-	}
-	_, _, err := syscall.Syscall6(
-		syscall.SYS_SETSOCKOPT,
-		uintptr(listener.sock),
-		SOL_SCTP,
-		SCTP_EVENTS,
-		uintptr(unsafe.Pointer(events)),
-		unsafe.Sizeof(*events),
-		0,
-	)
-	if err != 0 {
-		return err
+		return listener.wrap("set", "", fmt.Errorf("%w: events", ErrNilArgument))
+	}
+	err = setsockopt(sock, SOL_SCTP, SCTP_EVENTS, unsafe.Pointer(events), unsafe.Sizeof(*events))
+	if err != nil {
+		return listener.wrap("set", "setsockopt", err)
 	}
 	return nil
 }
 
 // GetEventSubscribe gets the SCTP event subscription.
 func (listener *SCTPListener) GetEventSubscribe() (*SCTPEventSubscribe, error) {
-	if listener.sock <= 0 {
-		return nil, errors.New("invalid listener")
+	sock, err := listener.socket()
+	if err != nil {
+		return nil, listener.wrap("get", "", err)
 	}
 	var (
 		events = &SCTPEventSubscribe{}
-		length = unsafe.Sizeof(*events)
-	)
-	_, _, err := syscall.Syscall6(
-		syscall.SYS_GETSOCKOPT,
-		uintptr(listener.sock),
-		SOL_SCTP,
-		SCTP_EVENTS,
-		uintptr(unsafe.Pointer(events)),
-		uintptr(unsafe.Pointer(&length)),
-		0,
+		length = uint32(unsafe.Sizeof(*events))
 	)
-	if err != 0 {
-		return nil, err
+	err = getsockopt(sock, SOL_SCTP, SCTP_EVENTS, unsafe.Pointer(events), &length)
+	if err != nil {
+		return nil, listener.wrap("get", "getsockopt", err)
 	}
 	return events, nil
 }
 
 // RecvMsg receives a message from the SCTP socket.
 func (listener *SCTPListener) RecvMsg(b []byte, info *SCTPSndRcvInfo, flags *int) (n int, err error) {
-	if listener.sock <= 0 {
-		return 0, errors.New("invalid listener")
+	sock, err := listener.socket()
+	if err != nil {
+		return 0, listener.wrap("read", "", err)
+	}
+	var (
+		control controlBuffer
+		oob     = control.bytes()
+		flag    = 0
+	)
+	if flags != nil {
+		flag = *flags
+	}
+	n, noob, flag, err := SCTPRecvMsg(sock, b, oob, flag)
+	if err != nil {
+		return n, listener.wrap("read", "recvmsg", err)
+	}
+	if flags != nil {
+		*flags = flag
+	}
+	if noob > 0 {
+		ParseSndRcvInfo(info, oob[:noob])
+	}
+	if flag&SCTP_MSG_NOTIFICATION != 0 && flag&syscall.MSG_EOR != 0 {
+		listener.track(b[:n])
+	} else if flag&SCTP_MSG_NOTIFICATION == 0 {
+		listener.captured(false, nil, info, b[:n])
+	}
+	return n, nil
+}
+
+// RecvMsgv receives a message from the SCTP socket, scattering it over the buffers in order.
+func (listener *SCTPListener) RecvMsgv(buffers net.Buffers, info *SCTPSndRcvInfo, flags *int) (n int, err error) {
+	sock, err := listener.socket()
+	if err != nil {
+		return 0, listener.wrap("read", "", err)
 	}
 	var (
-		oob  = make([]byte, syscall.CmsgSpace(SCTPSndRcvInfoSize))
-		flag = 0
+		control controlBuffer
+		oob     = control.bytes()
+		flag    = 0
 	)
 	if flags != nil {
 		flag = *flags
 	}
-	n, noob, flag, _, err := syscall.Recvmsg(listener.sock, b, oob[:], flag)
+	n, noob, flag, err := SCTPRecvMsgv(sock, buffers, oob, flag)
 	if err != nil {
-		return n, err
+		return n, listener.wrap("read", "recvmsg", err)
+	}
+	if flags != nil {
+		*flags = flag
 	}
-	*flags = flag
 	if noob > 0 {
 		ParseSndRcvInfo(info, oob[:noob])
 	}
+	if flag&SCTP_MSG_NOTIFICATION != 0 && flag&syscall.MSG_EOR != 0 && len(buffers) > 0 && n <= len(buffers[0]) {
+		listener.track(buffers[0][:n])
+	} else if flag&SCTP_MSG_NOTIFICATION == 0 && listener.capture.Load() != nil {
+		listener.captured(false, nil, info, gather(buffers, n))
+	}
 	return n, nil
 }
 
+// RecvBuffer receives a message from the SCTP socket into a buffer taken from pool. The
+// returned buffer holds the message in Data and must be released by the caller.
+func (listener *SCTPListener) RecvBuffer(pool *BufferPool, info *SCTPSndRcvInfo, flags *int) (*Buffer, error) {
+	buffer := pool.Get()
+	n, err := listener.RecvMsg(buffer.Data, info, flags)
+	if err != nil {
+		buffer.Release()
+		return nil, err
+	}
+	buffer.Data = buffer.Data[:n]
+	return buffer, nil
+}
+
+// RecvFrom receives a message from the SCTP socket and returns the address of the peer
+// that sent it. Linux does not report the local address a message arrived on: it accepts
+// SCTP_DSTADDRV4 and SCTP_DSTADDRV6 on sendmsg only and SCTP sockets ignore IP_PKTINFO.
+func (listener *SCTPListener) RecvFrom(b []byte, info *SCTPSndRcvInfo, flags *int) (int, *SCTPAddr, error) {
+	sock, err := listener.socket()
+	if err != nil {
+		return 0, nil, listener.wrap("read", "", err)
+	}
+	var control controlBuffer
+	n, from, err := listener.recvmsg(sock, b, control.bytes(), info, flags)
+	if err != nil {
+		return n, nil, listener.wrap("read", "recvmsg", err)
+	}
+	return n, FromSockaddr(from), nil
+}
+
+// recvmsg receives a message into b and the control data into oob, updating the
+// association table from notifications. It returns the number of bytes received and the
+// address of the sender.
+func (listener *SCTPListener) recvmsg(sock int, b, oob []byte, info *SCTPSndRcvInfo, flags *int) (int, syscall.Sockaddr, error) {
+	flag := 0
+	if flags != nil {
+		flag = *flags
+	}
+	n, noob, flag, from, err := syscall.Recvmsg(sock, b, oob, flag)
+	if err != nil {
+		return n, nil, err
+	}
+	if flags != nil {
+		*flags = flag
+	}
+	if noob > 0 {
+		ParseSndRcvInfo(info, oob[:noob])
+	}
+	if flag&SCTP_MSG_NOTIFICATION != 0 && flag&syscall.MSG_EOR != 0 {
+		listener.track(b[:n])
+	} else if flag&SCTP_MSG_NOTIFICATION == 0 {
+		listener.captured(false, FromSockaddr(from), info, b[:n])
+	}
+	return n, from, nil
+}
+
+// SendMsgv sends the buffers as a single message on the SCTP socket without copying them,
+// e.g. a protocol header followed by its payload.
+func (listener *SCTPListener) SendMsgv(buffers net.Buffers, info *SCTPSndRcvInfo) (int, error) {
+	sock, err := listener.socket()
+	if err != nil {
+		return 0, listener.wrap("write", "", err)
+	}
+	var oob []byte
+	if info != nil {
+		var control controlBuffer
+		buffer := control.bytes()
+		oob = buffer[:EncodeSndRcvInfo(buffer, info)]
+	}
+	n, err := SCTPSendMsgv(sock, buffers, oob, 0)
+	if err == nil && listener.capture.Load() != nil {
+		listener.captured(true, nil, info, gather(buffers, n))
+	}
+	return n, listener.wrap("write", "sendmsg", err)
+}
+
+// SendBatch sends the messages on the SCTP socket with a single sendmmsg call where possible.
+// Each message must carry an Info with the association id. It returns the number of messages sent.
+func (listener *SCTPListener) SendBatch(msgs []Message) (int, error) {
+	sock, err := listener.socket()
+	if err != nil {
+		return 0, listener.wrap("write", "", err)
+	}
+	n, err := SCTPSendBatch(sock, msgs)
+	for i := 0; i < n; i++ {
+		listener.captured(true, nil, msgs[i].Info, msgs[i].Buffer[:msgs[i].N])
+	}
+	return n, listener.wrap("write", "sendmmsg", err)
+}
+
+// RecvBatch receives up to len(msgs) messages from the SCTP socket with a single recvmmsg call,
+// updating the association table from notifications. It returns the number of messages received.
+func (listener *SCTPListener) RecvBatch(msgs []Message) (int, error) {
+	sock, err := listener.socket()
+	if err != nil {
+		return 0, listener.wrap("read", "", err)
+	}
+	n, err := SCTPRecvBatch(sock, msgs, 0)
+	for i := 0; i < n; i++ {
+		if msgs[i].Flags&SCTP_MSG_NOTIFICATION != 0 && msgs[i].Flags&syscall.MSG_EOR != 0 {
+			listener.track(msgs[i].Buffer[:msgs[i].N])
+		} else if msgs[i].Flags&SCTP_MSG_NOTIFICATION == 0 {
+			listener.captured(false, nil, msgs[i].Info, msgs[i].Buffer[:msgs[i].N])
+		}
+	}
+	return n, listener.wrap("read", "recvmmsg", err)
+}
+
+// SendAsync submits the message to engine, h is invoked from the engine loop once it is sent.
+// b must not be modified until then.
+func (listener *SCTPListener) SendAsync(engine Engine, b []byte, info *SCTPSndRcvInfo, h SendHandler) error {
+	sock, err := listener.socket()
+	if err != nil {
+		return listener.wrap("write", "", err)
+	}
+	if listener.capture.Load() != nil {
+		send := h
+		h = func(n int, err error) {
+			if err == nil {
+				listener.captured(true, nil, info, b[:n])
+			}
+			send(n, err)
+		}
+	}
+	return listener.wrap("write", "submit", engine.SendMsg(sock, b, info, h))
+}
+
+// RecvAsync submits a receive into b to engine, h is invoked from the engine loop with the
+// message. Notifications update the association table before h is invoked.
+func (listener *SCTPListener) RecvAsync(engine Engine, b []byte, h RecvHandler) error {
+	sock, err := listener.socket()
+	if err != nil {
+		return listener.wrap("read", "", err)
+	}
+	return listener.wrap("read", "submit", engine.RecvMsg(sock, b, listener.tracking(h)))
+}
+
+// RecvMultishot keeps receiving messages of up to size bytes through engine until the
+// listener fails or the receive is cancelled. b passed to h is only valid during the call.
+func (listener *SCTPListener) RecvMultishot(engine Engine, size int, h RecvHandler) error {
+	sock, err := listener.socket()
+	if err != nil {
+		return listener.wrap("read", "", err)
+	}
+	return listener.wrap("read", "submit", engine.RecvMultishot(sock, size, listener.tracking(h)))
+}
+
+// tracking wraps h to update the association table from received notifications and to
+// write received messages to the capture.
+func (listener *SCTPListener) tracking(h RecvHandler) RecvHandler {
+	return func(b []byte, info *SCTPSndRcvInfo, flags int, err error) {
+		if err == nil && flags&SCTP_MSG_NOTIFICATION != 0 && flags&syscall.MSG_EOR != 0 {
+			listener.track(b)
+		} else if err == nil && flags&SCTP_MSG_NOTIFICATION == 0 {
+			listener.captured(false, nil, info, b)
+		}
+		h(b, info, flags, err)
+	}
+}
+
+// Associations returns a snapshot of the associations known to the listener.
+// The table is maintained from SCTP_ASSOC_CHANGE and SCTP_SHUTDOWN_EVENT notifications
+// delivered through RecvMsg, so association and shutdown events must be subscribed.
+func (listener *SCTPListener) Associations() []*SCTPAssociation {
+	return listener.assocs.list()
+}
+
+// Lookup returns a snapshot of the association specified by assoc.
+func (listener *SCTPListener) Lookup(assoc int) (*SCTPAssociation, bool) {
+	return listener.assocs.lookup(assoc)
+}
+
+// SetAssociationHooks sets the callbacks invoked when associations go up, down or restart.
+func (listener *SCTPListener) SetAssociationHooks(hooks SCTPAssociationHooks) {
+	listener.assocs.setHooks(hooks)
+}
+
+// RefreshAssociations resynchronizes the association table with the kernel using
+// SCTP_GET_ASSOC_NUMBER and SCTP_GET_ASSOC_ID_LIST.
+func (listener *SCTPListener) RefreshAssociations() error {
+	sock, err := listener.socket()
+	if err != nil {
+		return listener.wrap("get", "", err)
+	}
+	ids, err := SCTPGetAssocIdList(sock)
+	if err != nil {
+		return listener.wrap("get", "getsockopt", err)
+	}
+	listener.assocs.sync(ids, func(id int) *SCTPAssociation {
+		status, err := SCTPGetStatus(sock, id)
+		if err != nil {
+			return nil
+		}
+		return &SCTPAssociation{
+			Id:              id,
+			State:           int(status.State),
+			PeerAddr:        listener.peerAddr(id),
+			InboundStreams:  status.InStreams,
+			OutboundStreams: status.OutStreams,
+			UpSince:         time.Now(),
+		}
+	})
+	return nil
+}
+
+// track updates the association table from a received notification.
+func (listener *SCTPListener) track(data []byte) {
+	notification, err := ParseNotification(data)
+	if err != nil {
+		return
+	}
+	listener.assocs.update(notification, listener.peerAddr)
+}
+
+func (listener *SCTPListener) peerAddr(assoc int) *SCTPAddr {
+	addr, _ := listener.RemoteAddr(assoc).(*SCTPAddr)
+	return addr
+}
+
 // SendMsg sends a message on the SCTP socket.
 func (listener *SCTPListener) SendMsg(b []byte, info *SCTPSndRcvInfo) (int, error) {
-	if listener.sock <= 0 {
-		return 0, errors.New("invalid listener")
+	sock, err := listener.socket()
+	if err != nil {
+		return 0, listener.wrap("write", "", err)
 	}
-	var buffer bytes.Buffer
+	var oob []byte
 	if info != nil {
-		size := int(unsafe.Sizeof(syscall.Cmsghdr{})) + int(unsafe.Sizeof(*info))
-		buffer.Grow(size)
-		hdr := syscall.Cmsghdr{
-			Level: syscall.IPPROTO_SCTP,
-			Type:  SCTP_SNDRCV,
-			Len:   uint64(syscall.CmsgSpace(SCTPSndRcvInfoSize)),
+		var control controlBuffer
+		buffer := control.bytes()
+		oob = buffer[:EncodeSndRcvInfo(buffer, info)]
+	}
+	n, err := SCTPSendMsg(sock, b, oob, 0)
+	if err == nil {
+		listener.captured(true, nil, info, b[:n])
+	}
+	if err != nil && info != nil {
+		return n, listener.wrapAssoc("write", "sendmsg", sock, int(info.AssocId), err)
+	}
+	return n, listener.wrap("write", "sendmsg", err)
+}
+
+// SendTo sends a message to the remote address, setting up the association implicitly
+// when none exists yet. The first address of remote is used as destination and the
+// remaining ones are passed as SCTP_DSTADDRV4/SCTP_DSTADDRV6 for a multi-homed peer.
+// It returns the number of bytes sent and the id of the association used.
+func (listener *SCTPListener) SendTo(b []byte, remote *SCTPAddr, info *SCTPSndRcvInfo) (int, int, error) {
+	sock, err := listener.socket()
+	if err != nil {
+		return 0, 0, listener.wrap("write", "", err)
+	}
+	if remote == nil || len(remote.addresses) == 0 {
+		return 0, 0, listener.wrap("write", "", syscall.EINVAL)
+	}
+	to := MakeSockaddr(&SCTPAddr{
+		addresses: remote.addresses[:1],
+		port:      remote.port,
+	})
+	var control []byte
+	if info != nil {
+		control = AppendCmsg(control, syscall.IPPROTO_SCTP, SCTP_SNDRCV, Pack(info))
+	}
+	for _, address := range remote.addresses[1:] {
+		if ip4 := address.To4(); ip4 != nil {
+			control = AppendCmsg(control, syscall.IPPROTO_SCTP, SCTP_DSTADDRV4, ip4)
+			continue
 		}
-		binary.Write(&buffer, endian, hdr)
-		binary.Write(&buffer, endian, *info)
+		control = AppendCmsg(control, syscall.IPPROTO_SCTP, SCTP_DSTADDRV6, address.To16())
+	}
+	n, err := SCTPSendMsgTo(sock, b, control, to, 0)
+	if err != nil {
+		return n, 0, listener.wrapAddr("write", "sendmsg", remote.Addr(), err)
 	}
-	return SCTPSendMsg(listener.sock, b, buffer.Bytes(), 0)
+	paddr, err := SCTPGetPeerAddrInfo(sock, 0, remote)
+	if err != nil {
+		return n, 0, listener.wrapAddr("write", "getsockopt", remote.Addr(), err)
+	}
+	if listener.capture.Load() != nil {
+		sent := SCTPSndRcvInfo{AssocId: paddr.AssocId}
+		if info != nil {
+			sent = *info
+			sent.AssocId = paddr.AssocId
+		}
+		listener.captured(true, remote, &sent, b[:n])
+	}
+	return n, int(paddr.AssocId), nil
+}
+
+// SetSendMsgConnect enables or disables SCTP_SENDMSG_CONNECT on the listener socket.
+func (listener *SCTPListener) SetSendMsgConnect(enable bool) error {
+	sock, err := listener.socket()
+	if err != nil {
+		return listener.wrap("set", "", err)
+	}
+	value := 0
+	if enable {
+		value = 1
+	}
+	return listener.wrap("set", "setsockopt", syscall.SetsockoptInt(sock, SOL_SCTP, SCTP_SENDMSG_CONNECT, value))
 }
 
 // SetInitMsg sets the SCTP initialization message.
 func (listener *SCTPListener) SetInitMsg(init *SCTPInitMsg) error {
-	if listener.sock <= 0 {
-		return errors.New("invalid listener")
+	sock, err := listener.socket()
+	if err != nil {
+		return listener.wrap("set", "", err)
 	}
 	if init == nil {
-		return errors.New("init cannot be nil")
-	}
-	_, _, err := syscall.Syscall6(
-		syscall.SYS_SETSOCKOPT,
-		uintptr(listener.sock),
-		SOL_SCTP,
-		SCTP_INITMSG,
-		uintptr(unsafe.Pointer(init)),
-		unsafe.Sizeof(*init),
-		0,
-	)
-	if err != 0 {
-		return err
+		return listener.wrap("set", "", fmt.Errorf("%w: init message", ErrNilArgument))
+	}
+	err = setsockopt(sock, SOL_SCTP, SCTP_INITMSG, unsafe.Pointer(init), unsafe.Sizeof(*init))
+	if err != nil {
+		return listener.wrap("set", "setsockopt", err)
 	}
 	return nil
 }
 
 // SetNonblock sets the listener socket to non-blocking mode.
 func (listener *SCTPListener) SetNonblock() error {
-	if listener.sock <= 0 {
-		return errors.New("invalid listener")
+	sock, err := listener.socket()
+	if err != nil {
+		return listener.wrap("set", "", err)
 	}
-	return syscall.SetNonblock(listener.sock, true)
+	return listener.wrap("set", "fcntl", syscall.SetNonblock(sock, true))
 }
 
 // ListenSCTP creates an SCTP listener on the specified network and address.
 func ListenSCTP(network string, sockettype int, local *SCTPAddr, init *SCTPInitMsg) (*SCTPListener, error) {
 	if local == nil {
-		return nil, errors.New("local address cannot be nil")
+		return nil, &net.OpError{
+			Op:  "listen",
+			Net: network,
+			Err: fmt.Errorf("%w: local address", ErrNilArgument),
+		}
 	}
 	if init == nil {
-		return nil, errors.New("init message cannot be nil")
+		return nil, &net.OpError{
+			Op:     "listen",
+			Net:    network,
+			Source: local.Addr(),
+			Err:    fmt.Errorf("%w: init message", ErrNilArgument),
+		}
 	}
 	switch network {
 	case "sctp", "sctp4", "sctp6":
@@ -294,11 +673,19 @@ func ListenSCTP(network string, sockettype int, local *SCTPAddr, init *SCTPInitM
 		err      error
 		listener *SCTPListener
 	)
+	failure := func(call string, err error) error {
+		return &net.OpError{
+			Op:     "listen",
+			Net:    network,
+			Source: local.Addr(),
+			Err:    NewSyscallError(call, err),
+		}
+	}
 	family := DetectAddrFamily(network)
 	// syscall.SOCK_SEQPACKET vs syscall.SOCK_STREAM
 	sock, err = SCTPSocket(family, sockettype)
 	if err != nil {
-		return nil, err
+		return nil, failure("socket", err)
 	}
 	defer func() {
 		if err != nil && sock > 0 {
@@ -307,35 +694,94 @@ func ListenSCTP(network string, sockettype int, local *SCTPAddr, init *SCTPInitM
 	}()
 	err = syscall.SetsockoptInt(sock, syscall.SOL_SOCKET, syscall.SO_BROADCAST, 1)
 	if err != nil {
-		return nil, err
+		return nil, failure("setsockopt", err)
 	}
 	err = syscall.SetsockoptInt(sock, syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
 	if err != nil {
-		return nil, err
+		return nil, failure("setsockopt", err)
 	}
-	_, _, errno := syscall.Syscall6(
-		syscall.SYS_SETSOCKOPT,
-		uintptr(sock),
-		SOL_SCTP,
-		SCTP_INITMSG,
-		uintptr(unsafe.Pointer(init)),
-		unsafe.Sizeof(*init),
-		0,
-	)
-	if errno != 0 {
-		err = errno
-		return nil, err
+	err = setsockopt(sock, SOL_SCTP, SCTP_INITMSG, unsafe.Pointer(init), unsafe.Sizeof(*init))
+	if err != nil {
+		return nil, failure("setsockopt", err)
 	}
 	err = SCTPBind(sock, local, SCTP_BINDX_ADD_ADDR)
 	if err != nil {
-		return nil, err
+		return nil, failure("bind", err)
 	}
 	err = syscall.Listen(sock, syscall.SOMAXCONN)
 	if err != nil {
-		return nil, err
+		return nil, failure("listen", err)
 	}
 	listener = &SCTPListener{
-		sock: sock,
+		sock:    sock,
+		network: network,
 	}
 	return listener, nil
 }
+
+// wrap returns err in a *net.OpError for op on the listener, recording call when err is a
+// syscall.Errno. Errors of a closed listener are reported as ErrListenerClosed.
+func (listener *SCTPListener) wrap(op, call string, err error) error {
+	return listener.wrapAddr(op, call, nil, err)
+}
+
+// wrapAddr is wrap for operations directed at the remote address addr.
+func (listener *SCTPListener) wrapAddr(op, call string, addr net.Addr, err error) error {
+	if err == nil {
+		return nil
+	}
+	if listener.closed.Load() {
+		err = ErrListenerClosed
+	} else {
+		err = NewSyscallError(call, err)
+	}
+	network := listener.network
+	if network == "" {
+		network = "sctp"
+	}
+	return &net.OpError{
+		Op:     op,
+		Net:    network,
+		Source: listener.errorAddr(),
+		Addr:   addr,
+		Err:    err,
+	}
+}
+
+// errorAddr returns the local address reported in errors, looked up on the first error
+// and kept once known.
+func (listener *SCTPListener) errorAddr() net.Addr {
+	if addrs := listener.addrs.Load(); addrs != nil {
+		return addrs.local
+	}
+	local := listener.Addr()
+	if local != nil {
+		listener.addrs.Store(&errorAddrs{local: local})
+	}
+	return local
+}
+
+// wrapAssoc is wrap for operations on the association assoc. The kernel fails these with
+// EPIPE or EINVAL when the association does not exist, which is reported as
+// ErrAssociationNotFound. The kernel is only asked for its associations when the
+// association table does not already know assoc.
+func (listener *SCTPListener) wrapAssoc(op, call string, sock, assoc int, err error) error {
+	if err == syscall.EPIPE || err == syscall.EINVAL {
+		if _, ok := listener.assocs.lookup(assoc); ok {
+			return listener.wrap(op, call, err)
+		}
+		if ids, e := SCTPGetAssocIdList(sock); e == nil && !containsAssoc(ids, assoc) {
+			err = &associationError{assoc: assoc}
+		}
+	}
+	return listener.wrap(op, call, err)
+}
+
+func containsAssoc(ids []int, assoc int) bool {
+	for _, id := range ids {
+		if id == assoc {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package sctp_go
+
+import (
+	"net"
+	"syscall"
+)
+
+// Conn is an SCTP association with the message API, implemented by SCTPConn over the kernel,
+// SCTPUDPConn over UDP encapsulation and PipeConn in memory. Code written against Conn runs
+// over any of them.
+type Conn interface {
+	net.Conn
+	// AssocId returns the association ID.
+	AssocId() int
+	// SendMsg sends a message with the stream, PPID and flags of info.
+	SendMsg(b []byte, info *SCTPSndRcvInfo) (int, error)
+	// RecvMsg receives a message or a notification, flagged SCTP_MSG_NOTIFICATION.
+	RecvMsg(b []byte, info *SCTPSndRcvInfo, flags *int) (int, error)
+	// Abort aborts the association.
+	Abort() error
+	// SetEventSubscribe sets the notifications delivered by RecvMsg.
+	SetEventSubscribe(events *SCTPEventSubscribe) error
+	// GetEventSubscribe returns the notifications delivered by RecvMsg.
+	GetEventSubscribe() (*SCTPEventSubscribe, error)
+	// GetInitMsg returns the streams of the association.
+	GetInitMsg() (*SCTPInitMsg, error)
+}
+
+// Listener accepts associations as Conn, implemented by SCTPListener, SCTPUDPListener and
+// PipeListener.
+type Listener interface {
+	net.Listener
+	// AcceptConn waits for and returns the next association.
+	AcceptConn() (Conn, error)
+	// SetEventSubscribe sets the notifications delivered by the associations accepted.
+	SetEventSubscribe(events *SCTPEventSubscribe) error
+}
+
+var (
+	_ Conn     = (*SCTPConn)(nil)
+	_ Conn     = (*SCTPUDPConn)(nil)
+	_ Conn     = (*PipeConn)(nil)
+	_ Listener = (*SCTPListener)(nil)
+	_ Listener = (*SCTPUDPListener)(nil)
+	_ Listener = (*PipeListener)(nil)
+)
+
+// Dial dials an association over the kernel for the networks "sctp", "sctp4" and "sctp6" and
+// over UDP encapsulation for "sctp+udp", "sctp4+udp" and "sctp6+udp".
+func Dial(network string, local, remote *SCTPAddr, init *SCTPInitMsg) (Conn, error) {
+	if _, ok := udpNetwork(network); ok {
+		conn, err := DialSCTPUDP(network, local, remote, init)
+		if err != nil {
+			return nil, err
+		}
+		return conn, nil
+	}
+	conn, err := DialSCTP(network, local, remote, init)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Listen announces on the local address over the kernel for the networks "sctp", "sctp4"
+// and "sctp6", with a one-to-one socket, and over UDP encapsulation for "sctp+udp",
+// "sctp4+udp" and "sctp6+udp".
+func Listen(network string, local *SCTPAddr, init *SCTPInitMsg) (Listener, error) {
+	if _, ok := udpNetwork(network); ok {
+		listener, err := ListenSCTPUDP(network, local, init)
+		if err != nil {
+			return nil, err
+		}
+		return listener, nil
+	}
+	listener, err := ListenSCTP(network, syscall.SOCK_STREAM, local, init)
+	if err != nil {
+		return nil, err
+	}
+	return listener, nil
+}
@@ -0,0 +1,513 @@
+package sctp_go
+
+import (
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// pipeBufferSize is the bytes a PipeConn queues for its peer before SendMsg blocks.
+const pipeBufferSize = 256 * 1024
+
+// pipeAssocIds numbers the ends of pipes.
+var pipeAssocIds atomic.Int32
+
+// pipeMessage is a message or notification waiting to be read from a PipeConn.
+type pipeMessage struct {
+	data  []byte
+	info  SCTPSndRcvInfo
+	flags int
+}
+
+// pipeAssoc is the association shared by the two ends of a pipe, the state of both ends is
+// guarded by its mutex.
+type pipeAssoc struct {
+	mutex sync.Mutex
+	cond  sync.Cond
+	ends  [2]*PipeConn
+	up    bool
+}
+
+// PipeConn is an end of an in-memory association, for testing code written against Conn
+// without the kernel SCTP module. Messages keep their boundaries, stream, PPID and
+// unordered flag, and are delivered in the order sent. Notifications of the association
+// are generated like the kernel does and synthetic ones are injected with Notify and Fail.
+type PipeConn struct {
+	assoc  *pipeAssoc
+	side   int
+	id     int32
+	local  *SCTPAddr
+	remote *SCTPAddr
+
+	init     SCTPInitMsg
+	events   SCTPEventSubscribe
+	ssn      []uint16
+	tsn      uint32
+	inbox    []pipeMessage
+	buffered int
+	err      error
+	closed   bool
+}
+
+// newPipe returns the two ends of an association, the streams of each end are its
+// outbound streams bounded by the inbound streams of the other.
+func newPipe(local, remote *SCTPAddr, init, peerInit SCTPInitMsg) (*PipeConn, *PipeConn) {
+	assoc := &pipeAssoc{up: true}
+	assoc.cond.L = &assoc.mutex
+	inits := [2]SCTPInitMsg{init, peerInit}
+	addrs := [2]*SCTPAddr{local, remote}
+	for side := range assoc.ends {
+		negotiated := inits[side]
+		negotiated.NumOutStreams = min(inits[side].NumOutStreams, inits[1-side].MaxInStreams)
+		negotiated.MaxInStreams = min(inits[side].MaxInStreams, inits[1-side].NumOutStreams)
+		assoc.ends[side] = &PipeConn{
+			assoc:  assoc,
+			side:   side,
+			id:     pipeAssocIds.Add(1),
+			local:  addrs[side],
+			remote: addrs[1-side],
+			init:   negotiated,
+			ssn:    make([]uint16, negotiated.NumOutStreams),
+		}
+	}
+	return assoc.ends[0], assoc.ends[1]
+}
+
+// Pipe returns the two ends of an in-memory association set up with init, with the
+// defaults of the kernel for a nil init.
+func Pipe(init *SCTPInitMsg) (*PipeConn, *PipeConn) {
+	m := defaultInitMsg(init)
+	return newPipe(
+		&SCTPAddr{addresses: []net.IP{net.IPv4(127, 0, 0, 1)}, port: 1},
+		&SCTPAddr{addresses: []net.IP{net.IPv4(127, 0, 0, 1)}, port: 2},
+		m, m,
+	)
+}
+
+// peer returns the other end of the association.
+func (conn *PipeConn) peer() *PipeConn {
+	return conn.assoc.ends[1-conn.side]
+}
+
+// AssocId returns the association ID of this end.
+func (conn *PipeConn) AssocId() int {
+	return int(conn.id)
+}
+
+// Read reads data from the connection, skipping notifications.
+func (conn *PipeConn) Read(b []byte) (n int, err error) {
+	var (
+		flags = 0
+		info  SCTPSndRcvInfo
+	)
+	for {
+		n, err = conn.RecvMsg(b, &info, &flags)
+		if err != nil || flags&SCTP_MSG_NOTIFICATION == 0 {
+			return n, err
+		}
+	}
+}
+
+// RecvMsg receives a message or a subscribed notification from the connection. A message
+// larger than b is returned over several calls, MSG_EOR is set in flags on the last one.
+// Once the association terminated and the messages received are read it returns io.EOF,
+// or the error of an aborted or failed association.
+func (conn *PipeConn) RecvMsg(b []byte, info *SCTPSndRcvInfo, flags *int) (int, error) {
+	assoc := conn.assoc
+	assoc.mutex.Lock()
+	defer assoc.mutex.Unlock()
+	for len(conn.inbox) == 0 && !conn.closed && assoc.up {
+		assoc.cond.Wait()
+	}
+	switch {
+	case conn.closed:
+		return 0, conn.wrap("read", "recvmsg", net.ErrClosed)
+	case len(conn.inbox) == 0 && conn.err != nil:
+		return 0, conn.wrap("read", "recvmsg", conn.err)
+	case len(conn.inbox) == 0:
+		return 0, io.EOF
+	}
+	message := &conn.inbox[0]
+	n := copy(b, message.data)
+	if info != nil {
+		*info = message.info
+	}
+	flag := message.flags
+	if n < len(message.data) {
+		message.data = message.data[n:]
+		flag &^= syscall.MSG_EOR
+	} else {
+		conn.inbox = conn.inbox[1:]
+	}
+	if flag&SCTP_MSG_NOTIFICATION == 0 {
+		conn.buffered -= n
+		assoc.cond.Broadcast()
+	}
+	if flags != nil {
+		*flags = flag
+	}
+	return n, nil
+}
+
+// Write writes data to the connection on stream 0.
+func (conn *PipeConn) Write(b []byte) (int, error) {
+	return conn.SendMsg(b, nil)
+}
+
+// SendMsg sends a message to the other end, blocking while it has pipeBufferSize bytes
+// unread. SCTP_UNORDERED, SCTP_EOF and SCTP_ABORT in the flags of info are honoured.
+func (conn *PipeConn) SendMsg(b []byte, info *SCTPSndRcvInfo) (int, error) {
+	assoc := conn.assoc
+	assoc.mutex.Lock()
+	defer assoc.mutex.Unlock()
+	var message SCTPSndRcvInfo
+	if info != nil {
+		message = *info
+	}
+	if message.Flags&SCTP_ABORT != 0 {
+		conn.abort()
+		return 0, nil
+	}
+	if len(b) == 0 && message.Flags&SCTP_EOF != 0 {
+		conn.shutdown()
+		return 0, nil
+	}
+	if err := conn.writable(); err != nil {
+		return 0, conn.wrap("write", "sendmsg", err)
+	}
+	if len(b) == 0 || message.Stream >= conn.init.NumOutStreams {
+		return 0, conn.wrap("write", "sendmsg", syscall.EINVAL)
+	}
+	peer := conn.peer()
+	for peer.buffered > 0 && peer.buffered+len(b) > pipeBufferSize {
+		assoc.cond.Wait()
+		if err := conn.writable(); err != nil {
+			return 0, conn.wrap("write", "sendmsg", err)
+		}
+	}
+	eof := message.Flags&SCTP_EOF != 0
+	if message.Flags&SCTP_UNORDERED == 0 {
+		message.Ssn = conn.ssn[message.Stream]
+		conn.ssn[message.Stream]++
+	} else {
+		message.Ssn = 0
+	}
+	conn.tsn++
+	message.Flags &= SCTP_UNORDERED
+	message.Tsn, message.CumTsn = conn.tsn, conn.tsn
+	message.AssocId = peer.id
+	peer.inbox = append(peer.inbox, pipeMessage{
+		data:  append([]byte(nil), b...),
+		info:  message,
+		flags: syscall.MSG_EOR,
+	})
+	peer.buffered += len(b)
+	assoc.cond.Broadcast()
+	if eof {
+		conn.shutdown()
+	}
+	return len(b), nil
+}
+
+// writable returns the error of a send in the current state.
+func (conn *PipeConn) writable() error {
+	switch {
+	case conn.closed:
+		return net.ErrClosed
+	case conn.assoc.up:
+		return nil
+	case conn.err != nil:
+		return conn.err
+	default:
+		return syscall.EPIPE
+	}
+}
+
+// notify queues a notification when events subscribes it or unconditionally for a nil
+// subscription.
+func (conn *PipeConn) notify(data []byte, subscribed func(*SCTPEventSubscribe) bool) {
+	if subscribed != nil && !subscribed(&conn.events) {
+		return
+	}
+	conn.inbox = append(conn.inbox, pipeMessage{
+		data:  data,
+		info:  SCTPSndRcvInfo{AssocId: conn.id},
+		flags: SCTP_MSG_NOTIFICATION | syscall.MSG_EOR,
+	})
+	conn.assoc.cond.Broadcast()
+}
+
+// notifyAssocChange queues an SCTP_ASSOC_CHANGE notification when subscribed.
+func (conn *PipeConn) notifyAssocChange(state uint16, errno syscall.Errno) {
+	conn.notify(Pack(&SCTPAssocChange{
+		Type:            SCTP_ASSOC_CHANGE,
+		Length:          SCTPAssocChangeSize,
+		State:           state,
+		Error:           uint16(errno),
+		OutboundStreams: conn.init.NumOutStreams,
+		InboundStreams:  conn.init.MaxInStreams,
+		AssocId:         conn.id,
+	}), func(events *SCTPEventSubscribe) bool { return events.AssociationEvent != 0 })
+}
+
+// shutdown shuts the association down gracefully, the other end receives an
+// SCTP_SHUTDOWN_EVENT and both an SCTP_SHUTDOWN_COMP once the messages sent are queued.
+func (conn *PipeConn) shutdown() {
+	if !conn.assoc.up {
+		return
+	}
+	peer := conn.peer()
+	peer.notify(Pack(&SCTPShutdownEvent{
+		Type:    SCTP_SHUTDOWN_EVENT,
+		Length:  SCTPShutdownEventSize,
+		AssocId: peer.id,
+	}), func(events *SCTPEventSubscribe) bool { return events.ShutdownEvent != 0 })
+	conn.terminate(SCTP_SHUTDOWN_COMP, 0)
+}
+
+// abort aborts the association, the other end receives an SCTP_COMM_LOST and fails with
+// ECONNRESET.
+func (conn *PipeConn) abort() {
+	if !conn.assoc.up {
+		return
+	}
+	conn.peer().err = syscall.ECONNRESET
+	conn.peer().notifyAssocChange(SCTP_COMM_LOST, 0)
+	conn.err = syscall.ECONNABORTED
+	conn.assoc.up = false
+	conn.assoc.cond.Broadcast()
+}
+
+// terminate ends the association on both ends with an SCTP_ASSOC_CHANGE of state, failing
+// later operations with errno unless zero.
+func (conn *PipeConn) terminate(state uint16, errno syscall.Errno) {
+	if !conn.assoc.up {
+		return
+	}
+	conn.assoc.up = false
+	for _, end := range conn.assoc.ends {
+		if errno != 0 {
+			end.err = errno
+		}
+		end.notifyAssocChange(state, errno)
+	}
+	conn.assoc.cond.Broadcast()
+}
+
+// Notify queues a synthetic notification to be read from this end, whatever the
+// subscription. The association is not affected, see Fail to terminate it.
+func (conn *PipeConn) Notify(n Notification) error {
+	data := Pack(n)
+	if data == nil {
+		return conn.wrap("notify", "", syscall.EINVAL)
+	}
+	conn.assoc.mutex.Lock()
+	defer conn.assoc.mutex.Unlock()
+	conn.notify(data, nil)
+	return nil
+}
+
+// Fail terminates the association as lost, both ends receive an SCTP_COMM_LOST with err if
+// subscribed and their operations fail with err, ETIMEDOUT like an association that
+// exhausted its retransmissions when zero.
+func (conn *PipeConn) Fail(err syscall.Errno) {
+	if err == 0 {
+		err = syscall.ETIMEDOUT
+	}
+	conn.assoc.mutex.Lock()
+	defer conn.assoc.mutex.Unlock()
+	conn.terminate(SCTP_COMM_LOST, err)
+}
+
+// Abort aborts the association, operations blocked on the connection fail.
+func (conn *PipeConn) Abort() error {
+	conn.assoc.mutex.Lock()
+	defer conn.assoc.mutex.Unlock()
+	if conn.closed {
+		return conn.wrap("close", "close", net.ErrClosed)
+	}
+	conn.abort()
+	conn.closed = true
+	conn.assoc.cond.Broadcast()
+	return nil
+}
+
+// Close closes the connection, shutting the association down gracefully. Operations
+// blocked on the connection fail with net.ErrClosed.
+func (conn *PipeConn) Close() error {
+	conn.assoc.mutex.Lock()
+	defer conn.assoc.mutex.Unlock()
+	if conn.closed {
+		return conn.wrap("close", "close", net.ErrClosed)
+	}
+	conn.shutdown()
+	conn.closed = true
+	conn.assoc.cond.Broadcast()
+	return nil
+}
+
+// LocalAddr returns the local network address.
+func (conn *PipeConn) LocalAddr() net.Addr {
+	return conn.local
+}
+
+// RemoteAddr returns the remote network address.
+func (conn *PipeConn) RemoteAddr() net.Addr {
+	return conn.remote
+}
+
+// SetDeadline sets the read and write deadlines. Not supported for SCTP.
+func (conn *PipeConn) SetDeadline(_ time.Time) error {
+	return syscall.ENOPROTOOPT
+}
+
+// SetReadDeadline sets the read deadline. Not supported for SCTP.
+func (conn *PipeConn) SetReadDeadline(_ time.Time) error {
+	return syscall.ENOPROTOOPT
+}
+
+// SetWriteDeadline sets the write deadline. Not supported for SCTP.
+func (conn *PipeConn) SetWriteDeadline(_ time.Time) error {
+	return syscall.ENOPROTOOPT
+}
+
+// SetEventSubscribe sets the notifications of the association delivered by RecvMsg.
+func (conn *PipeConn) SetEventSubscribe(events *SCTPEventSubscribe) error {
+	conn.assoc.mutex.Lock()
+	defer conn.assoc.mutex.Unlock()
+	conn.events = *events
+	return nil
+}
+
+// GetEventSubscribe returns the notifications delivered by RecvMsg.
+func (conn *PipeConn) GetEventSubscribe() (*SCTPEventSubscribe, error) {
+	conn.assoc.mutex.Lock()
+	defer conn.assoc.mutex.Unlock()
+	events := conn.events
+	return &events, nil
+}
+
+// GetInitMsg returns the streams negotiated with the other end in NumOutStreams and
+// MaxInStreams.
+func (conn *PipeConn) GetInitMsg() (*SCTPInitMsg, error) {
+	init := conn.init
+	return &init, nil
+}
+
+// wrap wraps the failure of an operation in a *net.OpError, errno values in a
+// *SyscallError naming call.
+func (conn *PipeConn) wrap(op, call string, err error) error {
+	return &net.OpError{
+		Op:     op,
+		Net:    "pipe",
+		Source: conn.local,
+		Addr:   conn.remote,
+		Err:    NewSyscallError(call, err),
+	}
+}
+
+// PipeListener accepts in-memory associations dialed with its Dial method.
+type PipeListener struct {
+	addr   *SCTPAddr
+	init   SCTPInitMsg
+	mutex  sync.Mutex
+	events SCTPEventSubscribe
+	accept chan *PipeConn
+	done   chan struct{}
+	once   sync.Once
+}
+
+// ListenPipe returns an in-memory listener on addr accepting associations set up with init,
+// with the defaults of the kernel for a nil init.
+func ListenPipe(addr *SCTPAddr, init *SCTPInitMsg) *PipeListener {
+	return &PipeListener{
+		addr:   addr,
+		init:   defaultInitMsg(init),
+		accept: make(chan *PipeConn, 128),
+		done:   make(chan struct{}),
+	}
+}
+
+// Dial sets up an association with the listener from local, it fails with ECONNREFUSED once
+// the listener is closed or its backlog is full.
+func (listener *PipeListener) Dial(local *SCTPAddr, init *SCTPInitMsg) (*PipeConn, error) {
+	if local == nil {
+		local = &SCTPAddr{addresses: []net.IP{net.IPv4(127, 0, 0, 1)}}
+	}
+	conn, peer := newPipe(local, listener.addr, defaultInitMsg(init), listener.init)
+	listener.mutex.Lock()
+	peer.events = listener.events
+	listener.mutex.Unlock()
+	peer.notifyAssocChange(SCTP_COMM_UP, 0)
+	select {
+	case <-listener.done:
+	default:
+		select {
+		case listener.accept <- peer:
+			return conn, nil
+		default:
+		}
+	}
+	return nil, &net.OpError{Op: "dial", Net: "pipe", Source: local, Addr: listener.addr, Err: NewSyscallError("connect", syscall.ECONNREFUSED)}
+}
+
+// AcceptPipe waits for and returns the next association.
+func (listener *PipeListener) AcceptPipe() (*PipeConn, error) {
+	select {
+	case conn := <-listener.accept:
+		return conn, nil
+	case <-listener.done:
+		return nil, &net.OpError{Op: "accept", Net: "pipe", Source: listener.addr, Err: ErrListenerClosed}
+	}
+}
+
+// Accept waits for and returns the next association.
+func (listener *PipeListener) Accept() (net.Conn, error) {
+	return listener.AcceptConn()
+}
+
+// AcceptConn waits for and returns the next association as a Conn.
+func (listener *PipeListener) AcceptConn() (Conn, error) {
+	conn, err := listener.AcceptPipe()
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Addr returns the address of the listener.
+func (listener *PipeListener) Addr() net.Addr {
+	return listener.addr
+}
+
+// SetEventSubscribe sets the notifications delivered by the associations accepted from now
+// on.
+func (listener *PipeListener) SetEventSubscribe(events *SCTPEventSubscribe) error {
+	listener.mutex.Lock()
+	defer listener.mutex.Unlock()
+	listener.events = *events
+	return nil
+}
+
+// Close stops accepting associations and aborts those not accepted yet.
+func (listener *PipeListener) Close() error {
+	closed := false
+	listener.once.Do(func() {
+		closed = true
+		close(listener.done)
+	})
+	if !closed {
+		return &net.OpError{Op: "close", Net: "pipe", Source: listener.addr, Err: ErrListenerClosed}
+	}
+	for {
+		select {
+		case conn := <-listener.accept:
+			_ = conn.Abort()
+		default:
+			return nil
+		}
+	}
+}
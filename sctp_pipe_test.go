@@ -0,0 +1,205 @@
+package sctp_go
+
+import (
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+)
+
+// recvConn reads a message or notification from conn.
+func recvConn(t *testing.T, conn Conn) ([]byte, SCTPSndRcvInfo, int) {
+	t.Helper()
+	var (
+		b     = make([]byte, 256)
+		info  SCTPSndRcvInfo
+		flags int
+	)
+	n, err := conn.RecvMsg(b, &info, &flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b[:n], info, flags
+}
+
+// expectNotification reads a notification of type kind from conn.
+func expectNotification(t *testing.T, conn Conn, kind uint16) Notification {
+	t.Helper()
+	data, _, flags := recvConn(t, conn)
+	if flags&SCTP_MSG_NOTIFICATION == 0 {
+		t.Fatalf("received %q instead of a notification", data)
+	}
+	notification, err := ParseNotification(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notification.GetType() != kind {
+		t.Fatalf("received notification %+v, expected type %d", notification, kind)
+	}
+	return notification
+}
+
+func TestPipe(t *testing.T) {
+	var conn, peer Conn = Pipe(&SCTPInitMsg{NumOutStreams: 4, MaxInStreams: 4})
+	messages := []SCTPSndRcvInfo{
+		{Stream: 1, Ppid: HostToNetwork(18)},
+		{Stream: 2, Flags: SCTP_UNORDERED, Ppid: HostToNetwork(60)},
+		{Stream: 1, Ppid: HostToNetwork(18)},
+	}
+	for i := range messages {
+		if n, err := conn.SendMsg([]byte{byte(i)}, &messages[i]); err != nil || n != 1 {
+			t.Fatalf("send %d: %d, %v", i, n, err)
+		}
+	}
+	for i, expected := range []struct {
+		stream, ssn, flags uint16
+		ppid               uint32
+	}{
+		{1, 0, 0, 18},
+		{2, 0, SCTP_UNORDERED, 60},
+		{1, 1, 0, 18},
+	} {
+		data, info, flags := recvConn(t, peer)
+		if len(data) != 1 || data[0] != byte(i) || flags != syscall.MSG_EOR {
+			t.Errorf("message %d: %v, flags %#x", i, data, flags)
+		}
+		if info.Stream != expected.stream || info.Ssn != expected.ssn || info.Flags != expected.flags ||
+			NetworkToHost(info.Ppid) != expected.ppid || info.AssocId != int32(peer.AssocId()) {
+			t.Errorf("message %d: info %+v", i, info)
+		}
+	}
+
+	if _, err := peer.Write([]byte("partial read")); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 7)
+	var flags int
+	if n, err := conn.RecvMsg(b, nil, &flags); err != nil || string(b[:n]) != "partial" || flags&syscall.MSG_EOR != 0 {
+		t.Fatalf("read %q, flags %#x: %v", b[:n], flags, err)
+	}
+	if n, err := conn.RecvMsg(b, nil, &flags); err != nil || string(b[:n]) != " read" || flags&syscall.MSG_EOR == 0 {
+		t.Fatalf("read %q, flags %#x: %v", b[:n], flags, err)
+	}
+
+	if _, err := conn.SendMsg([]byte("x"), &SCTPSndRcvInfo{Stream: 4}); !errors.Is(err, syscall.EINVAL) {
+		t.Errorf("send on an invalid stream: %v", err)
+	}
+	if init, _ := conn.GetInitMsg(); init.NumOutStreams != 4 || init.MaxInStreams != 4 {
+		t.Errorf("negotiated %+v", init)
+	}
+}
+
+func TestPipeShutdown(t *testing.T) {
+	conn, peer := Pipe(nil)
+	events := &SCTPEventSubscribe{AssociationEvent: 1, ShutdownEvent: 1}
+	_ = peer.SetEventSubscribe(events)
+	if _, err := conn.SendMsg([]byte("bye"), &SCTPSndRcvInfo{Flags: SCTP_EOF}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _, _ := recvConn(t, peer); string(data) != "bye" {
+		t.Fatalf("received %q", data)
+	}
+	expectNotification(t, peer, SCTP_SHUTDOWN_EVENT)
+	if change := expectNotification(t, peer, SCTP_ASSOC_CHANGE).(*SCTPAssocChange); change.State != SCTP_SHUTDOWN_COMP {
+		t.Errorf("state %d", change.State)
+	}
+	if _, err := peer.Read(make([]byte, 8)); err != io.EOF {
+		t.Errorf("read after shutdown: %v", err)
+	}
+	if _, err := peer.Write([]byte("late")); !errors.Is(err, syscall.EPIPE) {
+		t.Errorf("write after shutdown: %v", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Read(make([]byte, 8)); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("read after close: %v", err)
+	}
+
+	conn, peer = Pipe(nil)
+	_ = peer.SetEventSubscribe(events)
+	if err := conn.Abort(); err != nil {
+		t.Fatal(err)
+	}
+	if change := expectNotification(t, peer, SCTP_ASSOC_CHANGE).(*SCTPAssocChange); change.State != SCTP_COMM_LOST {
+		t.Errorf("state %d", change.State)
+	}
+	if _, err := peer.Read(make([]byte, 8)); !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("read after abort: %v", err)
+	}
+}
+
+func TestPipeNotifications(t *testing.T) {
+	conn, peer := Pipe(nil)
+	_ = conn.SetEventSubscribe(&SCTPEventSubscribe{AssociationEvent: 1})
+	if err := conn.Notify(&SCTPPAddrChange{
+		Type:    SCTP_PEER_ADDR_CHANGE,
+		Length:  SCTPPAddrChangeSize,
+		State:   SCTP_ADDR_UNREACHABLE,
+		AssocId: int32(conn.AssocId()),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if change := expectNotification(t, conn, SCTP_PEER_ADDR_CHANGE).(*SCTPPAddrChange); change.State != SCTP_ADDR_UNREACHABLE {
+		t.Errorf("state %d", change.State)
+	}
+
+	if _, err := peer.Write([]byte("queued")); err != nil {
+		t.Fatal(err)
+	}
+	peer.Fail(0)
+	if data, _, _ := recvConn(t, conn); string(data) != "queued" {
+		t.Fatalf("received %q", data)
+	}
+	change := expectNotification(t, conn, SCTP_ASSOC_CHANGE).(*SCTPAssocChange)
+	if change.State != SCTP_COMM_LOST || change.Error != uint16(syscall.ETIMEDOUT) {
+		t.Errorf("notification %+v", change)
+	}
+	for _, c := range []*PipeConn{conn, peer} {
+		if _, err := c.Read(make([]byte, 8)); !errors.Is(err, syscall.ETIMEDOUT) {
+			t.Errorf("read after failure: %v", err)
+		}
+		if _, err := c.Write([]byte("x")); !errors.Is(err, syscall.ETIMEDOUT) {
+			t.Errorf("write after failure: %v", err)
+		}
+	}
+}
+
+func TestPipeListener(t *testing.T) {
+	addr, _ := MakeSCTPAddr("sctp", "127.0.0.1:36412")
+	pipe := ListenPipe(addr, &SCTPInitMsg{NumOutStreams: 2, MaxInStreams: 8})
+	var listener Listener = pipe
+	_ = listener.SetEventSubscribe(&SCTPEventSubscribe{AssociationEvent: 1})
+	conn, err := pipe.Dial(nil, &SCTPInitMsg{NumOutStreams: 16, MaxInStreams: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	accepted, err := listener.AcceptConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	change := expectNotification(t, accepted, SCTP_ASSOC_CHANGE).(*SCTPAssocChange)
+	if change.State != SCTP_COMM_UP || change.OutboundStreams != 2 || change.InboundStreams != 8 {
+		t.Errorf("notification %+v", change)
+	}
+	if accepted.LocalAddr() != addr || conn.RemoteAddr() != addr {
+		t.Errorf("addresses %v, %v", accepted.LocalAddr(), conn.RemoteAddr())
+	}
+	if _, err := conn.SendMsg([]byte("x"), &SCTPSndRcvInfo{Stream: 7}); err != nil {
+		t.Fatal(err)
+	}
+	if _, info, _ := recvConn(t, accepted); info.Stream != 7 {
+		t.Errorf("stream %d", info.Stream)
+	}
+
+	if err := listener.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listener.Accept(); !errors.Is(err, ErrListenerClosed) {
+		t.Errorf("accept after close: %v", err)
+	}
+	if _, err := pipe.Dial(nil, nil); !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Errorf("dial after close: %v", err)
+	}
+}
@@ -0,0 +1,274 @@
+package sctp_go
+
+import (
+	"errors"
+	"syscall"
+)
+
+const (
+	DefaultQueueLimit = 4 << 20
+)
+
+var (
+	// ErrQueueFull is returned by Queue when the message does not fit in the outbound queue.
+	ErrQueueFull = errors.New("outbound queue full")
+	// ErrQueueDropped is returned by Close when queued messages could not be sent before closing.
+	ErrQueueDropped = errors.New("queued messages dropped")
+)
+
+// WouldBlockError is returned by a non-blocking SCTPConn when the operation cannot
+// complete without blocking. It matches syscall.EAGAIN with errors.Is.
+type WouldBlockError struct {
+	Op string
+}
+
+func (e *WouldBlockError) Error() string {
+	return e.Op + ": operation would block"
+}
+
+func (e *WouldBlockError) Unwrap() error {
+	return syscall.EAGAIN
+}
+
+// Temporary reports true, the operation may succeed once the socket is ready.
+func (e *WouldBlockError) Temporary() bool {
+	return true
+}
+
+// Timeout reports false, no deadline expired.
+func (e *WouldBlockError) Timeout() bool {
+	return false
+}
+
+// EventLoop registers file descriptors for readiness callbacks, it is implemented by
+// Poller and Reactor.
+type EventLoop interface {
+	Add(fd int, events Events, cb Callback) error
+	Modify(fd int, events Events) error
+	Del(fd int) error
+}
+
+// outbound holds the event loop registration and the queued messages of a connection.
+type outbound struct {
+	loop     EventLoop
+	events   Events
+	readable func()
+	writable func()
+	messages []outboundMessage
+	size     int
+	limit    int
+	err      error
+}
+
+// outboundMessage is a message waiting in the outbound queue.
+type outboundMessage struct {
+	data []byte
+	info *SCTPSndRcvInfo
+}
+
+// SetNonblock sets the connection socket to non-blocking mode. In non-blocking mode the
+// send and receive methods return a *WouldBlockError instead of waiting.
+func (conn *SCTPConn) SetNonblock(nonblock bool) error {
+	sock, err := conn.fd.acquire()
+	if err != nil {
+		return conn.wrap("set", "", err)
+	}
+	defer conn.fd.release()
+	if err := syscall.SetNonblock(sock, nonblock); err != nil {
+		return conn.wrap("set", "fcntl", err)
+	}
+	conn.nonblock.Store(nonblock)
+	return nil
+}
+
+// OnReadable registers cb to be invoked from loop whenever the connection is readable or
+// failed. A nil cb removes the registration, the connection leaves the loop once it has
+// neither a readable nor a writable callback nor queued messages.
+func (conn *SCTPConn) OnReadable(loop EventLoop, cb func()) error {
+	conn.qmutex.Lock()
+	conn.out.readable = cb
+	return conn.update(loop)
+}
+
+// OnWritable registers cb to be invoked from loop whenever the connection is writable and
+// its outbound queue is empty. A nil cb removes the registration, queued messages are
+// still flushed from loop.
+func (conn *SCTPConn) OnWritable(loop EventLoop, cb func()) error {
+	conn.qmutex.Lock()
+	conn.out.writable = cb
+	return conn.update(loop)
+}
+
+// update registers the connection with loop for the events its callbacks and queue need.
+// It is called with qmutex held and releases it before returning.
+func (conn *SCTPConn) update(loop EventLoop) error {
+	out := &conn.out
+	if loop != nil && out.loop != nil && loop != out.loop {
+		conn.qmutex.Unlock()
+		return errors.New("connection registered with another event loop")
+	}
+	if loop == nil {
+		loop = out.loop
+	}
+	var events Events
+	if out.readable != nil {
+		events |= EventIn | EventRdHup
+	}
+	if out.writable != nil || len(out.messages) > 0 {
+		events |= EventOut
+	}
+	switch {
+	case loop == nil || events == out.events:
+		conn.qmutex.Unlock()
+		return nil
+	case events == 0:
+		out.loop = nil
+		out.events = 0
+		conn.qmutex.Unlock()
+		return loop.Del(conn.fd.sysfd())
+	case out.loop == nil:
+		if err := loop.Add(conn.fd.sysfd(), events, conn.handle); err != nil {
+			conn.qmutex.Unlock()
+			return err
+		}
+		out.loop = loop
+	default:
+		if err := loop.Modify(conn.fd.sysfd(), events); err != nil {
+			conn.qmutex.Unlock()
+			return err
+		}
+	}
+	out.events = events
+	conn.qmutex.Unlock()
+	return nil
+}
+
+// handle dispatches the events of the connection fired by its event loop.
+func (conn *SCTPConn) handle(ev Events) {
+	failed := ev&(EventErr|EventHup) != 0
+	if ev.IsWritable() || failed {
+		_, _ = conn.Flush()
+	}
+	conn.qmutex.Lock()
+	var (
+		readable = conn.out.readable
+		writable = conn.out.writable
+		empty    = len(conn.out.messages) == 0
+	)
+	conn.qmutex.Unlock()
+	if readable != nil && (ev.IsReadable() || ev.IsHangup() || failed) {
+		readable()
+	}
+	if writable != nil && empty && (ev.IsWritable() || failed) {
+		writable()
+	}
+}
+
+// Queue sends the message, or queues a copy of it when the socket would block or earlier
+// messages are still queued. Queued messages are sent in order by Flush, which the event
+// loop calls on writability once a callback is registered. It returns ErrQueueFull when the
+// queue would exceed its limit and the error of a failed flush.
+func (conn *SCTPConn) Queue(b []byte, info *SCTPSndRcvInfo) error {
+	if _, err := conn.fd.acquire(); err != nil {
+		return conn.wrap("write", "", err)
+	}
+	defer conn.fd.release()
+	conn.qmutex.Lock()
+	out := &conn.out
+	if out.err != nil {
+		conn.qmutex.Unlock()
+		return out.err
+	}
+	if len(out.messages) == 0 {
+		_, err := conn.SendMsg(b, info)
+		var blocked *WouldBlockError
+		if !errors.As(err, &blocked) {
+			conn.qmutex.Unlock()
+			return err
+		}
+	}
+	limit := out.limit
+	if limit <= 0 {
+		limit = DefaultQueueLimit
+	}
+	if out.size+len(b) > limit {
+		conn.qmutex.Unlock()
+		return ErrQueueFull
+	}
+	msg := outboundMessage{
+		data: append([]byte(nil), b...),
+	}
+	if info != nil {
+		copied := *info
+		msg.info = &copied
+	}
+	out.messages = append(out.messages, msg)
+	out.size += len(b)
+	return conn.update(nil)
+}
+
+// Flush sends the queued messages in order until the socket would block. It returns the
+// number of messages sent, a failure is kept and also returned by later calls to Queue.
+func (conn *SCTPConn) Flush() (int, error) {
+	if _, err := conn.fd.acquire(); err != nil {
+		return 0, conn.wrap("write", "", err)
+	}
+	defer conn.fd.release()
+	conn.qmutex.Lock()
+	out := &conn.out
+	sent := 0
+	for len(out.messages) > 0 && out.err == nil {
+		msg := out.messages[0]
+		if _, err := conn.SendMsg(msg.data, msg.info); err != nil {
+			var blocked *WouldBlockError
+			if !errors.As(err, &blocked) {
+				out.err = err
+			}
+			break
+		}
+		out.messages[0] = outboundMessage{}
+		out.messages = out.messages[1:]
+		out.size -= len(msg.data)
+		sent++
+	}
+	if len(out.messages) == 0 {
+		out.messages = nil
+	}
+	err := out.err
+	if uerr := conn.update(nil); err == nil {
+		err = uerr
+	}
+	return sent, err
+}
+
+// Queued returns the number of bytes waiting in the outbound queue.
+func (conn *SCTPConn) Queued() int {
+	conn.qmutex.Lock()
+	defer conn.qmutex.Unlock()
+	return conn.out.size
+}
+
+// SetQueueLimit sets the maximum number of bytes held by the outbound queue, defaults to
+// DefaultQueueLimit.
+func (conn *SCTPConn) SetQueueLimit(bytes int) {
+	conn.qmutex.Lock()
+	defer conn.qmutex.Unlock()
+	conn.out.limit = bytes
+}
+
+// detach removes the connection from its event loop and drops the queued messages, after
+// sending what the socket accepts of them when flush is set. It returns the number of
+// messages dropped.
+func (conn *SCTPConn) detach(flush bool) int {
+	if flush {
+		_, _ = conn.Flush()
+	}
+	conn.qmutex.Lock()
+	dropped := len(conn.out.messages)
+	conn.out.readable = nil
+	conn.out.writable = nil
+	conn.out.messages = nil
+	conn.out.size = 0
+	_ = conn.update(nil)
+	return dropped
+}
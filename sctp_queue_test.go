@@ -0,0 +1,175 @@
+package sctp_go
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestNonblockQueue(t *testing.T) {
+	conn, peer := socketpair(t)
+	if err := conn.SetNonblock(true); err != nil {
+		t.Fatal(err)
+	}
+	var buffer [64]byte
+	if _, err := conn.Read(buffer[:]); !errors.Is(err, syscall.EAGAIN) {
+		t.Fatalf("expected would block error, got %v", err)
+	}
+	// Fill the socket until the send would block.
+	payload := make([]byte, 1024)
+	filled := 0
+	for {
+		_, err := conn.SendMsg(payload, nil)
+		if err == nil {
+			filled++
+			continue
+		}
+		var blocked *WouldBlockError
+		if !errors.As(err, &blocked) || blocked.Op != "write" {
+			t.Fatalf("expected *WouldBlockError, got %v", err)
+		}
+		break
+	}
+
+	p, err := NewPoller()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.Run(ctx)
+
+	if err := conn.Queue([]byte("queued"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if conn.Queued() != len("queued") {
+		t.Fatalf("expected %d queued bytes, got %d", len("queued"), conn.Queued())
+	}
+	conn.SetQueueLimit(8)
+	if err := conn.Queue([]byte("overflow"), nil); err != ErrQueueFull {
+		t.Errorf("expected ErrQueueFull, got %v", err)
+	}
+	writable := make(chan struct{}, 1)
+	if err := conn.OnWritable(p, func() {
+		select {
+		case writable <- struct{}{}:
+		default:
+		}
+		_ = conn.OnWritable(nil, nil)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < filled; i++ {
+		if _, err := syscall.Read(peer, buffer[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case <-writable:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for writable callback")
+	}
+	if conn.Queued() != 0 {
+		t.Errorf("queue not flushed, %d bytes left", conn.Queued())
+	}
+	n, err := syscall.Read(peer, buffer[:])
+	if err != nil || string(buffer[:n]) != "queued" {
+		t.Errorf("unexpected flushed message %q: %v", buffer[:n], err)
+	}
+
+	readable := make(chan struct{}, 1)
+	if err := conn.OnReadable(p, func() {
+		var info SCTPSndRcvInfo
+		flags := 0
+		for {
+			if _, err := conn.RecvMsg(buffer[:], &info, &flags); err != nil {
+				break
+			}
+		}
+		select {
+		case readable <- struct{}{}:
+		default:
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := syscall.Write(peer, []byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-readable:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for readable callback")
+	}
+	if err := conn.OnReadable(nil, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCloseQueued(t *testing.T) {
+	fill := func(t *testing.T) (*SCTPConn, int, int) {
+		conn, peer := socketpair(t)
+		if err := conn.SetNonblock(true); err != nil {
+			t.Fatal(err)
+		}
+		payload := make([]byte, 1024)
+		filled := 0
+		for {
+			if _, err := conn.SendMsg(payload, nil); err != nil {
+				break
+			}
+			filled++
+		}
+		for _, msg := range []string{"first", "second"} {
+			if err := conn.Queue([]byte(msg), nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return conn, peer, filled
+	}
+
+	t.Run("flushed", func(t *testing.T) {
+		conn, peer, filled := fill(t)
+		var buffer [2048]byte
+		for i := 0; i < filled; i++ {
+			if _, err := syscall.Read(peer, buffer[:]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := conn.Close(); err != nil {
+			t.Fatalf("expected queued messages flushed, got %v", err)
+		}
+		for _, expected := range []string{"first", "second"} {
+			n, err := syscall.Read(peer, buffer[:])
+			if err != nil || string(buffer[:n]) != expected {
+				t.Errorf("expected %q, got %q: %v", expected, buffer[:n], err)
+			}
+		}
+	})
+
+	t.Run("dropped", func(t *testing.T) {
+		conn, _, _ := fill(t)
+		err := conn.Close()
+		if !errors.Is(err, ErrQueueDropped) {
+			t.Fatalf("expected ErrQueueDropped, got %v", err)
+		}
+		var operr *net.OpError
+		if !errors.As(err, &operr) || operr.Op != "close" {
+			t.Errorf("expected *net.OpError for close, got %#v", err)
+		}
+		if !strings.Contains(err.Error(), "2 messages") {
+			t.Errorf("expected the dropped count in %q", err)
+		}
+		if conn.Queued() != 0 {
+			t.Errorf("expected empty queue after Close, %d bytes left", conn.Queued())
+		}
+		if _, err := conn.Write([]byte("closed")); !errors.Is(err, net.ErrClosed) {
+			t.Errorf("expected net.ErrClosed after Close, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package sctp_go
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// The helpers below issue the socket option and message system calls of the package. They
+// use the direct socket system calls of golang.org/x/sys/unix, which exist on every Linux
+// architecture (on 386 since Linux 4.3, before that only through socketcall), and pass
+// socklen_t lengths as uint32 so the kernel never writes into part of a wider integer.
+
+// getsockopt reads the socket option into the length bytes at value, the buffer may carry
+// input such as an association id. length is updated with the size written by the kernel.
+func getsockopt(sock, level, name int, value unsafe.Pointer, length *uint32) error {
+	_, _, errno := unix.Syscall6(
+		unix.SYS_GETSOCKOPT,
+		uintptr(sock),
+		uintptr(level),
+		uintptr(name),
+		uintptr(value),
+		uintptr(unsafe.Pointer(length)),
+		0,
+	)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// getsockoptBytes reads the socket option into b and returns the number of bytes written
+// by the kernel, in the manner of unix.GetsockoptString.
+func getsockoptBytes(sock, level, name int, b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, unix.EINVAL
+	}
+	length := uint32(len(b))
+	if err := getsockopt(sock, level, name, unsafe.Pointer(&b[0]), &length); err != nil {
+		return 0, err
+	}
+	return int(length), nil
+}
+
+// setsockopt sets the socket option from the length bytes at value.
+func setsockopt(sock, level, name int, value unsafe.Pointer, length uintptr) error {
+	_, _, errno := unix.Syscall6(
+		unix.SYS_SETSOCKOPT,
+		uintptr(sock),
+		uintptr(level),
+		uintptr(name),
+		uintptr(value),
+		length,
+		0,
+	)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// connectx connects the socket to the packed socket addresses with SCTP_SOCKOPT_CONNECTX,
+// which returns the id of the association from the system call.
+func connectx(sock int, addrs []byte) (int, error) {
+	if len(addrs) == 0 {
+		return 0, unix.EINVAL
+	}
+	assoc, _, errno := unix.Syscall6(
+		unix.SYS_SETSOCKOPT,
+		uintptr(sock),
+		unix.IPPROTO_SCTP,
+		SCTP_SOCKOPT_CONNECTX,
+		uintptr(unsafe.Pointer(&addrs[0])),
+		uintptr(len(addrs)),
+		0,
+	)
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(assoc), nil
+}
+
+// sendmsg sends the message described by msg and returns the number of bytes sent.
+func sendmsg(sock int, msg *unix.Msghdr, flags int) (int, error) {
+	n, _, errno := unix.Syscall(
+		unix.SYS_SENDMSG,
+		uintptr(sock),
+		uintptr(unsafe.Pointer(msg)),
+		uintptr(flags),
+	)
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(n), nil
+}
+
+// recvmsg receives a message into msg and returns the number of bytes received, the
+// control length and flags are updated in msg.
+func recvmsg(sock int, msg *unix.Msghdr, flags int) (int, error) {
+	n, _, errno := unix.Syscall(
+		unix.SYS_RECVMSG,
+		uintptr(sock),
+		uintptr(unsafe.Pointer(msg)),
+		uintptr(flags),
+	)
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(n), nil
+}
+
+// sendmmsg sends the messages of headers and returns the number of messages sent.
+func sendmmsg(sock int, headers []MultiMsgHeader, flags int) (int, error) {
+	n, _, errno := unix.Syscall6(
+		unix.SYS_SENDMMSG,
+		uintptr(sock),
+		uintptr(unsafe.Pointer(&headers[0])),
+		uintptr(len(headers)),
+		uintptr(flags),
+		0,
+		0,
+	)
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(n), nil
+}
+
+// recvmmsg receives up to len(headers) messages and returns the number received.
+func recvmmsg(sock int, headers []MultiMsgHeader, flags int) (int, error) {
+	n, _, errno := unix.Syscall6(
+		unix.SYS_RECVMMSG,
+		uintptr(sock),
+		uintptr(unsafe.Pointer(&headers[0])),
+		uintptr(len(headers)),
+		uintptr(flags),
+		0,
+		0,
+	)
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(n), nil
+}
+
+// putCmsg writes the header of a control message with size bytes of data at the start of
+// buffer, in the manner of unix.UnixRights, and returns its data. The header length has the
+// width of size_t of the architecture. buffer must be word aligned and hold CmsgSpace(size)
+// bytes.
+func putCmsg(buffer []byte, level, kind int32, size int) []byte {
+	hdr := (*unix.Cmsghdr)(unsafe.Pointer(&buffer[0]))
+	hdr.Level = level
+	hdr.Type = kind
+	hdr.SetLen(unix.CmsgLen(size))
+	return buffer[unix.CmsgLen(0):unix.CmsgLen(size)]
+}
@@ -0,0 +1,767 @@
+package sctp_go
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/thebagchi/sctp-go/packet"
+)
+
+// SCTPUDPPort is the UDP port registered for SCTP over UDP encapsulation, RFC 6951.
+const SCTPUDPPort = 9899
+
+// Defaults of SCTPUDPConfig, the protocol parameters follow RFC 9260 section 16.
+const (
+	udpRTOInitial         = time.Second
+	udpRTOMin             = time.Second
+	udpRTOMax             = 60 * time.Second
+	udpMaxRetransmissions = 10
+	udpMTU                = 1200
+	udpBufferSize         = 256 * 1024
+	udpCookieLifetime     = 60 * time.Second
+	udpHeartbeatInterval  = 30 * time.Second
+)
+
+// udpCookieSize is the size of a state cookie, the association parameters followed by their
+// HMAC-SHA256.
+const udpCookieSize = 36 + sha256.Size
+
+// SCTPUDPConfig configures the userspace SCTP transport over UDP encapsulation. Zero fields
+// take their default value.
+type SCTPUDPConfig struct {
+	// Port is the UDP port of listeners and of the peers dialed, SCTPUDPPort by default.
+	Port int
+	// LocalPort is the UDP port of dialed associations, an ephemeral port by default.
+	LocalPort int
+	// RTOInitial, RTOMin and RTOMax bound the retransmission timeout.
+	RTOInitial time.Duration
+	RTOMin     time.Duration
+	RTOMax     time.Duration
+	// MaxRetransmissions is the number of consecutive retransmissions after which the
+	// association fails with ETIMEDOUT.
+	MaxRetransmissions int
+	// MTU is the largest UDP payload sent, messages are fragmented to fit.
+	MTU int
+	// SendBuffer and ReceiveBuffer are the bytes queued per association.
+	SendBuffer    int
+	ReceiveBuffer int
+	// CookieLifetime is the validity of the state cookies sent by listeners.
+	CookieLifetime time.Duration
+	// HeartbeatInterval is the idle time, added to the retransmission timeout, after which
+	// a HEARTBEAT probes the peer. An association whose HEARTBEATs go unacknowledged
+	// MaxRetransmissions times in a row fails with ETIMEDOUT.
+	HeartbeatInterval time.Duration
+}
+
+// withDefaults returns a copy of config with zero fields set to their default.
+func (config *SCTPUDPConfig) withDefaults() SCTPUDPConfig {
+	var c SCTPUDPConfig
+	if config != nil {
+		c = *config
+	}
+	defaults := []struct {
+		field *time.Duration
+		value time.Duration
+	}{
+		{&c.RTOInitial, udpRTOInitial},
+		{&c.RTOMin, udpRTOMin},
+		{&c.RTOMax, udpRTOMax},
+		{&c.CookieLifetime, udpCookieLifetime},
+		{&c.HeartbeatInterval, udpHeartbeatInterval},
+	}
+	for _, d := range defaults {
+		if *d.field <= 0 {
+			*d.field = d.value
+		}
+	}
+	if c.Port == 0 {
+		c.Port = SCTPUDPPort
+	}
+	if c.MaxRetransmissions <= 0 {
+		c.MaxRetransmissions = udpMaxRetransmissions
+	}
+	if c.MTU <= packet.CommonHeaderSize+16 {
+		c.MTU = udpMTU
+	}
+	if c.SendBuffer <= 0 {
+		c.SendBuffer = udpBufferSize
+	}
+	if c.ReceiveBuffer <= 0 {
+		c.ReceiveBuffer = udpBufferSize
+	}
+	return c
+}
+
+// fragment returns the largest user data of a DATA chunk fitting the MTU.
+func (config *SCTPUDPConfig) fragment() int {
+	return config.MTU - packet.CommonHeaderSize - 16
+}
+
+// udpNetwork returns the UDP network carrying the SCTP network, "sctp+udp", "sctp4+udp" or
+// "sctp6+udp".
+func udpNetwork(network string) (string, bool) {
+	switch network {
+	case "sctp+udp":
+		return "udp", true
+	case "sctp4+udp":
+		return "udp4", true
+	case "sctp6+udp":
+		return "udp6", true
+	}
+	return "", false
+}
+
+// udpAddr returns the UDP address of the first address of addr at port.
+func udpAddr(addr *SCTPAddr, port int) *net.UDPAddr {
+	if addr == nil || len(addr.addresses) == 0 {
+		return &net.UDPAddr{Port: port}
+	}
+	return &net.UDPAddr{IP: addr.addresses[0], Port: port}
+}
+
+// udpAssocIds numbers the associations of the userspace transport.
+var udpAssocIds atomic.Int32
+
+// udpKey identifies the association with a peer, its UDP address and SCTP port.
+type udpKey struct {
+	addr string
+	port uint16
+}
+
+// udpEndpoint is a UDP socket carrying the associations of an SCTP port. A goroutine reads
+// the socket and feeds the packets to their association, or to the listener for the INIT and
+// COOKIE-ECHO of new associations. The socket is closed once the listener and the last
+// association are gone.
+type udpEndpoint struct {
+	conn   net.PacketConn
+	config SCTPUDPConfig
+	port   uint16
+
+	mutex    sync.Mutex
+	assocs   map[udpKey]*udpAssociation
+	listener *SCTPUDPListener
+	events   SCTPEventSubscribe
+	closed   bool
+}
+
+// newUDPEndpoint returns an endpoint for the SCTP port over conn.
+func newUDPEndpoint(conn net.PacketConn, config SCTPUDPConfig, port uint16) *udpEndpoint {
+	return &udpEndpoint{
+		conn:   conn,
+		config: config,
+		port:   port,
+		assocs: make(map[udpKey]*udpAssociation),
+	}
+}
+
+// newID returns the identifier of a new association.
+func (e *udpEndpoint) newID() int32 {
+	return udpAssocIds.Add(1)
+}
+
+// run reads the socket until it is closed, the associations left fail with the error of
+// the socket.
+func (e *udpEndpoint) run() {
+	b := make([]byte, 0x10000)
+	for {
+		n, from, err := e.conn.ReadFrom(b)
+		if err != nil {
+			e.fail(err)
+			return
+		}
+		var p packet.Packet
+		if err := p.Unmarshal(append([]byte(nil), b[:n]...)); err != nil || p.DestinationPort != e.port {
+			continue
+		}
+		e.dispatch(from, &p)
+	}
+}
+
+// fail terminates the associations of an endpoint whose socket failed.
+func (e *udpEndpoint) fail(err error) {
+	e.mutex.Lock()
+	e.closed = true
+	assocs := make([]*udpAssociation, 0, len(e.assocs))
+	for _, a := range e.assocs {
+		assocs = append(assocs, a)
+	}
+	e.mutex.Unlock()
+	if errors.Is(err, net.ErrClosed) {
+		err = syscall.ECONNABORTED
+	}
+	for _, a := range assocs {
+		a.mutex.Lock()
+		a.terminate(err, SCTP_COMM_LOST)
+		a.mutex.Unlock()
+	}
+}
+
+// dispatch passes a packet to its association or listener, out of the blue packets are
+// answered as of RFC 9260 section 8.4.
+func (e *udpEndpoint) dispatch(from net.Addr, p *packet.Packet) {
+	e.mutex.Lock()
+	a := e.assocs[udpKey{from.String(), p.SourcePort}]
+	listener := e.listener
+	e.mutex.Unlock()
+	switch {
+	case a != nil:
+		a.input(p)
+		return
+	case listener != nil && listener.input(from, p):
+		return
+	case len(p.Chunks) == 0:
+		return
+	}
+	reply := &packet.Packet{
+		SourcePort:      e.port,
+		DestinationPort: p.SourcePort,
+		VerificationTag: p.VerificationTag,
+	}
+	switch c := p.Chunks[0].(type) {
+	case *packet.Abort, *packet.ShutdownComplete, *packet.CookieAck:
+		return
+	case *packet.ShutdownAck:
+		reply.Chunks = []packet.Chunk{&packet.ShutdownComplete{NoTCB: true}}
+	case *packet.Init:
+		if c.Ack {
+			reply.Chunks = []packet.Chunk{&packet.Abort{NoTCB: true}}
+		} else {
+			reply.VerificationTag = c.InitiateTag
+			reply.Chunks = []packet.Chunk{&packet.Abort{}}
+		}
+	default:
+		reply.Chunks = []packet.Chunk{&packet.Abort{NoTCB: true}}
+	}
+	e.send(from, reply)
+}
+
+// send writes a packet to addr, losses are recovered by retransmissions.
+func (e *udpEndpoint) send(addr net.Addr, p *packet.Packet) {
+	data, err := p.Marshal()
+	if err != nil {
+		return
+	}
+	_, _ = e.conn.WriteTo(data, addr)
+}
+
+// register adds an association, unless one with the peer exists which is returned instead.
+func (e *udpEndpoint) register(a *udpAssociation) *udpAssociation {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	key := udpKey{a.peer.String(), a.peerPort}
+	if existing := e.assocs[key]; existing != nil {
+		return existing
+	}
+	e.assocs[key] = a
+	return a
+}
+
+// remove deletes a terminated association.
+func (e *udpEndpoint) remove(a *udpAssociation) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	key := udpKey{a.peer.String(), a.peerPort}
+	if e.assocs[key] == a {
+		delete(e.assocs, key)
+	}
+	e.release()
+}
+
+// release closes the socket once unused, called with the mutex held.
+func (e *udpEndpoint) release() {
+	if !e.closed && e.listener == nil && len(e.assocs) == 0 {
+		e.closed = true
+		_ = e.conn.Close()
+	}
+}
+
+// SCTPUDPConn is an SCTP association run in userspace over UDP encapsulation, RFC 6951. It
+// offers the message API of SCTPConn for hosts where the kernel SCTP module is unavailable.
+// The association is single homed on the first address of the local and remote SCTPAddr,
+// it runs the congestion control of RFC 9260 section 7 and probes an idle peer with
+// HEARTBEATs.
+type SCTPUDPConn struct {
+	assoc   *udpAssociation
+	network string
+	local   *SCTPAddr
+	remote  *SCTPAddr
+}
+
+// newSCTPUDPConn returns the connection of an association.
+func newSCTPUDPConn(a *udpAssociation, network string) *SCTPUDPConn {
+	local := a.endpoint.conn.LocalAddr().(*net.UDPAddr)
+	remote := a.peer.(*net.UDPAddr)
+	return &SCTPUDPConn{
+		assoc:   a,
+		network: network,
+		local:   &SCTPAddr{addresses: []net.IP{local.IP}, port: int(a.localPort)},
+		remote:  &SCTPAddr{addresses: []net.IP{remote.IP}, port: int(a.peerPort)},
+	}
+}
+
+// AssocId returns the association ID.
+func (conn *SCTPUDPConn) AssocId() int {
+	return int(conn.assoc.id)
+}
+
+// Read reads data from the connection, skipping notifications.
+func (conn *SCTPUDPConn) Read(b []byte) (n int, err error) {
+	var (
+		flags = 0
+		info  SCTPSndRcvInfo
+	)
+	for {
+		n, err = conn.RecvMsg(b, &info, &flags)
+		if err != nil || flags&SCTP_MSG_NOTIFICATION == 0 {
+			return n, err
+		}
+	}
+}
+
+// RecvMsg receives a message or a subscribed notification from the connection. A message
+// larger than b is returned over several calls, MSG_EOR is set in flags on the last one.
+// Once the peer shut the association down and the messages received are read it returns
+// io.EOF.
+func (conn *SCTPUDPConn) RecvMsg(b []byte, info *SCTPSndRcvInfo, flags *int) (int, error) {
+	n, err := conn.assoc.read(b, info, flags)
+	if err == io.EOF {
+		return 0, err
+	}
+	return n, conn.wrap("read", "recvmsg", err)
+}
+
+// Write writes data to the connection on stream 0.
+func (conn *SCTPUDPConn) Write(b []byte) (int, error) {
+	return conn.SendMsg(b, nil)
+}
+
+// SendMsg sends a message on the connection, blocking while the send buffer is full.
+// SCTP_UNORDERED, SCTP_EOF and SCTP_ABORT in the flags of info are honoured.
+func (conn *SCTPUDPConn) SendMsg(b []byte, info *SCTPSndRcvInfo) (int, error) {
+	n, err := conn.assoc.write(b, info)
+	return n, conn.wrap("write", "sendmsg", err)
+}
+
+// Abort aborts the association, operations blocked on the connection fail.
+func (conn *SCTPUDPConn) Abort() error {
+	a := conn.assoc
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.closed {
+		return conn.wrap("close", "close", net.ErrClosed)
+	}
+	a.closed = true
+	a.abort()
+	a.cond.Broadcast()
+	return nil
+}
+
+// Close closes the connection. The association is shut down gracefully in the background
+// once the data queued is acknowledged, operations blocked on the connection fail with
+// net.ErrClosed.
+func (conn *SCTPUDPConn) Close() error {
+	a := conn.assoc
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.closed {
+		return conn.wrap("close", "close", net.ErrClosed)
+	}
+	a.closed = true
+	a.close()
+	return nil
+}
+
+// LocalAddr returns the local network address.
+func (conn *SCTPUDPConn) LocalAddr() net.Addr {
+	return conn.local
+}
+
+// RemoteAddr returns the remote network address.
+func (conn *SCTPUDPConn) RemoteAddr() net.Addr {
+	return conn.remote
+}
+
+// SetDeadline sets the read and write deadlines. Not supported for SCTP.
+func (conn *SCTPUDPConn) SetDeadline(_ time.Time) error {
+	return syscall.ENOPROTOOPT
+}
+
+// SetReadDeadline sets the read deadline. Not supported for SCTP.
+func (conn *SCTPUDPConn) SetReadDeadline(_ time.Time) error {
+	return syscall.ENOPROTOOPT
+}
+
+// SetWriteDeadline sets the write deadline. Not supported for SCTP.
+func (conn *SCTPUDPConn) SetWriteDeadline(_ time.Time) error {
+	return syscall.ENOPROTOOPT
+}
+
+// SetEventSubscribe sets the notifications delivered by RecvMsg. SCTP_ASSOC_CHANGE and
+// SCTP_SHUTDOWN_EVENT are generated by the userspace transport.
+func (conn *SCTPUDPConn) SetEventSubscribe(events *SCTPEventSubscribe) error {
+	conn.assoc.mutex.Lock()
+	defer conn.assoc.mutex.Unlock()
+	conn.assoc.events = *events
+	return nil
+}
+
+// GetEventSubscribe returns the notifications delivered by RecvMsg.
+func (conn *SCTPUDPConn) GetEventSubscribe() (*SCTPEventSubscribe, error) {
+	conn.assoc.mutex.Lock()
+	defer conn.assoc.mutex.Unlock()
+	events := conn.assoc.events
+	return &events, nil
+}
+
+// GetInitMsg returns the streams negotiated with the peer in NumOutStreams and MaxInStreams.
+func (conn *SCTPUDPConn) GetInitMsg() (*SCTPInitMsg, error) {
+	conn.assoc.mutex.Lock()
+	defer conn.assoc.mutex.Unlock()
+	init := conn.assoc.init
+	init.NumOutStreams, init.MaxInStreams = conn.assoc.outStreams, conn.assoc.inStreams
+	return &init, nil
+}
+
+// wrap wraps the failure of an operation in a *net.OpError like SCTPConn, errno values in a
+// *SyscallError naming call.
+func (conn *SCTPUDPConn) wrap(op, call string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &net.OpError{
+		Op:     op,
+		Net:    conn.network,
+		Source: conn.local,
+		Addr:   conn.remote,
+		Err:    NewSyscallError(call, err),
+	}
+}
+
+// defaultInitMsg returns init with the defaults of the kernel for zero fields.
+func defaultInitMsg(init *SCTPInitMsg) SCTPInitMsg {
+	var m SCTPInitMsg
+	if init != nil {
+		m = *init
+	}
+	if m.NumOutStreams == 0 {
+		m.NumOutStreams = 10
+	}
+	if m.MaxInStreams == 0 {
+		m.MaxInStreams = 0xffff
+	}
+	if m.MaxAttempts == 0 {
+		m.MaxAttempts = 8
+	}
+	return m
+}
+
+// Dial sets up an association over UDP encapsulation to the remote address, network is
+// "sctp+udp", "sctp4+udp" or "sctp6+udp". The UDP port of the peer is config.Port, the SCTP
+// ports are the ports of the addresses. Without a local SCTP port the local UDP port is
+// used.
+func (config *SCTPUDPConfig) Dial(network string, local, remote *SCTPAddr, init *SCTPInitMsg) (*SCTPUDPConn, error) {
+	c := config.withDefaults()
+	failure := func(err error) error {
+		return &net.OpError{Op: "dial", Net: network, Source: local.Addr(), Addr: remote.Addr(), Err: err}
+	}
+	udp, ok := udpNetwork(network)
+	if !ok {
+		return nil, failure(net.UnknownNetworkError(network))
+	}
+	if remote == nil || len(remote.addresses) == 0 {
+		return nil, failure(net.InvalidAddrError("invalid remote addr"))
+	}
+	pc, err := net.ListenUDP(udp, udpAddr(local, c.LocalPort))
+	if err != nil {
+		return nil, failure(err)
+	}
+	return dialSCTPUDP(pc, c, network, local, remote, init)
+}
+
+// dialSCTPUDP sets up an association over conn, which is closed with the association.
+func dialSCTPUDP(conn net.PacketConn, config SCTPUDPConfig, network string, local, remote *SCTPAddr, init *SCTPInitMsg) (*SCTPUDPConn, error) {
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	if local != nil && local.port != 0 {
+		port = local.port
+	}
+	endpoint := newUDPEndpoint(conn, config, uint16(port))
+	a := endpoint.register(newUDPAssociation(endpoint, udpAddr(remote, config.Port), uint16(remote.port), defaultInitMsg(init)))
+	go endpoint.run()
+	a.connect()
+	if err := a.wait(); err != nil {
+		return nil, &net.OpError{Op: "dial", Net: network, Source: local.Addr(), Addr: remote.Addr(), Err: NewSyscallError("connect", err)}
+	}
+	return newSCTPUDPConn(a, network), nil
+}
+
+// SCTPUDPListener accepts associations over UDP encapsulation. It answers INIT chunks
+// statelessly with a signed state cookie, an association is created by a valid COOKIE-ECHO.
+type SCTPUDPListener struct {
+	endpoint *udpEndpoint
+	network  string
+	local    *SCTPAddr
+	init     SCTPInitMsg
+	secret   []byte
+	accept   chan *SCTPUDPConn
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Listen announces on the local address over UDP encapsulation, network is "sctp+udp",
+// "sctp4+udp" or "sctp6+udp". The listener binds config.Port on the first address of local
+// and accepts associations to the SCTP port of local.
+func (config *SCTPUDPConfig) Listen(network string, local *SCTPAddr, init *SCTPInitMsg) (*SCTPUDPListener, error) {
+	c := config.withDefaults()
+	failure := func(err error) error {
+		return &net.OpError{Op: "listen", Net: network, Source: local.Addr(), Err: err}
+	}
+	udp, ok := udpNetwork(network)
+	if !ok {
+		return nil, failure(net.UnknownNetworkError(network))
+	}
+	if local == nil || local.port == 0 {
+		return nil, failure(net.InvalidAddrError("invalid local addr"))
+	}
+	pc, err := net.ListenUDP(udp, udpAddr(local, c.Port))
+	if err != nil {
+		return nil, failure(err)
+	}
+	return listenSCTPUDP(pc, c, network, local, init), nil
+}
+
+// listenSCTPUDP returns a listener over conn.
+func listenSCTPUDP(conn net.PacketConn, config SCTPUDPConfig, network string, local *SCTPAddr, init *SCTPInitMsg) *SCTPUDPListener {
+	listener := &SCTPUDPListener{
+		endpoint: newUDPEndpoint(conn, config, uint16(local.port)),
+		network:  network,
+		init:     defaultInitMsg(init),
+		secret:   make([]byte, sha256.Size),
+		accept:   make(chan *SCTPUDPConn, 128),
+		done:     make(chan struct{}),
+	}
+	if _, err := rand.Read(listener.secret); err != nil {
+		panic(err)
+	}
+	address := conn.LocalAddr().(*net.UDPAddr)
+	listener.local = &SCTPAddr{addresses: []net.IP{address.IP}, port: local.port}
+	listener.endpoint.listener = listener
+	go listener.endpoint.run()
+	return listener
+}
+
+// DialSCTPUDP dials an association over UDP encapsulation with the default configuration.
+func DialSCTPUDP(network string, local, remote *SCTPAddr, init *SCTPInitMsg) (*SCTPUDPConn, error) {
+	return (&SCTPUDPConfig{}).Dial(network, local, remote, init)
+}
+
+// ListenSCTPUDP announces on the local address over UDP encapsulation with the default
+// configuration.
+func ListenSCTPUDP(network string, local *SCTPAddr, init *SCTPInitMsg) (*SCTPUDPListener, error) {
+	return (&SCTPUDPConfig{}).Listen(network, local, init)
+}
+
+// AcceptSCTPUDP waits for and returns the next association.
+func (listener *SCTPUDPListener) AcceptSCTPUDP() (*SCTPUDPConn, error) {
+	select {
+	case conn := <-listener.accept:
+		return conn, nil
+	case <-listener.done:
+		return nil, &net.OpError{Op: "accept", Net: listener.network, Source: listener.local, Err: ErrListenerClosed}
+	}
+}
+
+// Accept waits for and returns the next association.
+func (listener *SCTPUDPListener) Accept() (net.Conn, error) {
+	return listener.AcceptSCTPUDP()
+}
+
+// AcceptConn waits for and returns the next association as a Conn.
+func (listener *SCTPUDPListener) AcceptConn() (Conn, error) {
+	conn, err := listener.AcceptSCTPUDP()
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Addr returns the local address of the listener, with the SCTP port.
+func (listener *SCTPUDPListener) Addr() net.Addr {
+	return listener.local
+}
+
+// UDPAddr returns the UDP address the listener is bound to.
+func (listener *SCTPUDPListener) UDPAddr() net.Addr {
+	return listener.endpoint.conn.LocalAddr()
+}
+
+// SetEventSubscribe sets the notifications delivered by the associations accepted from now
+// on.
+func (listener *SCTPUDPListener) SetEventSubscribe(events *SCTPEventSubscribe) error {
+	listener.endpoint.mutex.Lock()
+	defer listener.endpoint.mutex.Unlock()
+	listener.endpoint.events = *events
+	return nil
+}
+
+// Close stops accepting associations and aborts those not accepted yet, the associations
+// accepted are left running.
+func (listener *SCTPUDPListener) Close() error {
+	closed := false
+	listener.once.Do(func() {
+		closed = true
+		close(listener.done)
+		e := listener.endpoint
+		e.mutex.Lock()
+		e.listener = nil
+		e.release()
+		e.mutex.Unlock()
+	})
+	if !closed {
+		return &net.OpError{Op: "close", Net: listener.network, Source: listener.local, Err: ErrListenerClosed}
+	}
+	for {
+		select {
+		case conn := <-listener.accept:
+			_ = conn.Abort()
+		default:
+			return nil
+		}
+	}
+}
+
+// input processes a packet of an unknown peer and reports whether it was handled.
+func (listener *SCTPUDPListener) input(from net.Addr, p *packet.Packet) bool {
+	if len(p.Chunks) == 0 {
+		return false
+	}
+	switch c := p.Chunks[0].(type) {
+	case *packet.Init:
+		if c.Ack || p.VerificationTag != 0 || len(p.Chunks) != 1 {
+			return false
+		}
+		listener.answer(from, p.SourcePort, c)
+		return true
+	case *packet.CookieEcho:
+		return listener.establish(from, p, c.Cookie)
+	}
+	return false
+}
+
+// answer sends the INIT-ACK with a state cookie for an INIT, no state is kept until the
+// cookie is echoed.
+func (listener *SCTPUDPListener) answer(from net.Addr, port uint16, init *packet.Init) {
+	e := listener.endpoint
+	if init.InitiateTag == 0 || init.OutboundStreams == 0 || init.InboundStreams == 0 {
+		e.send(from, &packet.Packet{
+			SourcePort:      e.port,
+			DestinationPort: port,
+			VerificationTag: init.InitiateTag,
+			Chunks:          []packet.Chunk{&packet.Abort{Causes: []packet.ErrorCause{{Code: packet.CauseInvalidMandatoryParameter}}}},
+		})
+		return
+	}
+	ack := &packet.Init{
+		Ack:              true,
+		InitiateTag:      randomTag(),
+		AdvertisedWindow: uint32(e.config.ReceiveBuffer),
+		OutboundStreams:  listener.init.NumOutStreams,
+		InboundStreams:   listener.init.MaxInStreams,
+		InitialTSN:       randomTag(),
+	}
+	cookie := make([]byte, 0, udpCookieSize)
+	cookie = binary.BigEndian.AppendUint32(cookie, ack.InitiateTag)
+	cookie = binary.BigEndian.AppendUint32(cookie, ack.InitialTSN)
+	cookie = binary.BigEndian.AppendUint32(cookie, init.InitiateTag)
+	cookie = binary.BigEndian.AppendUint32(cookie, init.InitialTSN)
+	cookie = binary.BigEndian.AppendUint32(cookie, init.AdvertisedWindow)
+	cookie = binary.BigEndian.AppendUint16(cookie, init.OutboundStreams)
+	cookie = binary.BigEndian.AppendUint16(cookie, init.InboundStreams)
+	cookie = binary.BigEndian.AppendUint16(cookie, port)
+	cookie = binary.BigEndian.AppendUint16(cookie, 0)
+	cookie = binary.BigEndian.AppendUint64(cookie, uint64(time.Now().UnixNano()))
+	cookie = append(cookie, listener.sign(from, cookie)...)
+	ack.Params = []packet.Param{&packet.StateCookie{Cookie: cookie}}
+	e.send(from, &packet.Packet{
+		SourcePort:      e.port,
+		DestinationPort: port,
+		VerificationTag: init.InitiateTag,
+		Chunks:          []packet.Chunk{ack},
+	})
+}
+
+// sign returns the HMAC of a state cookie sent to addr.
+func (listener *SCTPUDPListener) sign(addr net.Addr, cookie []byte) []byte {
+	mac := hmac.New(sha256.New, listener.secret)
+	mac.Write(cookie)
+	mac.Write([]byte(addr.String()))
+	return mac.Sum(nil)
+}
+
+// establish creates the association of a valid COOKIE-ECHO and queues it to be accepted.
+func (listener *SCTPUDPListener) establish(from net.Addr, p *packet.Packet, cookie []byte) bool {
+	if len(cookie) != udpCookieSize {
+		return false
+	}
+	state, mac := cookie[:udpCookieSize-sha256.Size], cookie[udpCookieSize-sha256.Size:]
+	if !hmac.Equal(mac, listener.sign(from, state)) ||
+		binary.BigEndian.Uint32(state) != p.VerificationTag ||
+		binary.BigEndian.Uint16(state[24:]) != p.SourcePort {
+		return false
+	}
+	e := listener.endpoint
+	created := time.Unix(0, int64(binary.BigEndian.Uint64(state[28:])))
+	if time.Since(created) > e.config.CookieLifetime {
+		staleness := uint32(time.Since(created) / time.Microsecond)
+		e.send(from, &packet.Packet{
+			SourcePort:      e.port,
+			DestinationPort: p.SourcePort,
+			VerificationTag: binary.BigEndian.Uint32(state[8:]),
+			Chunks:          []packet.Chunk{&packet.Error{Causes: []packet.ErrorCause{{Code: packet.CauseStaleCookie, Info: binary.BigEndian.AppendUint32(nil, staleness)}}}},
+		})
+		return true
+	}
+	e.mutex.Lock()
+	a := newUDPAssociation(e, from, p.SourcePort, listener.init)
+	e.mutex.Unlock()
+	a.localTag = binary.BigEndian.Uint32(state)
+	a.nextTSN = binary.BigEndian.Uint32(state[4:])
+	a.ackedTSN = a.nextTSN - 1
+	a.established(&packet.Init{
+		InitiateTag:      binary.BigEndian.Uint32(state[8:]),
+		InitialTSN:       binary.BigEndian.Uint32(state[12:]),
+		AdvertisedWindow: binary.BigEndian.Uint32(state[16:]),
+		OutboundStreams:  binary.BigEndian.Uint16(state[20:]),
+		InboundStreams:   binary.BigEndian.Uint16(state[22:]),
+	})
+	if existing := e.register(a); existing != a {
+		existing.input(p)
+		return true
+	}
+	a.mutex.Lock()
+	a.up()
+	a.mutex.Unlock()
+	select {
+	case <-listener.done:
+	default:
+		select {
+		case listener.accept <- newSCTPUDPConn(a, listener.network):
+			a.input(p)
+			return true
+		default:
+		}
+	}
+	a.mutex.Lock()
+	a.send(a.peerTag, &packet.Abort{})
+	a.terminate(syscall.ECONNREFUSED, SCTP_COMM_LOST)
+	a.mutex.Unlock()
+	return true
+}
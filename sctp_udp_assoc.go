@@ -0,0 +1,1058 @@
+package sctp_go
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/thebagchi/sctp-go/packet"
+)
+
+// States of a userspace association, RFC 9260 section 4.
+const (
+	udpClosed = iota
+	udpCookieWait
+	udpCookieEchoed
+	udpEstablished
+	udpShutdownPending
+	udpShutdownSent
+	udpShutdownReceived
+	udpShutdownAckSent
+)
+
+// udpChunk is a DATA chunk queued or in flight. A chunk in flight marked for
+// retransmission is sent again as the congestion window allows, misses counts the SACKs
+// reporting it missing for fast retransmit.
+type udpChunk struct {
+	data          packet.Data
+	sent          time.Time
+	retransmitted bool
+	acked         bool
+	marked        bool
+	misses        int
+	fast          bool
+}
+
+// size returns the size of the DATA chunk in a packet, padded to 4 bytes.
+func (chunk *udpChunk) size() int {
+	return 16 + (len(chunk.data.UserData)+3)&^3
+}
+
+// udpMessage is a message or notification waiting to be read.
+type udpMessage struct {
+	data  []byte
+	info  SCTPSndRcvInfo
+	flags int
+}
+
+// udpTimer is a restartable timer of an association, fired under the association mutex.
+type udpTimer struct {
+	timer *time.Timer
+	gen   uint64
+}
+
+// udpAssociation is the state machine of an association over UDP encapsulation. All its
+// fields are guarded by mutex, the endpoint feeds it received packets and timers fire
+// under the mutex.
+type udpAssociation struct {
+	endpoint  *udpEndpoint
+	config    *SCTPUDPConfig
+	peer      net.Addr
+	id        int32
+	localPort uint16
+	peerPort  uint16
+
+	mutex  sync.Mutex
+	cond   sync.Cond
+	state  int
+	events SCTPEventSubscribe
+
+	localTag   uint32
+	peerTag    uint32
+	outStreams uint16
+	inStreams  uint16
+	init       SCTPInitMsg
+	initChunk  *packet.Init
+	cookie     []byte
+	attempts   int
+
+	nextTSN    uint32
+	ackedTSN   uint32
+	ssn        []uint16
+	queue      []*udpChunk
+	flight     []*udpChunk
+	queued     int
+	peerWindow uint32
+	cwnd       int
+	ssthresh   int
+	ackedBytes int
+	recovery   bool
+	recoverTSN uint32
+	probe      []byte
+	rto        time.Duration
+	srtt       time.Duration
+	rttvar     time.Duration
+	failures   int
+
+	cumTSN     uint32
+	received   map[uint32]*packet.Data
+	duplicates []uint32
+	partial    []byte
+	expected   []uint16
+	reorder    []map[uint16]udpMessage
+	inbox      []udpMessage
+	buffered   int
+
+	handshake udpTimer
+	rtx       udpTimer
+	shutdown  udpTimer
+	idle      udpTimer
+
+	err    error
+	closed bool
+}
+
+// newUDPAssociation returns an association in the closed state with a random verification
+// tag and initial TSN.
+func newUDPAssociation(endpoint *udpEndpoint, peer net.Addr, peerPort uint16, init SCTPInitMsg) *udpAssociation {
+	a := &udpAssociation{
+		endpoint:  endpoint,
+		config:    &endpoint.config,
+		peer:      peer,
+		id:        endpoint.newID(),
+		localPort: endpoint.port,
+		peerPort:  peerPort,
+		init:      init,
+		localTag:  randomTag(),
+		received:  make(map[uint32]*packet.Data),
+		rto:       endpoint.config.RTOInitial,
+		events:    endpoint.events,
+	}
+	a.cond.L = &a.mutex
+	a.nextTSN = randomTag()
+	a.ackedTSN = a.nextTSN - 1
+	return a
+}
+
+// randomTag returns a random non-zero 32-bit value for verification tags and initial TSNs.
+func randomTag() uint32 {
+	var b [4]byte
+	for {
+		if _, err := rand.Read(b[:]); err != nil {
+			panic(err)
+		}
+		if tag := binary.BigEndian.Uint32(b[:]); tag != 0 {
+			return tag
+		}
+	}
+}
+
+// tsnLess compares TSNs with serial number arithmetic.
+func tsnLess(a, b uint32) bool {
+	return int32(a-b) < 0
+}
+
+// start arms t to invoke fire after d, replacing a pending expiry.
+func (a *udpAssociation) start(t *udpTimer, d time.Duration, fire func()) {
+	a.stop(t)
+	gen := t.gen
+	t.timer = time.AfterFunc(d, func() {
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+		if t.gen == gen && t.timer != nil {
+			t.timer = nil
+			fire()
+		}
+	})
+}
+
+// stop disarms t.
+func (a *udpAssociation) stop(t *udpTimer) {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	t.gen++
+}
+
+// send writes the chunks to the peer in a packet with tag as verification tag.
+func (a *udpAssociation) send(tag uint32, chunks ...packet.Chunk) {
+	a.endpoint.send(a.peer, &packet.Packet{
+		SourcePort:      a.localPort,
+		DestinationPort: a.peerPort,
+		VerificationTag: tag,
+		Chunks:          chunks,
+	})
+}
+
+// initParams returns the fixed part of the INIT or INIT-ACK of the association.
+func (a *udpAssociation) initParams(ack bool) *packet.Init {
+	return &packet.Init{
+		Ack:              ack,
+		InitiateTag:      a.localTag,
+		AdvertisedWindow: uint32(a.config.ReceiveBuffer),
+		OutboundStreams:  a.init.NumOutStreams,
+		InboundStreams:   a.init.MaxInStreams,
+		InitialTSN:       a.nextTSN,
+	}
+}
+
+// connect sends the INIT of an association set up by Dial.
+func (a *udpAssociation) connect() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.state = udpCookieWait
+	a.initChunk = a.initParams(false)
+	a.send(0, a.initChunk)
+	a.start(&a.handshake, a.rto, a.retransmitHandshake)
+}
+
+// retransmitHandshake resends the INIT or COOKIE-ECHO until the init attempts run out.
+func (a *udpAssociation) retransmitHandshake() {
+	a.attempts++
+	if a.attempts >= int(a.init.MaxAttempts) {
+		a.terminate(syscall.ETIMEDOUT, SCTP_CANT_STR_ASSOC)
+		return
+	}
+	a.rto = min(2*a.rto, a.config.RTOMax)
+	switch a.state {
+	case udpCookieWait:
+		a.send(0, a.initChunk)
+	case udpCookieEchoed:
+		a.send(a.peerTag, &packet.CookieEcho{Cookie: a.cookie})
+	default:
+		return
+	}
+	a.start(&a.handshake, a.rto, a.retransmitHandshake)
+}
+
+// wait blocks until the association set up by Dial is established or failed.
+func (a *udpAssociation) wait() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for a.state == udpCookieWait || a.state == udpCookieEchoed {
+		a.cond.Wait()
+	}
+	if a.state != udpEstablished {
+		if a.err != nil {
+			return a.err
+		}
+		return syscall.ECONNREFUSED
+	}
+	return nil
+}
+
+// established moves the association to the established state with the parameters of the
+// INIT or INIT-ACK of the peer.
+func (a *udpAssociation) established(peer *packet.Init) {
+	a.peerTag = peer.InitiateTag
+	a.outStreams = min(a.init.NumOutStreams, peer.InboundStreams)
+	a.inStreams = min(a.init.MaxInStreams, peer.OutboundStreams)
+	a.cumTSN = peer.InitialTSN - 1
+	a.peerWindow = peer.AdvertisedWindow
+	a.cwnd = min(4*a.config.MTU, max(2*a.config.MTU, 4404))
+	a.ssthresh = int(peer.AdvertisedWindow)
+	a.ssn = make([]uint16, a.outStreams)
+	a.expected = make([]uint16, a.inStreams)
+	a.reorder = make([]map[uint16]udpMessage, a.inStreams)
+	a.state = udpEstablished
+}
+
+// input processes a packet received for the association.
+func (a *udpAssociation) input(p *packet.Packet) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if !a.verify(p) {
+		return
+	}
+	sack := false
+	for _, chunk := range p.Chunks {
+		switch c := chunk.(type) {
+		case *packet.Init:
+			a.handleInit(c)
+		case *packet.CookieEcho:
+			if a.state == udpEstablished {
+				a.send(a.peerTag, &packet.CookieAck{})
+			}
+		case *packet.CookieAck:
+			if a.state == udpCookieEchoed {
+				a.stop(&a.handshake)
+				a.state = udpEstablished
+				a.rto = a.config.RTOInitial
+				a.up()
+				a.cond.Broadcast()
+			}
+		case *packet.Data:
+			if a.receive(c) {
+				sack = true
+			}
+		case *packet.Sack:
+			a.handleSack(c.CumulativeTSNAck, c.AdvertisedWindow, c.GapAckBlocks)
+		case *packet.Heartbeat:
+			if !c.Ack {
+				a.send(a.peerTag, &packet.Heartbeat{Ack: true, Info: c.Info})
+			} else if a.probe != nil && bytes.Equal(c.Info, a.probe) {
+				a.measure(time.Since(time.Unix(0, int64(binary.BigEndian.Uint64(a.probe)))))
+				a.probe = nil
+				a.failures = 0
+			}
+		case *packet.Abort:
+			if a.state < udpEstablished {
+				a.terminate(syscall.ECONNREFUSED, SCTP_CANT_STR_ASSOC)
+			} else {
+				a.terminate(syscall.ECONNRESET, SCTP_COMM_LOST)
+			}
+			return
+		case *packet.Shutdown:
+			a.handleShutdown(c.CumulativeTSNAck)
+		case *packet.ShutdownAck:
+			if a.state == udpShutdownSent || a.state == udpShutdownAckSent {
+				a.send(a.peerTag, &packet.ShutdownComplete{})
+				a.terminate(nil, SCTP_SHUTDOWN_COMP)
+				return
+			}
+		case *packet.ShutdownComplete:
+			if a.state == udpShutdownAckSent {
+				a.terminate(nil, SCTP_SHUTDOWN_COMP)
+				return
+			}
+		}
+	}
+	if sack {
+		a.sendSack()
+	}
+}
+
+// verify checks the verification tag of a packet, ABORT and SHUTDOWN-COMPLETE chunks with
+// the T bit carry the tag of the peer.
+func (a *udpAssociation) verify(p *packet.Packet) bool {
+	if len(p.Chunks) == 0 {
+		return false
+	}
+	switch c := p.Chunks[0].(type) {
+	case *packet.Init:
+		if !c.Ack {
+			return p.VerificationTag == 0
+		}
+	case *packet.Abort:
+		if c.NoTCB {
+			return p.VerificationTag == a.peerTag
+		}
+	case *packet.ShutdownComplete:
+		if c.NoTCB {
+			return p.VerificationTag == a.peerTag
+		}
+	}
+	return p.VerificationTag == a.localTag
+}
+
+// handleInit processes an INIT-ACK answering the INIT of the association, an INIT of an
+// existing association is a restart that is not supported and is ignored.
+func (a *udpAssociation) handleInit(c *packet.Init) {
+	if !c.Ack || a.state != udpCookieWait {
+		return
+	}
+	var cookie *packet.StateCookie
+	for _, param := range c.Params {
+		if p, ok := param.(*packet.StateCookie); ok {
+			cookie = p
+		}
+	}
+	if cookie == nil || c.InitiateTag == 0 || c.OutboundStreams == 0 || c.InboundStreams == 0 {
+		a.send(c.InitiateTag, &packet.Abort{Causes: []packet.ErrorCause{{Code: packet.CauseInvalidMandatoryParameter}}})
+		a.terminate(syscall.ECONNREFUSED, SCTP_CANT_STR_ASSOC)
+		return
+	}
+	a.established(c)
+	a.state = udpCookieEchoed
+	a.cookie = cookie.Cookie
+	a.attempts = 0
+	a.send(a.peerTag, &packet.CookieEcho{Cookie: a.cookie})
+	a.start(&a.handshake, a.rto, a.retransmitHandshake)
+}
+
+// receive processes a DATA chunk and reports whether it must be acknowledged.
+func (a *udpAssociation) receive(c *packet.Data) bool {
+	switch a.state {
+	case udpEstablished, udpShutdownPending, udpShutdownSent:
+	default:
+		return false
+	}
+	if len(c.UserData) == 0 {
+		a.send(a.peerTag, &packet.Abort{Causes: []packet.ErrorCause{{Code: packet.CauseNoUserData, Info: binary.BigEndian.AppendUint32(nil, c.TSN)}}})
+		a.terminate(syscall.ECONNRESET, SCTP_COMM_LOST)
+		return false
+	}
+	if !tsnLess(a.cumTSN, c.TSN) || a.received[c.TSN] != nil {
+		a.duplicates = append(a.duplicates, c.TSN)
+		return true
+	}
+	if c.StreamID >= a.inStreams {
+		a.send(a.peerTag, &packet.Error{Causes: []packet.ErrorCause{{
+			Code: packet.CauseInvalidStreamIdentifier,
+			Info: binary.BigEndian.AppendUint32(nil, uint32(c.StreamID)<<16),
+		}}})
+	} else if a.buffered+len(c.UserData) > a.config.ReceiveBuffer && c.TSN != a.cumTSN+1 {
+		// Out of window, the peer retransmits it once the buffer drained.
+		return true
+	}
+	chunk := *c
+	a.received[c.TSN] = &chunk
+	a.buffered += len(c.UserData)
+	for {
+		next, ok := a.received[a.cumTSN+1]
+		if !ok {
+			break
+		}
+		delete(a.received, a.cumTSN+1)
+		a.cumTSN++
+		a.reassemble(next)
+	}
+	return true
+}
+
+// reassemble appends a chunk received in sequence to the message being reassembled, the
+// fragments of a message carry consecutive TSNs.
+func (a *udpAssociation) reassemble(c *packet.Data) {
+	if c.StreamID >= a.inStreams {
+		a.buffered -= len(c.UserData)
+		return
+	}
+	if c.Beginning {
+		a.partial = a.partial[:0]
+	}
+	a.partial = append(a.partial, c.UserData...)
+	if !c.Ending {
+		return
+	}
+	message := udpMessage{
+		data: append([]byte(nil), a.partial...),
+		info: SCTPSndRcvInfo{
+			Stream:  c.StreamID,
+			Ssn:     c.StreamSeq,
+			Ppid:    HostToNetwork(c.PPID),
+			Tsn:     c.TSN,
+			CumTsn:  a.cumTSN,
+			AssocId: a.id,
+		},
+		flags: syscall.MSG_EOR,
+	}
+	a.partial = a.partial[:0]
+	if c.Unordered {
+		message.info.Flags = SCTP_UNORDERED
+		a.deliver(message)
+		return
+	}
+	if c.StreamSeq != a.expected[c.StreamID] {
+		if a.reorder[c.StreamID] == nil {
+			a.reorder[c.StreamID] = make(map[uint16]udpMessage)
+		}
+		a.reorder[c.StreamID][c.StreamSeq] = message
+		return
+	}
+	for {
+		a.deliver(message)
+		a.expected[c.StreamID]++
+		next, ok := a.reorder[c.StreamID][a.expected[c.StreamID]]
+		if !ok {
+			return
+		}
+		delete(a.reorder[c.StreamID], a.expected[c.StreamID])
+		message = next
+	}
+}
+
+// deliver queues a message to be read.
+func (a *udpAssociation) deliver(message udpMessage) {
+	a.inbox = append(a.inbox, message)
+	a.cond.Broadcast()
+}
+
+// notify queues a notification to be read.
+func (a *udpAssociation) notify(data []byte) {
+	a.inbox = append(a.inbox, udpMessage{
+		data:  data,
+		info:  SCTPSndRcvInfo{AssocId: a.id},
+		flags: SCTP_MSG_NOTIFICATION | syscall.MSG_EOR,
+	})
+	a.cond.Broadcast()
+}
+
+// up reports the association established and starts probing the peer with HEARTBEATs.
+func (a *udpAssociation) up() {
+	a.notifyAssocChange(SCTP_COMM_UP, 0)
+	a.start(&a.idle, a.rto+a.config.HeartbeatInterval, a.sendHeartbeat)
+}
+
+// sendHeartbeat probes an idle peer, RFC 9260 section 8.3. A HEARTBEAT unacknowledged when
+// the next is due counts as a retransmission, DATA in flight is probed by its own
+// retransmissions instead.
+func (a *udpAssociation) sendHeartbeat() {
+	switch a.state {
+	case udpEstablished, udpShutdownPending:
+	default:
+		return
+	}
+	if a.probe != nil {
+		a.failures++
+		if a.failures > a.config.MaxRetransmissions {
+			a.send(a.peerTag, &packet.Abort{})
+			a.terminate(syscall.ETIMEDOUT, SCTP_COMM_LOST)
+			return
+		}
+		a.rto = min(2*a.rto, a.config.RTOMax)
+	}
+	a.probe = nil
+	if len(a.flight) == 0 {
+		a.probe = binary.BigEndian.AppendUint64(nil, uint64(time.Now().UnixNano()))
+		a.send(a.peerTag, &packet.Heartbeat{Info: a.probe})
+	}
+	a.start(&a.idle, a.rto+a.config.HeartbeatInterval, a.sendHeartbeat)
+}
+
+// notifyAssocChange queues an SCTP_ASSOC_CHANGE notification when subscribed.
+func (a *udpAssociation) notifyAssocChange(state, errno uint16) {
+	if a.events.AssociationEvent == 0 {
+		return
+	}
+	a.notify(Pack(&SCTPAssocChange{
+		Type:            SCTP_ASSOC_CHANGE,
+		Length:          SCTPAssocChangeSize,
+		State:           state,
+		Error:           errno,
+		OutboundStreams: a.outStreams,
+		InboundStreams:  a.inStreams,
+		AssocId:         a.id,
+	}))
+}
+
+// sendSack acknowledges the DATA received, with the gaps and duplicates since the last SACK.
+func (a *udpAssociation) sendSack() {
+	tsns := make([]uint32, 0, len(a.received))
+	for tsn := range a.received {
+		tsns = append(tsns, tsn-a.cumTSN)
+	}
+	sort.Slice(tsns, func(i, j int) bool { return tsns[i] < tsns[j] })
+	var blocks []packet.GapAckBlock
+	for _, offset := range tsns {
+		if offset > 0xffff {
+			break
+		}
+		if n := len(blocks); n > 0 && uint32(blocks[n-1].End)+1 == offset {
+			blocks[n-1].End++
+			continue
+		}
+		blocks = append(blocks, packet.GapAckBlock{Start: uint16(offset), End: uint16(offset)})
+	}
+	sack := &packet.Sack{
+		CumulativeTSNAck: a.cumTSN,
+		AdvertisedWindow: uint32(max(a.config.ReceiveBuffer-a.buffered, 0)),
+		GapAckBlocks:     blocks,
+		DuplicateTSNs:    a.duplicates,
+	}
+	a.duplicates = nil
+	a.send(a.peerTag, sack)
+}
+
+// handleSack processes the acknowledgement of DATA sent, measuring the round trip time on
+// chunks sent once and adjusting the congestion window, RFC 9260 section 7.2.
+func (a *udpAssociation) handleSack(cum, window uint32, blocks []packet.GapAckBlock) {
+	if a.state < udpEstablished || tsnLess(cum, a.ackedTSN) {
+		return
+	}
+	advanced := tsnLess(a.ackedTSN, cum)
+	a.ackedTSN = cum
+	var (
+		rtt         time.Duration
+		outstanding int
+		acked       int
+		highest     = cum
+		sacked      = cum
+		full        = a.flightSize() >= a.cwnd
+		flight      = a.flight[:0]
+	)
+	for _, chunk := range a.flight {
+		if !tsnLess(cum, chunk.data.TSN) {
+			if !chunk.retransmitted {
+				rtt = time.Since(chunk.sent)
+			}
+			if !chunk.acked {
+				acked += len(chunk.data.UserData)
+			}
+			a.queued -= len(chunk.data.UserData)
+			continue
+		}
+		for _, block := range blocks {
+			if offset := chunk.data.TSN - cum; offset >= uint32(block.Start) && offset <= uint32(block.End) {
+				if !chunk.acked {
+					acked += len(chunk.data.UserData)
+					highest = chunk.data.TSN
+				}
+				chunk.acked = true
+				chunk.marked = false
+			}
+		}
+		if chunk.acked {
+			sacked = chunk.data.TSN
+		} else {
+			outstanding += len(chunk.data.UserData)
+		}
+		flight = append(flight, chunk)
+	}
+	clear(a.flight[len(flight):])
+	a.flight = flight
+	a.peerWindow = uint32(max(int(window)-outstanding, 0))
+	if a.recovery && !tsnLess(cum, a.recoverTSN) {
+		a.recovery = false
+	}
+	if advanced && !a.recovery {
+		a.grow(acked, full)
+	}
+	if len(a.flight) == 0 {
+		a.ackedBytes = 0
+	}
+	if a.recovery && advanced {
+		highest = sacked
+	}
+	fast := a.missing(highest)
+	if advanced {
+		a.failures = 0
+		if rtt > 0 {
+			a.measure(rtt)
+		}
+		if len(a.flight) > 0 {
+			a.start(&a.rtx, a.rto, a.retransmit)
+		} else {
+			a.stop(&a.rtx)
+		}
+	}
+	if fast {
+		a.fastRetransmit()
+	}
+	a.transmit()
+	a.cond.Broadcast()
+	a.drained()
+}
+
+// grow opens the congestion window for bytes newly acknowledged, by slow start below
+// ssthresh and by congestion avoidance above, only while the window was fully used.
+func (a *udpAssociation) grow(acked int, full bool) {
+	if a.cwnd <= a.ssthresh {
+		if full {
+			a.cwnd += min(acked, a.config.MTU)
+		}
+		return
+	}
+	a.ackedBytes += acked
+	if full && a.ackedBytes >= a.cwnd {
+		a.ackedBytes -= a.cwnd
+		a.cwnd += a.config.MTU
+	}
+}
+
+// missing counts a miss indication for the chunks in flight below highest, the highest TSN
+// newly acknowledged or in fast recovery the highest acknowledged, and marks those missed three times for fast retransmit, which it
+// reports. A chunk is fast retransmitted once.
+func (a *udpAssociation) missing(highest uint32) bool {
+	fast := false
+	for _, chunk := range a.flight {
+		if !tsnLess(chunk.data.TSN, highest) {
+			break
+		}
+		if chunk.acked || chunk.fast {
+			continue
+		}
+		chunk.misses++
+		if chunk.misses >= 3 {
+			chunk.fast = true
+			chunk.marked = true
+			fast = true
+		}
+	}
+	return fast
+}
+
+// fastRetransmit resends the chunks marked by missing in one packet regardless of the
+// congestion window, entering fast recovery with the window halved, RFC 9260 section
+// 7.2.4. The window is reduced once per recovery.
+func (a *udpAssociation) fastRetransmit() {
+	if !a.recovery {
+		a.recovery = true
+		a.recoverTSN = a.nextTSN - 1
+		a.ssthresh = max(a.cwnd/2, 4*a.config.MTU)
+		a.cwnd = a.ssthresh
+		a.ackedBytes = 0
+	}
+	first := len(a.flight) > 0 && a.flight[0].marked
+	if a.retransmitPacket() && first {
+		a.start(&a.rtx, a.rto, a.retransmit)
+	}
+}
+
+// measure updates the retransmission timeout with a round trip time, RFC 9260 section 6.3.1.
+func (a *udpAssociation) measure(rtt time.Duration) {
+	if a.srtt == 0 {
+		a.srtt, a.rttvar = rtt, rtt/2
+	} else {
+		a.rttvar = (3*a.rttvar + (a.srtt - rtt).Abs()) / 4
+		a.srtt = (7*a.srtt + rtt) / 8
+	}
+	a.rto = min(max(a.srtt+4*a.rttvar, a.config.RTOMin), a.config.RTOMax)
+}
+
+// flightSize returns the bytes of DATA in flight, neither acknowledged nor marked for
+// retransmission.
+func (a *udpAssociation) flightSize() int {
+	size := 0
+	for _, chunk := range a.flight {
+		if !chunk.acked && !chunk.marked {
+			size += len(chunk.data.UserData)
+		}
+	}
+	return size
+}
+
+// transmit sends the chunks marked for retransmission then the queued chunks, bundled up
+// to the MTU, while the data in flight is below the congestion window, RFC 9260 section
+// 6.1. The window of the peer limits new chunks, one chunk is always allowed in flight to
+// probe a closed window.
+func (a *udpAssociation) transmit() {
+	for a.flightSize() < a.cwnd {
+		if a.retransmitPacket() {
+			continue
+		}
+		var (
+			chunks []packet.Chunk
+			size   = packet.CommonHeaderSize
+		)
+		for len(a.queue) > 0 {
+			chunk := a.queue[0]
+			n := uint32(len(chunk.data.UserData))
+			if n > a.peerWindow && (len(a.flight) > 0 || len(chunks) > 0) {
+				break
+			}
+			if len(chunks) > 0 && size+chunk.size() > a.config.MTU {
+				break
+			}
+			a.queue = a.queue[1:]
+			a.peerWindow -= min(n, a.peerWindow)
+			chunk.sent = time.Now()
+			a.flight = append(a.flight, chunk)
+			chunks = append(chunks, &chunk.data)
+			size += chunk.size()
+		}
+		if len(chunks) == 0 {
+			return
+		}
+		a.send(a.peerTag, chunks...)
+		if a.rtx.timer == nil {
+			a.start(&a.rtx, a.rto, a.retransmit)
+		}
+	}
+}
+
+// retransmitPacket resends the earliest chunks marked for retransmission that fit in one
+// packet, reporting whether it sent any.
+func (a *udpAssociation) retransmitPacket() bool {
+	var (
+		chunks []packet.Chunk
+		size   = packet.CommonHeaderSize
+		now    = time.Now()
+	)
+	for _, chunk := range a.flight {
+		if !chunk.marked {
+			continue
+		}
+		if len(chunks) > 0 && size+chunk.size() > a.config.MTU {
+			break
+		}
+		chunk.marked = false
+		chunk.retransmitted = true
+		chunk.sent = now
+		chunks = append(chunks, &chunk.data)
+		size += chunk.size()
+	}
+	if len(chunks) == 0 {
+		return false
+	}
+	a.send(a.peerTag, chunks...)
+	return true
+}
+
+// retransmit handles the expiry of the retransmission timer, RFC 9260 section 6.3.3. The
+// congestion window collapses to one MTU, the chunks in flight not acknowledged are marked
+// for retransmission and the earliest of them resent in one packet, the rest follows as
+// SACKs open the window. The association fails after Association.Max.Retrans expiries in a
+// row.
+func (a *udpAssociation) retransmit() {
+	a.failures++
+	if a.failures > a.config.MaxRetransmissions {
+		a.send(a.peerTag, &packet.Abort{})
+		a.terminate(syscall.ETIMEDOUT, SCTP_COMM_LOST)
+		return
+	}
+	a.rto = min(2*a.rto, a.config.RTOMax)
+	a.ssthresh = max(a.cwnd/2, 4*a.config.MTU)
+	a.cwnd = a.config.MTU
+	a.ackedBytes = 0
+	a.recovery = false
+	for _, chunk := range a.flight {
+		if !chunk.acked {
+			chunk.marked = true
+		}
+	}
+	a.retransmitPacket()
+	if len(a.flight) > 0 {
+		a.start(&a.rtx, a.rto, a.retransmit)
+	}
+}
+
+// write queues a message, fragmented to the path MTU, and sends what the window allows.
+func (a *udpAssociation) write(b []byte, info *SCTPSndRcvInfo) (int, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	var stream uint16
+	var ppid uint32
+	var flags uint16
+	if info != nil {
+		stream, ppid, flags = info.Stream, info.Ppid, info.Flags
+	}
+	if flags&SCTP_ABORT != 0 {
+		a.abort()
+		return 0, nil
+	}
+	if len(b) == 0 && flags&SCTP_EOF != 0 {
+		a.close()
+		return 0, nil
+	}
+	if err := a.writable(); err != nil {
+		return 0, err
+	}
+	if len(b) == 0 || stream >= a.outStreams {
+		return 0, syscall.EINVAL
+	}
+	for a.queued > 0 && a.queued+len(b) > a.config.SendBuffer {
+		a.cond.Wait()
+		if err := a.writable(); err != nil {
+			return 0, err
+		}
+	}
+	unordered := flags&SCTP_UNORDERED != 0
+	var ssn uint16
+	if !unordered {
+		ssn = a.ssn[stream]
+		a.ssn[stream]++
+	}
+	fragment := a.config.fragment()
+	for offset := 0; offset < len(b); offset += fragment {
+		end := min(offset+fragment, len(b))
+		chunk := &udpChunk{data: packet.Data{
+			DataFlags: packet.DataFlags{Unordered: unordered, Beginning: offset == 0, Ending: end == len(b)},
+			TSN:       a.nextTSN,
+			StreamID:  stream,
+			StreamSeq: ssn,
+			PPID:      NetworkToHost(ppid),
+			UserData:  append([]byte(nil), b[offset:end]...),
+		}}
+		a.nextTSN++
+		a.queue = append(a.queue, chunk)
+	}
+	a.queued += len(b)
+	a.transmit()
+	if flags&SCTP_EOF != 0 {
+		a.close()
+	}
+	return len(b), nil
+}
+
+// writable returns the error of a send in the current state.
+func (a *udpAssociation) writable() error {
+	switch {
+	case a.closed:
+		return net.ErrClosed
+	case a.state == udpEstablished:
+		return nil
+	case a.err != nil:
+		return a.err
+	default:
+		return syscall.EPIPE
+	}
+}
+
+// read returns the next message or notification, a message larger than b is returned over
+// several reads with MSG_EOR set on the last one. Once the association terminated and the
+// inbox drained it returns io.EOF after a graceful shutdown.
+func (a *udpAssociation) read(b []byte, info *SCTPSndRcvInfo, flags *int) (int, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for len(a.inbox) == 0 && !a.closed && a.state != udpClosed {
+		a.cond.Wait()
+	}
+	switch {
+	case a.closed:
+		return 0, net.ErrClosed
+	case len(a.inbox) == 0 && a.err != nil:
+		return 0, a.err
+	case len(a.inbox) == 0:
+		return 0, io.EOF
+	}
+	message := &a.inbox[0]
+	n := copy(b, message.data)
+	if info != nil {
+		*info = message.info
+	}
+	flag := message.flags
+	if n < len(message.data) {
+		message.data = message.data[n:]
+		flag &^= syscall.MSG_EOR
+	} else {
+		a.inbox = a.inbox[1:]
+	}
+	if flag&SCTP_MSG_NOTIFICATION == 0 {
+		a.release(n)
+	}
+	if flags != nil {
+		*flags = flag
+	}
+	return n, nil
+}
+
+// release returns buffer space of data read, announcing a reopened window to the peer.
+func (a *udpAssociation) release(n int) {
+	closed := a.buffered >= a.config.ReceiveBuffer
+	a.buffered -= n
+	if closed && a.state >= udpEstablished && a.state <= udpShutdownSent {
+		a.sendSack()
+	}
+}
+
+// close starts the graceful shutdown of the association once the data queued is
+// acknowledged, reads fail from now on.
+func (a *udpAssociation) close() {
+	switch a.state {
+	case udpCookieWait, udpCookieEchoed:
+		a.abort()
+	case udpEstablished:
+		a.state = udpShutdownPending
+		a.drained()
+	}
+	a.cond.Broadcast()
+}
+
+// drained moves a shutting down association on once all its data is acknowledged.
+func (a *udpAssociation) drained() {
+	if len(a.queue) > 0 || len(a.flight) > 0 {
+		return
+	}
+	switch a.state {
+	case udpShutdownPending:
+		a.state = udpShutdownSent
+		a.failures = 0
+		a.sendShutdown()
+	case udpShutdownReceived:
+		a.state = udpShutdownAckSent
+		a.failures = 0
+		a.sendShutdownAck()
+	}
+}
+
+// sendShutdown sends the SHUTDOWN until it is acknowledged.
+func (a *udpAssociation) sendShutdown() {
+	if a.state != udpShutdownSent {
+		return
+	}
+	if a.failures > a.config.MaxRetransmissions {
+		a.send(a.peerTag, &packet.Abort{})
+		a.terminate(syscall.ETIMEDOUT, SCTP_COMM_LOST)
+		return
+	}
+	a.failures++
+	a.send(a.peerTag, &packet.Shutdown{CumulativeTSNAck: a.cumTSN})
+	a.start(&a.shutdown, a.rto, a.sendShutdown)
+}
+
+// sendShutdownAck sends the SHUTDOWN-ACK until the SHUTDOWN-COMPLETE is received.
+func (a *udpAssociation) sendShutdownAck() {
+	if a.state != udpShutdownAckSent {
+		return
+	}
+	if a.failures > a.config.MaxRetransmissions {
+		a.send(a.peerTag, &packet.Abort{})
+		a.terminate(syscall.ETIMEDOUT, SCTP_COMM_LOST)
+		return
+	}
+	a.failures++
+	a.send(a.peerTag, &packet.ShutdownAck{})
+	a.start(&a.shutdown, a.rto, a.sendShutdownAck)
+}
+
+// handleShutdown processes a SHUTDOWN of the peer, which sends no more data.
+func (a *udpAssociation) handleShutdown(cum uint32) {
+	switch a.state {
+	case udpEstablished, udpShutdownPending:
+		a.handleSack(cum, a.peerWindow, nil)
+		if a.state == udpClosed {
+			return
+		}
+		a.state = udpShutdownReceived
+		if a.events.ShutdownEvent != 0 {
+			a.notify(Pack(&SCTPShutdownEvent{
+				Type:    SCTP_SHUTDOWN_EVENT,
+				Length:  SCTPShutdownEventSize,
+				AssocId: a.id,
+			}))
+		}
+		a.drained()
+		a.cond.Broadcast()
+	case udpShutdownSent:
+		a.stop(&a.shutdown)
+		a.state = udpShutdownAckSent
+		a.failures = 0
+		a.sendShutdownAck()
+	case udpShutdownAckSent:
+		a.send(a.peerTag, &packet.ShutdownAck{})
+	}
+}
+
+// abort sends an ABORT and terminates the association.
+func (a *udpAssociation) abort() {
+	if a.state == udpClosed {
+		return
+	}
+	if a.peerTag != 0 {
+		a.send(a.peerTag, &packet.Abort{Causes: []packet.ErrorCause{{Code: packet.CauseUserInitiatedAbort}}})
+	}
+	a.terminate(syscall.ECONNABORTED, SCTP_COMM_LOST)
+}
+
+// terminate moves the association to the closed state, err is returned by later operations
+// and nil for a graceful shutdown. Data still queued to be read remains readable.
+func (a *udpAssociation) terminate(err error, state uint16) {
+	if a.state == udpClosed {
+		return
+	}
+	established := a.state >= udpEstablished
+	a.stop(&a.handshake)
+	a.stop(&a.rtx)
+	a.stop(&a.shutdown)
+	a.stop(&a.idle)
+	a.state = udpClosed
+	a.queue, a.flight = nil, nil
+	a.queued = 0
+	a.err = err
+	if established || state == SCTP_CANT_STR_ASSOC {
+		var errno uint16
+		if e, ok := err.(syscall.Errno); ok {
+			errno = uint16(e)
+		}
+		a.notifyAssocChange(state, errno)
+	}
+	a.cond.Broadcast()
+	a.endpoint.remove(a)
+}
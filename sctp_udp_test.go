@@ -0,0 +1,489 @@
+package sctp_go
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/thebagchi/sctp-go/packet"
+)
+
+// lossyConn drops every nth packet written, or every packet once cut.
+type lossyConn struct {
+	net.PacketConn
+	every   int64
+	cut     atomic.Bool
+	written atomic.Int64
+	dropped atomic.Int64
+}
+
+func (conn *lossyConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	if n := conn.written.Add(1); conn.cut.Load() || conn.every > 0 && n%conn.every == 0 {
+		conn.dropped.Add(1)
+		return len(b), nil
+	}
+	return conn.PacketConn.WriteTo(b, addr)
+}
+
+// recordConn records the packets written instead of sending them.
+type recordConn struct {
+	net.PacketConn
+	mutex   sync.Mutex
+	packets [][]byte
+}
+
+func (conn *recordConn) WriteTo(b []byte, _ net.Addr) (int, error) {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	conn.packets = append(conn.packets, append([]byte(nil), b...))
+	return len(b), nil
+}
+
+func (conn *recordConn) Close() error {
+	return nil
+}
+
+// sent returns the packets recorded since the last call.
+func (conn *recordConn) sent(t *testing.T) []*packet.Packet {
+	t.Helper()
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	packets := make([]*packet.Packet, 0, len(conn.packets))
+	for _, b := range conn.packets {
+		var p packet.Packet
+		if err := p.Unmarshal(b); err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, &p)
+	}
+	conn.packets = nil
+	return packets
+}
+
+// testUDPConfig retransmits quickly for the tests over loopback.
+var testUDPConfig = SCTPUDPConfig{
+	RTOInitial: 50 * time.Millisecond,
+	RTOMin:     20 * time.Millisecond,
+	RTOMax:     200 * time.Millisecond,
+}
+
+// udpPair returns a listener and an association dialed to it with its accepted end, wrap
+// wraps the socket of the dialer.
+func udpPair(t *testing.T, config SCTPUDPConfig, wrap func(net.PacketConn) net.PacketConn) (*SCTPUDPListener, *SCTPUDPConn, *SCTPUDPConn) {
+	t.Helper()
+	config = config.withDefaults()
+	pc, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp unavailable: %v", err)
+	}
+	local, _ := MakeSCTPAddr("sctp4+udp", "127.0.0.1:36412")
+	listener := listenSCTPUDP(pc, config, "sctp4+udp", local, &SCTPInitMsg{NumOutStreams: 4, MaxInStreams: 8})
+	t.Cleanup(func() { _ = listener.Close() })
+	if err := listener.SetEventSubscribe(&SCTPEventSubscribe{AssociationEvent: 1, ShutdownEvent: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	dialer, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wrap != nil {
+		dialer = wrap(dialer)
+	}
+	config.Port = pc.LocalAddr().(*net.UDPAddr).Port
+	conn, err := dialSCTPUDP(dialer, config, "sctp4+udp", nil, local, &SCTPInitMsg{NumOutStreams: 16, MaxInStreams: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Abort() })
+	accepted, err := listener.AcceptSCTPUDP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = accepted.Abort() })
+	return listener, conn, accepted
+}
+
+// recvNotification reads a notification from conn.
+func recvNotification(t *testing.T, conn *SCTPUDPConn) Notification {
+	t.Helper()
+	var (
+		b     = make([]byte, 256)
+		info  SCTPSndRcvInfo
+		flags int
+	)
+	n, err := conn.RecvMsg(b, &info, &flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flags&SCTP_MSG_NOTIFICATION == 0 {
+		t.Fatalf("received %q instead of a notification", b[:n])
+	}
+	notification, err := ParseNotification(b[:n])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return notification
+}
+
+// expectAssocChange reads an SCTP_ASSOC_CHANGE notification with state from conn.
+func expectAssocChange(t *testing.T, conn *SCTPUDPConn, state uint16) *SCTPAssocChange {
+	t.Helper()
+	change, ok := recvNotification(t, conn).(*SCTPAssocChange)
+	if !ok || change.State != state || change.AssocId != int32(conn.AssocId()) {
+		t.Fatalf("unexpected notification %+v, expected state %d", change, state)
+	}
+	return change
+}
+
+func TestUDPAssociation(t *testing.T) {
+	_, conn, accepted := udpPair(t, testUDPConfig, nil)
+	change := expectAssocChange(t, accepted, SCTP_COMM_UP)
+	if change.OutboundStreams != 2 || change.InboundStreams != 8 {
+		t.Errorf("negotiated %d outbound and %d inbound streams", change.OutboundStreams, change.InboundStreams)
+	}
+	if init, _ := conn.GetInitMsg(); init.NumOutStreams != 8 || init.MaxInStreams != 2 {
+		t.Errorf("dialer negotiated %+v", init)
+	}
+	if conn.RemoteAddr().String() != "127.0.0.1:36412" || accepted.LocalAddr().String() != "127.0.0.1:36412" {
+		t.Errorf("addresses %v, %v", conn.RemoteAddr(), accepted.LocalAddr())
+	}
+
+	large := bytes.Repeat([]byte("0123456789"), 1000)
+	messages := []struct {
+		data   []byte
+		stream uint16
+		ppid   uint32
+	}{
+		{[]byte("first"), 0, 18},
+		{large, 3, 60},
+		{[]byte("third"), 3, 0},
+	}
+	for _, m := range messages {
+		if _, err := conn.SendMsg(m.data, &SCTPSndRcvInfo{Stream: m.stream, Ppid: HostToNetwork(m.ppid)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b := make([]byte, len(large))
+	for i, m := range messages {
+		var (
+			info  SCTPSndRcvInfo
+			flags int
+		)
+		n, err := accepted.RecvMsg(b, &info, &flags)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b[:n], m.data) || flags&syscall.MSG_EOR == 0 || info.Stream != m.stream ||
+			NetworkToHost(info.Ppid) != m.ppid || info.AssocId != int32(accepted.AssocId()) {
+			t.Errorf("message %d: %d bytes, flags %#x, info %+v", i, n, flags, info)
+		}
+	}
+
+	// A message larger than the buffer is read in parts.
+	if _, err := accepted.SendMsg([]byte("partial read"), &SCTPSndRcvInfo{Stream: 1, Flags: SCTP_UNORDERED}); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		info  SCTPSndRcvInfo
+		flags int
+	)
+	if n, err := conn.RecvMsg(b[:7], &info, &flags); err != nil || string(b[:n]) != "partial" || flags&syscall.MSG_EOR != 0 {
+		t.Fatalf("read %q, flags %#x: %v", b[:n], flags, err)
+	}
+	if n, err := conn.RecvMsg(b, &info, &flags); err != nil || string(b[:n]) != " read" || flags&syscall.MSG_EOR == 0 ||
+		info.Flags&SCTP_UNORDERED == 0 || info.Stream != 1 {
+		t.Fatalf("read %q, flags %#x, info %+v: %v", b[:n], flags, info, err)
+	}
+
+	if _, err := conn.SendMsg([]byte("x"), &SCTPSndRcvInfo{Stream: 8}); !errors.Is(err, syscall.EINVAL) {
+		t.Errorf("send on an invalid stream: %v", err)
+	}
+}
+
+func TestUDPLoss(t *testing.T) {
+	var lossy *lossyConn
+	_, conn, accepted := udpPair(t, testUDPConfig, func(pc net.PacketConn) net.PacketConn {
+		lossy = &lossyConn{PacketConn: pc, every: 3}
+		return lossy
+	})
+	const count = 50
+	go func() {
+		for i := 0; i < count; i++ {
+			data := bytes.Repeat([]byte{byte(i)}, 100+i*50)
+			if _, err := conn.SendMsg(data, &SCTPSndRcvInfo{Stream: uint16(i % 2)}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	b := make([]byte, 8192)
+	next := [2]int{0, 1}
+	for received := 0; received < count; {
+		n, err := accepted.Read(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		i := int(b[0])
+		if n != 100+i*50 || next[i%2] != i {
+			t.Fatalf("received message %d of %d bytes, expected %d", i, n, next[i%2])
+		}
+		next[i%2] += 2
+		received++
+	}
+	if lossy.dropped.Load() == 0 {
+		t.Error("no packet dropped")
+	}
+}
+
+func TestUDPShutdown(t *testing.T) {
+	_, conn, accepted := udpPair(t, testUDPConfig, nil)
+	expectAssocChange(t, accepted, SCTP_COMM_UP)
+	if _, err := conn.SendMsg([]byte("bye"), &SCTPSndRcvInfo{Flags: SCTP_EOF}); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 16)
+	if n, err := accepted.Read(b); err != nil || string(b[:n]) != "bye" {
+		t.Fatalf("read %q: %v", b[:n], err)
+	}
+	if _, ok := recvNotification(t, accepted).(*SCTPShutdownEvent); !ok {
+		t.Fatal("expected SCTP_SHUTDOWN_EVENT")
+	}
+	expectAssocChange(t, accepted, SCTP_SHUTDOWN_COMP)
+	if _, err := accepted.Read(b); err != io.EOF {
+		t.Errorf("read after shutdown: %v", err)
+	}
+	if _, err := accepted.Write(b); !errors.Is(err, syscall.EPIPE) {
+		t.Errorf("write after shutdown: %v", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Read(b); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("read after close: %v", err)
+	}
+	if err := conn.Close(); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("second close: %v", err)
+	}
+}
+
+func TestUDPAbort(t *testing.T) {
+	_, conn, accepted := udpPair(t, testUDPConfig, nil)
+	expectAssocChange(t, accepted, SCTP_COMM_UP)
+	if err := conn.Abort(); err != nil {
+		t.Fatal(err)
+	}
+	expectAssocChange(t, accepted, SCTP_COMM_LOST)
+	if _, err := accepted.Read(make([]byte, 16)); !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("read after abort: %v", err)
+	}
+}
+
+func TestUDPErrors(t *testing.T) {
+	pc, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp unavailable: %v", err)
+	}
+	defer pc.Close()
+	config := testUDPConfig
+	config.Port = pc.LocalAddr().(*net.UDPAddr).Port
+	remote, _ := MakeSCTPAddr("sctp4+udp", "127.0.0.1:5000")
+
+	// An endpoint without listener aborts the INIT.
+	endpoint := newUDPEndpoint(pc, config.withDefaults(), 5000)
+	go endpoint.run()
+	if _, err := config.Dial("sctp4+udp", nil, remote, nil); !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Errorf("dial without listener: %v", err)
+	}
+
+	// Unanswered INIT chunks time out.
+	silent, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+	config.Port = silent.LocalAddr().(*net.UDPAddr).Port
+	start := time.Now()
+	if _, err := config.Dial("sctp4+udp", nil, remote, &SCTPInitMsg{MaxAttempts: 2}); !errors.Is(err, syscall.ETIMEDOUT) {
+		t.Errorf("dial to a silent peer: %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("dial timed out after %v", time.Since(start))
+	}
+
+	if _, err := config.Dial("sctp", nil, remote, nil); err == nil {
+		t.Error("dial on network sctp")
+	}
+	if _, err := config.Listen("sctp4+udp", nil, nil); err == nil {
+		t.Error("listen without address")
+	}
+}
+
+// recordedAssociation returns an established association whose packets are recorded, its
+// retransmission timer does not expire during the tests.
+func recordedAssociation(t *testing.T) (*udpAssociation, *recordConn) {
+	t.Helper()
+	conn := &recordConn{}
+	config := SCTPUDPConfig{RTOInitial: time.Hour, RTOMin: time.Hour, RTOMax: time.Hour}
+	endpoint := newUDPEndpoint(conn, config.withDefaults(), 5000)
+	a := newUDPAssociation(endpoint, &net.UDPAddr{}, 5000, defaultInitMsg(nil))
+	a.established(&packet.Init{InitiateTag: 1, InitialTSN: 1, AdvertisedWindow: 1 << 20, OutboundStreams: 1, InboundStreams: 1})
+	t.Cleanup(func() {
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+		a.terminate(nil, SCTP_COMM_LOST)
+	})
+	return a, conn
+}
+
+// dataTSNs returns the TSNs of the DATA chunks of packets.
+func dataTSNs(packets []*packet.Packet) []uint32 {
+	var tsns []uint32
+	for _, p := range packets {
+		for _, chunk := range p.Chunks {
+			if data, ok := chunk.(*packet.Data); ok {
+				tsns = append(tsns, data.TSN)
+			}
+		}
+	}
+	return tsns
+}
+
+func TestUDPCongestion(t *testing.T) {
+	t.Run("slow start", func(t *testing.T) {
+		a, conn := recordedAssociation(t)
+		mtu := a.config.MTU
+		initial := a.cwnd
+		if expected := min(4*mtu, max(2*mtu, 4404)); initial != expected {
+			t.Fatalf("initial cwnd %d, expected %d", initial, expected)
+		}
+		if _, err := a.write(make([]byte, 64*1024), nil); err != nil {
+			t.Fatal(err)
+		}
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+		packets := conn.sent(t)
+		if flight := a.flightSize(); flight < a.cwnd || flight >= a.cwnd+mtu || len(a.queue) == 0 {
+			t.Fatalf("%d bytes in flight with cwnd %d and %d chunks queued", flight, a.cwnd, len(a.queue))
+		}
+		if len(packets) != len(a.flight) {
+			t.Errorf("%d packets for %d chunks of an MTU", len(packets), len(a.flight))
+		}
+		a.handleSack(a.flight[len(a.flight)-1].data.TSN, 1<<20, nil)
+		if a.cwnd != initial+mtu {
+			t.Errorf("cwnd %d after a full window acknowledged, expected %d", a.cwnd, initial+mtu)
+		}
+	})
+
+	t.Run("bundling", func(t *testing.T) {
+		a, conn := recordedAssociation(t)
+		a.mutex.Lock()
+		a.cwnd = 0
+		a.mutex.Unlock()
+		for i := 0; i < 20; i++ {
+			if _, err := a.write(make([]byte, 100), nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+		a.cwnd = 1
+		a.transmit()
+		packets := conn.sent(t)
+		if len(packets) != 1 || len(packets[0].Chunks) != 10 {
+			t.Fatalf("sent %d packets, expected one of 10 chunks", len(packets))
+		}
+		if data, _ := packets[0].Marshal(); len(data) > a.config.MTU {
+			t.Errorf("packet of %d bytes exceeds the MTU", len(data))
+		}
+	})
+
+	t.Run("fast retransmit", func(t *testing.T) {
+		a, conn := recordedAssociation(t)
+		mtu := a.config.MTU
+		a.mutex.Lock()
+		a.cwnd = 20 * mtu
+		a.mutex.Unlock()
+		if _, err := a.write(make([]byte, 64*1024), nil); err != nil {
+			t.Fatal(err)
+		}
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+		conn.sent(t)
+		cum, lost := a.ackedTSN, a.flight[0].data.TSN
+		for end := uint16(2); end <= 4; end++ {
+			if tsns := dataTSNs(conn.sent(t)); len(tsns) > 0 && tsns[0] == lost {
+				t.Fatalf("TSN %d retransmitted after %d miss indications", lost, end-2)
+			}
+			a.handleSack(cum, 1<<20, []packet.GapAckBlock{{Start: 2, End: end}})
+		}
+		if tsns := dataTSNs(conn.sent(t)); len(tsns) == 0 || tsns[0] != lost {
+			t.Fatalf("TSN %d not fast retransmitted, sent %v", lost, tsns)
+		}
+		if !a.recovery || a.cwnd != 10*mtu || a.ssthresh != 10*mtu {
+			t.Errorf("recovery %v, cwnd %d, ssthresh %d after fast retransmit", a.recovery, a.cwnd, a.ssthresh)
+		}
+		a.handleSack(cum, 1<<20, []packet.GapAckBlock{{Start: 2, End: 5}})
+		if tsns := dataTSNs(conn.sent(t)); len(tsns) > 0 && tsns[0] == lost {
+			t.Error("TSN fast retransmitted twice")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		a, conn := recordedAssociation(t)
+		mtu := a.config.MTU
+		if _, err := a.write(make([]byte, 64*1024), nil); err != nil {
+			t.Fatal(err)
+		}
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+		conn.sent(t)
+		first := a.flight[0].data.TSN
+		a.retransmit()
+		packets := conn.sent(t)
+		if tsns := dataTSNs(packets); len(packets) != 1 || len(tsns) != 1 || tsns[0] != first {
+			t.Fatalf("resent %d packets with TSNs %v, expected one with %d", len(packets), tsns, first)
+		}
+		if a.cwnd != mtu || a.ssthresh != 4*mtu {
+			t.Errorf("cwnd %d, ssthresh %d after the timeout", a.cwnd, a.ssthresh)
+		}
+	})
+}
+
+func TestUDPHeartbeat(t *testing.T) {
+	config := testUDPConfig
+	config.HeartbeatInterval = 10 * time.Millisecond
+	config.MaxRetransmissions = 2
+	var lossy *lossyConn
+	_, conn, accepted := udpPair(t, config, func(pc net.PacketConn) net.PacketConn {
+		lossy = &lossyConn{PacketConn: pc}
+		return lossy
+	})
+	expectAssocChange(t, accepted, SCTP_COMM_UP)
+
+	// Acknowledged heartbeats keep an idle association up.
+	time.Sleep(200 * time.Millisecond)
+	if _, err := conn.SendMsg([]byte("alive"), nil); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 16)
+	if n, err := accepted.Read(b); err != nil || string(b[:n]) != "alive" {
+		t.Fatalf("read %q: %v", b[:n], err)
+	}
+
+	// A peer that stopped answering is detected while idle.
+	lossy.cut.Store(true)
+	start := time.Now()
+	if _, err := conn.Read(b); !errors.Is(err, syscall.ETIMEDOUT) {
+		t.Errorf("read from a dead peer: %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("dead peer detected after %v", time.Since(start))
+	}
+}
@@ -0,0 +1,38 @@
+package sctp_go
+
+import (
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestMsgv(t *testing.T) {
+	conn, peer := socketpair(t)
+	var (
+		header  = []byte("header:")
+		payload = []byte("payload")
+		buffer  = make([]byte, 64)
+		info    SCTPSndRcvInfo
+		flags   int
+	)
+	n, err := conn.SendMsgv(net.Buffers{header, nil, payload}, &SCTPSndRcvInfo{Ppid: 3})
+	if err != nil || n != len(header)+len(payload) {
+		t.Fatal(n, err)
+	}
+	n, err = syscall.Read(peer, buffer)
+	if err != nil || string(buffer[:n]) != "header:payload" {
+		t.Fatalf("unexpected message %q: %v", buffer[:n], err)
+	}
+
+	if _, err := syscall.Write(peer, []byte("header:payload")); err != nil {
+		t.Fatal(err)
+	}
+	first, second := make([]byte, len(header)), make([]byte, 32)
+	n, err = conn.RecvMsgv(net.Buffers{first, second}, &info, &flags)
+	if err != nil || n != len(header)+len(payload) {
+		t.Fatal(n, err)
+	}
+	if string(first) != "header:" || string(second[:n-len(first)]) != "payload" {
+		t.Errorf("unexpected scatter %q %q", first, second[:n-len(first)])
+	}
+}
@@ -526,3 +526,31 @@ func TestMakeSockaddr(t *testing.T) {
 		fmt.Println(len(buffer))
 	}
 }
+
+func TestAppendCmsg(t *testing.T) {
+	var buffer []byte
+	buffer = AppendCmsg(buffer, syscall.IPPROTO_SCTP, SCTP_SNDRCV, Pack(&SCTPSndRcvInfo{AssocId: 100}))
+	buffer = AppendCmsg(buffer, syscall.IPPROTO_SCTP, SCTP_DSTADDRV4, []byte{127, 0, 0, 1})
+	messages, err := syscall.ParseSocketControlMessage(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 control messages, got %d", len(messages))
+	}
+	if messages[1].Header.Type != SCTP_DSTADDRV4 || len(messages[1].Data) != 4 {
+		t.Error("SCTP_DSTADDRV4 control message mismatch")
+	}
+	info := &SCTPSndRcvInfo{}
+	ParseSndRcvInfo(info, buffer)
+	if info.AssocId != 100 {
+		t.Errorf("expected assoc id 100, got %d", info.AssocId)
+	}
+}
+
+func TestFromSockaddr(t *testing.T) {
+	if addr := FromSockaddr(&syscall.SockaddrInet4{Port: 36412, Addr: [4]byte{10, 0, 0, 2}}); addr == nil ||
+		addr.String() != "10.0.0.2:36412" {
+		t.Errorf("unexpected source address: %v", addr)
+	}
+}
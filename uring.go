@@ -0,0 +1,817 @@
+package sctp_go
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	DefaultRingEntries      = 256
+	DefaultMultishotBuffers = 64
+)
+
+const (
+	IORING_SETUP_CLAMP = 1 << 4
+
+	IORING_ENTER_GETEVENTS = 1 << 0
+
+	IORING_OFF_SQ_RING = 0
+	IORING_OFF_CQ_RING = 0x8000000
+	IORING_OFF_SQES    = 0x10000000
+
+	IORING_OP_NOP          = 0
+	IORING_OP_SENDMSG      = 9
+	IORING_OP_RECVMSG      = 10
+	IORING_OP_ASYNC_CANCEL = 14
+
+	IOSQE_BUFFER_SELECT = 1 << 5
+
+	IORING_RECV_MULTISHOT = 1 << 1
+
+	IORING_ASYNC_CANCEL_ALL = 1 << 0
+	IORING_ASYNC_CANCEL_FD  = 1 << 1
+
+	IORING_CQE_F_BUFFER         = 1 << 0
+	IORING_CQE_F_MORE           = 1 << 1
+	IORING_CQE_BUFFER_SHIFT     = 16
+	IORING_REGISTER_PBUF_RING   = 22
+	IORING_UNREGISTER_PBUF_RING = 23
+)
+
+// ringParams represents the C struct io_uring_params.
+type ringParams struct {
+	SqEntries    uint32
+	CqEntries    uint32
+	Flags        uint32
+	SqThreadCpu  uint32
+	SqThreadIdle uint32
+	Features     uint32
+	WqFd         uint32
+	Resv         [3]uint32
+	SqOff        ringSqOffsets
+	CqOff        ringCqOffsets
+}
+
+// ringSqOffsets represents the C struct io_sqring_offsets.
+type ringSqOffsets struct {
+	Head        uint32
+	Tail        uint32
+	RingMask    uint32
+	RingEntries uint32
+	Flags       uint32
+	Dropped     uint32
+	Array       uint32
+	Resv1       uint32
+	UserAddr    uint64
+}
+
+// ringCqOffsets represents the C struct io_cqring_offsets.
+type ringCqOffsets struct {
+	Head        uint32
+	Tail        uint32
+	RingMask    uint32
+	RingEntries uint32
+	Overflow    uint32
+	Cqes        uint32
+	Flags       uint32
+	Resv1       uint32
+	UserAddr    uint64
+}
+
+// ringSqe represents the C struct io_uring_sqe.
+type ringSqe struct {
+	Opcode      uint8
+	Flags       uint8
+	Ioprio      uint16
+	Fd          int32
+	Off         uint64
+	Addr        uint64
+	Len         uint32
+	OpFlags     uint32
+	UserData    uint64
+	BufGroup    uint16
+	Personality uint16
+	FileIndex   uint32
+	Addr3       uint64
+	Pad         uint64
+}
+
+// ringCqe represents the C struct io_uring_cqe.
+type ringCqe struct {
+	UserData uint64
+	Res      int32
+	Flags    uint32
+}
+
+// ringBufReg represents the C struct io_uring_buf_reg.
+type ringBufReg struct {
+	RingAddr    uint64
+	RingEntries uint32
+	Bgid        uint16
+	Flags       uint16
+	Resv        [3]uint64
+}
+
+// ringBuf represents the C struct io_uring_buf, the tail of a buffer ring overlays the
+// Resv field of its first entry.
+type ringBuf struct {
+	Addr uint64
+	Len  uint32
+	Bid  uint16
+	Resv uint16
+}
+
+// ringRecvmsgOut represents the C struct io_uring_recvmsg_out heading multishot buffers.
+type ringRecvmsgOut struct {
+	Namelen    uint32
+	Controllen uint32
+	Payloadlen uint32
+	Flags      uint32
+}
+
+const (
+	ringRecvmsgOutSize = int(unsafe.Sizeof(ringRecvmsgOut{}))
+	ringWakeup         = ^uint64(0)
+)
+
+// ringOp holds a submitted operation, keeping the memory the kernel refers to alive
+// until its completion.
+type ringOp struct {
+	fd        int
+	header    unix.Msghdr
+	iovec     unix.Iovec
+	control   controlBuffer
+	data      []byte
+	send      SendHandler
+	recv      RecvHandler
+	multishot *ringBuffers
+	// size is the message size of a multishot receive and delivered whether it received
+	// a message yet.
+	size      int
+	delivered bool
+}
+
+// ringBuffers is the provided buffer ring of a multishot receive.
+type ringBuffers struct {
+	bgid    uint16
+	size    int
+	count   int
+	ring    []byte
+	buffers []byte
+	tail    uint16
+}
+
+// ringEngine implements Engine with io_uring.
+type ringEngine struct {
+	fd        int
+	mutex     sync.Mutex
+	sqRing    []byte
+	cqRing    []byte
+	sqes      []ringSqe
+	sqHead    *uint32
+	sqTail    *uint32
+	sqMask    uint32
+	sqEntries uint32
+	sqArray   []uint32
+	cqHead    *uint32
+	cqTail    *uint32
+	cqMask    uint32
+	cqEntries uint32
+	cqes      []ringCqe
+	pending   map[uint64]*ringOp
+	sequence  uint64
+	bgid      uint16
+	multishot atomic.Int32
+	running   chan struct{}
+	waiting   bool
+	closed    atomic.Bool
+	enters    atomic.Uint64
+}
+
+// NewRingEngine creates an io_uring engine with room for entries submissions. It returns
+// syscall.ENOSYS or syscall.EPERM when the kernel lacks io_uring or it is disabled.
+func NewRingEngine(entries int) (Engine, error) {
+	if entries <= 0 {
+		entries = DefaultRingEntries
+	}
+	var params ringParams
+	params.Flags = IORING_SETUP_CLAMP
+	fd, _, errno := syscall.Syscall(
+		unix.SYS_IO_URING_SETUP,
+		uintptr(entries),
+		uintptr(unsafe.Pointer(&params)),
+		0,
+	)
+	if errno != 0 {
+		return nil, errno
+	}
+	e := &ringEngine{
+		fd:      int(fd),
+		pending: make(map[uint64]*ringOp),
+	}
+	if err := e.mmap(&params); err != nil {
+		e.release()
+		return nil, err
+	}
+	// Multishot receive is probed on first use, -1 unknown, 0 unsupported, 1 supported.
+	e.multishot.Store(-1)
+	return e, nil
+}
+
+// mmap maps the submission and completion rings of the engine.
+func (e *ringEngine) mmap(params *ringParams) error {
+	var err error
+	size := int(params.SqOff.Array) + int(params.SqEntries)*4
+	if e.sqRing, err = syscall.Mmap(e.fd, IORING_OFF_SQ_RING, size,
+		syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED|syscall.MAP_POPULATE); err != nil {
+		return err
+	}
+	size = int(params.CqOff.Cqes) + int(params.CqEntries)*int(unsafe.Sizeof(ringCqe{}))
+	if e.cqRing, err = syscall.Mmap(e.fd, IORING_OFF_CQ_RING, size,
+		syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED|syscall.MAP_POPULATE); err != nil {
+		return err
+	}
+	size = int(params.SqEntries) * int(unsafe.Sizeof(ringSqe{}))
+	sqes, err := syscall.Mmap(e.fd, IORING_OFF_SQES, size,
+		syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED|syscall.MAP_POPULATE)
+	if err != nil {
+		return err
+	}
+	sq := unsafe.Pointer(&e.sqRing[0])
+	cq := unsafe.Pointer(&e.cqRing[0])
+	e.sqes = unsafe.Slice((*ringSqe)(unsafe.Pointer(&sqes[0])), params.SqEntries)
+	e.sqHead = (*uint32)(unsafe.Add(sq, params.SqOff.Head))
+	e.sqTail = (*uint32)(unsafe.Add(sq, params.SqOff.Tail))
+	e.sqMask = *(*uint32)(unsafe.Add(sq, params.SqOff.RingMask))
+	e.sqEntries = *(*uint32)(unsafe.Add(sq, params.SqOff.RingEntries))
+	e.sqArray = unsafe.Slice((*uint32)(unsafe.Add(sq, params.SqOff.Array)), params.SqEntries)
+	e.cqHead = (*uint32)(unsafe.Add(cq, params.CqOff.Head))
+	e.cqTail = (*uint32)(unsafe.Add(cq, params.CqOff.Tail))
+	e.cqMask = *(*uint32)(unsafe.Add(cq, params.CqOff.RingMask))
+	e.cqEntries = *(*uint32)(unsafe.Add(cq, params.CqOff.RingEntries))
+	e.cqes = unsafe.Slice((*ringCqe)(unsafe.Add(cq, params.CqOff.Cqes)), params.CqEntries)
+	return nil
+}
+
+func (e *ringEngine) Backend() string {
+	return "io_uring"
+}
+
+// enter calls io_uring_enter, retrying on EINTR.
+func (e *ringEngine) enter(submit, complete, flags uint32) error {
+	e.enters.Add(1)
+	for {
+		_, _, errno := syscall.Syscall6(
+			unix.SYS_IO_URING_ENTER,
+			uintptr(e.fd),
+			uintptr(submit),
+			uintptr(complete),
+			uintptr(flags),
+			0,
+			0,
+		)
+		if errno == syscall.EINTR {
+			continue
+		}
+		if errno != 0 {
+			return errno
+		}
+		return nil
+	}
+}
+
+// submit queues an operation, fill prepares its submission entry. A nil op is not tracked
+// and its completion is ignored.
+func (e *ringEngine) submit(op *ringOp, fill func(sqe *ringSqe)) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.submitLocked(op, fill)
+}
+
+// submitLocked is submit with the mutex held. The entries queued while Run is reaping
+// completions are submitted together by its next io_uring_enter, the queue is only
+// flushed right away when Run is waiting, is not running or the queue is full.
+func (e *ringEngine) submitLocked(op *ringOp, fill func(sqe *ringSqe)) error {
+	if e.closed.Load() {
+		return errors.New("engine closed")
+	}
+	if op != nil && uint32(len(e.pending)) >= e.cqEntries {
+		return syscall.EBUSY
+	}
+	tail := *e.sqTail
+	if tail-atomic.LoadUint32(e.sqHead) >= e.sqEntries {
+		if err := e.flushLocked(); err != nil {
+			return err
+		}
+		if tail-atomic.LoadUint32(e.sqHead) >= e.sqEntries {
+			return syscall.EBUSY
+		}
+	}
+	index := tail & e.sqMask
+	sqe := &e.sqes[index]
+	*sqe = ringSqe{}
+	fill(sqe)
+	if op != nil {
+		e.sequence++
+		sqe.UserData = e.sequence
+		e.pending[e.sequence] = op
+	} else if sqe.UserData == 0 {
+		sqe.UserData = ringWakeup
+	}
+	e.sqArray[index] = index
+	atomic.StoreUint32(e.sqTail, tail+1)
+	if e.waiting || e.running == nil {
+		// A failed flush leaves the entries queued for the next one.
+		_ = e.flushLocked()
+	}
+	return nil
+}
+
+// unsubmitted returns the number of queued submission entries the kernel has not consumed.
+func (e *ringEngine) unsubmitted() uint32 {
+	return atomic.LoadUint32(e.sqTail) - atomic.LoadUint32(e.sqHead)
+}
+
+// flushLocked submits the queued entries, the caller must hold the mutex.
+func (e *ringEngine) flushLocked() error {
+	if pending := e.unsubmitted(); pending > 0 {
+		return e.enter(pending, 0, 0)
+	}
+	return nil
+}
+
+func (e *ringEngine) SendMsg(fd int, b []byte, info *SCTPSndRcvInfo, h SendHandler) error {
+	if h == nil {
+		return errors.New("handler cannot be nil")
+	}
+	op := &ringOp{
+		fd:   fd,
+		data: b,
+		send: h,
+	}
+	op.prepare(info)
+	return e.submit(op, func(sqe *ringSqe) {
+		sqe.Opcode = IORING_OP_SENDMSG
+		sqe.Fd = int32(fd)
+		sqe.Addr = uint64(uintptr(unsafe.Pointer(&op.header)))
+		sqe.Len = 1
+	})
+}
+
+func (e *ringEngine) RecvMsg(fd int, b []byte, h RecvHandler) error {
+	if h == nil {
+		return errors.New("handler cannot be nil")
+	}
+	op := &ringOp{
+		fd:   fd,
+		data: b,
+		recv: h,
+	}
+	op.prepare(nil)
+	return e.submit(op, func(sqe *ringSqe) {
+		sqe.Opcode = IORING_OP_RECVMSG
+		sqe.Fd = int32(fd)
+		sqe.Addr = uint64(uintptr(unsafe.Pointer(&op.header)))
+		sqe.Len = 1
+	})
+}
+
+// prepare points the message header of the operation at its data and control buffers.
+func (op *ringOp) prepare(info *SCTPSndRcvInfo) {
+	if len(op.data) > 0 {
+		op.iovec.Base = &op.data[0]
+		op.iovec.SetLen(len(op.data))
+		op.header.Iov = &op.iovec
+		op.header.SetIovlen(1)
+	}
+	control := op.control.bytes()
+	if op.send != nil {
+		control = control[:EncodeSndRcvInfo(control, info)]
+	}
+	if len(control) > 0 {
+		op.header.Control = &control[0]
+		op.header.SetControllen(len(control))
+	}
+}
+
+// RecvMultishot uses a multishot receive with a provided buffer ring where the kernel
+// supports it, and otherwise re-submits a single receive after each completion.
+func (e *ringEngine) RecvMultishot(fd int, size int, h RecvHandler) error {
+	if h == nil {
+		return errors.New("handler cannot be nil")
+	}
+	if size <= 0 {
+		return syscall.EINVAL
+	}
+	if e.multishot.Load() != 0 {
+		buffers, err := e.register(size+ringRecvmsgOutSize+syscall.CmsgSpace(SCTPSndRcvInfoSize), DefaultMultishotBuffers)
+		switch {
+		case err == nil:
+			e.multishot.Store(1)
+			op := &ringOp{
+				fd:        fd,
+				recv:      h,
+				multishot: buffers,
+				size:      size,
+			}
+			op.header.SetControllen(syscall.CmsgSpace(SCTPSndRcvInfoSize))
+			if err := e.submit(op, op.fillMultishot); err != nil {
+				e.unregister(buffers)
+				return err
+			}
+			return nil
+		case err == syscall.EINVAL:
+			e.multishot.Store(0)
+		default:
+			return err
+		}
+	}
+	return e.recvResubmit(fd, size, h)
+}
+
+// recvResubmit emulates a multishot receive by submitting a single receive again after
+// each message.
+func (e *ringEngine) recvResubmit(fd int, size int, h RecvHandler) error {
+	buffer := make([]byte, size)
+	var resubmit RecvHandler
+	resubmit = func(b []byte, info *SCTPSndRcvInfo, flags int, err error) {
+		h(b, info, flags, err)
+		if err == nil && len(b) > 0 {
+			if err := e.RecvMsg(fd, buffer, resubmit); err != nil {
+				h(nil, nil, 0, err)
+			}
+		}
+	}
+	return e.RecvMsg(fd, buffer, resubmit)
+}
+
+// fillMultishot prepares the submission entry of a multishot receive.
+func (op *ringOp) fillMultishot(sqe *ringSqe) {
+	sqe.Opcode = IORING_OP_RECVMSG
+	sqe.Fd = int32(op.fd)
+	sqe.Addr = uint64(uintptr(unsafe.Pointer(&op.header)))
+	sqe.Len = 1
+	sqe.Flags = IOSQE_BUFFER_SELECT
+	sqe.Ioprio = IORING_RECV_MULTISHOT
+	sqe.BufGroup = op.multishot.bgid
+}
+
+// register creates and registers a provided buffer ring of count buffers.
+func (e *ringEngine) register(size, count int) (*ringBuffers, error) {
+	ring, err := syscall.Mmap(-1, 0, count*int(unsafe.Sizeof(ringBuf{})),
+		syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, err
+	}
+	buffers, err := syscall.Mmap(-1, 0, count*size,
+		syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		_ = syscall.Munmap(ring)
+		return nil, err
+	}
+	e.mutex.Lock()
+	e.bgid++
+	b := &ringBuffers{
+		bgid:    e.bgid,
+		size:    size,
+		count:   count,
+		ring:    ring,
+		buffers: buffers,
+	}
+	e.mutex.Unlock()
+	reg := ringBufReg{
+		RingAddr:    uint64(uintptr(unsafe.Pointer(&ring[0]))),
+		RingEntries: uint32(count),
+		Bgid:        b.bgid,
+	}
+	_, _, errno := syscall.Syscall6(
+		unix.SYS_IO_URING_REGISTER,
+		uintptr(e.fd),
+		IORING_REGISTER_PBUF_RING,
+		uintptr(unsafe.Pointer(&reg)),
+		1,
+		0,
+		0,
+	)
+	if errno != 0 {
+		_ = syscall.Munmap(ring)
+		_ = syscall.Munmap(buffers)
+		return nil, errno
+	}
+	for i := 0; i < count; i++ {
+		b.recycle(uint16(i))
+	}
+	return b, nil
+}
+
+// unregister removes a provided buffer ring from the kernel and unmaps it.
+func (e *ringEngine) unregister(b *ringBuffers) {
+	reg := ringBufReg{
+		Bgid: b.bgid,
+	}
+	_, _, _ = syscall.Syscall6(
+		unix.SYS_IO_URING_REGISTER,
+		uintptr(e.fd),
+		IORING_UNREGISTER_PBUF_RING,
+		uintptr(unsafe.Pointer(&reg)),
+		1,
+		0,
+		0,
+	)
+	_ = syscall.Munmap(b.ring)
+	_ = syscall.Munmap(b.buffers)
+}
+
+// buffer returns the provided buffer with the given id.
+func (b *ringBuffers) buffer(id uint16) []byte {
+	return b.buffers[int(id)*b.size : (int(id)+1)*b.size]
+}
+
+// recycle hands the buffer with the given id back to the kernel.
+func (b *ringBuffers) recycle(id uint16) {
+	var (
+		base  = unsafe.Pointer(&b.ring[0])
+		entry = (*ringBuf)(unsafe.Add(base, uintptr(int(b.tail)&(b.count-1))*unsafe.Sizeof(ringBuf{})))
+		data  = b.buffer(id)
+	)
+	entry.Addr = uint64(uintptr(unsafe.Pointer(&data[0])))
+	entry.Len = uint32(b.size)
+	entry.Bid = id
+	b.tail++
+	// The tail is the 16 bit Resv field of the first entry, it is published together with
+	// the Bid sharing its 32 bit word so the store has release semantics.
+	var (
+		word  = (*uint32)(unsafe.Add(base, 12))
+		bytes [4]byte
+	)
+	binary.NativeEndian.PutUint32(bytes[:], atomic.LoadUint32(word))
+	binary.NativeEndian.PutUint16(bytes[2:], b.tail)
+	atomic.StoreUint32(word, binary.NativeEndian.Uint32(bytes[:]))
+}
+
+func (e *ringEngine) Cancel(fd int) error {
+	return e.submit(nil, func(sqe *ringSqe) {
+		sqe.Opcode = IORING_OP_ASYNC_CANCEL
+		sqe.Fd = int32(fd)
+		sqe.OpFlags = IORING_ASYNC_CANCEL_FD | IORING_ASYNC_CANCEL_ALL
+	})
+}
+
+// Run reaps completions and invokes their handlers until ctx is cancelled or the engine
+// is closed. Only one Run may be active on an engine at a time.
+func (e *ringEngine) Run(ctx context.Context) error {
+	e.mutex.Lock()
+	if e.closed.Load() {
+		e.mutex.Unlock()
+		return errors.New("engine closed")
+	}
+	if e.running != nil {
+		e.mutex.Unlock()
+		return errors.New("engine already running")
+	}
+	running := make(chan struct{})
+	e.running = running
+	e.mutex.Unlock()
+
+	defer func() {
+		e.mutex.Lock()
+		e.running = nil
+		closed := e.closed.Load()
+		e.mutex.Unlock()
+		if closed {
+			e.release()
+		}
+		close(running)
+	}()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			e.wake()
+		case <-stop:
+		}
+	}()
+
+	for {
+		if e.closed.Load() {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// The entries queued since the last pass are submitted with the wait for completions.
+		e.mutex.Lock()
+		wait := atomic.LoadUint32(e.cqHead) == atomic.LoadUint32(e.cqTail)
+		pending := e.unsubmitted()
+		e.waiting = wait
+		e.mutex.Unlock()
+		var err error
+		switch {
+		case wait:
+			err = e.enter(pending, 1, IORING_ENTER_GETEVENTS)
+			e.mutex.Lock()
+			e.waiting = false
+			e.mutex.Unlock()
+		case pending > 0:
+			err = e.enter(pending, 0, 0)
+		}
+		if err != nil {
+			return err
+		}
+		head := atomic.LoadUint32(e.cqHead)
+		tail := atomic.LoadUint32(e.cqTail)
+		for ; head != tail; head++ {
+			cqe := e.cqes[head&e.cqMask]
+			atomic.StoreUint32(e.cqHead, head+1)
+			e.complete(cqe)
+		}
+	}
+}
+
+// complete invokes the handler of a completed operation.
+func (e *ringEngine) complete(cqe ringCqe) {
+	if cqe.UserData == ringWakeup {
+		return
+	}
+	more := cqe.Flags&IORING_CQE_F_MORE != 0
+	e.mutex.Lock()
+	op, ok := e.pending[cqe.UserData]
+	if ok && !more {
+		delete(e.pending, cqe.UserData)
+	}
+	e.mutex.Unlock()
+	if !ok {
+		return
+	}
+	var err error
+	if cqe.Res < 0 {
+		err = syscall.Errno(-cqe.Res)
+	}
+	switch {
+	case op.send != nil:
+		if err != nil {
+			op.send(0, err)
+			return
+		}
+		op.send(int(cqe.Res), nil)
+	case op.multishot != nil:
+		e.completeMultishot(op, cqe, err, more)
+	default:
+		if err != nil {
+			op.recv(nil, nil, 0, err)
+			return
+		}
+		var info SCTPSndRcvInfo
+		if op.header.Controllen > 0 {
+			ParseSndRcvInfo(&info, op.control.bytes()[:op.header.Controllen])
+		}
+		op.recv(op.data[:cqe.Res], &info, int(op.header.Flags), nil)
+	}
+}
+
+// completeMultishot delivers a message received into a provided buffer and re-arms the
+// receive when the kernel ended it because it ran out of buffers.
+func (e *ringEngine) completeMultishot(op *ringOp, cqe ringCqe, err error, more bool) {
+	b := op.multishot
+	if cqe.Flags&IORING_CQE_F_BUFFER != 0 {
+		id := uint16(cqe.Flags >> IORING_CQE_BUFFER_SHIFT)
+		data := b.buffer(id)[:cqe.Res]
+		if len(data) >= ringRecvmsgOutSize {
+			var (
+				out     = (*ringRecvmsgOut)(unsafe.Pointer(&data[0]))
+				control = syscall.CmsgSpace(SCTPSndRcvInfoSize)
+				offset  = ringRecvmsgOutSize + control
+				info    SCTPSndRcvInfo
+			)
+			if out.Controllen > 0 && int(out.Controllen) <= control {
+				ParseSndRcvInfo(&info, data[ringRecvmsgOutSize:ringRecvmsgOutSize+int(out.Controllen)])
+			}
+			payload := data[offset:]
+			if int(out.Payloadlen) < len(payload) {
+				payload = payload[:out.Payloadlen]
+			}
+			op.delivered = true
+			op.recv(payload, &info, int(out.Flags), nil)
+		}
+		b.recycle(id)
+	}
+	if more {
+		return
+	}
+	switch {
+	case err == syscall.EINVAL && !op.delivered:
+		// Kernels from 5.19 register buffer rings but only 6.0 supports multishot recvmsg.
+		e.multishot.Store(0)
+		e.unregister(b)
+		if err = e.recvResubmit(op.fd, op.size, op.recv); err == nil {
+			return
+		}
+		op.recv(nil, nil, 0, err)
+		return
+	case err == syscall.ENOBUFS || err == nil && cqe.Flags&IORING_CQE_F_BUFFER != 0:
+		// The kernel ended the receive after running out of buffers, it is re-armed. A
+		// completion without a buffer nor an error is the end of the stream.
+		e.mutex.Lock()
+		serr := e.submitLocked(op, op.fillMultishot)
+		e.mutex.Unlock()
+		if serr == nil {
+			return
+		}
+		err = serr
+	}
+	e.unregister(b)
+	op.recv(nil, nil, 0, err)
+}
+
+// wake interrupts a blocking wait of Run with a no-op completion.
+func (e *ringEngine) wake() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.wakeLocked()
+}
+
+// wakeLocked is wake with the mutex held, it does nothing once the rings are released.
+func (e *ringEngine) wakeLocked() {
+	if e.sqes == nil {
+		return
+	}
+	tail := *e.sqTail
+	if tail-atomic.LoadUint32(e.sqHead) >= e.sqEntries {
+		return
+	}
+	index := tail & e.sqMask
+	e.sqes[index] = ringSqe{
+		Opcode:   IORING_OP_NOP,
+		UserData: ringWakeup,
+	}
+	e.sqArray[index] = index
+	atomic.StoreUint32(e.sqTail, tail+1)
+	_ = e.flushLocked()
+}
+
+// Close closes the engine. A running Run returns once the handler it is running, if any,
+// returned and releases the rings itself, Close does not wait for it.
+func (e *ringEngine) Close() error {
+	e.mutex.Lock()
+	if e.closed.Swap(true) {
+		e.mutex.Unlock()
+		return nil
+	}
+	running := e.running != nil
+	if running {
+		e.wakeLocked()
+	}
+	e.mutex.Unlock()
+	if !running {
+		e.release()
+	}
+	return nil
+}
+
+// release unmaps the rings and closes the io_uring descriptor. The handlers of the
+// operations still pending are invoked with syscall.ECANCELED.
+func (e *ringEngine) release() {
+	e.mutex.Lock()
+	pending := e.pending
+	defer func() {
+		e.mutex.Unlock()
+		for _, op := range pending {
+			if op.send != nil {
+				op.send(0, syscall.ECANCELED)
+			} else {
+				op.recv(nil, nil, 0, syscall.ECANCELED)
+			}
+		}
+	}()
+	for _, op := range e.pending {
+		if op.multishot != nil {
+			e.unregister(op.multishot)
+		}
+	}
+	e.pending = nil
+	if e.sqes != nil {
+		_ = syscall.Munmap(unsafe.Slice((*byte)(unsafe.Pointer(&e.sqes[0])), len(e.sqes)*int(unsafe.Sizeof(ringSqe{}))))
+		e.sqes = nil
+	}
+	if e.cqRing != nil {
+		_ = syscall.Munmap(e.cqRing)
+		e.cqRing = nil
+	}
+	if e.sqRing != nil {
+		_ = syscall.Munmap(e.sqRing)
+		e.sqRing = nil
+	}
+	if e.fd >= 0 {
+		_ = syscall.Close(e.fd)
+		e.fd = -1
+	}
+}